@@ -5,6 +5,7 @@ go 1.13
 require (
 	cloud.google.com/go v0.49.0
 	github.com/GoogleCloudPlatform/devrel-services/drghs v0.0.0-20191204181555-5cde750c6624
+	github.com/golang/protobuf v1.4.2
 	github.com/sirupsen/logrus v1.4.2
 	google.golang.org/grpc v1.27.1
 )
@@ -16,6 +16,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"net"
@@ -29,6 +30,7 @@ import (
 	"cloud.google.com/go/errorreporting"
 	"cloud.google.com/go/profiler"
 
+	"github.com/golang/protobuf/ptypes"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -159,6 +161,89 @@ func (s *adminServer) UpdateTrackedRepos(ctx context.Context, r *drghs_v1.Update
 	return &drghs_v1.UpdateTrackedReposResponse{}, nil
 }
 
+// supervisorStatus is the status a supervisor serves on /status.
+type supervisorStatus struct {
+	LastUpdate time.Time `json:"last_update"`
+	LastResult string    `json:"last_result"`
+	Blocked    string    `json:"blocked"`
+	Workloads  []struct {
+		Repository    string   `json:"repository"`
+		Tracked       bool     `json:"tracked"`
+		Deployment    string   `json:"deployment"`
+		Service       string   `json:"service"`
+		Process       string   `json:"process"`
+		Images        []string `json:"images"`
+		Replicas      int32    `json:"replicas"`
+		ReadyReplicas int32    `json:"ready_replicas"`
+		Restarts      int32    `json:"restarts"`
+	} `json:"workloads"`
+}
+
+func (s *adminServer) GetSupervisorStatus(ctx context.Context, r *drghs_v1.GetSupervisorStatusRequest) (*drghs_v1.GetSupervisorStatusResponse, error) {
+	resp := &drghs_v1.GetSupervisorStatusResponse{}
+	for _, spr := range []struct{ name, address string }{
+		{"maintnerd-sprvsr", *mtrSpr},
+		{"samplrd-sprvsr", *smpSpr},
+	} {
+		log.Debugf("getting status of %v", spr.name)
+		st, err := getSupervisorStatus(ctx, spr.address)
+		if err != nil {
+			log.Errorf("could not get status of %v: %v", spr.name, err)
+			// One supervisor being down should not hide the other
+			st = &drghs_v1.SupervisorStatus{Error: err.Error()}
+		}
+		st.Name = spr.name
+		resp.Supervisors = append(resp.Supervisors, st)
+	}
+	return resp, nil
+}
+
+// getSupervisorStatus gets the status of the supervisor at address.
+func getSupervisorStatus(ctx context.Context, address string) (*drghs_v1.SupervisorStatus, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("http://%s/status", address), nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("getting status: %v", res.Status)
+	}
+
+	var st supervisorStatus
+	if err := json.NewDecoder(res.Body).Decode(&st); err != nil {
+		return nil, err
+	}
+
+	ret := &drghs_v1.SupervisorStatus{
+		LastResult: st.LastResult,
+		Blocked:    st.Blocked,
+	}
+	if !st.LastUpdate.IsZero() {
+		ret.LastReconcile, err = ptypes.TimestampProto(st.LastUpdate)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, w := range st.Workloads {
+		ret.Workloads = append(ret.Workloads, &drghs_v1.ManagedWorkload{
+			Repository:    w.Repository,
+			Tracked:       w.Tracked,
+			Deployment:    w.Deployment,
+			Service:       w.Service,
+			Process:       w.Process,
+			Images:        w.Images,
+			Replicas:      w.Replicas,
+			ReadyReplicas: w.ReadyReplicas,
+			Restarts:      w.Restarts,
+		})
+	}
+	return ret, nil
+}
+
 func unaryInterceptorLog(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 	start := time.Now()
 	log.Tracef("Starting RPC: %v at %v", info.FullMethod, start)
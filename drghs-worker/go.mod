@@ -19,7 +19,7 @@ require (
 	cloud.google.com/go/storage v1.10.0
 	github.com/GoogleCloudPlatform/devrel-services/drghs v0.0.0-20200730153546-93a9c4fcaf2c
 	github.com/GoogleCloudPlatform/devrel-services/repos v0.0.0
-	github.com/GoogleCloudPlatform/devrel-services/rtr v0.0.0 // indirect
+	github.com/GoogleCloudPlatform/devrel-services/rtr v0.0.0
 	github.com/GoogleCloudPlatform/devrel-services/sprvsr v0.0.0
 	github.com/GoogleCloudPlatform/opentelemetry-operations-go/exporter/trace v0.2.1
 	github.com/aclements/go-gg v0.0.0-20170323211221-abd1f791f5ee // indirect
@@ -61,6 +61,7 @@ require (
 	github.com/gonum/matrix v0.0.0-20181209220409-c518dec07be9 // indirect
 	github.com/google/cel-go v0.5.1
 	github.com/google/go-cmp v0.5.1
+	github.com/google/go-github v17.0.0+incompatible
 	github.com/gorilla/mux v1.7.2
 	github.com/gorilla/schema v1.1.0 // indirect
 	github.com/gorilla/websocket v1.4.1 // indirect
@@ -80,6 +81,7 @@ require (
 	github.com/nbutton23/zxcvbn-go v0.0.0-20180912185939-ae427f1e4c1d // indirect
 	github.com/pkg/sftp v1.11.0 // indirect
 	github.com/remyoudompheng/bigfft v0.0.0-20190728182440-6a916e37a237 // indirect
+	github.com/robfig/cron/v3 v3.0.1
 	github.com/rogpeppe/go-charset v0.0.0-20190617161244-0dc95cdf6f31 // indirect
 	github.com/rwcarlsen/goexif v0.0.0-20190401172101-9e8deecbddbd // indirect
 	github.com/shurcooL/githubv4 v0.0.0-20191102174205-af46314aec7b
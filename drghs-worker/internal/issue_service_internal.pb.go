@@ -110,51 +110,290 @@ func (m *TombstoneIssuesResponse) GetTombstonedCount() int32 {
 	return 0
 }
 
+type IssueTransfer struct {
+	IssueNumber int32 `protobuf:"varint,1,opt,name=issue_number,json=issueNumber,proto3" json:"issue_number,omitempty"`
+	// The name of the issue it was transferred to, in the format
+	// `owner/repository/issues/N`.
+	TransferredTo        string   `protobuf:"bytes,2,opt,name=transferred_to,json=transferredTo,proto3" json:"transferred_to,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *IssueTransfer) Reset()         { *m = IssueTransfer{} }
+func (m *IssueTransfer) String() string { return proto.CompactTextString(m) }
+func (*IssueTransfer) ProtoMessage()    {}
+func (*IssueTransfer) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9459a83d0b98bca9, []int{2}
+}
+
+func (m *IssueTransfer) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_IssueTransfer.Unmarshal(m, b)
+}
+func (m *IssueTransfer) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_IssueTransfer.Marshal(b, m, deterministic)
+}
+func (m *IssueTransfer) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IssueTransfer.Merge(m, src)
+}
+func (m *IssueTransfer) XXX_Size() int {
+	return xxx_messageInfo_IssueTransfer.Size(m)
+}
+func (m *IssueTransfer) XXX_DiscardUnknown() {
+	xxx_messageInfo_IssueTransfer.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_IssueTransfer proto.InternalMessageInfo
+
+func (m *IssueTransfer) GetIssueNumber() int32 {
+	if m != nil {
+		return m.IssueNumber
+	}
+	return 0
+}
+
+func (m *IssueTransfer) GetTransferredTo() string {
+	if m != nil {
+		return m.TransferredTo
+	}
+	return ""
+}
+
+type RecordTransfersRequest struct {
+	Parent               string           `protobuf:"bytes,1,opt,name=parent,proto3" json:"parent,omitempty"`
+	Transfers            []*IssueTransfer `protobuf:"bytes,2,rep,name=transfers,proto3" json:"transfers,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *RecordTransfersRequest) Reset()         { *m = RecordTransfersRequest{} }
+func (m *RecordTransfersRequest) String() string { return proto.CompactTextString(m) }
+func (*RecordTransfersRequest) ProtoMessage()    {}
+func (*RecordTransfersRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9459a83d0b98bca9, []int{3}
+}
+
+func (m *RecordTransfersRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RecordTransfersRequest.Unmarshal(m, b)
+}
+func (m *RecordTransfersRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RecordTransfersRequest.Marshal(b, m, deterministic)
+}
+func (m *RecordTransfersRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RecordTransfersRequest.Merge(m, src)
+}
+func (m *RecordTransfersRequest) XXX_Size() int {
+	return xxx_messageInfo_RecordTransfersRequest.Size(m)
+}
+func (m *RecordTransfersRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RecordTransfersRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RecordTransfersRequest proto.InternalMessageInfo
+
+func (m *RecordTransfersRequest) GetParent() string {
+	if m != nil {
+		return m.Parent
+	}
+	return ""
+}
+
+func (m *RecordTransfersRequest) GetTransfers() []*IssueTransfer {
+	if m != nil {
+		return m.Transfers
+	}
+	return nil
+}
+
+type RecordTransfersResponse struct {
+	RecordedCount        int32    `protobuf:"varint,1,opt,name=recorded_count,json=recordedCount,proto3" json:"recorded_count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RecordTransfersResponse) Reset()         { *m = RecordTransfersResponse{} }
+func (m *RecordTransfersResponse) String() string { return proto.CompactTextString(m) }
+func (*RecordTransfersResponse) ProtoMessage()    {}
+func (*RecordTransfersResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9459a83d0b98bca9, []int{4}
+}
+
+func (m *RecordTransfersResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RecordTransfersResponse.Unmarshal(m, b)
+}
+func (m *RecordTransfersResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RecordTransfersResponse.Marshal(b, m, deterministic)
+}
+func (m *RecordTransfersResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RecordTransfersResponse.Merge(m, src)
+}
+func (m *RecordTransfersResponse) XXX_Size() int {
+	return xxx_messageInfo_RecordTransfersResponse.Size(m)
+}
+func (m *RecordTransfersResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RecordTransfersResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RecordTransfersResponse proto.InternalMessageInfo
+
+func (m *RecordTransfersResponse) GetRecordedCount() int32 {
+	if m != nil {
+		return m.RecordedCount
+	}
+	return 0
+}
+
+type RefetchIssuesRequest struct {
+	Parent               string   `protobuf:"bytes,1,opt,name=parent,proto3" json:"parent,omitempty"`
+	IssueNumbers         []int32  `protobuf:"varint,2,rep,packed,name=issue_numbers,json=issueNumbers,proto3" json:"issue_numbers,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RefetchIssuesRequest) Reset()         { *m = RefetchIssuesRequest{} }
+func (m *RefetchIssuesRequest) String() string { return proto.CompactTextString(m) }
+func (*RefetchIssuesRequest) ProtoMessage()    {}
+func (*RefetchIssuesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9459a83d0b98bca9, []int{5}
+}
+
+func (m *RefetchIssuesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RefetchIssuesRequest.Unmarshal(m, b)
+}
+func (m *RefetchIssuesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RefetchIssuesRequest.Marshal(b, m, deterministic)
+}
+func (m *RefetchIssuesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RefetchIssuesRequest.Merge(m, src)
+}
+func (m *RefetchIssuesRequest) XXX_Size() int {
+	return xxx_messageInfo_RefetchIssuesRequest.Size(m)
+}
+func (m *RefetchIssuesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RefetchIssuesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RefetchIssuesRequest proto.InternalMessageInfo
+
+func (m *RefetchIssuesRequest) GetParent() string {
+	if m != nil {
+		return m.Parent
+	}
+	return ""
+}
+
+func (m *RefetchIssuesRequest) GetIssueNumbers() []int32 {
+	if m != nil {
+		return m.IssueNumbers
+	}
+	return nil
+}
+
+type RefetchIssuesResponse struct {
+	RefetchedCount       int32    `protobuf:"varint,1,opt,name=refetched_count,json=refetchedCount,proto3" json:"refetched_count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RefetchIssuesResponse) Reset()         { *m = RefetchIssuesResponse{} }
+func (m *RefetchIssuesResponse) String() string { return proto.CompactTextString(m) }
+func (*RefetchIssuesResponse) ProtoMessage()    {}
+func (*RefetchIssuesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9459a83d0b98bca9, []int{6}
+}
+
+func (m *RefetchIssuesResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RefetchIssuesResponse.Unmarshal(m, b)
+}
+func (m *RefetchIssuesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RefetchIssuesResponse.Marshal(b, m, deterministic)
+}
+func (m *RefetchIssuesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RefetchIssuesResponse.Merge(m, src)
+}
+func (m *RefetchIssuesResponse) XXX_Size() int {
+	return xxx_messageInfo_RefetchIssuesResponse.Size(m)
+}
+func (m *RefetchIssuesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RefetchIssuesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RefetchIssuesResponse proto.InternalMessageInfo
+
+func (m *RefetchIssuesResponse) GetRefetchedCount() int32 {
+	if m != nil {
+		return m.RefetchedCount
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*TombstoneIssuesRequest)(nil), "maintner.internal.TombstoneIssuesRequest")
 	proto.RegisterType((*TombstoneIssuesResponse)(nil), "maintner.internal.TombstoneIssuesResponse")
+	proto.RegisterType((*IssueTransfer)(nil), "maintner.internal.IssueTransfer")
+	proto.RegisterType((*RecordTransfersRequest)(nil), "maintner.internal.RecordTransfersRequest")
+	proto.RegisterType((*RecordTransfersResponse)(nil), "maintner.internal.RecordTransfersResponse")
+	proto.RegisterType((*RefetchIssuesRequest)(nil), "maintner.internal.RefetchIssuesRequest")
+	proto.RegisterType((*RefetchIssuesResponse)(nil), "maintner.internal.RefetchIssuesResponse")
 }
 
 func init() { proto.RegisterFile("issue_service_internal.proto", fileDescriptor_9459a83d0b98bca9) }
 
 var fileDescriptor_9459a83d0b98bca9 = []byte{
-	// 212 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x92, 0xc9, 0x2c, 0x2e, 0x2e,
-	0x4d, 0x8d, 0x2f, 0x4e, 0x2d, 0x2a, 0xcb, 0x4c, 0x4e, 0x8d, 0xcf, 0xcc, 0x2b, 0x49, 0x2d, 0xca,
-	0x4b, 0xcc, 0xd1, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x12, 0xcc, 0x4d, 0xcc, 0xcc, 0x2b, 0xc9,
-	0x4b, 0x2d, 0xd2, 0x83, 0x49, 0x28, 0x85, 0x72, 0x89, 0x85, 0xe4, 0xe7, 0x26, 0x15, 0x97, 0xe4,
-	0xe7, 0xa5, 0x7a, 0x82, 0xf4, 0x16, 0x07, 0xa5, 0x16, 0x96, 0xa6, 0x16, 0x97, 0x08, 0x89, 0x71,
-	0xb1, 0x15, 0x24, 0x16, 0xa5, 0xe6, 0x95, 0x48, 0x30, 0x2a, 0x30, 0x6a, 0x70, 0x06, 0x41, 0x79,
-	0x42, 0xca, 0x5c, 0xbc, 0x10, 0x4b, 0xf2, 0x4a, 0x73, 0x93, 0x52, 0x8b, 0x8a, 0x25, 0x98, 0x14,
-	0x98, 0x35, 0x58, 0x83, 0x78, 0xc0, 0x82, 0x7e, 0x10, 0x31, 0x25, 0x17, 0x2e, 0x71, 0x0c, 0x63,
-	0x8b, 0x0b, 0xf2, 0xf3, 0x8a, 0x53, 0x85, 0x34, 0xb9, 0x04, 0x4a, 0x60, 0x52, 0x29, 0xf1, 0xc9,
-	0xf9, 0xa5, 0x50, 0x1b, 0x58, 0x83, 0xf8, 0x11, 0xe2, 0xce, 0x20, 0x61, 0xa3, 0x26, 0x46, 0x2e,
-	0x11, 0x4f, 0xa8, 0x4b, 0xc1, 0xa6, 0x04, 0x43, 0xfc, 0x25, 0x94, 0xc5, 0xc5, 0x8f, 0x66, 0xbc,
-	0x90, 0xa6, 0x1e, 0x86, 0xe7, 0xf4, 0xb0, 0xfb, 0x4c, 0x4a, 0x8b, 0x18, 0xa5, 0x10, 0xd7, 0x2a,
-	0x31, 0x24, 0xb1, 0x81, 0xc3, 0xce, 0x18, 0x10, 0x00, 0x00, 0xff, 0xff, 0xd7, 0x7d, 0x9d, 0x66,
-	0x5b, 0x01, 0x00, 0x00,
+	// 364 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x53, 0xbd, 0x6e, 0xf2, 0x40,
+	0x10, 0xfc, 0xe0, 0x13, 0x48, 0x2c, 0x18, 0x92, 0x13, 0x01, 0x84, 0x52, 0x10, 0x47, 0x88, 0x9f,
+	0xc2, 0x05, 0xe9, 0x23, 0xa4, 0xa4, 0xa1, 0x49, 0x61, 0x48, 0x91, 0xca, 0x32, 0xf6, 0xa2, 0x38,
+	0x0a, 0x77, 0xce, 0xdd, 0x39, 0x4f, 0x9a, 0x07, 0x8a, 0xb8, 0x3b, 0xf3, 0x63, 0x5b, 0x82, 0x22,
+	0xa5, 0x67, 0x77, 0x67, 0xe6, 0x66, 0xd7, 0x70, 0x1b, 0x09, 0x91, 0xa0, 0x27, 0x90, 0x7f, 0x47,
+	0x01, 0x7a, 0x11, 0x95, 0xc8, 0xa9, 0xff, 0xe9, 0xc4, 0x9c, 0x49, 0x46, 0xae, 0xb7, 0x7e, 0x44,
+	0x25, 0x45, 0xee, 0xa4, 0x05, 0xfb, 0x15, 0x3a, 0x2b, 0xb6, 0x5d, 0x0b, 0xc9, 0x28, 0x2e, 0x76,
+	0xb3, 0xc2, 0xc5, 0xaf, 0x04, 0x85, 0x24, 0x1d, 0xa8, 0xc6, 0x3e, 0x47, 0x2a, 0x7b, 0xa5, 0x41,
+	0x69, 0x5c, 0x73, 0xcd, 0x17, 0xb9, 0x07, 0x4b, 0x8b, 0xd0, 0x64, 0xbb, 0x46, 0x2e, 0x7a, 0xe5,
+	0xc1, 0xff, 0x71, 0xc5, 0x6d, 0x28, 0xf0, 0x45, 0x63, 0xf6, 0x33, 0x74, 0x73, 0xb4, 0x22, 0x66,
+	0x54, 0x20, 0x99, 0xc0, 0x95, 0x4c, 0x4b, 0xa1, 0x17, 0xb0, 0xc4, 0x28, 0x54, 0xdc, 0xd6, 0x01,
+	0x7f, 0xda, 0xc1, 0xf6, 0x1b, 0x58, 0x6a, 0x78, 0xc5, 0x7d, 0x2a, 0x36, 0xc8, 0xc9, 0x1d, 0x34,
+	0x8e, 0xb5, 0xcd, 0x5c, 0xfd, 0x48, 0x9a, 0x0c, 0xa1, 0x29, 0x4d, 0x3b, 0xc7, 0xd0, 0x93, 0xac,
+	0x57, 0x56, 0xf6, 0xad, 0x23, 0x74, 0xc5, 0xec, 0x18, 0x3a, 0x2e, 0x06, 0x8c, 0x87, 0x29, 0xf7,
+	0xd9, 0x77, 0x3f, 0x42, 0x2d, 0xa5, 0xd0, 0x6f, 0xae, 0xcf, 0x06, 0x4e, 0x2e, 0x50, 0xe7, 0xc4,
+	0xb0, 0x7b, 0x18, 0xb1, 0xe7, 0xd0, 0xcd, 0x29, 0x9a, 0x48, 0x86, 0xd0, 0xe4, 0xaa, 0x94, 0x09,
+	0xc4, 0x4a, 0x51, 0x1d, 0xc7, 0x12, 0xda, 0x2e, 0x6e, 0x50, 0x06, 0xef, 0x7f, 0xb8, 0xa9, 0x39,
+	0xdc, 0x64, 0x48, 0x8d, 0xa9, 0x11, 0xb4, 0xb8, 0x2e, 0x64, 0x5c, 0x35, 0xf7, 0xb0, 0xb2, 0x35,
+	0xfb, 0x29, 0x43, 0x7b, 0x61, 0x9e, 0xaf, 0x38, 0x96, 0xfa, 0xfa, 0xc8, 0x07, 0xb4, 0x32, 0x47,
+	0x40, 0x26, 0x05, 0x89, 0x15, 0xdf, 0x5f, 0x7f, 0x7a, 0x49, 0xab, 0xf6, 0x6a, 0xff, 0xdb, 0x69,
+	0x65, 0xd2, 0x2d, 0xd4, 0x2a, 0xde, 0x79, 0x7f, 0x7a, 0x49, 0xeb, 0x5e, 0x2b, 0x04, 0xeb, 0x24,
+	0x32, 0x32, 0x2a, 0x1c, 0xcf, 0x6f, 0xaa, 0x3f, 0x3e, 0xdf, 0x98, 0xaa, 0xac, 0xab, 0xea, 0x9f,
+	0x7d, 0xf8, 0x1d, 0x00, 0xed, 0x9e, 0xc6, 0x92, 0xd3, 0x03, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
 var _ context.Context
-var _ grpc.ClientConn
+var _ grpc.ClientConnInterface
 
 // This is a compile-time assertion to ensure that this generated file
 // is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+const _ = grpc.SupportPackageIsVersion6
 
 // InternalIssueServiceClient is the client API for InternalIssueService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type InternalIssueServiceClient interface {
 	TombstoneIssues(ctx context.Context, in *TombstoneIssuesRequest, opts ...grpc.CallOption) (*TombstoneIssuesResponse, error)
+	// Records where issues were transferred to and tombstones them.
+	RecordTransfers(ctx context.Context, in *RecordTransfersRequest, opts ...grpc.CallOption) (*RecordTransfersResponse, error)
+	// Fetches issues from GitHub again, replacing what the corpus has of them.
+	RefetchIssues(ctx context.Context, in *RefetchIssuesRequest, opts ...grpc.CallOption) (*RefetchIssuesResponse, error)
 }
 
 type internalIssueServiceClient struct {
-	cc *grpc.ClientConn
+	cc grpc.ClientConnInterface
 }
 
-func NewInternalIssueServiceClient(cc *grpc.ClientConn) InternalIssueServiceClient {
+func NewInternalIssueServiceClient(cc grpc.ClientConnInterface) InternalIssueServiceClient {
 	return &internalIssueServiceClient{cc}
 }
 
@@ -167,9 +406,31 @@ func (c *internalIssueServiceClient) TombstoneIssues(ctx context.Context, in *To
 	return out, nil
 }
 
+func (c *internalIssueServiceClient) RecordTransfers(ctx context.Context, in *RecordTransfersRequest, opts ...grpc.CallOption) (*RecordTransfersResponse, error) {
+	out := new(RecordTransfersResponse)
+	err := c.cc.Invoke(ctx, "/maintner.internal.InternalIssueService/RecordTransfers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *internalIssueServiceClient) RefetchIssues(ctx context.Context, in *RefetchIssuesRequest, opts ...grpc.CallOption) (*RefetchIssuesResponse, error) {
+	out := new(RefetchIssuesResponse)
+	err := c.cc.Invoke(ctx, "/maintner.internal.InternalIssueService/RefetchIssues", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // InternalIssueServiceServer is the server API for InternalIssueService service.
 type InternalIssueServiceServer interface {
 	TombstoneIssues(context.Context, *TombstoneIssuesRequest) (*TombstoneIssuesResponse, error)
+	// Records where issues were transferred to and tombstones them.
+	RecordTransfers(context.Context, *RecordTransfersRequest) (*RecordTransfersResponse, error)
+	// Fetches issues from GitHub again, replacing what the corpus has of them.
+	RefetchIssues(context.Context, *RefetchIssuesRequest) (*RefetchIssuesResponse, error)
 }
 
 // UnimplementedInternalIssueServiceServer can be embedded to have forward compatible implementations.
@@ -179,6 +440,12 @@ type UnimplementedInternalIssueServiceServer struct {
 func (*UnimplementedInternalIssueServiceServer) TombstoneIssues(ctx context.Context, req *TombstoneIssuesRequest) (*TombstoneIssuesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method TombstoneIssues not implemented")
 }
+func (*UnimplementedInternalIssueServiceServer) RecordTransfers(ctx context.Context, req *RecordTransfersRequest) (*RecordTransfersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecordTransfers not implemented")
+}
+func (*UnimplementedInternalIssueServiceServer) RefetchIssues(ctx context.Context, req *RefetchIssuesRequest) (*RefetchIssuesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefetchIssues not implemented")
+}
 
 func RegisterInternalIssueServiceServer(s *grpc.Server, srv InternalIssueServiceServer) {
 	s.RegisterService(&_InternalIssueService_serviceDesc, srv)
@@ -202,6 +469,42 @@ func _InternalIssueService_TombstoneIssues_Handler(srv interface{}, ctx context.
 	return interceptor(ctx, in, info, handler)
 }
 
+func _InternalIssueService_RecordTransfers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecordTransfersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InternalIssueServiceServer).RecordTransfers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/maintner.internal.InternalIssueService/RecordTransfers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InternalIssueServiceServer).RecordTransfers(ctx, req.(*RecordTransfersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InternalIssueService_RefetchIssues_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefetchIssuesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InternalIssueServiceServer).RefetchIssues(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/maintner.internal.InternalIssueService/RefetchIssues",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InternalIssueServiceServer).RefetchIssues(ctx, req.(*RefetchIssuesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _InternalIssueService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "maintner.internal.InternalIssueService",
 	HandlerType: (*InternalIssueServiceServer)(nil),
@@ -210,6 +513,14 @@ var _InternalIssueService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "TombstoneIssues",
 			Handler:    _InternalIssueService_TombstoneIssues_Handler,
 		},
+		{
+			MethodName: "RecordTransfers",
+			Handler:    _InternalIssueService_RecordTransfers_Handler,
+		},
+		{
+			MethodName: "RefetchIssues",
+			Handler:    _InternalIssueService_RefetchIssues_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "issue_service_internal.proto",
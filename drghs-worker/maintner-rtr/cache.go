@@ -0,0 +1,249 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"container/list"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	drghs_v1 "github.com/GoogleCloudPlatform/devrel-services/drghs/v1"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/genproto/protobuf/field_mask"
+)
+
+// responseCache is an LRU cache of backend responses, keyed by method and
+// normalized request and bounded by the total size of the responses it
+// holds. Entries expire after a TTL, which should match how often maintnerd
+// syncs with GitHub: a response can't change more often than that.
+//
+// maintnerd has no way to tell the router its data changed yet. The
+// responses of repositories dropped or changed in the repos file are
+// invalidated when the router updates its list.
+//
+// Paged responses are never cached: maintnerd's page tokens name state it
+// keeps for the caller, so neither a page nor the token to the next one may
+// be handed to someone else.
+//
+// A nil *responseCache caches nothing.
+type responseCache struct {
+	ttl      time.Duration
+	maxBytes int
+	now      func() time.Time
+
+	mu      sync.Mutex
+	ll      *list.List // of *cacheEntry, most recently used first
+	entries map[string]*list.Element
+	bytes   int
+	stats   cacheStats
+}
+
+type cacheEntry struct {
+	key     string
+	repo    string
+	resp    proto.Message
+	size    int
+	expires time.Time
+}
+
+// cacheStats counts what a responseCache did. It is published as an expvar.
+type cacheStats struct {
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+	Evictions int64 `json:"evictions"`
+	Entries   int   `json:"entries"`
+	Bytes     int   `json:"bytes"`
+}
+
+// newResponseCache returns a responseCache holding at most maxBytes of
+// responses for ttl each, or nil if either is not positive.
+func newResponseCache(ttl time.Duration, maxBytes int) *responseCache {
+	if ttl <= 0 || maxBytes <= 0 {
+		return nil
+	}
+	return &responseCache{
+		ttl:      ttl,
+		maxBytes: maxBytes,
+		now:      time.Now,
+		ll:       list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// get returns the cached response to req, a request for repo's method, or
+// calls fetch and caches its response. Errors and pages of a paged response
+// are not cached. The returned response is the caller's to keep.
+func (c *responseCache) get(method, repo string, req proto.Message, fetch func() (proto.Message, error)) (proto.Message, error) {
+	if c == nil || isPaged(req) {
+		return fetch()
+	}
+	key, err := cacheKey(method, req)
+	if err != nil {
+		return fetch()
+	}
+
+	if resp, ok := c.lookup(key); ok {
+		return resp, nil
+	}
+
+	resp, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	if !isPaged(resp) {
+		c.add(key, repo, resp)
+	}
+	return resp, nil
+}
+
+// isPaged reports whether m is a request for a page after the first, or a
+// response with more pages after it.
+func isPaged(m proto.Message) bool {
+	switch m := m.(type) {
+	case *drghs_v1.ListIssuesRequest:
+		return m.PageToken != ""
+	case *drghs_v1.ListIssuesResponse:
+		return m.NextPageToken != ""
+	}
+	return false
+}
+
+func (c *responseCache) lookup(key string) (proto.Message, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+	e := el.Value.(*cacheEntry)
+	if !c.now().Before(e.expires) {
+		c.remove(el)
+		c.stats.Misses++
+		return nil, false
+	}
+	c.ll.MoveToFront(el)
+	c.stats.Hits++
+	return proto.Clone(e.resp), true
+}
+
+func (c *responseCache) add(key, repo string, resp proto.Message) {
+	size := proto.Size(resp)
+	if size > c.maxBytes {
+		return
+	}
+	e := &cacheEntry{
+		key:     key,
+		repo:    repo,
+		resp:    proto.Clone(resp),
+		size:    size,
+		expires: c.now().Add(c.ttl),
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		c.remove(el)
+	}
+	c.entries[key] = c.ll.PushFront(e)
+	c.bytes += size
+	for c.bytes > c.maxBytes {
+		c.remove(c.ll.Back())
+		c.stats.Evictions++
+	}
+}
+
+// remove drops el. c.mu must be held.
+func (c *responseCache) remove(el *list.Element) {
+	e := c.ll.Remove(el).(*cacheEntry)
+	delete(c.entries, e.key)
+	c.bytes -= e.size
+}
+
+// invalidate drops every response for repo.
+func (c *responseCache) invalidate(repo string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for el := c.ll.Front(); el != nil; {
+		next := el.Next()
+		if el.Value.(*cacheEntry).repo == repo {
+			c.remove(el)
+		}
+		el = next
+	}
+}
+
+// Stats returns what c did so far. It is safe to call on a nil
+// *responseCache.
+func (c *responseCache) Stats() interface{} {
+	if c == nil {
+		return cacheStats{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.stats
+	s.Entries = c.ll.Len()
+	s.Bytes = c.bytes
+	return s
+}
+
+// cacheKey returns the key of req for method. Requests that differ only in
+// ways the backend ignores have the same key.
+func cacheKey(method string, req proto.Message) (string, error) {
+	req = normalizeRequest(req)
+	b := proto.NewBuffer(nil)
+	b.SetDeterministic(true)
+	if err := b.Marshal(req); err != nil {
+		return "", err
+	}
+	return method + "\x00" + string(b.Bytes()), nil
+}
+
+// normalizeRequest returns a copy of req with defaults filled in and field
+// mask paths sorted.
+func normalizeRequest(req proto.Message) proto.Message {
+	req = proto.Clone(req)
+	switch r := req.(type) {
+	case *drghs_v1.ListIssuesRequest:
+		// maintnerd serves at most 500 issues per page.
+		if r.PageSize <= 0 || r.PageSize > 500 {
+			r.PageSize = 500
+		}
+		r.Filter = strings.TrimSpace(r.Filter)
+		normalizeFieldMask(r.FieldMask)
+	case *drghs_v1.GetIssueRequest:
+		normalizeFieldMask(r.FieldMask)
+	}
+	return req
+}
+
+func normalizeFieldMask(fm *field_mask.FieldMask) {
+	if fm == nil {
+		return
+	}
+	sort.Strings(fm.Paths)
+	paths := fm.Paths[:0]
+	for _, p := range fm.Paths {
+		if len(paths) == 0 || p != paths[len(paths)-1] {
+			paths = append(paths, p)
+		}
+	}
+	fm.Paths = paths
+}
@@ -0,0 +1,341 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	drghs_v1 "github.com/GoogleCloudPlatform/devrel-services/drghs/v1"
+	"github.com/GoogleCloudPlatform/devrel-services/repos"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genproto/protobuf/field_mask"
+)
+
+// fakeBackend counts the calls made to it and answers GetIssue with the
+// requested issue.
+type fakeBackend struct {
+	calls int
+	err   error
+	title string
+}
+
+func (b *fakeBackend) getIssue(r *drghs_v1.GetIssueRequest) func() (proto.Message, error) {
+	return func() (proto.Message, error) {
+		b.calls++
+		if b.err != nil {
+			return nil, b.err
+		}
+		return &drghs_v1.GetIssueResponse{Issue: &drghs_v1.Issue{Name: r.Name, Title: b.title}}, nil
+	}
+}
+
+func TestResponseCacheTTL(t *testing.T) {
+	now := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	c := newResponseCache(10*time.Minute, 1<<20)
+	c.now = func() time.Time { return now }
+
+	b := &fakeBackend{}
+	req := &drghs_v1.GetIssueRequest{Name: "foo/bar/issues/1"}
+
+	get := func() *drghs_v1.GetIssueResponse {
+		resp, err := c.get("GetIssue", "foo/bar", req, b.getIssue(req))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.(*drghs_v1.GetIssueResponse)
+	}
+
+	get()
+	got := get()
+	if b.calls != 1 {
+		t.Errorf("get() within the TTL. Wanted 1 backend call, Got %v", b.calls)
+	}
+	// Callers may modify what they get without affecting the cache.
+	got.Issue.Title = "modified"
+	if got := get(); got.Issue.Title != "" {
+		t.Errorf("get() returned a response modified by an earlier caller: %q", got.Issue.Title)
+	}
+
+	now = now.Add(10 * time.Minute)
+	get()
+	if b.calls != 2 {
+		t.Errorf("get() after the TTL. Wanted 2 backend calls, Got %v", b.calls)
+	}
+
+	want := cacheStats{Hits: 2, Misses: 2, Entries: 1, Bytes: c.Stats().(cacheStats).Bytes}
+	if diff := cmp.Diff(want, c.Stats()); diff != "" {
+		t.Errorf("Stats() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestResponseCacheErrorsNotCached(t *testing.T) {
+	c := newResponseCache(time.Minute, 1<<20)
+	b := &fakeBackend{err: errors.New("unavailable")}
+	req := &drghs_v1.GetIssueRequest{Name: "foo/bar/issues/1"}
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.get("GetIssue", "foo/bar", req, b.getIssue(req)); err != b.err {
+			t.Errorf("get() Wanted %v, Got %v", b.err, err)
+		}
+	}
+	if b.calls != 2 {
+		t.Errorf("get() after an error. Wanted 2 backend calls, Got %v", b.calls)
+	}
+}
+
+func TestResponseCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	b := &fakeBackend{title: strings.Repeat("x", 100)}
+	reqs := []*drghs_v1.GetIssueRequest{
+		{Name: "foo/bar/issues/1"},
+		{Name: "foo/bar/issues/2"},
+		{Name: "foo/bar/issues/3"},
+	}
+	size := proto.Size(&drghs_v1.GetIssueResponse{Issue: &drghs_v1.Issue{Name: reqs[0].Name, Title: b.title}})
+	// Room for two responses.
+	c := newResponseCache(time.Minute, 2*size+size/2)
+
+	get := func(i int) {
+		if _, err := c.get("GetIssue", "foo/bar", reqs[i], b.getIssue(reqs[i])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	get(0)
+	get(1)
+	get(0) // 1 is now the least recently used.
+	get(2)
+	if b.calls != 3 {
+		t.Fatalf("Wanted 3 backend calls, Got %v", b.calls)
+	}
+
+	get(0)
+	if b.calls != 3 {
+		t.Errorf("get() of a recently used response. Wanted 3 backend calls, Got %v", b.calls)
+	}
+	get(1)
+	if b.calls != 4 {
+		t.Errorf("get() of an evicted response. Wanted 4 backend calls, Got %v", b.calls)
+	}
+	if s := c.Stats().(cacheStats); s.Evictions != 2 || s.Bytes > 2*size+size/2 {
+		t.Errorf("Stats() Wanted 2 evictions and at most %v bytes, Got %+v", 2*size+size/2, s)
+	}
+}
+
+func TestResponseCacheInvalidate(t *testing.T) {
+	c := newResponseCache(time.Minute, 1<<20)
+	b := &fakeBackend{}
+	foo := &drghs_v1.GetIssueRequest{Name: "foo/bar/issues/1"}
+	baz := &drghs_v1.GetIssueRequest{Name: "foo/baz/issues/1"}
+
+	get := func(repo string, req *drghs_v1.GetIssueRequest) {
+		if _, err := c.get("GetIssue", repo, req, b.getIssue(req)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	get("foo/bar", foo)
+	get("foo/baz", baz)
+
+	c.invalidate("foo/bar")
+	get("foo/bar", foo)
+	get("foo/baz", baz)
+	if b.calls != 3 {
+		t.Errorf("get() after invalidate(). Wanted 3 backend calls, Got %v", b.calls)
+	}
+}
+
+func TestResponseCacheSkipsPages(t *testing.T) {
+	c := newResponseCache(time.Minute, 1<<20)
+	calls := 0
+	list := func(next string) func() (proto.Message, error) {
+		return func() (proto.Message, error) {
+			calls++
+			return &drghs_v1.ListIssuesResponse{NextPageToken: next}, nil
+		}
+	}
+
+	tests := []struct {
+		Name      string
+		Req       *drghs_v1.ListIssuesRequest
+		Next      string
+		WantCalls int
+	}{
+		{
+			Name:      "First of several pages",
+			Req:       &drghs_v1.ListIssuesRequest{Parent: "foo/bar"},
+			Next:      "token1",
+			WantCalls: 2,
+		},
+		{
+			Name:      "Later page",
+			Req:       &drghs_v1.ListIssuesRequest{Parent: "foo/bar", PageToken: "token1"},
+			WantCalls: 2,
+		},
+		{
+			Name:      "Single page",
+			Req:       &drghs_v1.ListIssuesRequest{Parent: "foo/baz"},
+			WantCalls: 1,
+		},
+	}
+	for _, tc := range tests {
+		calls = 0
+		for i := 0; i < 2; i++ {
+			if _, err := c.get("ListIssues", "foo/bar", tc.Req, list(tc.Next)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if calls != tc.WantCalls {
+			t.Errorf("Test: %v Wanted %v backend calls Got %v", tc.Name, tc.WantCalls, calls)
+		}
+	}
+}
+
+func TestResponseCacheNil(t *testing.T) {
+	var c *responseCache
+	b := &fakeBackend{}
+	req := &drghs_v1.GetIssueRequest{Name: "foo/bar/issues/1"}
+	for i := 0; i < 2; i++ {
+		if _, err := c.get("GetIssue", "foo/bar", req, b.getIssue(req)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if b.calls != 2 {
+		t.Errorf("get() of a nil cache. Wanted 2 backend calls, Got %v", b.calls)
+	}
+	c.invalidate("foo/bar")
+}
+
+func TestCacheKey(t *testing.T) {
+	mask := func(paths ...string) *field_mask.FieldMask { return &field_mask.FieldMask{Paths: paths} }
+
+	tests := []struct {
+		Name string
+		A    proto.Message
+		B    proto.Message
+		Same bool
+	}{
+		{
+			Name: "Default page size",
+			A:    &drghs_v1.ListIssuesRequest{Parent: "foo/bar"},
+			B:    &drghs_v1.ListIssuesRequest{Parent: "foo/bar", PageSize: 500},
+			Same: true,
+		},
+		{
+			Name: "Oversized page size",
+			A:    &drghs_v1.ListIssuesRequest{Parent: "foo/bar", PageSize: 1000},
+			B:    &drghs_v1.ListIssuesRequest{Parent: "foo/bar", PageSize: 500},
+			Same: true,
+		},
+		{
+			Name: "Different page size",
+			A:    &drghs_v1.ListIssuesRequest{Parent: "foo/bar", PageSize: 10},
+			B:    &drghs_v1.ListIssuesRequest{Parent: "foo/bar", PageSize: 20},
+			Same: false,
+		},
+		{
+			Name: "Filter whitespace",
+			A:    &drghs_v1.ListIssuesRequest{Parent: "foo/bar", Filter: " closed == false "},
+			B:    &drghs_v1.ListIssuesRequest{Parent: "foo/bar", Filter: "closed == false"},
+			Same: true,
+		},
+		{
+			Name: "Field mask order",
+			A:    &drghs_v1.GetIssueRequest{Name: "foo/bar/issues/1", FieldMask: mask("title", "body", "title")},
+			B:    &drghs_v1.GetIssueRequest{Name: "foo/bar/issues/1", FieldMask: mask("body", "title")},
+			Same: true,
+		},
+		{
+			Name: "Different issue",
+			A:    &drghs_v1.GetIssueRequest{Name: "foo/bar/issues/1"},
+			B:    &drghs_v1.GetIssueRequest{Name: "foo/bar/issues/2"},
+			Same: false,
+		},
+	}
+	for _, c := range tests {
+		a, err := cacheKey("M", c.A)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := cacheKey("M", c.B)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (a == b) != c.Same {
+			t.Errorf("%v: cacheKey() equal Wanted %v, Got %v", c.Name, c.Same, a == b)
+		}
+	}
+
+	// The request itself is left untouched.
+	req := &drghs_v1.GetIssueRequest{FieldMask: mask("title", "body")}
+	cacheKey("M", req)
+	if diff := cmp.Diff([]string{"title", "body"}, req.FieldMask.Paths); diff != "" {
+		t.Errorf("cacheKey() modified its input (-want +got):\n%s", diff)
+	}
+
+	ka, _ := cacheKey("ListIssues", &drghs_v1.GetIssueRequest{})
+	kb, _ := cacheKey("GetIssue", &drghs_v1.GetIssueRequest{})
+	if ka == kb {
+		t.Errorf("cacheKey() of different methods are equal")
+	}
+}
+
+type updatingRepoList struct {
+	repos, next []repos.TrackedRepository
+}
+
+func (l *updatingRepoList) UpdateTrackedRepos(context.Context) (bool, error) {
+	l.repos = l.next
+	return true, nil
+}
+
+func (l *updatingRepoList) GetTrackedRepos() []repos.TrackedRepository { return l.repos }
+
+func TestUpdateTrackedReposInvalidates(t *testing.T) {
+	c := newResponseCache(time.Minute, 1<<20)
+	b := &fakeBackend{}
+	get := func(repo string) {
+		req := &drghs_v1.GetIssueRequest{Name: repo + "/issues/1"}
+		if _, err := c.get("GetIssue", repo, req, b.getIssue(req)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	kept := repos.TrackedRepository{Owner: "foo", Name: "bar", IsTrackingIssues: true}
+	changed := repos.TrackedRepository{Owner: "foo", Name: "baz", IsTrackingIssues: true}
+	dropped := repos.TrackedRepository{Owner: "foo", Name: "qux", IsTrackingIssues: true}
+	redeployed := changed
+	redeployed.ImageTag = "v2"
+	l := &updatingRepoList{
+		repos: []repos.TrackedRepository{kept, changed, dropped},
+		next:  []repos.TrackedRepository{kept, redeployed},
+	}
+	for _, tr := range l.repos {
+		get(tr.String())
+	}
+
+	if err := updateTrackedRepos(context.Background(), l, c); err != nil {
+		t.Fatal(err)
+	}
+	for _, tr := range []repos.TrackedRepository{kept, changed, dropped} {
+		get(tr.String())
+	}
+	// The dropped and changed repositories went to the backend again.
+	if b.calls != 5 {
+		t.Errorf("get() after updateTrackedRepos(). Wanted 5 backend calls, Got %v", b.calls)
+	}
+}
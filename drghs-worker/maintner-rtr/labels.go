@@ -0,0 +1,250 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	drghs_v1 "github.com/GoogleCloudPlatform/devrel-services/drghs/v1"
+	"github.com/GoogleCloudPlatform/devrel-services/repos"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	tspb "github.com/golang/protobuf/ptypes/timestamp"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const maxPageSize = 500
+
+// ListLabels proxies to the maintner instance of the requested repository.
+// A parent with a wildcard owner or repository ("-" or "*") is answered by
+// querying every matching tracked repository and aggregating their labels
+// by name.
+func (s *reverseProxyServer) ListLabels(ctx context.Context, r *drghs_v1.ListLabelsRequest) (*drghs_v1.ListLabelsResponse, error) {
+	if owner, name, ok := wildcardParent(r.Parent); ok {
+		return s.listAggregatedLabels(ctx, r, owner, name)
+	}
+
+	tr := buildTR(r.Parent)
+
+	if tr == nil {
+		return nil, status.Error(codes.FailedPrecondition, fmt.Sprintf("invalid parent: %v", r.Parent))
+	}
+
+	if is := s.checkRepoIsTracked(tr); !is {
+		return nil, status.Error(codes.NotFound, fmt.Sprintf("repository %v is not tracking issues", tr.String()))
+	}
+
+	pth, err := s.calculateHost(ctx, tr)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, release, err := s.pool.Get(ctx, pth)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	client := drghs_v1.NewIssueServiceClient(conn)
+	return client.ListLabels(ctx, r)
+}
+
+// listAggregatedLabels lists the labels of every tracked repository matching
+// owner and name, either of which may be a wildcard, asking their maintner
+// instances in parallel. Instances that fail are listed in the response.
+// Every page is built from a fresh fan-out, so the page token is just an
+// offset into the aggregated list.
+func (s *reverseProxyServer) listAggregatedLabels(ctx context.Context, r *drghs_v1.ListLabelsRequest, owner, name string) (*drghs_v1.ListLabelsResponse, error) {
+	offset, err := decodeLabelPageToken(r.PageToken)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("invalid page_token: %v", err))
+	}
+
+	trs := make([]repos.TrackedRepository, 0)
+	for _, tr := range s.reps.GetTrackedRepos() {
+		if !tr.IsTrackingIssues || !matchesParent(tr, owner, name) || !s.auth.CanRead(ctx, tr.Owner, tr.Name) {
+			continue
+		}
+		trs = append(trs, tr)
+	}
+
+	lists := make([][]*drghs_v1.Label, len(trs))
+	errs := s.fanOut(ctx, trs, func(ctx context.Context, i int, tr repos.TrackedRepository) error {
+		var err error
+		lists[i], err = s.getRepoLabels(ctx, tr)
+		return err
+	})
+
+	unreachable := unreachableBackends(trs, errs)
+	for _, u := range unreachable {
+		log.Warnf("got error listing labels for repo: %v code: %v err: %v", u.Repository, codes.Code(u.Code), u.Message)
+	}
+
+	all := mergeLabels(lists)
+	if offset > len(all) {
+		offset = len(all)
+	}
+	end := offset + pageSize(r.PageSize)
+	if end > len(all) {
+		end = len(all)
+	}
+
+	resp := &drghs_v1.ListLabelsResponse{
+		Labels:              all[offset:end],
+		UnreachableBackends: unreachable,
+	}
+	if end < len(all) {
+		resp.NextPageToken = encodeLabelPageToken(end)
+	}
+	return resp, nil
+}
+
+// getRepoLabels returns every label of tr from its maintner instance.
+func (s *reverseProxyServer) getRepoLabels(ctx context.Context, tr repos.TrackedRepository) ([]*drghs_v1.Label, error) {
+	pth, err := s.calculateHost(ctx, &tr)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, release, err := s.pool.Get(ctx, pth)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	client := drghs_v1.NewIssueServiceClient(conn)
+	ret := make([]*drghs_v1.Label, 0)
+	npt := ""
+	for {
+		resp, err := client.ListLabels(ctx, &drghs_v1.ListLabelsRequest{
+			Parent:    tr.String(),
+			PageToken: npt,
+			PageSize:  maxPageSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, resp.Labels...)
+		if resp.NextPageToken == "" {
+			break
+		}
+		npt = resp.NextPageToken
+	}
+	return ret, nil
+}
+
+// wildcardParent splits parent into its owner and repository, and reports
+// whether either is a wildcard.
+func wildcardParent(parent string) (string, string, bool) {
+	parts := strings.Split(parent, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], isWildcard(parts[0]) || isWildcard(parts[1])
+}
+
+func isWildcard(s string) bool {
+	return s == "-" || s == "*"
+}
+
+func matchesParent(tr repos.TrackedRepository, owner, name string) bool {
+	return (isWildcard(owner) || strings.EqualFold(owner, tr.Owner)) &&
+		(isWildcard(name) || strings.EqualFold(name, tr.Name))
+}
+
+// mergeLabels aggregates the labels of several repositories by name,
+// ordered by name. Counts are summed, usage times widened, and the color
+// and description taken from the first repository that has them.
+func mergeLabels(lists [][]*drghs_v1.Label) []*drghs_v1.Label {
+	byName := make(map[string]*drghs_v1.Label)
+	for _, labels := range lists {
+		for _, l := range labels {
+			m, ok := byName[l.Name]
+			if !ok {
+				byName[l.Name] = proto.Clone(l).(*drghs_v1.Label)
+				continue
+			}
+			m.Repositories = append(m.Repositories, l.Repositories...)
+			if m.Color == "" {
+				m.Color = l.Color
+			}
+			if m.Description == "" {
+				m.Description = l.Description
+			}
+			m.OpenIssueCount += l.OpenIssueCount
+			m.ClosedIssueCount += l.ClosedIssueCount
+			m.OpenPullRequestCount += l.OpenPullRequestCount
+			m.ClosedPullRequestCount += l.ClosedPullRequestCount
+			if l.FirstUsedTime != nil && (m.FirstUsedTime == nil || before(l.FirstUsedTime, m.FirstUsedTime)) {
+				m.FirstUsedTime = l.FirstUsedTime
+			}
+			if l.LastUsedTime != nil && (m.LastUsedTime == nil || before(m.LastUsedTime, l.LastUsedTime)) {
+				m.LastUsedTime = l.LastUsedTime
+			}
+		}
+	}
+
+	ret := make([]*drghs_v1.Label, 0, len(byName))
+	for _, l := range byName {
+		sort.Strings(l.Repositories)
+		ret = append(ret, l)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+	return ret
+}
+
+func before(a, b *tspb.Timestamp) bool {
+	at, aerr := ptypes.Timestamp(a)
+	bt, berr := ptypes.Timestamp(b)
+	return aerr == nil && berr == nil && at.Before(bt)
+}
+
+func pageSize(n int32) int {
+	if n <= 0 || n > maxPageSize {
+		return maxPageSize
+	}
+	return int(n)
+}
+
+func encodeLabelPageToken(offset int) string {
+	return base64.StdEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodeLabelPageToken(token string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+	b, err := base64.StdEncoding.DecodeString(token)
+	if err != nil {
+		return 0, err
+	}
+	offset, err := strconv.Atoi(string(b))
+	if err != nil {
+		return 0, err
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("negative offset %v", offset)
+	}
+	return offset, nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	drghs_v1 "github.com/GoogleCloudPlatform/devrel-services/drghs/v1"
+	"github.com/GoogleCloudPlatform/devrel-services/repos"
+	"github.com/GoogleCloudPlatform/devrel-services/rtr/resolver"
+	tspb "github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestWildcardParent(t *testing.T) {
+	tests := []struct {
+		Input     string
+		WantOwner string
+		WantName  string
+		Want      bool
+	}{
+		{Input: "foo/bar", WantOwner: "foo", WantName: "bar", Want: false},
+		{Input: "foo/-", WantOwner: "foo", WantName: "-", Want: true},
+		{Input: "*/*", WantOwner: "*", WantName: "*", Want: true},
+		{Input: "-/bar", WantOwner: "-", WantName: "bar", Want: true},
+		{Input: "foo/bar/issues", Want: false},
+		{Input: "-", Want: false},
+	}
+	for _, c := range tests {
+		owner, name, got := wildcardParent(c.Input)
+		if owner != c.WantOwner || name != c.WantName || got != c.Want {
+			t.Errorf("wildcardParent(%q) Wanted %q, %q, %v. Got %q, %q, %v", c.Input, c.WantOwner, c.WantName, c.Want, owner, name, got)
+		}
+	}
+}
+
+func TestMergeLabels(t *testing.T) {
+	ts := func(s int64) *tspb.Timestamp { return &tspb.Timestamp{Seconds: s} }
+
+	lists := [][]*drghs_v1.Label{
+		{
+			{Name: "bug", Repositories: []string{"foo/b"}, OpenIssueCount: 1, FirstUsedTime: ts(20), LastUsedTime: ts(30)},
+			{Name: "p1", Repositories: []string{"foo/b"}, ClosedPullRequestCount: 2},
+		},
+		{
+			{Name: "bug", Repositories: []string{"foo/a"}, Color: "d73a4a", ClosedIssueCount: 3, FirstUsedTime: ts(10), LastUsedTime: ts(25)},
+			{Name: "docs", Repositories: []string{"foo/a"}, Description: "Documentation"},
+		},
+	}
+	want := []*drghs_v1.Label{
+		{Name: "bug", Repositories: []string{"foo/a", "foo/b"}, Color: "d73a4a", OpenIssueCount: 1, ClosedIssueCount: 3, FirstUsedTime: ts(10), LastUsedTime: ts(30)},
+		{Name: "docs", Repositories: []string{"foo/a"}, Description: "Documentation"},
+		{Name: "p1", Repositories: []string{"foo/b"}, ClosedPullRequestCount: 2},
+	}
+
+	got := mergeLabels(lists)
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreUnexported(tspb.Timestamp{}, drghs_v1.Label{})); diff != "" {
+		t.Errorf("mergeLabels() mismatch (-want +got):\n%s", diff)
+	}
+	// The inputs must be left untouched.
+	if n := lists[0][0].ClosedIssueCount; n != 0 {
+		t.Errorf("mergeLabels() modified its input. Wanted 0, Got %v", n)
+	}
+}
+
+func TestLabelPageToken(t *testing.T) {
+	for _, offset := range []int{0, 1, 500, 12345} {
+		got, err := decodeLabelPageToken(encodeLabelPageToken(offset))
+		if err != nil || got != offset {
+			t.Errorf("decodeLabelPageToken(encodeLabelPageToken(%v)) Wanted %v, nil. Got %v, %v", offset, offset, got, err)
+		}
+	}
+	if _, err := decodeLabelPageToken("not a token"); err == nil {
+		t.Errorf("decodeLabelPageToken() of an invalid token. Wanted an error, Got nil")
+	}
+}
+
+type fakeRepoList []repos.TrackedRepository
+
+func (f fakeRepoList) UpdateTrackedRepos(context.Context) (bool, error) { return false, nil }
+
+func (f fakeRepoList) GetTrackedRepos() []repos.TrackedRepository { return f }
+
+func TestListAggregatedLabelsReportsUnreachable(t *testing.T) {
+	s := &reverseProxyServer{
+		reps: fakeRepoList{
+			{Owner: "foo", Name: "a", IsTrackingIssues: true},
+			{Owner: "foo", Name: "b", IsTrackingIssues: true},
+			{Owner: "foo", Name: "c"},
+			{Owner: "bar", Name: "a", IsTrackingIssues: true},
+		},
+		resolver:       resolver.Static{},
+		fanout:         2,
+		backendTimeout: time.Second,
+	}
+
+	resp, err := s.ListLabels(context.Background(), &drghs_v1.ListLabelsRequest{Parent: "foo/-"})
+	if err != nil {
+		t.Fatalf("ListLabels() Wanted nil error, Got %v", err)
+	}
+	if len(resp.Labels) != 0 {
+		t.Errorf("ListLabels() Wanted 0 labels, Got %v", len(resp.Labels))
+	}
+	got := make([]string, 0)
+	for _, u := range resp.UnreachableBackends {
+		got = append(got, u.Repository)
+	}
+	if diff := cmp.Diff([]string{"foo/a", "foo/b"}, got); diff != "" {
+		t.Errorf("ListLabels() unreachable backends mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -16,17 +16,22 @@ package main
 
 import (
 	"context"
+	"expvar"
 	"flag"
 	"fmt"
 	"net"
 	"net/http"
 	"os"
 	"regexp"
-	"strings"
 	"time"
 
 	drghs_v1 "github.com/GoogleCloudPlatform/devrel-services/drghs/v1"
 	"github.com/GoogleCloudPlatform/devrel-services/repos"
+	"github.com/GoogleCloudPlatform/devrel-services/rtr/auth"
+	"github.com/GoogleCloudPlatform/devrel-services/rtr/pool"
+	"github.com/GoogleCloudPlatform/devrel-services/rtr/ratelimit"
+	"github.com/GoogleCloudPlatform/devrel-services/rtr/resolver"
+	"github.com/golang/protobuf/proto"
 	"golang.org/x/sync/errgroup"
 
 	"cloud.google.com/go/errorreporting"
@@ -52,6 +57,22 @@ var (
 	sprvsrAddr = flag.String("sprvsr", "maintner-sprvsr", "address for supervisor")
 	rbucket    = flag.String("settings-bucket", "", "bucket to get repo list")
 	rfile      = flag.String("repos-file", "", "file in bucket to read repos from")
+	resolve    = flag.String("resolver", "k8s", "how to find the maintner instance of a repository: k8s, static:<yaml file> or srv:<domain>")
+
+	backendIdleTimeout    = flag.Duration("backend-idle-timeout", pool.DefaultIdleTimeout, "close connections to a maintner instance after they have been unused for this long")
+	backendMaxConcurrency = flag.Int("backend-max-concurrency", 0, "maximum number of calls in flight to one maintner instance. 0 is unbounded")
+	fanoutConcurrency     = flag.Int("fanout-concurrency", 16, "maximum number of maintner instances queried at once by calls that span every repository")
+	backendTimeout        = flag.Duration("backend-timeout", 10*time.Second, "deadline for each maintner instance queried by calls that span every repository")
+
+	cacheTTL    = flag.Duration("cache-ttl", 0, "how long ListIssues and GetIssue responses are cached, e.g. 10m. Should match how often maintnerd syncs. 0 disables the cache")
+	cacheSizeMB = flag.Int("cache-size-mb", 0, "maximum size of the cached responses in megabytes, e.g. 256. 0 disables the cache")
+	debugListen = flag.String("debug-listen", "", "listen address for /debug/vars, which includes the cache and rate limiting metrics. Empty disables it")
+
+	authPolicy = flag.String("auth-policy", "", "YAML file mapping API keys and JWT subjects to the repositories they may read. Empty trusts every caller")
+
+	rateLimitConfig = flag.String("rate-limit-config", "", "YAML file of the per-caller and per-method quotas")
+	rateLimitRPS    = flag.Float64("rate-limit-rps", 0, "calls per second each caller may make to each method, unless --rate-limit-config says otherwise. 0 is unlimited")
+	rateLimitBurst  = flag.Int("rate-limit-burst", 20, "calls each caller may make to each method at once, unless --rate-limit-config says otherwise")
 )
 
 var (
@@ -102,8 +123,27 @@ func main() {
 		log.Fatal("error: must specify --repos-file")
 	}
 
+	backendResolver, err := resolver.Parse(*resolve, "mtr-s-")
+	if err != nil {
+		log.Fatalf("error: invalid --resolver: %v", err)
+	}
+
+	var authorizer *auth.Authorizer
+	if *authPolicy != "" {
+		policy, err := auth.LoadPolicy(*authPolicy)
+		if err != nil {
+			log.Fatalf("error: invalid --auth-policy: %v", err)
+		}
+		authorizer = auth.NewAuthorizer(policy)
+	}
+
+	quotas, err := ratelimit.Load(*rateLimitConfig, ratelimit.Quota{RPS: *rateLimitRPS, Burst: *rateLimitBurst})
+	if err != nil {
+		log.Fatalf("error: invalid --rate-limit-config: %v", err)
+	}
+
 	rlist := repos.NewBucketRepo(*rbucket, *rfile)
-	_, err := rlist.UpdateTrackedRepos(context.Background())
+	_, err = rlist.UpdateTrackedRepos(context.Background())
 	if err != nil {
 		log.Fatalf("got error updating repos: %v", err)
 	}
@@ -128,34 +168,69 @@ func main() {
 
 	group, ctx := errgroup.WithContext(context.Background())
 
+	cache := newResponseCache(*cacheTTL, *cacheSizeMB<<20)
+	expvar.Publish("cache", expvar.Func(cache.Stats))
+	if *debugListen != "" {
+		group.Go(func() error {
+			// expvar serves /debug/vars on the default mux.
+			return http.ListenAndServe(*debugListen, nil)
+		})
+	}
+
 	group.Go(func() error {
 		ticker := time.NewTicker(10 * time.Minute)
 		for t := range ticker.C {
 			log.Printf("Update tracked repo list at %v", t)
-			// Lock it for writes
-			// Sync
-			if _, err := rlist.UpdateTrackedRepos(ctx); err != nil {
+			if err := updateTrackedRepos(ctx, rlist, cache); err != nil {
 				log.Printf("Error during tracked repo update %v", err)
 			}
-			// Unlock
 		}
 		return nil
 	})
 
 	group.Go(func() error {
+		interceptors := []grpc.UnaryServerInterceptor{
+			grpctrace.UnaryServerInterceptor(global.Tracer("maintner-rtr")),
+		}
+		if authorizer != nil {
+			interceptors = append(interceptors, authorizer.UnaryServerInterceptor())
+		}
+		if quotas != nil {
+			// After auth, to throttle callers by principal.
+			interceptors = append(interceptors, ratelimit.NewLimiter(quotas).UnaryServerInterceptor())
+		}
+		interceptors = append(interceptors, unaryInterceptorLog)
+
 		grpcServer := grpc.NewServer(
-			grpc.UnaryInterceptor(
-				grpc_middleware.ChainUnaryServer(
-					grpctrace.UnaryServerInterceptor(global.Tracer("maintner-rtr")),
-					unaryInterceptorLog,
-				),
-			),
+			grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(interceptors...)),
 			grpc.KeepaliveParams(keepalive.ServerParameters{
 				MaxConnectionIdle: 5 * time.Minute,
 			}),
 		)
+		backends := pool.New(pool.Config{
+			IdleTimeout:             *backendIdleTimeout,
+			MaxConcurrentPerBackend: *backendMaxConcurrency,
+			DialOptions: []grpc.DialOption{
+				grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxMessageSize)),
+				grpc.WithInsecure(),
+				grpc.WithUnaryInterceptor(
+					grpc_middleware.ChainUnaryClient(
+						grpctrace.UnaryClientInterceptor(global.Tracer("maintner-rtr")),
+						buildRetryInterceptor(),
+					),
+				),
+			},
+		})
+		defer backends.Close()
+
 		reverseProxy := &reverseProxyServer{
-			reps: rlist,
+			reps:           rlist,
+			pool:           backends,
+			resolver:       backendResolver,
+			fanout:         *fanoutConcurrency,
+			backendTimeout: *backendTimeout,
+			cache:          cache,
+			auth:           authorizer,
 		}
 
 		go func() {
@@ -176,7 +251,22 @@ func main() {
 }
 
 type reverseProxyServer struct {
-	reps repos.RepoList
+	reps     repos.RepoList
+	pool     *pool.Pool
+	resolver resolver.Resolver
+
+	// fanout bounds how many backends a call spanning every repository
+	// queries at once, and backendTimeout is the deadline for each of them.
+	fanout         int
+	backendTimeout time.Duration
+
+	// cache holds ListIssues and GetIssue responses. It may be nil.
+	cache *responseCache
+
+	// auth checks callers may read the repositories they ask for. Calls
+	// spanning repositories leave out the ones the caller may not read. It
+	// may be nil.
+	auth *auth.Authorizer
 }
 
 // Check is for health checking.
@@ -188,51 +278,71 @@ func (s *reverseProxyServer) Watch(req *healthpb.HealthCheckRequest, ws healthpb
 	return status.Errorf(codes.Unimplemented, "health check via Watch not implemented")
 }
 
-func (s *reverseProxyServer) ListRepositories(ctx context.Context, r *drghs_v1.ListRepositoriesRequest) (*drghs_v1.ListRepositoriesResponse, error) {
-	resp := drghs_v1.ListRepositoriesResponse{}
-	for _, tr := range s.reps.GetTrackedRepos() {
+func (s *reverseProxyServer) ListIssues(ctx context.Context, r *drghs_v1.ListIssuesRequest) (*drghs_v1.ListIssuesResponse, error) {
+	tr := buildTR(r.Parent)
 
-		if !tr.IsTrackingIssues {
-			log.Debugf("skipping repo: %v", tr.String())
-			continue
-		}
+	if tr == nil {
+		return nil, status.Error(codes.FailedPrecondition, fmt.Sprintf("invalid parent: %v", r.Parent))
+	}
 
-		pth, err := calculateHost(&tr)
-		if err != nil {
-			return nil, err
-		}
-		// Dial and get the repos
-		log.Debugf("getting tracked repos from repo: %v path: %v", tr.String(), pth)
-		conn, err := grpc.Dial(
-			pth,
-			grpc.WithInsecure(),
-			grpc.WithUnaryInterceptor(
-				grpc_middleware.ChainUnaryClient(
-					grpctrace.UnaryClientInterceptor(global.Tracer("maintner-rtr")),
-					buildRetryInterceptor(),
-				),
-			),
-		)
+	if is := s.checkRepoIsTracked(tr); !is {
+		return nil, status.Error(codes.NotFound, fmt.Sprintf("repository %v is not tracking issues", tr.String()))
+	}
 
+	pth, err := s.calculateHost(ctx, tr)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.cache.get("ListIssues", tr.String(), r, func() (proto.Message, error) {
+		conn, release, err := s.pool.Get(ctx, pth)
 		if err != nil {
-			log.Warnf("got error dialing to repo: %v path: %v err: %v", tr.String(), pth, err)
-			continue
+			return nil, err
 		}
+		defer release()
 
 		client := drghs_v1.NewIssueServiceClient(conn)
-		// Naive right now... every service has exactly one repo
-		srepos, err := getTrackedRepositories(ctx, client)
+		return client.ListIssues(ctx, r)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*drghs_v1.ListIssuesResponse), nil
+}
+
+func (s *reverseProxyServer) GetIssue(ctx context.Context, r *drghs_v1.GetIssueRequest) (*drghs_v1.GetIssueResponse, error) {
+	tr := buildTR(r.Name)
+
+	if tr == nil {
+		return nil, status.Error(codes.FailedPrecondition, fmt.Sprintf("invalid parent: %v", r.Name))
+	}
+
+	if is := s.checkRepoIsTracked(tr); !is {
+		return nil, status.Error(codes.NotFound, fmt.Sprintf("repository %v is not tracking issues", tr.String()))
+	}
+
+	pth, err := s.calculateHost(ctx, tr)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.cache.get("GetIssue", tr.String(), r, func() (proto.Message, error) {
+		conn, release, err := s.pool.Get(ctx, pth)
 		if err != nil {
-			log.Warnf("got error listing repositories for repo: %v path: %v err: %v", tr.String(), pth, err)
-			continue
+			return nil, err
 		}
+		defer release()
 
-		resp.Repositories = append(resp.Repositories, srepos...)
+		client := drghs_v1.NewIssueServiceClient(conn)
+		return client.GetIssue(ctx, r)
+	})
+	if err != nil {
+		return nil, err
 	}
-	return &resp, nil
+	return resp.(*drghs_v1.GetIssueResponse), nil
 }
 
-func (s *reverseProxyServer) ListIssues(ctx context.Context, r *drghs_v1.ListIssuesRequest) (*drghs_v1.ListIssuesResponse, error) {
+func (s *reverseProxyServer) ListIssueLinks(ctx context.Context, r *drghs_v1.ListIssueLinksRequest) (*drghs_v1.ListIssueLinksResponse, error) {
 	tr := buildTR(r.Parent)
 
 	if tr == nil {
@@ -243,74 +353,73 @@ func (s *reverseProxyServer) ListIssues(ctx context.Context, r *drghs_v1.ListIss
 		return nil, status.Error(codes.NotFound, fmt.Sprintf("repository %v is not tracking issues", tr.String()))
 	}
 
-	pth, err := calculateHost(tr)
+	pth, err := s.calculateHost(ctx, tr)
 	if err != nil {
 		return nil, err
 	}
 
-	conn, err := grpc.Dial(
-		pth,
-		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxMessageSize)),
-		grpc.WithInsecure(),
-		grpc.WithUnaryInterceptor(
-			grpc_middleware.ChainUnaryClient(
-				grpctrace.UnaryClientInterceptor(global.Tracer("maintner-rtr")),
-				buildRetryInterceptor(),
-			),
-		),
-	)
+	conn, release, err := s.pool.Get(ctx, pth)
 	if err != nil {
 		return nil, err
 	}
-	defer conn.Close()
+	defer release()
 
 	client := drghs_v1.NewIssueServiceClient(conn)
-	return client.ListIssues(ctx, r)
+	return client.ListIssueLinks(ctx, r)
 }
 
-func (s *reverseProxyServer) GetIssue(ctx context.Context, r *drghs_v1.GetIssueRequest) (*drghs_v1.GetIssueResponse, error) {
-	tr := buildTR(r.Name)
+func (s *reverseProxyServer) ListStaleIssues(ctx context.Context, r *drghs_v1.ListStaleIssuesRequest) (*drghs_v1.ListStaleIssuesResponse, error) {
+	tr := buildTR(r.Parent)
 
 	if tr == nil {
-		return nil, status.Error(codes.FailedPrecondition, fmt.Sprintf("invalid parent: %v", r.Name))
+		return nil, status.Error(codes.FailedPrecondition, fmt.Sprintf("invalid parent: %v", r.Parent))
 	}
 
 	if is := s.checkRepoIsTracked(tr); !is {
 		return nil, status.Error(codes.NotFound, fmt.Sprintf("repository %v is not tracking issues", tr.String()))
 	}
 
-	pth, err := calculateHost(tr)
+	pth, err := s.calculateHost(ctx, tr)
 	if err != nil {
 		return nil, err
 	}
 
-	conn, err := grpc.Dial(
-		pth,
-		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxMessageSize)),
-		grpc.WithInsecure(),
-		grpc.WithUnaryInterceptor(
-			grpc_middleware.ChainUnaryClient(
-				grpctrace.UnaryClientInterceptor(global.Tracer("maintner-rtr")),
-				buildRetryInterceptor(),
-			),
-		),
-	)
+	conn, release, err := s.pool.Get(ctx, pth)
 	if err != nil {
 		return nil, err
 	}
-	defer conn.Close()
+	defer release()
 
 	client := drghs_v1.NewIssueServiceClient(conn)
-	return client.GetIssue(ctx, r)
+	return client.ListStaleIssues(ctx, r)
 }
 
 func (s *reverseProxyServer) UpdateTrackedRepos(ctx context.Context, r *drghs_v1.UpdateTrackedReposRequest) (*drghs_v1.UpdateTrackedReposResponse, error) {
 	_, err := http.Get(fmt.Sprintf("http://%s/update", *sprvsrAddr))
-	s.reps.UpdateTrackedRepos(ctx)
+	updateTrackedRepos(ctx, s.reps, s.cache)
 
 	return &drghs_v1.UpdateTrackedReposResponse{}, err
 }
 
+// updateTrackedRepos updates reps and drops the cached responses of the
+// repositories it no longer tracks, or tracks with other settings, as those
+// may have been redeployed.
+func updateTrackedRepos(ctx context.Context, reps repos.RepoList, cache *responseCache) error {
+	before := reps.GetTrackedRepos()
+	_, err := reps.UpdateTrackedRepos(ctx)
+
+	after := make(map[repos.TrackedRepository]bool)
+	for _, tr := range reps.GetTrackedRepos() {
+		after[tr] = true
+	}
+	for _, tr := range before {
+		if !after[tr] {
+			cache.invalidate(tr.String())
+		}
+	}
+	return err
+}
+
 func getTrackedRepositories(ctx context.Context, c drghs_v1.IssueServiceClient) ([]*drghs_v1.Repository, error) {
 	ret := make([]*drghs_v1.Repository, 0)
 	npt := ""
@@ -367,7 +476,7 @@ func buildTR(path string) *repos.TrackedRepository {
 	return nil
 }
 
-func calculateHost(ta *repos.TrackedRepository) (string, error) {
+func (s *reverseProxyServer) calculateHost(ctx context.Context, ta *repos.TrackedRepository) (string, error) {
 	// We might need to put some more real "smarts" to this logic
 	// in the event we need to handle the /v1/owners/*/repositories
 	// call, which asks for a list of all repositories in a given org.
@@ -375,21 +484,13 @@ func calculateHost(ta *repos.TrackedRepository) (string, error) {
 	// forward to "null"?
 
 	if ta != nil {
-		sn, err := serviceName(ta)
-		if err != nil {
-			return "", err
-		}
-		return sn + ":80", nil
+		return s.resolver.Resolve(ctx, ta.Owner, ta.Name, resolver.PortGRPC)
 	}
 
 	log.Tracef("No match... returning null: %v", devnull)
 	return devnull, nil
 }
 
-func serviceName(t *repos.TrackedRepository) (string, error) {
-	return strings.ToLower(fmt.Sprintf("mtr-s-%s", t.RepoSha())), nil
-}
-
 func unaryInterceptorLog(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 	start := time.Now()
 	log.Tracef("Starting RPC: %v at %v", info.FullMethod, start)
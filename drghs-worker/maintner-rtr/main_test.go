@@ -15,9 +15,11 @@
 package main
 
 import (
+	"context"
 	"testing"
 
 	"github.com/GoogleCloudPlatform/devrel-services/repos"
+	"github.com/GoogleCloudPlatform/devrel-services/rtr/resolver"
 	"github.com/google/go-cmp/cmp"
 )
 
@@ -157,8 +159,9 @@ func TestCalculateHost(t *testing.T) {
 			WantErr: nil,
 		},
 	}
+	s := &reverseProxyServer{resolver: resolver.NewK8s("mtr-s-")}
 	for _, c := range tests {
-		got, gotErr := calculateHost(c.Input)
+		got, gotErr := s.calculateHost(context.Background(), c.Input)
 		if gotErr != c.WantErr {
 			t.Errorf("%v Errors Differ. Want %v. Got %v", c.Name, c.WantErr, gotErr)
 		}
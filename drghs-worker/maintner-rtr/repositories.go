@@ -0,0 +1,182 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"sync"
+
+	drghs_v1 "github.com/GoogleCloudPlatform/devrel-services/drghs/v1"
+	"github.com/GoogleCloudPlatform/devrel-services/repos"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListRepositories asks the maintner instance of every repository tracking
+// issues for its repositories, a bounded number at a time and each with its
+// own deadline. Instances that fail are listed in the response, or fail the
+// call if the request is strict.
+//
+// The repositories are sorted by name and the page token holds the name of
+// the last one returned, so paging is not thrown off by instances that come
+// and go between calls.
+func (s *reverseProxyServer) ListRepositories(ctx context.Context, r *drghs_v1.ListRepositoriesRequest) (*drghs_v1.ListRepositoriesResponse, error) {
+	after, err := decodeRepositoryPageToken(r.PageToken)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("invalid page_token: %v", err))
+	}
+
+	trs := make([]repos.TrackedRepository, 0)
+	for _, tr := range s.reps.GetTrackedRepos() {
+		if !tr.IsTrackingIssues {
+			log.Debugf("skipping repo: %v", tr.String())
+			continue
+		}
+		if !s.auth.CanRead(ctx, tr.Owner, tr.Name) {
+			continue
+		}
+		trs = append(trs, tr)
+	}
+
+	lists := make([][]*drghs_v1.Repository, len(trs))
+	errs := s.fanOut(ctx, trs, func(ctx context.Context, i int, tr repos.TrackedRepository) error {
+		pth, err := s.calculateHost(ctx, &tr)
+		if err != nil {
+			return err
+		}
+		log.Debugf("getting tracked repos from repo: %v path: %v", tr.String(), pth)
+		conn, release, err := s.pool.Get(ctx, pth)
+		if err != nil {
+			return err
+		}
+		defer release()
+
+		client := drghs_v1.NewIssueServiceClient(conn)
+		// Naive right now... every service has exactly one repo
+		lists[i], err = getTrackedRepositories(ctx, client)
+		return err
+	})
+
+	unreachable := unreachableBackends(trs, errs)
+	for _, u := range unreachable {
+		log.Warnf("got error listing repositories for repo: %v code: %v err: %v", u.Repository, codes.Code(u.Code), u.Message)
+	}
+	if r.Strict && len(unreachable) > 0 {
+		return nil, status.Error(codes.Unavailable, fmt.Sprintf("%v of %v backends unreachable, first: %v: %v", len(unreachable), len(trs), unreachable[0].Repository, unreachable[0].Message))
+	}
+
+	all := make([]*drghs_v1.Repository, 0)
+	for _, l := range lists {
+		all = append(all, l...)
+	}
+	pg, next := pageRepositories(all, after, pageSize(r.PageSize))
+
+	return &drghs_v1.ListRepositoriesResponse{
+		Repositories:        pg,
+		NextPageToken:       encodeRepositoryPageToken(next),
+		Total:               int32(len(all)),
+		UnreachableBackends: unreachable,
+	}, nil
+}
+
+// fanOut calls fn for every repository in trs, with at most s.fanout calls
+// in flight and each bounded by s.backendTimeout. It returns the error of
+// each call, in the order of trs.
+func (s *reverseProxyServer) fanOut(ctx context.Context, trs []repos.TrackedRepository, fn func(ctx context.Context, i int, tr repos.TrackedRepository) error) []error {
+	errs := make([]error, len(trs))
+	n := s.fanout
+	if n <= 0 || n > len(trs) {
+		n = len(trs)
+	}
+	sem := make(chan struct{}, n)
+
+	var wg sync.WaitGroup
+	for i, tr := range trs {
+		wg.Add(1)
+		go func(i int, tr repos.TrackedRepository) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			defer func() { <-sem }()
+
+			bctx := ctx
+			if s.backendTimeout > 0 {
+				var cancel context.CancelFunc
+				bctx, cancel = context.WithTimeout(ctx, s.backendTimeout)
+				defer cancel()
+			}
+			errs[i] = fn(bctx, i, tr)
+		}(i, tr)
+	}
+	wg.Wait()
+	return errs
+}
+
+// unreachableBackends describes the repositories of trs whose call in errs
+// failed.
+func unreachableBackends(trs []repos.TrackedRepository, errs []error) []*drghs_v1.UnreachableBackend {
+	ret := make([]*drghs_v1.UnreachableBackend, 0)
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		st := status.Convert(err)
+		if err == context.DeadlineExceeded {
+			st = status.New(codes.DeadlineExceeded, err.Error())
+		}
+		ret = append(ret, &drghs_v1.UnreachableBackend{
+			Repository: trs[i].String(),
+			Code:       int32(st.Code()),
+			Message:    st.Message(),
+		})
+	}
+	return ret
+}
+
+// pageRepositories sorts rs by name and returns at most size of those after
+// the name after, along with the name to continue from or "" if none are
+// left.
+func pageRepositories(rs []*drghs_v1.Repository, after string, size int) ([]*drghs_v1.Repository, string) {
+	sort.SliceStable(rs, func(i, j int) bool { return rs[i].Name < rs[j].Name })
+	start := sort.Search(len(rs), func(i int) bool { return rs[i].Name > after })
+	end := start + size
+	if end >= len(rs) {
+		return rs[start:], ""
+	}
+	return rs[start:end], rs[end-1].Name
+}
+
+func encodeRepositoryPageToken(after string) string {
+	if after == "" {
+		return ""
+	}
+	return base64.StdEncoding.EncodeToString([]byte(after))
+}
+
+func decodeRepositoryPageToken(token string) (string, error) {
+	b, err := base64.StdEncoding.DecodeString(token)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,142 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	drghs_v1 "github.com/GoogleCloudPlatform/devrel-services/drghs/v1"
+	"github.com/GoogleCloudPlatform/devrel-services/repos"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPageRepositories(t *testing.T) {
+	names := func(rs []*drghs_v1.Repository) []string {
+		ret := make([]string, 0)
+		for _, r := range rs {
+			ret = append(ret, r.Name)
+		}
+		return ret
+	}
+	all := []*drghs_v1.Repository{{Name: "foo/c"}, {Name: "foo/a"}, {Name: "bar/z"}, {Name: "foo/b"}}
+
+	tests := []struct {
+		After    string
+		Size     int
+		Want     []string
+		WantNext string
+	}{
+		{After: "", Size: 2, Want: []string{"bar/z", "foo/a"}, WantNext: "foo/a"},
+		{After: "foo/a", Size: 2, Want: []string{"foo/b", "foo/c"}, WantNext: ""},
+		{After: "", Size: 10, Want: []string{"bar/z", "foo/a", "foo/b", "foo/c"}, WantNext: ""},
+		// The repository the token points at went away.
+		{After: "foo/aa", Size: 1, Want: []string{"foo/b"}, WantNext: "foo/b"},
+		{After: "zzz", Size: 1, Want: []string{}, WantNext: ""},
+	}
+	for _, c := range tests {
+		got, next := pageRepositories(all, c.After, c.Size)
+		if diff := cmp.Diff(c.Want, names(got)); diff != "" || next != c.WantNext {
+			t.Errorf("pageRepositories(%q, %v) mismatch (-want +got):\n%s next Wanted %q, Got %q", c.After, c.Size, diff, c.WantNext, next)
+		}
+	}
+}
+
+func TestRepositoryPageToken(t *testing.T) {
+	for _, after := range []string{"", "foo/bar"} {
+		got, err := decodeRepositoryPageToken(encodeRepositoryPageToken(after))
+		if err != nil || got != after {
+			t.Errorf("decodeRepositoryPageToken(encodeRepositoryPageToken(%q)) Wanted %q, nil. Got %q, %v", after, after, got, err)
+		}
+	}
+	if _, err := decodeRepositoryPageToken("not a token"); err == nil {
+		t.Errorf("decodeRepositoryPageToken() of an invalid token. Wanted an error, Got nil")
+	}
+}
+
+func TestUnreachableBackends(t *testing.T) {
+	trs := []repos.TrackedRepository{
+		{Owner: "foo", Name: "a"},
+		{Owner: "foo", Name: "b"},
+		{Owner: "foo", Name: "c"},
+	}
+	errs := []error{
+		nil,
+		status.Error(codes.Unavailable, "connection refused"),
+		context.DeadlineExceeded,
+	}
+	want := []*drghs_v1.UnreachableBackend{
+		{Repository: "foo/b", Code: int32(codes.Unavailable), Message: "connection refused"},
+		{Repository: "foo/c", Code: int32(codes.DeadlineExceeded), Message: context.DeadlineExceeded.Error()},
+	}
+	got := unreachableBackends(trs, errs)
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreUnexported(drghs_v1.UnreachableBackend{})); diff != "" {
+		t.Errorf("unreachableBackends() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFanOut(t *testing.T) {
+	trs := make([]repos.TrackedRepository, 10)
+	s := &reverseProxyServer{fanout: 3, backendTimeout: 20 * time.Millisecond}
+
+	var inFlight, peak int32
+	errs := s.fanOut(context.Background(), trs, func(ctx context.Context, i int, tr repos.TrackedRepository) error {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		if i == 4 {
+			// A backend that hangs is cut off by its own deadline.
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		if i == 7 {
+			return errors.New("boom")
+		}
+		time.Sleep(5 * time.Millisecond)
+		return nil
+	})
+
+	if peak > 3 {
+		t.Errorf("fanOut() ran %v calls at once. Wanted at most 3", peak)
+	}
+	for i, err := range errs {
+		switch i {
+		case 4:
+			if err != context.DeadlineExceeded {
+				t.Errorf("fanOut() error of the hanging call. Wanted %v, Got %v", context.DeadlineExceeded, err)
+			}
+		case 7:
+			if err == nil {
+				t.Errorf("fanOut() error of the failing call. Wanted an error, Got nil")
+			}
+		default:
+			if err != nil {
+				t.Errorf("fanOut() error of call %v. Wanted nil, Got %v", i, err)
+			}
+		}
+	}
+}
@@ -16,16 +16,22 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
-	"math/rand"
+	"io/ioutil"
+	"net/http"
 	"os"
+	"os/exec"
 	"path"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/GoogleCloudPlatform/devrel-services/drghs-worker/pkg/tokens"
 	"github.com/GoogleCloudPlatform/devrel-services/repos"
+	"github.com/GoogleCloudPlatform/devrel-services/rtr/resolver"
 	"github.com/GoogleCloudPlatform/devrel-services/sprvsr"
 
 	"cloud.google.com/go/errorreporting"
@@ -34,6 +40,7 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -52,6 +59,23 @@ var (
 	sasecretname     = flag.String("service-account-secret", "", "The name of the ServiceAccount for our Pods to run as")
 	mimagename       = flag.String("maint-image-name", "", "The name of the image to run maintner")
 	mutationBucket   = flag.String("mutation-bucket", "", "The bucket to store mutation data")
+	retainClosedDays = flag.Int("maint-retain-closed-days", 0, "Passed to maintnerd as --retain-closed-days. 0 keeps every issue's text in memory")
+	backend          = flag.String("backend", "k8s", "Where to run maintnerd: k8s deploys it to the cluster, local runs it as a child process")
+	maintnerdPath    = flag.String("maintnerd-path", "maintnerd", "The maintnerd binary to run with --backend=local")
+	addressFile      = flag.String("address-file", "", "With --backend=local, the file to write the addresses of each maintnerd to, for the routers' static resolver")
+	dryRun           = flag.Bool("dry-run", false, "With --backend=k8s, log the changes an update would make to the cluster instead of making them")
+	maxDeletions     = flag.Int("max-deletions", 0, "The most deployments an update may delete. 0 sets no limit")
+	maxDeletePct     = flag.Float64("max-deletion-percent", 0, "The most deployments an update may delete, as a percentage of those managed. 0 sets no limit")
+	allowEmpty       = flag.Bool("allow-empty-repos", false, "Let an update delete every deployment when the list of repositories is empty")
+	deleteGrace      = flag.Duration("deletion-grace-period", 0, "How long a repository has to be untracked before its deployment is deleted")
+	reconcileEvery   = flag.Duration("reconcile-interval", 0, "How often to repair deployments and services edited or deleted by hand. 0 only updates the cluster when the list of repositories changes")
+	leaseName        = flag.String("leader-election-lease", "", "With --backend=k8s, the Lease the replicas of the supervisor compete for, so only one updates the cluster. Empty runs a single replica")
+	leaderID         = flag.String("leader-election-id", "", "The host:port the other replicas reach this one at. Defaults to the POD_IP environment variable and the --listen port")
+	deployTemplate   = flag.String("deployment-template", "", "With --backend=k8s, a YAML template to render the Deployment of each repository from, instead of the built-in one. Needs --service-template")
+	serviceTemplate  = flag.String("service-template", "", "With --backend=k8s, a YAML template to render the Service of each repository from, instead of the built-in one. Needs --deployment-template")
+	tokenUsageEvery  = flag.Duration("token-usage-interval", 10*time.Minute, "With --backend=k8s, how often to check how much of its rate limit each GitHub token used, to move repositories off those near it. 0 never checks")
+	tokenMaxUsage    = flag.Float64("token-max-usage", 0.8, "The share of its rate limit a GitHub token may use before repositories move to other tokens")
+	tokenUsageMap    = flag.String("token-usage-configmap", "maintner-token-usage", "With --backend=k8s, the ConfigMap to keep the rate limit usage of the GitHub tokens in, so repositories keep their tokens across restarts and failovers")
 )
 
 // Config
@@ -60,8 +84,19 @@ var (
 	errorClient *errorreporting.Client
 	config      *rest.Config
 	mu          sync.RWMutex
+	// tokenAssigner picks the GitHub token of the repositories that do not
+	// set one
+	tokenAssigner = tokens.NewAssigner()
 )
 
+// tokenUsageWindow is how long the rate limit usage of a GitHub token
+// weighs on the repositories it is assigned.
+const tokenUsageWindow = 24 * time.Hour
+
+// tokenUsageKey is the key of the usage of the GitHub tokens in the
+// --token-usage-configmap ConfigMap.
+const tokenUsageKey = "usage.json"
+
 // Log
 var log *logrus.Logger
 
@@ -117,74 +152,144 @@ func main() {
 		log.Fatal(err)
 	}
 
-	if *githubSecretName == "" {
-		err := fmt.Errorf("must provide --github-secret")
+	repoList = repos.NewBucketRepo(*settingsBucket, *reposFileName)
+
+	var super sprvsr.Supervisor
+	switch *backend {
+	case "k8s":
+		super, err = newK8sSupervisor()
+	case "local":
+		super, err = newLocalSupervisor()
+	default:
+		err = fmt.Errorf("unknown --backend %q. must be k8s or local", *backend)
+	}
+	if err != nil {
 		logAndPrintError(err)
 		log.Fatal(err)
 	}
 
+	log.Fatal(super.Supervise(*listen, logAndPrintError))
+}
+
+func newK8sSupervisor() (sprvsr.Supervisor, error) {
+	if *githubSecretName == "" {
+		return nil, fmt.Errorf("must provide --github-secret")
+	}
+
 	if *sasecretname == "" {
-		err := fmt.Errorf("must provide --service-account-secret")
-		logAndPrintError(err)
-		log.Fatal(err)
+		return nil, fmt.Errorf("must provide --service-account-secret")
 	}
 
 	if *mimagename == "" {
-		err := fmt.Errorf("must provide --maint-image-name")
-		logAndPrintError(err)
-		log.Fatal(err)
+		return nil, fmt.Errorf("must provide --maint-image-name")
 	}
 	if *mutationBucket == "" {
-		err := fmt.Errorf("must provide --mutation-bucket")
-		logAndPrintError(err)
-		log.Fatal(err)
+		return nil, fmt.Errorf("must provide --mutation-bucket")
 	}
 
 	// Init k8s info
 	// creates the in-cluster config
+	var err error
 	config, err = rest.InClusterConfig()
 	if err != nil {
-		logAndPrintError(err)
-		log.Fatal(err)
+		return nil, err
 	}
 
 	// We need to interface with the k8s api
 	cs, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		logAndPrintError(err)
-		log.Fatal(err)
+		return nil, err
 	}
 
-	repoList = repos.NewBucketRepo(*settingsBucket, *reposFileName)
-
 	preDeploy := func(ta repos.TrackedRepository) error {
 		return nil
 	}
 
+	if *tokenUsageEvery > 0 {
+		// Weigh the tokens as before a restart until they are checked again,
+		// so the repositories don't move
+		usage := tokens.NewUsage(tokenUsageWindow, *tokenMaxUsage)
+		if err := loadTokenUsage(cs, apiv1.NamespaceDefault, usage); err != nil {
+			log.Errorf("could not load the usage of the GitHub tokens: %v", err)
+		}
+		tokenAssigner.SetWeights(usage.Weights(time.Now()))
+		go watchTokenUsage(context.Background(), cs, apiv1.NamespaceDefault, usage)
+	}
+
 	cdeployment := func(ta repos.TrackedRepository) (*appsv1.Deployment, error) {
-		githubsecretkey, err := getGithubSecretName(cs, apiv1.NamespaceDefault)
+		githubsecretkey, err := githubSecretKey(cs, apiv1.NamespaceDefault, ta)
 		if err != nil {
 			return nil, err
 		}
 		return buildDeployment(*sasecretname, *githubSecretName, githubsecretkey, ta)
 	}
 
+	bd, bs, err := templateBuilders(cdeployment, buildService, func(ta repos.TrackedRepository) (map[string]interface{}, error) {
+		return templateValues(cs, ta)
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	kcfg := sprvsr.K8sConfiguration{
 		ServiceNamer:      serviceName,
 		DeploymentNamer:   deploymentName,
-		ServiceBuilder:    buildService,
-		DeploymentBuilder: cdeployment,
+		ServiceBuilder:    bs,
+		DeploymentBuilder: bd,
 		PreDeploy:         preDeploy,
 		ShouldDeploy:      shouldDeploy,
+		DryRun:            *dryRun,
+		Guard: sprvsr.DeletionGuard{
+			MaxDeletions:       *maxDeletions,
+			MaxDeletionPercent: *maxDeletePct,
+			AllowEmpty:         *allowEmpty,
+			GracePeriod:        *deleteGrace,
+		},
+		ReconcileInterval: *reconcileEvery,
+	}
+	if *leaseName != "" {
+		id := *leaderID
+		if id == "" {
+			var err error
+			if id, err = sprvsr.PodIdentity(*listen); err != nil {
+				return nil, fmt.Errorf("could not derive --leader-election-id: %v", err)
+			}
+		}
+		kcfg.LeaderElection = &sprvsr.LeaderElection{
+			LeaseName: *leaseName,
+			Identity:  id,
+		}
 	}
 
-	super, err := sprvsr.NewK8sSupervisor(log, cs, kcfg, repoList, "maintner")
+	return sprvsr.NewK8sSupervisor(log, cs, kcfg, repoList, "maintner")
+}
+
+// newLocalSupervisor runs a maintnerd process on this machine for each
+// repository. They share the GitHub token in the GITHUB_TOKEN environment
+// variable.
+func newLocalSupervisor() (sprvsr.Supervisor, error) {
+	if *mutationBucket == "" {
+		return nil, fmt.Errorf("must provide --mutation-bucket")
+	}
+	if os.Getenv("GITHUB_TOKEN") == "" {
+		return nil, fmt.Errorf("must set GITHUB_TOKEN with --backend=local")
+	}
+	bin, err := exec.LookPath(*maintnerdPath)
 	if err != nil {
-		logAndPrintError(err)
-		log.Fatal(err)
+		return nil, err
 	}
 
-	log.Fatal(super.Supervise(*listen, logAndPrintError))
+	lcfg := sprvsr.LocalConfiguration{
+		ProcessNamer: deploymentName,
+		ProcessBuilder: func(ta repos.TrackedRepository, ports map[string]int) (*exec.Cmd, error) {
+			return buildProcess(bin, ta, ports)
+		},
+		ShouldDeploy: shouldDeploy,
+		Ports:        []string{resolver.PortGRPC, resolver.PortInternal},
+		AddressFile:  *addressFile,
+	}
+
+	return sprvsr.NewLocalSupervisor(log, lcfg, repoList)
 }
 
 func logAndPrintError(err error) {
@@ -194,7 +299,11 @@ func logAndPrintError(err error) {
 	log.Error(err)
 }
 
-func getGithubSecretName(cs *kubernetes.Clientset, ns string) (string, error) {
+// githubSecretKey returns the key of the GitHub token ta uses in the
+// --github-secret Secret: the token the repos file sets for it, or else the
+// one tokenAssigner picks, so its Deployment keeps the same token from one
+// update to the next.
+func githubSecretKey(cs *kubernetes.Clientset, ns string, ta repos.TrackedRepository) (string, error) {
 	// We need some information to add our deployments... in particular, we
 	// need the set of github keys we have available as secrets
 	availablesecrets, err := getTokenNames(cs, ns, *githubSecretName)
@@ -209,21 +318,130 @@ func getGithubSecretName(cs *kubernetes.Clientset, ns string) (string, error) {
 	}
 	log.Debugf("have secrets to vend: %v", len(availablesecrets))
 
-	src := rand.NewSource(time.Now().UnixNano())
-	rng := rand.New(src)
+	if ta.Token != "" {
+		for _, k := range availablesecrets {
+			if k == ta.Token {
+				return k, nil
+			}
+		}
+		err := fmt.Errorf("no token %v for %v stored in %v", ta.Token, ta, *githubSecretName)
+		logAndPrintError(err)
+		return "", err
+	}
+	return tokenAssigner.Assign(ta.String(), availablesecrets)
+}
 
-	// Get a random key for our secrets
-	// TODO(colnnelson): if a Tracked Repository specifies
-	// a particular key to use, look that up and use it instead
-	//
-	// rng.Intn() returns a random int32 between 0 and n, so we need
-	// to guard against 0
-	idx := 0
-	if len(availablesecrets) != 1 {
-		idx = rng.Intn(len(availablesecrets) - 1)
+// watchTokenUsage checks the rate limit of each GitHub token every
+// --token-usage-interval until ctx is done, records it in usage and weighs
+// the tokens of tokenAssigner by it.
+func watchTokenUsage(ctx context.Context, cs *kubernetes.Clientset, ns string, usage *tokens.Usage) {
+	ticker := time.NewTicker(*tokenUsageEvery)
+	defer ticker.Stop()
+	for {
+		secrets, err := getTokens(cs, ns, *githubSecretName)
+		if err != nil {
+			log.Errorf("could not get the GitHub tokens: %v", err)
+		}
+		for name, token := range secrets {
+			used, limit, err := rateLimit(ctx, token)
+			if err != nil {
+				log.Errorf("could not get the rate limit of token %v: %v", name, err)
+				continue
+			}
+			log.Debugf("token %v used %v of %v", name, used, limit)
+			usage.Observe(name, used, limit, time.Now())
+		}
+		weights := usage.Weights(time.Now())
+		log.Debugf("token weights: %v", weights)
+		tokenAssigner.SetWeights(weights)
+		if err := saveTokenUsage(cs, ns, usage); err != nil {
+			log.Errorf("could not save the usage of the GitHub tokens: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// loadTokenUsage adds the usage of the GitHub tokens saved in the
+// --token-usage-configmap ConfigMap, if any, to usage.
+func loadTokenUsage(cs kubernetes.Interface, ns string, usage *tokens.Usage) error {
+	cm, err := cs.CoreV1().ConfigMaps(ns).Get(*tokenUsageMap, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	data, ok := cm.Data[tokenUsageKey]
+	if !ok {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(data), usage); err != nil {
+		return fmt.Errorf("parsing ConfigMap %v: %v", *tokenUsageMap, err)
+	}
+	return nil
+}
+
+// saveTokenUsage saves usage in the --token-usage-configmap ConfigMap,
+// creating it if needed. Every replica observes the same tokens, so the
+// last to save wins.
+func saveTokenUsage(cs kubernetes.Interface, ns string, usage *tokens.Usage) error {
+	b, err := json.Marshal(usage)
+	if err != nil {
+		return err
+	}
+	cms := cs.CoreV1().ConfigMaps(ns)
+	cm, err := cms.Get(*tokenUsageMap, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = cms.Create(&apiv1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: *tokenUsageMap},
+			Data:       map[string]string{tokenUsageKey: string(b)},
+		})
+		return err
 	}
-	keyName := availablesecrets[idx]
-	return keyName, nil
+	if err != nil {
+		return err
+	}
+	if cm.Data == nil {
+		cm.Data = make(map[string]string)
+	}
+	cm.Data[tokenUsageKey] = string(b)
+	_, err = cms.Update(cm)
+	return err
+}
+
+// rateLimit returns how many requests of its core rate limit the GitHub
+// token used. Asking does not count against it.
+func rateLimit(ctx context.Context, token string) (used, limit int, err error) {
+	req, err := http.NewRequest("GET", "https://api.github.com/rate_limit", nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	req.Header.Set("Authorization", "token "+token)
+	res, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, 0, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return 0, 0, fmt.Errorf("getting the rate limit: %v", res.Status)
+	}
+	var rl struct {
+		Resources struct {
+			Core struct {
+				Limit     int `json:"limit"`
+				Remaining int `json:"remaining"`
+			} `json:"core"`
+		} `json:"resources"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&rl); err != nil {
+		return 0, 0, err
+	}
+	return rl.Resources.Core.Limit - rl.Resources.Core.Remaining, rl.Resources.Core.Limit, nil
 }
 
 func serviceName(t repos.TrackedRepository) (string, error) {
@@ -276,6 +494,12 @@ func buildDeployment(sasecretname, githubsecretname, githubsecretkey string, ta
 		return nil, err
 	}
 	enableServiceLinks := false
+	command := maintnerdCommand("/maintnerd", "$(GITHUB_TOKEN)", ":80", ":8080", ta)
+	resources, err := sprvsr.WithResources(defaultResources(), ta.Resources)
+	if err != nil {
+		return nil, fmt.Errorf("resources of %v: %v", ta, err)
+	}
+
 	return &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: dep,
@@ -311,19 +535,9 @@ func buildDeployment(sasecretname, githubsecretname, githubsecretkey string, ta
 					Containers: []apiv1.Container{
 						apiv1.Container{
 							Name:            "maintnerd",
-							Image:           *mimagename,
+							Image:           sprvsr.ImageWithTag(*mimagename, ta.ImageTag),
 							ImagePullPolicy: "Always",
-							Command: []string{
-								"/maintnerd",
-								fmt.Sprintf("--bucket=%v", bucketName(ta)),
-								"--verbose",
-								"--token=$(GITHUB_TOKEN)",
-								"--listen=:80",
-								"--intListen=:8080",
-								fmt.Sprintf("--gcp-project=%v", *projectID),
-								fmt.Sprintf("--owner=%v", ta.Owner),
-								fmt.Sprintf("--repo=%v", ta.Name),
-							},
+							Command:         command,
 							Ports: []apiv1.ContainerPort{
 								{
 									Name:          "http",
@@ -366,21 +580,7 @@ func buildDeployment(sasecretname, githubsecretname, githubsecretkey string, ta
 									MountPath: "/var/secrets/google",
 								},
 							},
-							Resources: apiv1.ResourceRequirements{
-								Requests: apiv1.ResourceList{
-									// Our application does not need "that" much CPU.
-									// For context, if unspecified, GKE applies a default request of "100m"
-									apiv1.ResourceCPU:    resource.MustParse("50m"),
-									apiv1.ResourceMemory: resource.MustParse("160M"),
-								},
-								Limits: apiv1.ResourceList{
-									// Limit the CPU ask
-									apiv1.ResourceCPU: resource.MustParse("1000m"),
-									// As of this writing the "monolithic" maintner service is
-									// consuming 3 GB of RAM, and peaked at 3.4 GB.
-									apiv1.ResourceMemory: resource.MustParse("2G"),
-								},
-							},
+							Resources: resources,
 						},
 					},
 				},
@@ -389,6 +589,121 @@ func buildDeployment(sasecretname, githubsecretname, githubsecretkey string, ta
 	}, nil
 }
 
+// templateBuilders returns the builders rendering --deployment-template and
+// --service-template with values, or bd and bs if they are not set.
+func templateBuilders(bd sprvsr.DeploymentBuilder, bs sprvsr.ServiceBuilder, values func(repos.TrackedRepository) (map[string]interface{}, error)) (sprvsr.DeploymentBuilder, sprvsr.ServiceBuilder, error) {
+	if *deployTemplate == "" && *serviceTemplate == "" {
+		return bd, bs, nil
+	}
+	if *deployTemplate == "" || *serviceTemplate == "" {
+		return nil, nil, fmt.Errorf("must provide both --deployment-template and --service-template")
+	}
+	dtmpl, err := ioutil.ReadFile(*deployTemplate)
+	if err != nil {
+		return nil, nil, err
+	}
+	stmpl, err := ioutil.ReadFile(*serviceTemplate)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sprvsr.NewTemplateBuilders(sprvsr.TemplateConfiguration{
+		Deployment:      string(dtmpl),
+		Service:         string(stmpl),
+		DeploymentNamer: deploymentName,
+		ServiceNamer:    serviceName,
+		Values:          values,
+	})
+}
+
+// templateValues returns the .Values of the templates of ta: what
+// buildDeployment would set.
+func templateValues(cs *kubernetes.Clientset, ta repos.TrackedRepository) (map[string]interface{}, error) {
+	githubsecretkey, err := githubSecretKey(cs, apiv1.NamespaceDefault, ta)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := sprvsr.WithResources(defaultResources(), ta.Resources)
+	if err != nil {
+		return nil, fmt.Errorf("resources of %v: %v", ta, err)
+	}
+	return map[string]interface{}{
+		"Image":                sprvsr.ImageWithTag(*mimagename, ta.ImageTag),
+		"Command":              maintnerdCommand("/maintnerd", "$(GITHUB_TOKEN)", ":80", ":8080", ta),
+		"Resources":            resources,
+		"ServiceAccountSecret": *sasecretname,
+		"GitHubSecret":         *githubSecretName,
+		"GitHubSecretKey":      githubsecretkey,
+	}, nil
+}
+
+// defaultResources returns the resources of a repository's maintnerd that
+// the repos file does not override.
+func defaultResources() apiv1.ResourceRequirements {
+	return apiv1.ResourceRequirements{
+		Requests: apiv1.ResourceList{
+			// Our application does not need "that" much CPU.
+			// For context, if unspecified, GKE applies a default request of "100m"
+			apiv1.ResourceCPU:    resource.MustParse("50m"),
+			apiv1.ResourceMemory: resource.MustParse("160M"),
+		},
+		Limits: apiv1.ResourceList{
+			// Limit the CPU ask
+			apiv1.ResourceCPU: resource.MustParse("1000m"),
+			// As of this writing the "monolithic" maintner service is
+			// consuming 3 GB of RAM, and peaked at 3.4 GB.
+			apiv1.ResourceMemory: resource.MustParse("2G"),
+		},
+	}
+}
+
+// buildProcess returns the command running the maintnerd binary bin for ta,
+// listening on the given ports. The GitHub token is passed in the
+// environment: the command line of a process is visible to every user.
+func buildProcess(bin string, ta repos.TrackedRepository, ports map[string]int) (*exec.Cmd, error) {
+	command := maintnerdCommand(
+		bin,
+		"",
+		fmt.Sprintf("localhost:%v", ports[resolver.PortGRPC]),
+		fmt.Sprintf("localhost:%v", ports[resolver.PortInternal]),
+		ta,
+	)
+	if *retainClosedDays > 0 {
+		// The processes must not share their retention directories.
+		name, err := deploymentName(ta)
+		if err != nil {
+			return nil, err
+		}
+		command = append(command, fmt.Sprintf("--retention-dir=%v", filepath.Join(os.TempDir(), "maintnr-retention", name)))
+	}
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Env = append(os.Environ(), "GITHUB_TOKEN="+os.Getenv("GITHUB_TOKEN"))
+	return cmd, nil
+}
+
+// maintnerdCommand returns the command line of the maintnerd binary bin for
+// ta. Without a token, maintnerd reads it from GITHUB_TOKEN.
+func maintnerdCommand(bin, token, listen, intListen string, ta repos.TrackedRepository) []string {
+	command := []string{
+		bin,
+		fmt.Sprintf("--bucket=%v", bucketName(ta)),
+		"--verbose",
+	}
+	if token != "" {
+		command = append(command, fmt.Sprintf("--token=%v", token))
+	}
+	command = append(command,
+		fmt.Sprintf("--listen=%v", listen),
+		fmt.Sprintf("--intListen=%v", intListen),
+		fmt.Sprintf("--gcp-project=%v", *projectID),
+		fmt.Sprintf("--owner=%v", ta.Owner),
+		fmt.Sprintf("--repo=%v", ta.Name),
+	)
+	if *retainClosedDays > 0 {
+		command = append(command, fmt.Sprintf("--retain-closed-days=%v", *retainClosedDays))
+	}
+	return append(command, ta.Flags()...)
+}
+
 func getTokenNames(clientset *kubernetes.Clientset, ns, secretname string) ([]string, error) {
 	secret, err := clientset.CoreV1().Secrets(ns).Get(secretname, metav1.GetOptions{})
 	if err != nil {
@@ -403,6 +718,19 @@ func getTokenNames(clientset *kubernetes.Clientset, ns, secretname string) ([]st
 	return avail, nil
 }
 
+// getTokens returns the GitHub tokens in the Secret secretname, by key.
+func getTokens(clientset *kubernetes.Clientset, ns, secretname string) (map[string]string, error) {
+	secret, err := clientset.CoreV1().Secrets(ns).Get(secretname, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(secret.Data))
+	for k, v := range secret.Data {
+		values[k] = strings.TrimSpace(string(v))
+	}
+	return values, nil
+}
+
 func createBucket(ctx context.Context, ta repos.TrackedRepository, projectID string) error {
 	sc, err := storage.NewClient(ctx)
 	if err != nil {
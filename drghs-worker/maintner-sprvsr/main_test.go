@@ -0,0 +1,157 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/devrel-services/drghs-worker/pkg/tokens"
+	"github.com/GoogleCloudPlatform/devrel-services/repos"
+	"github.com/GoogleCloudPlatform/devrel-services/rtr/resolver"
+	"github.com/GoogleCloudPlatform/devrel-services/sprvsr"
+
+	"github.com/google/go-cmp/cmp"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestResolverFindsBuiltServices(t *testing.T) {
+	tmpl, err := ioutil.ReadFile("templates/service.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, bs, err := sprvsr.NewTemplateBuilders(sprvsr.TemplateConfiguration{
+		Deployment:      "metadata: {name: {{.Deployment}}}",
+		Service:         string(tmpl),
+		DeploymentNamer: deploymentName,
+		ServiceNamer:    serviceName,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ta := repos.TrackedRepository{Owner: "foo", Name: "bar"}
+	builders := []struct {
+		Name  string
+		Build func(repos.TrackedRepository) (*apiv1.Service, error)
+	}{
+		{Name: "buildService", Build: buildService},
+		{Name: "templates/service.yaml", Build: bs},
+	}
+	for _, b := range builders {
+		svc, err := b.Build(ta)
+		if err != nil {
+			t.Errorf("Test: %v Got an error building the service: %v", b.Name, err)
+			continue
+		}
+		for _, port := range []string{resolver.PortGRPC, resolver.PortInternal} {
+			got, err := resolver.NewK8s("mtr-s-").Resolve(context.Background(), ta.Owner, ta.Name, port)
+			if err != nil {
+				t.Errorf("Test: %v Got an error resolving port %v: %v", b.Name, port, err)
+				continue
+			}
+			want := ""
+			for _, p := range svc.Spec.Ports {
+				// SRV records are named after the ports of the Service
+				if p.Name == port {
+					want = net.JoinHostPort(svc.Name, fmt.Sprint(p.Port))
+				}
+			}
+			if got != want {
+				t.Errorf("Test: %v Port: %v Wanted %v Got %v", b.Name, port, want, got)
+			}
+		}
+	}
+}
+
+func TestTokenUsageSurvivesRestarts(t *testing.T) {
+	cs := fake.NewSimpleClientset()
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		usage := tokens.NewUsage(tokenUsageWindow, 0.8)
+		if err := loadTokenUsage(cs, apiv1.NamespaceDefault, usage); err != nil {
+			t.Fatalf("Got an error loading the token usage: %v", err)
+		}
+		if i == 0 {
+			usage.Observe("one", 5000, 5000, now)
+		}
+		if err := saveTokenUsage(cs, apiv1.NamespaceDefault, usage); err != nil {
+			t.Fatalf("Got an error saving the token usage: %v", err)
+		}
+	}
+
+	usage := tokens.NewUsage(tokenUsageWindow, 0.8)
+	if err := loadTokenUsage(cs, apiv1.NamespaceDefault, usage); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]float64{"one": 0.1}
+	if diff := cmp.Diff(want, usage.Weights(now)); diff != "" {
+		t.Errorf("Weights after restarts differ (-want +got)\n%s", diff)
+	}
+}
+
+func TestBuildProcessKeepsTokenOffCommandLine(t *testing.T) {
+	old, had := os.LookupEnv("GITHUB_TOKEN")
+	os.Setenv("GITHUB_TOKEN", "secret-token")
+	defer func() {
+		if had {
+			os.Setenv("GITHUB_TOKEN", old)
+		} else {
+			os.Unsetenv("GITHUB_TOKEN")
+		}
+	}()
+
+	ta := repos.TrackedRepository{Owner: "foo", Name: "bar"}
+	cmd, err := buildProcess("/maintnerd", ta, map[string]int{resolver.PortGRPC: 6343, resolver.PortInternal: 6344})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, arg := range cmd.Args {
+		if strings.Contains(arg, "secret-token") {
+			t.Errorf("buildProcess() put the token on the command line: %v", arg)
+		}
+	}
+	found := false
+	for _, env := range cmd.Env {
+		if env == "GITHUB_TOKEN=secret-token" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("buildProcess() did not pass the token in the environment. Got %v", cmd.Env)
+	}
+
+	// Deployments reference the Secret, not the token
+	if got := maintnerdCommand("/maintnerd", "$(GITHUB_TOKEN)", ":80", ":8080", ta); !contains(got, "--token=$(GITHUB_TOKEN)") {
+		t.Errorf("maintnerdCommand() Wanted --token=$(GITHUB_TOKEN), Got %v", got)
+	}
+}
+
+func contains(s []string, v string) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,316 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	drghs_v1 "github.com/GoogleCloudPlatform/devrel-services/drghs/v1"
+	"github.com/robfig/cron/v3"
+	"golang.org/x/time/rate"
+)
+
+// sweeper sweeps the tracked repositories, once or on a schedule.
+type sweeper struct {
+	drghsc  drghs_v1.IssueServiceClient
+	limiter *rate.Limiter
+	// workers is how many repositories are processed at a time.
+	workers int
+	// checkpoint is the file the progress of a sweep is kept in, so a
+	// restarted sweeper resumes it. Empty keeps none.
+	checkpoint string
+	status     *status
+	// process sweeps a single repository.
+	process func(ctx context.Context, repo *drghs_v1.Repository) (*repoReport, error)
+	// onError is called with the error of each repository that fails.
+	onError func(err error)
+	// onReport is called with the report of each sweep.
+	onReport func(rpt *report)
+}
+
+// progress is what a sweep has done so far.
+type progress struct {
+	Started time.Time `json:"started"`
+	// Done is the reports of the repositories already swept, by name.
+	Done map[string]*repoReport `json:"done"`
+}
+
+// run lists the tracked repositories and sweeps them, resuming the sweep in
+// the checkpoint if there is one.
+func (s *sweeper) run(ctx context.Context, cfg sweepConfig) *report {
+	rpt := &report{
+		DryRun:              cfg.DryRun,
+		MaxTombstonePercent: cfg.MaxTombstonePercent,
+		Started:             time.Now(),
+	}
+	s.status.start(rpt.Started)
+	defer func() { s.status.finish(rpt.Finished) }()
+
+	repos, err := getTrackedRepositories(ctx, s.drghsc)
+	if err != nil {
+		log.Errorf("listing tracked repositories: %v", err)
+		s.onError(err)
+		rpt.Finished = time.Now()
+		return rpt
+	}
+
+	var nipr int32
+	for _, repo := range repos {
+		nipr = nipr + repo.PullRequestCount
+		nipr = nipr + repo.IssueCount
+	}
+	log.Debugf("have %v repositories to query with a total of %v Issues and PRs", len(repos), nipr)
+
+	// Queries per second as we retrieve 100 issues at a time from GitHub
+	l := buildLimiter(nipr)
+	s.limiter.SetLimit(l.Limit())
+	s.limiter.SetBurst(l.Burst())
+
+	s.sweep(ctx, repos, rpt)
+	rpt.Finished = time.Now()
+	if s.onReport != nil {
+		s.onReport(rpt)
+	}
+	return rpt
+}
+
+// sweep processes repos with s.workers workers and adds their reports to
+// rpt. Repositories the checkpoint says are done are not processed again.
+// The checkpoint is removed once every repository is done.
+func (s *sweeper) sweep(ctx context.Context, repos []*drghs_v1.Repository, rpt *report) {
+	prog, err := s.loadProgress()
+	if err != nil {
+		log.Warnf("ignoring the checkpoint: %v", err)
+	}
+	if prog == nil {
+		prog = &progress{Started: rpt.Started, Done: make(map[string]*repoReport)}
+	} else {
+		log.Infof("resuming the sweep started at %v. %v repositories already done", prog.Started, len(prog.Done))
+		rpt.Started = prog.Started
+	}
+
+	left := make([]*drghs_v1.Repository, 0, len(repos))
+	for _, repo := range repos {
+		if _, ok := prog.Done[repo.Name]; !ok {
+			left = append(left, repo)
+		}
+	}
+
+	var mu sync.Mutex
+	todo := make(chan *drghs_v1.Repository)
+	var wg sync.WaitGroup
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for repo := range todo {
+				start := time.Now()
+				rr, err := s.process(ctx, repo)
+				if ctx.Err() != nil {
+					// Interrupted: left for the resumed sweep.
+					continue
+				}
+				if err != nil {
+					s.onError(err)
+				}
+				s.status.record(rr, start, time.Now())
+
+				mu.Lock()
+				prog.Done[repo.Name] = rr
+				if err := s.saveProgress(prog); err != nil {
+					log.Errorf("writing the checkpoint: %v", err)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, repo := range left {
+		select {
+		case todo <- repo:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(todo)
+	wg.Wait()
+
+	names := make([]string, 0, len(prog.Done))
+	for name := range prog.Done {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		rpt.add(prog.Done[name])
+	}
+
+	if ctx.Err() == nil {
+		if err := s.removeProgress(); err != nil {
+			log.Errorf("removing the checkpoint: %v", err)
+		}
+	}
+}
+
+// loadProgress returns the progress in the checkpoint, or nil if there is
+// none.
+func (s *sweeper) loadProgress() (*progress, error) {
+	if s.checkpoint == "" {
+		return nil, nil
+	}
+	b, err := ioutil.ReadFile(s.checkpoint)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	prog := &progress{}
+	if err := json.Unmarshal(b, prog); err != nil {
+		return nil, err
+	}
+	if prog.Done == nil {
+		prog.Done = make(map[string]*repoReport)
+	}
+	return prog, nil
+}
+
+// saveProgress replaces the checkpoint with prog.
+func (s *sweeper) saveProgress(prog *progress) error {
+	if s.checkpoint == "" {
+		return nil
+	}
+	b, err := json.Marshal(prog)
+	if err != nil {
+		return err
+	}
+	// Write then rename, so a crash never leaves half a checkpoint.
+	tmp, err := ioutil.TempFile(filepath.Dir(s.checkpoint), filepath.Base(s.checkpoint)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.checkpoint)
+}
+
+func (s *sweeper) removeProgress() error {
+	if s.checkpoint == "" {
+		return nil
+	}
+	err := os.Remove(s.checkpoint)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// runDaemon sweeps at the times of sched until ctx is done. An interrupted
+// sweep in the checkpoint is resumed right away.
+func (s *sweeper) runDaemon(ctx context.Context, sched cron.Schedule, cfg sweepConfig) error {
+	if prog, _ := s.loadProgress(); prog != nil {
+		s.run(ctx, cfg)
+	}
+	for {
+		next := sched.Next(time.Now())
+		s.status.schedule(next)
+		log.Infof("next sweep at %v", next)
+
+		t := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+		s.run(ctx, cfg)
+	}
+}
+
+// status is the state of the sweeper and the last run of each repository.
+// It is safe for concurrent use.
+type status struct {
+	mu           sync.RWMutex
+	Running      bool                   `json:"running"`
+	LastStarted  time.Time              `json:"last_started"`
+	LastFinished time.Time              `json:"last_finished"`
+	NextRun      time.Time              `json:"next_run"`
+	Repositories map[string]*repoStatus `json:"repositories"`
+}
+
+// repoStatus is the last run of a repository.
+type repoStatus struct {
+	Started  time.Time   `json:"started"`
+	Finished time.Time   `json:"finished"`
+	Report   *repoReport `json:"report"`
+}
+
+func newStatus() *status {
+	return &status{Repositories: make(map[string]*repoStatus)}
+}
+
+func (st *status) start(t time.Time) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.Running = true
+	st.LastStarted = t
+}
+
+func (st *status) finish(t time.Time) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.Running = false
+	st.LastFinished = t
+}
+
+func (st *status) schedule(next time.Time) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.NextRun = next
+}
+
+func (st *status) record(rr *repoReport, started, finished time.Time) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.Repositories[rr.Repository] = &repoStatus{Started: started, Finished: finished, Report: rr}
+}
+
+// ServeHTTP writes st as JSON.
+func (st *status) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	st.mu.RLock()
+	b, err := json.MarshalIndent(st, "", "  ")
+	st.mu.RUnlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(b)
+}
@@ -0,0 +1,167 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	drghs_v1 "github.com/GoogleCloudPlatform/devrel-services/drghs/v1"
+	"github.com/google/go-cmp/cmp"
+)
+
+func testRepos(names ...string) []*drghs_v1.Repository {
+	repos := make([]*drghs_v1.Repository, 0, len(names))
+	for _, n := range names {
+		repos = append(repos, &drghs_v1.Repository{Name: n})
+	}
+	return repos
+}
+
+func TestSweepWorkers(t *testing.T) {
+	var mu sync.Mutex
+	var running, maxRunning int
+	s := &sweeper{
+		workers: 2,
+		status:  newStatus(),
+		process: func(ctx context.Context, repo *drghs_v1.Repository) (*repoReport, error) {
+			mu.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			running--
+			mu.Unlock()
+			if repo.Name == "foo/err" {
+				return &repoReport{Repository: repo.Name, Action: actionError}, errors.New("unavailable")
+			}
+			return &repoReport{Repository: repo.Name, Action: actionNone}, nil
+		},
+	}
+	var nerrs int
+	s.onError = func(err error) {
+		mu.Lock()
+		nerrs++
+		mu.Unlock()
+	}
+
+	rpt := &report{}
+	s.sweep(context.Background(), testRepos("foo/d", "foo/err", "foo/b", "foo/a", "foo/c"), rpt)
+
+	if maxRunning != 2 {
+		t.Errorf("test: workers failed. got: %v at a time want: 2", maxRunning)
+	}
+	if nerrs != 1 {
+		t.Errorf("test: errors failed. got: %v want: 1", nerrs)
+	}
+	var got []string
+	for _, rr := range rpt.Repositories {
+		got = append(got, rr.Repository)
+	}
+	if diff := cmp.Diff([]string{"foo/a", "foo/b", "foo/c", "foo/d", "foo/err"}, got); diff != "" {
+		t.Errorf("test: report failed. repositories mismatch (-want +got)\n%s", diff)
+	}
+	if len(s.status.Repositories) != 5 {
+		t.Errorf("test: status failed. got: %v repositories want: 5", len(s.status.Repositories))
+	}
+}
+
+func TestSweepResumes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "swpr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	checkpoint := filepath.Join(dir, "checkpoint.json")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var processed []string
+	s := &sweeper{
+		workers:    1,
+		checkpoint: checkpoint,
+		status:     newStatus(),
+		process: func(ctx context.Context, repo *drghs_v1.Repository) (*repoReport, error) {
+			if repo.Name == "foo/c" {
+				// Interrupted half way through.
+				cancel()
+				return &repoReport{Repository: repo.Name, Action: actionError}, ctx.Err()
+			}
+			processed = append(processed, repo.Name)
+			return &repoReport{Repository: repo.Name, Action: actionNone}, nil
+		},
+		onError: func(err error) { t.Errorf("test: interrupted failed. got error: %v", err) },
+	}
+	repos := testRepos("foo/a", "foo/b", "foo/c", "foo/d")
+	started := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.sweep(ctx, repos, &report{Started: started})
+
+	if _, err := os.Stat(checkpoint); err != nil {
+		t.Fatalf("test: interrupted failed. got: %v want: a checkpoint", err)
+	}
+
+	processed = nil
+	s.process = func(ctx context.Context, repo *drghs_v1.Repository) (*repoReport, error) {
+		processed = append(processed, repo.Name)
+		return &repoReport{Repository: repo.Name, Action: actionNone}, nil
+	}
+	rpt := &report{Started: time.Now()}
+	s.sweep(context.Background(), repos, rpt)
+
+	if diff := cmp.Diff([]string{"foo/c", "foo/d"}, processed); diff != "" {
+		t.Errorf("test: resumed failed. processed mismatch (-want +got)\n%s", diff)
+	}
+	if rpt.Totals.Repositories != 4 {
+		t.Errorf("test: resumed failed. got: %v repositories want: 4", rpt.Totals.Repositories)
+	}
+	if !rpt.Started.Equal(started) {
+		t.Errorf("test: resumed failed. got started: %v want: %v", rpt.Started, started)
+	}
+	if _, err := os.Stat(checkpoint); !os.IsNotExist(err) {
+		t.Errorf("test: resumed failed. got: %v want: the checkpoint removed", err)
+	}
+}
+
+func TestStatusServeHTTP(t *testing.T) {
+	st := newStatus()
+	started := time.Date(2020, 1, 1, 2, 0, 0, 0, time.UTC)
+	st.start(started)
+	st.record(&repoReport{Repository: "foo/bar", Action: actionTombstoned, Tombstoned: 2}, started, started.Add(time.Minute))
+
+	w := httptest.NewRecorder()
+	st.ServeHTTP(w, httptest.NewRequest("GET", "/status", nil))
+
+	got := newStatus()
+	if err := json.Unmarshal(w.Body.Bytes(), got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Running || !got.LastStarted.Equal(started) {
+		t.Errorf("test: status failed. got running: %v started: %v want running: true started: %v", got.Running, got.LastStarted, started)
+	}
+	rs := got.Repositories["foo/bar"]
+	if rs == nil || rs.Report.Tombstoned != 2 || !rs.Finished.Equal(started.Add(time.Minute)) {
+		t.Errorf("test: status failed. got: %+v want: the last run of foo/bar", rs)
+	}
+}
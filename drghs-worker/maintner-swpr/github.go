@@ -16,6 +16,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	drghs_v1 "github.com/GoogleCloudPlatform/devrel-services/drghs/v1"
@@ -25,11 +26,53 @@ import (
 type issue struct {
 	ID     string
 	Number int32
+	Title  string
+	State  string
+	Labels labels `graphql:"labels(first: 100)"`
 }
 
 type pullRequest struct {
 	ID     string
 	Number int32
+	Title  string
+	State  string
+	Labels labels `graphql:"labels(first: 100)"`
+}
+
+type labels struct {
+	TotalCount int
+	Nodes      []struct {
+		Name string
+	}
+}
+
+// ghIssueState is what the sweeper compares of a GitHub issue or pull
+// request with the corpus.
+type ghIssueState struct {
+	Title  string
+	Closed bool
+	// Labels is nil if the issue has too many labels to fetch them all.
+	Labels []string
+}
+
+func (i issue) state() ghIssueState {
+	return ghIssueState{Title: i.Title, Closed: i.State != "OPEN", Labels: i.Labels.names()}
+}
+
+// state treats merged pull requests as closed, as maintner does.
+func (p pullRequest) state() ghIssueState {
+	return ghIssueState{Title: p.Title, Closed: p.State != "OPEN", Labels: p.Labels.names()}
+}
+
+func (l labels) names() []string {
+	if len(l.Nodes) < l.TotalCount {
+		return nil
+	}
+	names := make([]string, 0, len(l.Nodes))
+	for _, n := range l.Nodes {
+		names = append(names, n.Name)
+	}
+	return names
 }
 
 type ghIssuesQuery struct {
@@ -56,6 +99,46 @@ type ghPullRequestsQuery struct {
 	} `graphql:"repository(owner: $repositoryOwner, name: $repositoryName)"`
 }
 
+// ghIssueLocationQuery looks an issue up by its number in the repository it
+// was opened in. GitHub answers with the issue wherever it was transferred to.
+type ghIssueLocationQuery struct {
+	Repository struct {
+		Issue *struct {
+			Number     int32
+			Repository struct {
+				NameWithOwner string
+			}
+		} `graphql:"issue(number: $number)"`
+	} `graphql:"repository(owner: $repositoryOwner, name: $repositoryName)"`
+}
+
+// getGitHubTransferDestination returns the name of the issue, in the format
+// owner/repository/issues/N, that issue number of repo was transferred to.
+// It returns "" if the issue was not transferred, e.g. if it was deleted.
+func getGitHubTransferDestination(ctx context.Context, c *githubv4.Client, repo *drghs_v1.Repository, number int32) (string, error) {
+	parts := strings.Split(repo.GetName(), "/")
+
+	var q ghIssueLocationQuery
+	variables := map[string]interface{}{
+		"repositoryOwner": githubv4.String(parts[0]),
+		"repositoryName":  githubv4.String(parts[1]),
+		"number":          githubv4.Int(number),
+	}
+	err := c.Query(ctx, &q, variables)
+	iss := q.Repository.Issue
+	if iss == nil {
+		// A deleted issue is an error that names the number.
+		if err != nil && !strings.Contains(err.Error(), "Could not resolve to") {
+			return "", err
+		}
+		return "", nil
+	}
+	if strings.EqualFold(iss.Repository.NameWithOwner, repo.GetName()) {
+		return "", nil
+	}
+	return fmt.Sprintf("%v/issues/%v", iss.Repository.NameWithOwner, iss.Number), nil
+}
+
 func getGitHubIssuesForRepo(ctx context.Context, c *githubv4.Client, repo *drghs_v1.Repository) ([]issue, error) {
 	log.Debugf("getting GitHub issues for: %v", repo.String())
 
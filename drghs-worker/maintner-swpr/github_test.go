@@ -0,0 +1,81 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	drghs_v1 "github.com/GoogleCloudPlatform/devrel-services/drghs/v1"
+	"github.com/shurcooL/githubv4"
+)
+
+func TestGetGitHubTransferDestination(t *testing.T) {
+	cases := []struct {
+		Name    string
+		Status  int
+		Body    string
+		Want    string
+		WantErr bool
+	}{
+		{
+			Name:   "Transferred",
+			Status: http.StatusOK,
+			Body:   `{"data": {"repository": {"issue": {"number": 12, "repository": {"nameWithOwner": "foo/baz"}}}}}`,
+			Want:   "foo/baz/issues/12",
+		},
+		{
+			Name:   "Still here",
+			Status: http.StatusOK,
+			Body:   `{"data": {"repository": {"issue": {"number": 7, "repository": {"nameWithOwner": "Foo/Bar"}}}}}`,
+		},
+		{
+			Name:   "Deleted",
+			Status: http.StatusOK,
+			Body:   `{"data": {"repository": {"issue": null}}, "errors": [{"type": "NOT_FOUND", "message": "Could not resolve to an Issue with the number of 7."}]}`,
+		},
+		{
+			Name:    "Other error",
+			Status:  http.StatusOK,
+			Body:    `{"data": null, "errors": [{"message": "API rate limit exceeded"}]}`,
+			WantErr: true,
+		},
+		{
+			Name:    "Server error",
+			Status:  http.StatusBadGateway,
+			Body:    `bad gateway`,
+			WantErr: true,
+		},
+	}
+	for _, c := range cases {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(c.Status)
+			io.WriteString(w, c.Body)
+		}))
+		gqlc := githubv4.NewEnterpriseClient(srv.URL, srv.Client())
+
+		got, err := getGitHubTransferDestination(context.Background(), gqlc, &drghs_v1.Repository{Name: "foo/bar"}, 7)
+		srv.Close()
+		if (err != nil) != c.WantErr {
+			t.Errorf("test: %v failed. got error: %v want error: %v", c.Name, err, c.WantErr)
+		}
+		if got != c.Want {
+			t.Errorf("test: %v failed. got: %q want: %q", c.Name, got, c.Want)
+		}
+	}
+}
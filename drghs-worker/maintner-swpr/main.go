@@ -16,6 +16,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"net/http"
@@ -23,13 +24,16 @@ import (
 	"os/signal"
 	"regexp"
 	"strings"
+	"syscall"
 	"time"
 
 	"cloud.google.com/go/errorreporting"
 	maintner_internal "github.com/GoogleCloudPlatform/devrel-services/drghs-worker/internal"
 	drghs_v1 "github.com/GoogleCloudPlatform/devrel-services/drghs/v1"
 	"github.com/GoogleCloudPlatform/devrel-services/repos"
+	"github.com/GoogleCloudPlatform/devrel-services/rtr/resolver"
 	grpc_retry "github.com/grpc-ecosystem/go-grpc-middleware/retry"
+	"github.com/robfig/cron/v3"
 	"github.com/shurcooL/githubv4"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/ssh/terminal"
@@ -47,14 +51,29 @@ var log *logrus.Logger
 var (
 	flRtrAddr   *string
 	flProjectID *string
+	flResolver  *string
+
+	flDryRun              *bool
+	flReport              *string
+	flReportFormat        *string
+	flMaxTombstonePercent *float64
+	flDetectTransfers     *bool
+	flRepair              *bool
+	flMaxRefetch          *int
+
+	flSchedule   *string
+	flWorkers    *int
+	flCheckpoint *string
+	flListen     *string
 )
 
+// backends finds the maintner instance of a repository.
+var backends resolver.Resolver
+
 // Constants
 const (
-	GitHubEnvVar        = "GITHUB_TOKEN"
-	SecondsPerDay       = 86400.0
-	ServicePort         = "80"
-	ServicePortInternal = "8080"
+	GitHubEnvVar  = "GITHUB_TOKEN"
+	SecondsPerDay = 86400.0
 )
 
 // Uses
@@ -79,6 +98,38 @@ func init() {
 
 	flRtrAddr = flag.String("rtr-address", "", "specifies the address of the router to dial")
 	flProjectID = flag.String("project-id", "", "the GCP Project ID this is running in.")
+	flResolver = flag.String("resolver", "k8s", "how to find the maintner instance of a repository: k8s, static:<yaml file> or srv:<domain>")
+
+	flDryRun = flag.Bool("dry-run", false, "report the issues that would be tombstoned without tombstoning them")
+	flReport = flag.String("report", "", "file to write the report of the sweep to. - is stdout. Empty writes none")
+	flReportFormat = flag.String("report-format", formatJSON, "format of the report: json or csv")
+	flMaxTombstonePercent = flag.Float64("max-tombstone-percent", 10, "refuse to tombstone more than this percentage of a repository's issues in a single run")
+	flDetectTransfers = flag.Bool("detect-transfers", true, "look up on GitHub whether missing issues were transferred and record where they went")
+	flRepair = flag.Bool("repair", false, "ask maintner to refetch the issues it lacks or whose state, title or labels differ from GitHub's")
+	flMaxRefetch = flag.Int("max-refetch", 500, "refuse to refetch more than this many of a repository's issues in a single run")
+
+	flSchedule = flag.String("schedule", "", "cron schedule to sweep on, e.g. \"0 2 * * *\" or @daily. Empty sweeps once and exits")
+	flWorkers = flag.Int("workers", 4, "number of repositories to sweep at a time")
+	flCheckpoint = flag.String("checkpoint", "", "file to keep the progress of a sweep in, to resume it after a restart. Empty keeps none")
+	flListen = flag.String("listen", "", "address to serve the status of the sweeps on at /status. Empty serves none")
+}
+
+// errTooManyTombstones is returned for a repository with more tombstone
+// candidates than --max-tombstone-percent allows.
+var errTooManyTombstones = errors.New("too many issues to tombstone")
+
+// sweepConfig is how a sweep treats the tombstone candidates it finds.
+type sweepConfig struct {
+	DryRun              bool
+	MaxTombstonePercent float64
+	// DetectTransfers looks up on GitHub whether the missing issues were
+	// transferred, to record where they went instead of only tombstoning
+	// them.
+	DetectTransfers bool
+	// Repair refetches the issues the corpus lacks or disagrees with GitHub
+	// about, up to MaxRefetch of them.
+	Repair     bool
+	MaxRefetch int
 }
 
 func main() {
@@ -87,7 +138,7 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 	signalCh := make(chan os.Signal, 1)
-	signal.Notify(signalCh, os.Interrupt)
+	signal.Notify(signalCh, os.Interrupt, syscall.SIGTERM)
 	go func() {
 		sig := <-signalCh
 		log.Printf("termination signal received: %s", sig)
@@ -106,7 +157,30 @@ func main() {
 		log.Fatal("--project-id is empty")
 	}
 
-	var errorClient, err = errorreporting.NewClient(ctx, *flProjectID, errorreporting.Config{
+	if *flReportFormat != formatJSON && *flReportFormat != formatCSV {
+		log.Fatalf("--report-format must be %v or %v", formatJSON, formatCSV)
+	}
+
+	if *flWorkers < 1 {
+		log.Fatal("--workers must be at least 1")
+	}
+
+	var sched cron.Schedule
+	if *flSchedule != "" {
+		var err error
+		sched, err = cron.ParseStandard(*flSchedule)
+		if err != nil {
+			log.Fatalf("invalid --schedule: %v", err)
+		}
+	}
+
+	var err error
+	backends, err = resolver.Parse(*flResolver, "mtr-s-")
+	if err != nil {
+		log.Fatalf("invalid --resolver: %v", err)
+	}
+
+	errorClient, err := errorreporting.NewClient(ctx, *flProjectID, errorreporting.Config{
 		ServiceName: "maintner-sweeper",
 		OnError: func(err error) {
 			log.Printf("Could not report error: %v", err)
@@ -136,27 +210,11 @@ func main() {
 
 	drghsc = drghs_v1.NewIssueServiceClient(conn)
 
-	repos, err := getTrackedRepositories(ctx, drghsc)
-	if err != nil {
-		errorClient.Report(errorreporting.Entry{
-			Error: err,
-		})
-		log.Fatal(err)
-	}
-
-	var nipr int32
-	for _, repo := range repos {
-		nipr = nipr + repo.PullRequestCount
-		nipr = nipr + repo.IssueCount
-	}
-
-	log.Debugf("have %v repositories to query with a total of %v Issues and PRs", len(repos), nipr)
-
 	// Setup GraphQL Client
 	//
 
-	// Queries per second as we retrieve 100 issues at a time from GitHub
-	limiter := buildLimiter(nipr)
+	// The limit is set from the number of issues before each sweep.
+	limiter := rate.NewLimiter(rate.Inf, 1)
 	src := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: strings.Trim(os.Getenv(GitHubEnvVar), "\n")},
 	)
@@ -167,77 +225,216 @@ func main() {
 	}
 	gqlc := githubv4.NewClient(httpClient)
 
+	cfg := sweepConfig{
+		DryRun:              *flDryRun,
+		MaxTombstonePercent: *flMaxTombstonePercent,
+		DetectTransfers:     *flDetectTransfers,
+		Repair:              *flRepair,
+		MaxRefetch:          *flMaxRefetch,
+	}
+
 	// For each repo, get all the GitHub Issues for the Repo
 	// Then get all the mainter issues for the repo
 	// Finally, compare the two, find the ones in maintner that
-	// are not in GitHub && Flag them as NotExist
-	errs := make([]error, 0)
-	for _, repo := range repos {
-		err := processRepo(ctx, repo, gqlc)
-		if err != nil {
-			// Append and report
-			errs = append(errs, err)
+	// are not in GitHub && Flag them as NotExist, and with --repair
+	// the ones in GitHub that maintner lacks or has stale && Refetch them
+	sw := &sweeper{
+		drghsc:     drghsc,
+		limiter:    limiter,
+		workers:    *flWorkers,
+		checkpoint: *flCheckpoint,
+		status:     newStatus(),
+		process: func(ctx context.Context, repo *drghs_v1.Repository) (*repoReport, error) {
+			return processRepo(ctx, repo, gqlc, cfg)
+		},
+		onError: func(err error) {
 			errorClient.Report(errorreporting.Entry{
 				Error: err,
 			})
-		}
+		},
+		onReport: func(rpt *report) {
+			log.Infof("finished with %v errors", rpt.Totals.Errors)
+			if *flReport != "" {
+				if err := rpt.writeFile(*flReport, *flReportFormat); err != nil {
+					log.Errorf("writing the report: %v", err)
+				}
+			}
+		},
+	}
+
+	if *flListen != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/status", sw.status)
+		go func() {
+			log.Infof("serving status on %v", *flListen)
+			log.Error(http.ListenAndServe(*flListen, mux))
+		}()
 	}
 
-	log.Infof("finished with %v errors: %v", len(errs), errs)
+	if sched == nil {
+		sw.run(ctx, cfg)
+		return
+	}
+	if err := sw.runDaemon(ctx, sched, cfg); err != nil {
+		log.Infof("stopped: %v", err)
+	}
 }
 
-func processRepo(ctx context.Context, repo *drghs_v1.Repository, gqlc *githubv4.Client) error {
+// processRepo tombstones the issues of repo maintner has but GitHub does
+// not, refetches those GitHub has but maintner lacks or has stale if
+// cfg.Repair is set, and reports what it did.
+func processRepo(ctx context.Context, repo *drghs_v1.Repository, gqlc *githubv4.Client, cfg sweepConfig) (*repoReport, error) {
 	log.Debugf("processing repo: %v", repo.String())
 
+	rr := &repoReport{Repository: repo.Name, Action: actionNone}
+	fail := func(err error) (*repoReport, error) {
+		if rr.Action != actionRefused {
+			rr.Action = actionError
+		}
+		rr.Error = err.Error()
+		return rr, err
+	}
+
 	tr := repoToTrackedRepo(repo)
+	if tr == nil {
+		return fail(fmt.Errorf("invalid repository name %q", repo.Name))
+	}
 
 	ghIssues, err := getGitHubIssuesForRepo(ctx, gqlc, repo)
 	if err != nil {
 		log.Errorf("processing repo %v. hit an error getting GitHub Issues: %v", repo.String(), err)
-		return err
+		return fail(err)
 	}
+	rr.GitHubIssues = len(ghIssues)
 	log.Debugf("repo: %v number of issues: %v\n", repo.String(), len(ghIssues))
 
 	ghPrs, err := getGitHubPullRequestsForRepo(ctx, gqlc, repo)
 	if err != nil {
 		log.Errorf("processing repo %v. hit an error getting GitHub Pull Requests: %v", repo.String(), err)
-		return err
+		return fail(err)
 	}
+	rr.GitHubPullRequests = len(ghPrs)
 	log.Debugf("repo: %v number of pull requests: %v\n", repo.String(), len(ghPrs))
 
-	ghIssuesByID := make(map[int32]struct{})
+	ghIssuesByID := make(map[int32]ghIssueState)
 	for _, iss := range ghIssues {
-		ghIssuesByID[iss.Number] = struct{}{}
+		ghIssuesByID[iss.Number] = iss.state()
 	}
 	for _, pr := range ghPrs {
-		ghIssuesByID[pr.Number] = struct{}{}
+		ghIssuesByID[pr.Number] = pr.state()
 	}
 
 	mtrIssues, err := getMaintnerIssuesForRepo(ctx, tr, repo)
 	if err != nil {
 		log.Errorf("processing repo %v. hit an error getting Maintner Issues and PRs: %v", repo.String(), err)
-		return err
+		return fail(err)
 	}
+	rr.MaintnerIssues = len(mtrIssues)
 
 	log.Debugf("repo: %v number of maintner issues %v\n", repo.Name, len(mtrIssues))
 
+	if err := tombstoneMissing(ctx, gqlc, tr, repo, ghIssuesByID, mtrIssues, cfg, rr); err != nil {
+		if errors.Is(err, errTooManyTombstones) {
+			log.Errorf("processing repo %v. refusing to tombstone: %v", repo.String(), err)
+			rr.Action = actionRefused
+		}
+		return fail(err)
+	}
+
+	if cfg.Repair {
+		if err := repairRepo(ctx, tr, repo, ghIssuesByID, mtrIssues, cfg, rr); err != nil {
+			log.Errorf("processing repo %v. hit an error refetching Issues: %v", repo.String(), err)
+			rr.Error = err.Error()
+			return rr, err
+		}
+	}
+	return rr, nil
+}
+
+// tombstoneMissing tombstones the issues of repo in mtr that are not in gh,
+// or records where they were transferred to, and adds them to rr.
+func tombstoneMissing(ctx context.Context, gqlc *githubv4.Client, tr *repos.TrackedRepository, repo *drghs_v1.Repository, gh map[int32]ghIssueState, mtr []*drghs_v1.Issue, cfg sweepConfig, rr *repoReport) error {
+	missing := make([]int32, 0)
+	for _, mtri := range mtr {
+		if mtri.TransferredTo != "" {
+			// Already tombstoned, and served only to say where it went.
+			continue
+		}
+		if _, ok := gh[mtri.IssueId]; !ok {
+			missing = append(missing, mtri.IssueId)
+		}
+	}
+
+	log.Debugf("repo: %v number of tombstoned issues %v\nissues to tombstone: %v\n", repo.Name, len(missing), missing)
+
+	if len(missing) == 0 {
+		return nil
+	}
+	for _, id := range missing {
+		rr.Candidates = append(rr.Candidates, &candidate{IssueID: id, Reason: reasonMissing})
+	}
+	if err := checkTombstoneThreshold(len(missing), len(mtr), cfg.MaxTombstonePercent); err != nil {
+		return err
+	}
+
+	// Transferred issues are missing too, but GitHub knows where they went.
 	tmbIssues := make([]int32, 0)
-	for _, mtri := range mtrIssues {
-		if _, ok := ghIssuesByID[mtri.IssueId]; !ok {
-			tmbIssues = append(tmbIssues, mtri.IssueId)
+	transfers := make([]*maintner_internal.IssueTransfer, 0)
+	for _, c := range rr.Candidates {
+		if cfg.DetectTransfers {
+			to, err := getGitHubTransferDestination(ctx, gqlc, repo, c.IssueID)
+			if err != nil {
+				log.Errorf("processing repo %v. hit an error looking up issue %v on GitHub: %v", repo.String(), c.IssueID, err)
+				return err
+			}
+			if to != "" {
+				c.Reason = reasonTransferred
+				c.TransferredTo = to
+				transfers = append(transfers, &maintner_internal.IssueTransfer{IssueNumber: c.IssueID, TransferredTo: to})
+				continue
+			}
 		}
+		tmbIssues = append(tmbIssues, c.IssueID)
+	}
+
+	if cfg.DryRun {
+		log.Infof("repo: %v would tombstone: %v issues and record %v transfers\n", repo.Name, len(tmbIssues), len(transfers))
+		rr.Action = actionDryRun
+		return nil
 	}
 
-	log.Debugf("repo: %v number of tombstoned issues %v\nissues to tombstone: %v\n", repo.Name, len(tmbIssues), tmbIssues)
+	rr.Action = actionTombstoned
+	if len(transfers) > 0 {
+		log.Infof("repo: %v recording: %v transfers\n", repo.Name, len(transfers))
 
+		n, err := recordIssueTransfers(ctx, tr, repo, transfers)
+		if err != nil {
+			log.Errorf("processing repo %v. hit an error recording transferred Issues: %v", repo.String(), err)
+			return err
+		}
+		rr.Transferred = n
+	}
 	if len(tmbIssues) > 0 {
 		log.Infof("repo: %v tombstoning: %v issues\n", repo.Name, len(tmbIssues))
 
-		err = flagIssuesTombstoned(ctx, tr, repo, tmbIssues)
+		n, err := flagIssuesTombstoned(ctx, tr, repo, tmbIssues)
 		if err != nil {
 			log.Errorf("processing repo %v. hit an error getting Tombstoning Issues: %v", repo.String(), err)
 			return err
 		}
+		rr.Tombstoned = n
+	}
+	return nil
+}
+
+// checkTombstoneThreshold returns an error if tombstoning n of a
+// repository's total issues tombstones more than maxPercent of them.
+func checkTombstoneThreshold(n, total int, maxPercent float64) error {
+	if total == 0 {
+		return nil
+	}
+	if pct := 100 * float64(n) / float64(total); pct > maxPercent {
+		return fmt.Errorf("%w: %v of %v issues (%.1f%%) exceeds the maximum of %v%%", errTooManyTombstones, n, total, pct, maxPercent)
 	}
 	return nil
 }
@@ -245,13 +442,13 @@ func processRepo(ctx context.Context, repo *drghs_v1.Repository, gqlc *githubv4.
 func getMaintnerIssuesForRepo(ctx context.Context, tr *repos.TrackedRepository, repo *drghs_v1.Repository) ([]*drghs_v1.Issue, error) {
 	log.Debugf("getting Issues from maintner for repo: %v", repo.Name)
 
-	maddr, err := serviceName(tr)
+	maddr, err := backends.Resolve(ctx, tr.Owner, tr.Name, resolver.PortGRPC)
 	if err != nil {
 		return nil, err
 	}
 
 	conn, err := grpc.Dial(
-		maddr+":"+ServicePort,
+		maddr,
 		grpc.WithInsecure(),
 		grpc.WithUnaryInterceptor(buildRetryInterceptor()),
 	)
@@ -273,6 +470,10 @@ func getMaintnerIssuesForRepo(ctx context.Context, tr *repos.TrackedRepository,
 			FieldMask: &field_mask.FieldMask{
 				Paths: []string{
 					"issue_id",
+					"title",
+					"closed",
+					"labels",
+					"transferred_to",
 				},
 			},
 		})
@@ -312,19 +513,21 @@ func getTrackedRepositories(ctx context.Context, c drghs_v1.IssueServiceClient)
 	return ret, nil
 }
 
-func flagIssuesTombstoned(ctx context.Context, tr *repos.TrackedRepository, repo *drghs_v1.Repository, issueIds []int32) error {
-	maddr, err := serviceName(tr)
+// flagIssuesTombstoned tombstones issueIds in repo and returns how many
+// were tombstoned.
+func flagIssuesTombstoned(ctx context.Context, tr *repos.TrackedRepository, repo *drghs_v1.Repository, issueIds []int32) (int, error) {
+	maddr, err := backends.Resolve(ctx, tr.Owner, tr.Name, resolver.PortInternal)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	conn, err := grpc.Dial(
-		maddr+":"+ServicePortInternal,
+		maddr,
 		grpc.WithInsecure(),
 		grpc.WithUnaryInterceptor(buildRetryInterceptor()),
 	)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer conn.Close()
 
@@ -336,7 +539,7 @@ func flagIssuesTombstoned(ctx context.Context, tr *repos.TrackedRepository, repo
 	}
 	resp, err := c.TombstoneIssues(ctx, req)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	log.Infof("tombstoned: %v issues", resp.TombstonedCount)
@@ -344,11 +547,77 @@ func flagIssuesTombstoned(ctx context.Context, tr *repos.TrackedRepository, repo
 		log.Warnf("expected to tombstone %v, actually tombstoned: %v", len(issueIds), resp.TombstonedCount)
 	}
 
-	return err
+	return int(resp.TombstonedCount), nil
+}
+
+// recordIssueTransfers records where the issues of repo in transfers went
+// and returns how many were recorded.
+func recordIssueTransfers(ctx context.Context, tr *repos.TrackedRepository, repo *drghs_v1.Repository, transfers []*maintner_internal.IssueTransfer) (int, error) {
+	maddr, err := backends.Resolve(ctx, tr.Owner, tr.Name, resolver.PortInternal)
+	if err != nil {
+		return 0, err
+	}
+
+	conn, err := grpc.Dial(
+		maddr,
+		grpc.WithInsecure(),
+		grpc.WithUnaryInterceptor(buildRetryInterceptor()),
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	c := maintner_internal.NewInternalIssueServiceClient(conn)
+
+	resp, err := c.RecordTransfers(ctx, &maintner_internal.RecordTransfersRequest{
+		Parent:    repo.Name,
+		Transfers: transfers,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	log.Infof("recorded: %v transfers", resp.RecordedCount)
+	if int(resp.RecordedCount) != len(transfers) {
+		log.Warnf("expected to record %v transfers, actually recorded: %v", len(transfers), resp.RecordedCount)
+	}
+	return int(resp.RecordedCount), nil
 }
 
-func serviceName(t *repos.TrackedRepository) (string, error) {
-	return strings.ToLower(fmt.Sprintf("mtr-s-%s", t.RepoSha())), nil
+// refetchIssues asks maintner to refetch issueIds in repo from GitHub and
+// returns how many were refetched.
+func refetchIssues(ctx context.Context, tr *repos.TrackedRepository, repo *drghs_v1.Repository, issueIds []int32) (int, error) {
+	maddr, err := backends.Resolve(ctx, tr.Owner, tr.Name, resolver.PortInternal)
+	if err != nil {
+		return 0, err
+	}
+
+	conn, err := grpc.Dial(
+		maddr,
+		grpc.WithInsecure(),
+		grpc.WithUnaryInterceptor(buildRetryInterceptor()),
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	c := maintner_internal.NewInternalIssueServiceClient(conn)
+
+	resp, err := c.RefetchIssues(ctx, &maintner_internal.RefetchIssuesRequest{
+		Parent:       repo.Name,
+		IssueNumbers: issueIds,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	log.Infof("refetched: %v issues", resp.RefetchedCount)
+	if int(resp.RefetchedCount) != len(issueIds) {
+		log.Warnf("expected to refetch %v, actually refetched: %v", len(issueIds), resp.RefetchedCount)
+	}
+	return int(resp.RefetchedCount), nil
 }
 
 func repoToTrackedRepo(r *drghs_v1.Repository) *repos.TrackedRepository {
@@ -0,0 +1,142 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	drghs_v1 "github.com/GoogleCloudPlatform/devrel-services/drghs/v1"
+	"github.com/GoogleCloudPlatform/devrel-services/repos"
+)
+
+// Why an issue is refetched.
+const (
+	reasonMissingFromCorpus = "missing from corpus"
+	reasonState             = "state differs"
+	reasonTitle             = "title differs"
+	reasonLabels            = "labels differ"
+)
+
+// actionRefetched means the repair candidates were refetched.
+const actionRefetched = "refetched"
+
+// errTooManyRefetches is returned for a repository with more repair
+// candidates than --max-refetch allows.
+var errTooManyRefetches = errors.New("too many issues to refetch")
+
+// repair is an issue GitHub has that the corpus lacks or disagrees with
+// GitHub about.
+type repair struct {
+	IssueID int32    `json:"issue_id"`
+	Reasons []string `json:"reasons"`
+}
+
+// findRepairs compares the issues and pull requests GitHub has, by number,
+// with those of the corpus and returns the ones the corpus lacks or whose
+// state, title or labels differ, in order of number.
+func findRepairs(gh map[int32]ghIssueState, mtr []*drghs_v1.Issue) []*repair {
+	mtrByID := make(map[int32]*drghs_v1.Issue, len(mtr))
+	for _, mtri := range mtr {
+		mtrByID[mtri.IssueId] = mtri
+	}
+
+	numbers := make([]int32, 0, len(gh))
+	for n := range gh {
+		numbers = append(numbers, n)
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+
+	repairs := make([]*repair, 0)
+	for _, n := range numbers {
+		ghi := gh[n]
+		mtri, ok := mtrByID[n]
+		if !ok {
+			repairs = append(repairs, &repair{IssueID: n, Reasons: []string{reasonMissingFromCorpus}})
+			continue
+		}
+		var reasons []string
+		if ghi.Closed != mtri.Closed {
+			reasons = append(reasons, reasonState)
+		}
+		if ghi.Title != mtri.Title {
+			reasons = append(reasons, reasonTitle)
+		}
+		if ghi.Labels != nil && !sameLabels(ghi.Labels, mtri.Labels) {
+			reasons = append(reasons, reasonLabels)
+		}
+		if len(reasons) > 0 {
+			repairs = append(repairs, &repair{IssueID: n, Reasons: reasons})
+		}
+	}
+	return repairs
+}
+
+// sameLabels reports whether a and b hold the same label names, in any
+// order.
+func sameLabels(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[string]int, len(a))
+	for _, l := range a {
+		set[l]++
+	}
+	for _, l := range b {
+		if set[l] == 0 {
+			return false
+		}
+		set[l]--
+	}
+	return true
+}
+
+// repairRepo asks maintner to refetch the issues of repo in gh it lacks or
+// disagrees with GitHub about, and adds them to rr.
+func repairRepo(ctx context.Context, tr *repos.TrackedRepository, repo *drghs_v1.Repository, gh map[int32]ghIssueState, mtr []*drghs_v1.Issue, cfg sweepConfig, rr *repoReport) error {
+	rr.Repairs = findRepairs(gh, mtr)
+	rr.RepairAction = actionNone
+	log.Debugf("repo: %v number of issues to refetch %v\n", repo.Name, len(rr.Repairs))
+
+	if len(rr.Repairs) == 0 {
+		return nil
+	}
+	if len(rr.Repairs) > cfg.MaxRefetch {
+		rr.RepairAction = actionRefused
+		return fmt.Errorf("%w: %v issues exceeds the maximum of %v", errTooManyRefetches, len(rr.Repairs), cfg.MaxRefetch)
+	}
+	if cfg.DryRun {
+		log.Infof("repo: %v would refetch: %v issues\n", repo.Name, len(rr.Repairs))
+		rr.RepairAction = actionDryRun
+		return nil
+	}
+
+	numbers := make([]int32, 0, len(rr.Repairs))
+	for _, r := range rr.Repairs {
+		numbers = append(numbers, r.IssueID)
+	}
+	log.Infof("repo: %v refetching: %v issues\n", repo.Name, len(numbers))
+
+	n, err := refetchIssues(ctx, tr, repo, numbers)
+	if err != nil {
+		rr.RepairAction = actionError
+		return err
+	}
+	rr.RepairAction = actionRefetched
+	rr.Refetched = n
+	return nil
+}
@@ -0,0 +1,107 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	drghs_v1 "github.com/GoogleCloudPlatform/devrel-services/drghs/v1"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFindRepairs(t *testing.T) {
+	cases := []struct {
+		Name string
+		GH   map[int32]ghIssueState
+		Mtr  []*drghs_v1.Issue
+		Want []*repair
+	}{
+		{
+			Name: "In sync",
+			GH: map[int32]ghIssueState{
+				1: {Title: "foo", Labels: []string{"a", "b"}},
+				2: {Title: "bar", Closed: true, Labels: []string{}},
+			},
+			Mtr: []*drghs_v1.Issue{
+				{IssueId: 1, Title: "foo", Labels: []string{"b", "a"}},
+				{IssueId: 2, Title: "bar", Closed: true},
+				{IssueId: 3, Title: "tombstone candidate"},
+			},
+			Want: []*repair{},
+		},
+		{
+			Name: "Missing from corpus",
+			GH: map[int32]ghIssueState{
+				1: {Title: "foo"},
+				3: {Title: "baz"},
+				2: {Title: "bar"},
+			},
+			Mtr: []*drghs_v1.Issue{
+				{IssueId: 1, Title: "foo"},
+			},
+			Want: []*repair{
+				{IssueID: 2, Reasons: []string{reasonMissingFromCorpus}},
+				{IssueID: 3, Reasons: []string{reasonMissingFromCorpus}},
+			},
+		},
+		{
+			Name: "Drifted",
+			GH: map[int32]ghIssueState{
+				1: {Title: "foo", Closed: true},
+				2: {Title: "new title", Labels: []string{"a"}},
+				3: {Title: "baz", Labels: []string{"a", "a"}},
+			},
+			Mtr: []*drghs_v1.Issue{
+				{IssueId: 1, Title: "foo"},
+				{IssueId: 2, Title: "old title", Labels: []string{"b"}},
+				{IssueId: 3, Title: "baz", Labels: []string{"a", "b"}},
+			},
+			Want: []*repair{
+				{IssueID: 1, Reasons: []string{reasonState}},
+				{IssueID: 2, Reasons: []string{reasonTitle, reasonLabels}},
+				{IssueID: 3, Reasons: []string{reasonLabels}},
+			},
+		},
+		{
+			Name: "Too many labels to compare",
+			GH: map[int32]ghIssueState{
+				1: {Title: "foo"},
+			},
+			Mtr: []*drghs_v1.Issue{
+				{IssueId: 1, Title: "foo", Labels: []string{"a"}},
+			},
+			Want: []*repair{},
+		},
+	}
+	for _, c := range cases {
+		got := findRepairs(c.GH, c.Mtr)
+		if diff := cmp.Diff(c.Want, got); diff != "" {
+			t.Errorf("test: %v failed. findRepairs() mismatch (-want +got)\n%s", c.Name, diff)
+		}
+	}
+}
+
+func TestLabelsNames(t *testing.T) {
+	var l labels
+	l.TotalCount = 2
+	l.Nodes = append(l.Nodes, struct{ Name string }{"a"})
+	if got := l.names(); got != nil {
+		t.Errorf("test: truncated failed. got: %v want: nil", got)
+	}
+	l.Nodes = append(l.Nodes, struct{ Name string }{"b"})
+	if diff := cmp.Diff([]string{"a", "b"}, l.names()); diff != "" {
+		t.Errorf("test: complete failed. names() mismatch (-want +got)\n%s", diff)
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Report formats.
+const (
+	formatJSON = "json"
+	formatCSV  = "csv"
+)
+
+// Why an issue is tombstoned.
+const (
+	reasonMissing     = "missing from GitHub"
+	reasonTransferred = "transferred"
+)
+
+// What the sweeper did with a repository's tombstone candidates.
+const (
+	// actionNone means there were no candidates.
+	actionNone       = "none"
+	actionTombstoned = "tombstoned"
+	// actionDryRun means the candidates would have been tombstoned.
+	actionDryRun = "dry-run"
+	// actionRefused means there were more candidates than the safety
+	// threshold allows.
+	actionRefused = "refused"
+	// actionError means the repository could not be swept.
+	actionError = "error"
+)
+
+// report is what a sweep did, or would have done in a dry run.
+type report struct {
+	DryRun              bool          `json:"dry_run"`
+	MaxTombstonePercent float64       `json:"max_tombstone_percent"`
+	Started             time.Time     `json:"started"`
+	Finished            time.Time     `json:"finished"`
+	Totals              reportTotals  `json:"totals"`
+	Repositories        []*repoReport `json:"repositories"`
+}
+
+type reportTotals struct {
+	Repositories int `json:"repositories"`
+	Candidates   int `json:"candidates"`
+	Tombstoned   int `json:"tombstoned"`
+	Transferred  int `json:"transferred"`
+	Repairs      int `json:"repairs"`
+	Refetched    int `json:"refetched"`
+	Refused      int `json:"refused"`
+	Errors       int `json:"errors"`
+}
+
+// repoReport is what a sweep did with one repository.
+type repoReport struct {
+	Repository         string       `json:"repository"`
+	MaintnerIssues     int          `json:"maintner_issues"`
+	GitHubIssues       int          `json:"github_issues"`
+	GitHubPullRequests int          `json:"github_pull_requests"`
+	Action             string       `json:"action"`
+	Candidates         []*candidate `json:"candidates,omitempty"`
+	Tombstoned         int          `json:"tombstoned"`
+	Transferred        int          `json:"transferred"`
+	// RepairAction is what the sweeper did with the repair candidates, if
+	// it looked for them.
+	RepairAction string    `json:"repair_action,omitempty"`
+	Repairs      []*repair `json:"repairs,omitempty"`
+	Refetched    int       `json:"refetched"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// candidate is an issue maintner has that the sweeper would tombstone.
+type candidate struct {
+	IssueID int32  `json:"issue_id"`
+	Reason  string `json:"reason"`
+	// TransferredTo is the issue it was transferred to, if it was.
+	TransferredTo string `json:"transferred_to,omitempty"`
+}
+
+// add adds rr to r and its totals.
+func (r *report) add(rr *repoReport) {
+	r.Repositories = append(r.Repositories, rr)
+	r.Totals.Repositories++
+	r.Totals.Candidates += len(rr.Candidates)
+	r.Totals.Tombstoned += rr.Tombstoned
+	r.Totals.Transferred += rr.Transferred
+	r.Totals.Repairs += len(rr.Repairs)
+	r.Totals.Refetched += rr.Refetched
+	if rr.Action == actionRefused || rr.RepairAction == actionRefused {
+		r.Totals.Refused++
+	}
+	if rr.Action == actionError || rr.RepairAction == actionError {
+		r.Totals.Errors++
+	}
+}
+
+// write writes r in format to w.
+func (r *report) write(w io.Writer, format string) error {
+	switch format {
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case formatCSV:
+		return r.writeCSV(w)
+	}
+	return fmt.Errorf("unknown report format %q", format)
+}
+
+// writeCSV writes a row per candidate and repair candidate, and one for
+// each repository without candidates.
+func (r *report) writeCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"repository", "issue_id", "reason", "transferred_to", "action", "maintner_issues", "error"})
+	for _, rr := range r.Repositories {
+		row := func(id, reason, to, action string) {
+			cw.Write([]string{rr.Repository, id, reason, to, action, strconv.Itoa(rr.MaintnerIssues), rr.Error})
+		}
+		if len(rr.Candidates) == 0 && len(rr.Repairs) == 0 {
+			row("", "", "", rr.Action)
+		}
+		for _, c := range rr.Candidates {
+			row(strconv.Itoa(int(c.IssueID)), c.Reason, c.TransferredTo, rr.Action)
+		}
+		for _, rp := range rr.Repairs {
+			row(strconv.Itoa(int(rp.IssueID)), strings.Join(rp.Reasons, "; "), "", rr.RepairAction)
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeFile writes r in format to the file at path, or to stdout if path
+// is "-".
+func (r *report) writeFile(path, format string) error {
+	if path == "-" {
+		return r.write(os.Stdout, format)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := r.write(f, format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,117 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func testReport() *report {
+	r := &report{DryRun: true, MaxTombstonePercent: 10}
+	r.add(&repoReport{
+		Repository:     "foo/bar",
+		MaintnerIssues: 100,
+		Action:         actionDryRun,
+		Candidates: []*candidate{
+			{IssueID: 4, Reason: reasonMissing},
+			{IssueID: 7, Reason: reasonTransferred, TransferredTo: "foo/qux/issues/1"},
+		},
+	})
+	r.add(&repoReport{
+		Repository:     "foo/baz",
+		MaintnerIssues: 3,
+		Action:         actionNone,
+		RepairAction:   actionRefetched,
+		Repairs: []*repair{
+			{IssueID: 5, Reasons: []string{reasonState, reasonLabels}},
+		},
+		Refetched: 1,
+	})
+	r.add(&repoReport{Repository: "foo/qux", Action: actionError, Error: "unavailable"})
+	return r
+}
+
+func TestReportTotals(t *testing.T) {
+	want := reportTotals{Repositories: 3, Candidates: 2, Repairs: 1, Refetched: 1, Errors: 1}
+	if diff := cmp.Diff(want, testReport().Totals); diff != "" {
+		t.Errorf("totals mismatch (-want +got)\n%s", diff)
+	}
+}
+
+func TestReportWriteCSV(t *testing.T) {
+	var b bytes.Buffer
+	if err := testReport().write(&b, formatCSV); err != nil {
+		t.Fatal(err)
+	}
+	want := `repository,issue_id,reason,transferred_to,action,maintner_issues,error
+foo/bar,4,missing from GitHub,,dry-run,100,
+foo/bar,7,transferred,foo/qux/issues/1,dry-run,100,
+foo/baz,5,state differs; labels differ,,refetched,3,
+foo/qux,,,,error,0,unavailable
+`
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Errorf("write() mismatch (-want +got)\n%s", diff)
+	}
+}
+
+func TestReportWriteJSON(t *testing.T) {
+	var b bytes.Buffer
+	want := testReport()
+	if err := want.write(&b, formatJSON); err != nil {
+		t.Fatal(err)
+	}
+	got := &report{}
+	if err := json.Unmarshal(b.Bytes(), got); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("write() round trip mismatch (-want +got)\n%s", diff)
+	}
+
+	if err := want.write(&b, "xml"); err == nil {
+		t.Errorf("write() with an unknown format. Wanted an error, Got nil")
+	}
+}
+
+func TestCheckTombstoneThreshold(t *testing.T) {
+	cases := []struct {
+		Name       string
+		N          int
+		Total      int
+		MaxPercent float64
+		WantErr    bool
+	}{
+		{Name: "Below", N: 5, Total: 100, MaxPercent: 10},
+		{Name: "At", N: 10, Total: 100, MaxPercent: 10},
+		{Name: "Above", N: 11, Total: 100, MaxPercent: 10, WantErr: true},
+		{Name: "All", N: 3, Total: 3, MaxPercent: 10, WantErr: true},
+		{Name: "Disabled", N: 3, Total: 3, MaxPercent: 100},
+		{Name: "No issues", N: 0, Total: 0, MaxPercent: 10},
+	}
+	for _, c := range cases {
+		err := checkTombstoneThreshold(c.N, c.Total, c.MaxPercent)
+		if (err != nil) != c.WantErr {
+			t.Errorf("test: %v failed. got error: %v want error: %v", c.Name, err, c.WantErr)
+		}
+		if err != nil && !errors.Is(err, errTooManyTombstones) {
+			t.Errorf("test: %v failed. got error: %v want: %v", c.Name, err, errTooManyTombstones)
+		}
+	}
+}
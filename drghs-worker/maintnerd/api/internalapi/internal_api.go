@@ -6,20 +6,37 @@ import (
 	"strings"
 
 	maintner_internal "github.com/GoogleCloudPlatform/devrel-services/drghs-worker/internal"
+	"github.com/GoogleCloudPlatform/devrel-services/drghs-worker/pkg/transfers"
 	"golang.org/x/build/maintner"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // TransferProxyServer implements maintner_internal.InternalIssueServiceServer
 type TransferProxyServer struct {
-	c *maintner.Corpus
+	c         *maintner.Corpus
+	transfers *transfers.Store
+	refetcher Refetcher
 }
 
 var _ maintner_internal.InternalIssueServiceServer = &TransferProxyServer{}
 
-// NewTransferProxyServer builds and returns a TransferProxyServer
-func NewTransferProxyServer(c *maintner.Corpus) *TransferProxyServer {
+// Refetcher replaces what the corpus has of issues with what GitHub has of
+// them now.
+type Refetcher interface {
+	// Refetch refetches the issues of rID with the given numbers and returns
+	// how many it refetched.
+	Refetch(ctx context.Context, rID maintner.GitHubRepoID, numbers []int32) (int32, error)
+}
+
+// NewTransferProxyServer builds and returns a TransferProxyServer. t records
+// where issues were transferred to, and may be nil to refuse recording
+// transfers. rf may be nil to refuse refetching issues.
+func NewTransferProxyServer(c *maintner.Corpus, t *transfers.Store, rf Refetcher) *TransferProxyServer {
 	return &TransferProxyServer{
-		c: c,
+		c:         c,
+		transfers: t,
+		refetcher: rf,
 	}
 }
 
@@ -61,6 +78,92 @@ func (s *TransferProxyServer) TombstoneIssues(ctx context.Context, r *maintner_i
 	}, nil
 }
 
+// RecordTransfers records where the requested issues that are in the corpus
+// were transferred to, then tombstones them
+func (s *TransferProxyServer) RecordTransfers(ctx context.Context, r *maintner_internal.RecordTransfersRequest) (*maintner_internal.RecordTransfersResponse, error) {
+	if s.transfers == nil {
+		return nil, status.Error(codes.FailedPrecondition, "this instance does not record transfers")
+	}
+	dests := make(map[int32]string, len(r.Transfers))
+	for _, t := range r.Transfers {
+		if !transfers.ValidDestination(t.TransferredTo) {
+			return nil, status.Errorf(codes.InvalidArgument, "issue %v: invalid transferred_to %q", t.IssueNumber, t.TransferredTo)
+		}
+		dests[t.IssueNumber] = t.TransferredTo
+	}
+
+	var nrecorded int32
+	err := s.c.GitHub().ForeachRepo(func(repo *maintner.GitHubRepo) error {
+		repoID := getRepoPath(repo)
+		if !strings.HasPrefix(r.Parent, repoID) {
+			// Not our repository... ignore
+			return nil
+		}
+
+		issues := make([]*maintner.GitHubIssue, 0, len(dests))
+		for n := range dests {
+			issue := repo.Issue(n)
+			if issue == nil {
+				return status.Errorf(codes.NotFound, "Issue: %v not found", n)
+			}
+			issues = append(issues, issue)
+		}
+
+		// Record first: an issue tombstoned without its transfer would be
+		// indistinguishable from a deleted one.
+		if err := s.transfers.Record(ctx, repo.ID(), dests); err != nil {
+			return err
+		}
+		for _, issue := range issues {
+			if err := repo.MarkTombstoned(issue); err != nil {
+				return err
+			}
+			nrecorded++
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &maintner_internal.RecordTransfersResponse{
+		RecordedCount: nrecorded,
+	}, nil
+}
+
+// RefetchIssues fetches the requested issues from GitHub again, adding those
+// the corpus lacks and replacing the others
+func (s *TransferProxyServer) RefetchIssues(ctx context.Context, r *maintner_internal.RefetchIssuesRequest) (*maintner_internal.RefetchIssuesResponse, error) {
+	if s.refetcher == nil {
+		return nil, status.Error(codes.FailedPrecondition, "this instance does not refetch issues")
+	}
+
+	var nrefetched int32
+	err := s.c.GitHub().ForeachRepo(func(repo *maintner.GitHubRepo) error {
+		repoID := getRepoPath(repo)
+		if !strings.HasPrefix(r.Parent, repoID) {
+			// Not our repository... ignore
+			return nil
+		}
+
+		n, err := s.refetcher.Refetch(ctx, repo.ID(), r.IssueNumbers)
+		if err != nil {
+			return err
+		}
+		nrefetched += n
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &maintner_internal.RefetchIssuesResponse{
+		RefetchedCount: nrefetched,
+	}, nil
+}
+
 func getRepoPath(ta *maintner.GitHubRepo) string {
 	return fmt.Sprintf("%v/%v", ta.ID().Owner, ta.ID().Repo)
 }
@@ -0,0 +1,222 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	"time"
+
+	"github.com/GoogleCloudPlatform/devrel-services/drghs-worker/pkg/utils"
+	drghs_v1 "github.com/GoogleCloudPlatform/devrel-services/drghs/v1"
+	"google.golang.org/genproto/protobuf/field_mask"
+
+	"golang.org/x/build/maintner"
+)
+
+// makeIssuePBAsOf is like makeIssuePB, but returns the issue as it was at
+// time t. It returns nil if the issue did not exist yet at t.
+func makeIssuePBAsOf(repo *maintner.GitHubRepo, issue *maintner.GitHubIssue, t time.Time, includeComments bool, includeReviews bool, fm *field_mask.FieldMask) (*drghs_v1.Issue, error) {
+	snap := issueAsOf(repo, issue, t)
+	if snap == nil {
+		return nil, nil
+	}
+
+	// The snapshot still shares its comments, reviews and events with the
+	// live issue, so those are filled in below, bounded by t.
+	riss, err := makeIssuePB(snap, repo.ID(), false, false, fm)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := fm.GetPaths()
+	if paths == nil || contains(paths, "commit") {
+		commitID := ""
+		issue.ForeachEvent(func(event *maintner.GitHubIssueEvent) error {
+			if !event.Created.After(t) && event.CommitID != "" {
+				commitID = event.CommitID
+			}
+			return nil
+		})
+		riss.Commit = commitID
+	}
+
+	if paths == nil || contains(paths, "approved") {
+		riss.Approved = utils.IsApprovedAsOf(issue, t)
+	}
+
+	if includeComments || (paths != nil && contains(paths, "comments")) {
+		riss.Comments = make([]*drghs_v1.GitHubComment, 0)
+		err := issue.ForeachComment(func(co *maintner.GitHubComment) error {
+			if co.Created.After(t) {
+				return nil
+			}
+			cpb, err := makeCommentPB(co)
+			if err != nil {
+				return err
+			}
+			riss.Comments = append(riss.Comments, cpb)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if includeReviews || (paths != nil && contains(paths, "reviews")) {
+		riss.Reviews = make([]*drghs_v1.GitHubReview, 0)
+		err := issue.ForeachReview(func(rev *maintner.GitHubReview) error {
+			if rev.Created.After(t) {
+				return nil
+			}
+			rpb, err := makeReviewPB(rev)
+			if err != nil {
+				return err
+			}
+			riss.Reviews = append(riss.Reviews, rpb)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return riss, nil
+}
+
+// issueAsOf returns a copy of issue with its state, title, labels and
+// assignees as they were at time t. It returns nil if the issue was created
+// after t.
+func issueAsOf(repo *maintner.GitHubRepo, issue *maintner.GitHubIssue, t time.Time) *maintner.GitHubIssue {
+	if issue.Created.After(t) {
+		return nil
+	}
+
+	labelIDs := make(map[string]int64)
+	repo.ForeachLabel(func(l *maintner.GitHubLabel) error {
+		labelIDs[l.Name] = l.ID
+		return nil
+	})
+
+	events := make([]*maintner.GitHubIssueEvent, 0)
+	issue.ForeachEvent(func(e *maintner.GitHubIssueEvent) error {
+		events = append(events, e)
+		return nil
+	})
+
+	snap := rollbackIssue(issue, events, labelIDs, t)
+
+	if issue.Updated.After(t) {
+		bump := func(at time.Time) {
+			if !at.After(t) && at.After(snap.Updated) {
+				snap.Updated = at
+			}
+		}
+		issue.ForeachComment(func(co *maintner.GitHubComment) error {
+			bump(co.Created)
+			return nil
+		})
+		issue.ForeachReview(func(rev *maintner.GitHubReview) error {
+			bump(rev.Created)
+			return nil
+		})
+	}
+
+	return snap
+}
+
+// rollbackIssue returns a copy of issue with every event in events that
+// happened after t undone, newest first. events must be in chronological
+// order, as yielded by ForeachEvent. labelIDs maps label names to their IDs
+// so that labels removed after t can be restored.
+func rollbackIssue(issue *maintner.GitHubIssue, events []*maintner.GitHubIssueEvent, labelIDs map[string]int64, t time.Time) *maintner.GitHubIssue {
+	snap := *issue
+	snap.Labels = make(map[int64]*maintner.GitHubLabel, len(issue.Labels))
+	for id, l := range issue.Labels {
+		snap.Labels[id] = l
+	}
+	snap.Assignees = append([]*maintner.GitHubUser(nil), issue.Assignees...)
+
+	stateChanged := false
+	for i := len(events) - 1; i >= 0; i-- {
+		e := events[i]
+		if !e.Created.After(t) {
+			break
+		}
+		switch e.Type {
+		case "labeled":
+			for id, l := range snap.Labels {
+				if l.Name == e.Label {
+					delete(snap.Labels, id)
+				}
+			}
+		case "unlabeled":
+			id, ok := labelIDs[e.Label]
+			if !ok {
+				// The label has since been deleted from the repository.
+				// Any key unique within this snapshot will do.
+				id = -int64(len(snap.Labels) + 1)
+			}
+			snap.Labels[id] = &maintner.GitHubLabel{ID: id, Name: e.Label}
+		case "assigned":
+			snap.Assignees = removeUser(snap.Assignees, e.Assignee)
+		case "unassigned":
+			if e.Assignee != nil {
+				snap.Assignees = append(removeUser(snap.Assignees, e.Assignee), e.Assignee)
+			}
+		case "closed":
+			snap.Closed = false
+			stateChanged = true
+		case "reopened":
+			snap.Closed = true
+			stateChanged = true
+		case "renamed":
+			snap.Title = e.From
+		}
+	}
+
+	if issue.Updated.After(t) {
+		snap.Updated = snap.Created
+	}
+	if stateChanged {
+		snap.ClosedAt = time.Time{}
+		snap.ClosedBy = nil
+	}
+	for _, e := range events {
+		if e.Created.After(t) {
+			break
+		}
+		if issue.Updated.After(t) && e.Created.After(snap.Updated) {
+			snap.Updated = e.Created
+		}
+		if stateChanged && snap.Closed && e.Type == "closed" {
+			snap.ClosedAt = e.Created
+			snap.ClosedBy = e.Actor
+		}
+	}
+
+	return &snap
+}
+
+func removeUser(users []*maintner.GitHubUser, u *maintner.GitHubUser) []*maintner.GitHubUser {
+	if u == nil {
+		return users
+	}
+	ret := users[:0]
+	for _, o := range users {
+		if o != u && (o.ID == 0 || o.ID != u.ID) {
+			ret = append(ret, o)
+		}
+	}
+	return ret
+}
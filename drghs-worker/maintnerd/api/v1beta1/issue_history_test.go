@@ -0,0 +1,189 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/build/maintner"
+)
+
+func TestRollbackIssue(t *testing.T) {
+	created := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	day := func(n int) time.Time { return created.Add(time.Duration(n) * 24 * time.Hour) }
+
+	alice := &maintner.GitHubUser{ID: 1, Login: "alice"}
+	bob := &maintner.GitHubUser{ID: 2, Login: "bob"}
+	carol := &maintner.GitHubUser{ID: 3, Login: "carol"}
+
+	// The issue as it is "now": closed by carol on day 5, renamed on day 4,
+	// labeled "p1" (after "p2" was removed) on day 3, reassigned on day 2.
+	issue := &maintner.GitHubIssue{
+		Number:    1,
+		Created:   created,
+		Updated:   day(5),
+		Closed:    true,
+		ClosedAt:  day(5),
+		Title:     "new title",
+		Assignees: []*maintner.GitHubUser{bob},
+		Labels: map[int64]*maintner.GitHubLabel{
+			10: {ID: 10, Name: "bug"},
+			12: {ID: 12, Name: "p1"},
+		},
+	}
+	events := []*maintner.GitHubIssueEvent{
+		{ID: 1, Type: "labeled", Label: "bug", Created: created},
+		{ID: 2, Type: "labeled", Label: "p2", Created: created},
+		{ID: 3, Type: "assigned", Assignee: alice, Created: day(1)},
+		{ID: 4, Type: "unassigned", Assignee: alice, Created: day(2)},
+		{ID: 5, Type: "assigned", Assignee: bob, Created: day(2)},
+		{ID: 6, Type: "unlabeled", Label: "p2", Created: day(3)},
+		{ID: 7, Type: "labeled", Label: "p1", Created: day(3)},
+		{ID: 8, Type: "renamed", From: "old title", To: "new title", Created: day(4)},
+		{ID: 9, Type: "closed", Actor: carol, Created: day(5)},
+	}
+	labelIDs := map[string]int64{"bug": 10, "p2": 11, "p1": 12}
+
+	type state struct {
+		Title     string
+		Closed    bool
+		ClosedAt  time.Time
+		ClosedBy  string
+		Updated   time.Time
+		Labels    []string
+		Assignees []string
+	}
+	summarize := func(iss *maintner.GitHubIssue) state {
+		s := state{
+			Title:    iss.Title,
+			Closed:   iss.Closed,
+			ClosedAt: iss.ClosedAt,
+			Updated:  iss.Updated,
+		}
+		if iss.ClosedBy != nil {
+			s.ClosedBy = iss.ClosedBy.Login
+		}
+		for _, l := range iss.Labels {
+			s.Labels = append(s.Labels, l.Name)
+		}
+		sort.Strings(s.Labels)
+		for _, a := range iss.Assignees {
+			s.Assignees = append(s.Assignees, a.Login)
+		}
+		return s
+	}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want state
+	}{
+		{
+			name: "At creation",
+			at:   created,
+			want: state{
+				Title:   "old title",
+				Updated: created,
+				Labels:  []string{"bug", "p2"},
+			},
+		},
+		{
+			name: "Assigned to alice",
+			at:   day(1),
+			want: state{
+				Title:     "old title",
+				Updated:   day(1),
+				Labels:    []string{"bug", "p2"},
+				Assignees: []string{"alice"},
+			},
+		},
+		{
+			name: "Relabeled",
+			at:   day(3),
+			want: state{
+				Title:     "old title",
+				Updated:   day(3),
+				Labels:    []string{"bug", "p1"},
+				Assignees: []string{"bob"},
+			},
+		},
+		{
+			name: "Renamed but still open",
+			at:   day(4).Add(time.Hour),
+			want: state{
+				Title:     "new title",
+				Updated:   day(4),
+				Labels:    []string{"bug", "p1"},
+				Assignees: []string{"bob"},
+			},
+		},
+		{
+			name: "Current state",
+			at:   day(10),
+			want: state{
+				Title:     "new title",
+				Closed:    true,
+				ClosedAt:  day(5),
+				Updated:   day(5),
+				Labels:    []string{"bug", "p1"},
+				Assignees: []string{"bob"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		got := rollbackIssue(issue, events, labelIDs, test.at)
+		if diff := cmp.Diff(test.want, summarize(got)); diff != "" {
+			t.Errorf("%v: rollbackIssue() mismatch (-want +got):\n%s", test.name, diff)
+		}
+	}
+
+	// The live issue must be left untouched.
+	if diff := cmp.Diff([]string{"bob"}, summarize(issue).Assignees); diff != "" {
+		t.Errorf("rollbackIssue() modified the issue's assignees (-want +got):\n%s", diff)
+	}
+	if len(issue.Labels) != 2 {
+		t.Errorf("rollbackIssue() modified the issue's labels. Wanted 2, Got %v", len(issue.Labels))
+	}
+}
+
+func TestRollbackIssueReopened(t *testing.T) {
+	created := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	carol := &maintner.GitHubUser{ID: 3, Login: "carol"}
+
+	issue := &maintner.GitHubIssue{
+		Created: created,
+		Updated: created.Add(2 * time.Hour),
+		Closed:  false,
+	}
+	events := []*maintner.GitHubIssueEvent{
+		{ID: 1, Type: "closed", Actor: carol, Created: created.Add(time.Hour)},
+		{ID: 2, Type: "reopened", Actor: carol, Created: created.Add(2 * time.Hour)},
+	}
+
+	got := rollbackIssue(issue, events, nil, created.Add(90*time.Minute))
+	if !got.Closed {
+		t.Errorf("rollbackIssue() Closed. Wanted true, Got false")
+	}
+	if !got.ClosedAt.Equal(created.Add(time.Hour)) {
+		t.Errorf("rollbackIssue() ClosedAt. Wanted %v, Got %v", created.Add(time.Hour), got.ClosedAt)
+	}
+	if got.ClosedBy != carol {
+		t.Errorf("rollbackIssue() ClosedBy. Wanted %v, Got %v", carol, got.ClosedBy)
+	}
+}
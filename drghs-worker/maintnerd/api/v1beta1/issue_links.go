@@ -0,0 +1,317 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/GoogleCloudPlatform/devrel-services/drghs-worker/pkg/retention"
+	drghs_v1 "github.com/GoogleCloudPlatform/devrel-services/drghs/v1"
+	"google.golang.org/genproto/protobuf/field_mask"
+
+	"golang.org/x/build/maintner"
+)
+
+// linkGraphTTL is how long a repository's link graph is reused before it is
+// rebuilt from the corpus.
+const linkGraphTTL = time.Minute
+
+// issueRef matches a reference to an issue: "#N", "owner/repo#N" or a
+// GitHub issue or pull request URL.
+const issueRef = `((?:[\w.-]+/[\w.-]+)?#\d+|https://github\.com/[\w.-]+/[\w.-]+/(?:issues|pull)/\d+)`
+
+var (
+	fixesReg     = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?):?\s+` + issueRef)
+	blockedByReg = regexp.MustCompile(`(?i)\bblocked\s+by:?\s+` + issueRef)
+	mentionReg   = regexp.MustCompile(`(?:^|[^\w/#])` + issueRef)
+	issueRefReg  = regexp.MustCompile(`^(?:([\w.-]+)/([\w.-]+))?#(\d+)$|^https://github\.com/([\w.-]+)/([\w.-]+)/(?:issues|pull)/(\d+)$`)
+)
+
+// issueLink is a directed link between two issues, named as by getIssueName.
+type issueLink struct {
+	source   string
+	target   string
+	linkType drghs_v1.IssueLink_LinkType
+	merged   bool
+	// created is when the text or event establishing the link was created.
+	created time.Time
+}
+
+func (l issueLink) proto() *drghs_v1.IssueLink {
+	return &drghs_v1.IssueLink{
+		Source:   l.source,
+		Target:   l.target,
+		LinkType: l.linkType,
+		Merged:   l.merged,
+	}
+}
+
+// linkText is a piece of text links are parsed from.
+type linkText struct {
+	body    string
+	created time.Time
+	// fixes reports whether closing keywords in body create FIXES links.
+	fixes bool
+}
+
+// linkGraph holds the links involving the issues of one repository.
+type linkGraph struct {
+	links    []issueLink
+	bySource map[string][]int
+	byTarget map[string][]int
+	built    time.Time
+}
+
+// buildLinkGraph scans the pull request descriptions, issue bodies, comments
+// and cross-reference events of repo for links to other issues. Text store
+// has evicted from memory is read back from it; store may be nil.
+func buildLinkGraph(repo *maintner.GitHubRepo, store *retention.Store) (*linkGraph, error) {
+	rID := repo.ID()
+	seen := make(map[issueLink]bool)
+	links := make([]issueLink, 0)
+	add := func(l issueLink) {
+		if l.source == l.target {
+			return
+		}
+		key := issueLink{source: l.source, target: l.target, linkType: l.linkType}
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		links = append(links, l)
+	}
+
+	err := repo.ForeachIssue(func(issue *maintner.GitHubIssue) error {
+		if issue.NotExist {
+			return nil
+		}
+		source := fmt.Sprintf("%v/%v/issues/%v", rID.Owner, rID.Repo, issue.Number)
+		merged := issue.PullRequest && issue.HasEvent("merged")
+
+		texts, err := issueLinkTexts(rID, issue, store)
+		if err != nil {
+			return err
+		}
+		for _, txt := range texts {
+			if txt.fixes {
+				for _, target := range findRefs(fixesReg, txt.body, rID) {
+					add(issueLink{source: source, target: target, linkType: drghs_v1.IssueLink_FIXES, merged: merged, created: txt.created})
+				}
+			}
+			for _, target := range findRefs(blockedByReg, txt.body, rID) {
+				add(issueLink{source: source, target: target, linkType: drghs_v1.IssueLink_BLOCKED_BY, merged: merged, created: txt.created})
+			}
+			for _, target := range findRefs(mentionReg, txt.body, rID) {
+				add(issueLink{source: source, target: target, linkType: drghs_v1.IssueLink_MENTIONS, merged: merged, created: txt.created})
+			}
+		}
+
+		// maintner keeps the source of "cross-referenced" events in
+		// their OtherJSON.
+		issue.ForeachEvent(func(e *maintner.GitHubIssueEvent) error {
+			if e.Type != "cross-referenced" || e.OtherJSON == "" {
+				return nil
+			}
+			var ev struct {
+				Source struct {
+					Issue struct {
+						Number     int32 `json:"number"`
+						Repository struct {
+							FullName string `json:"full_name"`
+						} `json:"repository"`
+					} `json:"issue"`
+				} `json:"source"`
+			}
+			if err := json.Unmarshal([]byte(e.OtherJSON), &ev); err != nil || ev.Source.Issue.Number == 0 {
+				return nil
+			}
+			from := ev.Source.Issue.Repository.FullName
+			if from == "" {
+				from = fmt.Sprintf("%v/%v", rID.Owner, rID.Repo)
+			}
+			// The event is recorded on the referenced issue; the link
+			// originates in the referencing one.
+			add(issueLink{
+				source:   fmt.Sprintf("%v/issues/%v", from, ev.Source.Issue.Number),
+				target:   source,
+				linkType: drghs_v1.IssueLink_MENTIONS,
+				created:  e.Created,
+			})
+			return nil
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// A mention is redundant with a stronger link between the same issues.
+	kept := links[:0]
+	for _, l := range links {
+		if l.linkType == drghs_v1.IssueLink_MENTIONS {
+			fkey := issueLink{source: l.source, target: l.target, linkType: drghs_v1.IssueLink_FIXES}
+			bkey := issueLink{source: l.source, target: l.target, linkType: drghs_v1.IssueLink_BLOCKED_BY}
+			if seen[fkey] || seen[bkey] {
+				continue
+			}
+		}
+		kept = append(kept, l)
+	}
+	links = kept
+
+	sort.SliceStable(links, func(i, j int) bool {
+		if links[i].source != links[j].source {
+			return links[i].source < links[j].source
+		}
+		if links[i].target != links[j].target {
+			return links[i].target < links[j].target
+		}
+		return links[i].linkType < links[j].linkType
+	})
+
+	g := &linkGraph{
+		links:    links,
+		bySource: make(map[string][]int),
+		byTarget: make(map[string][]int),
+		built:    time.Now(),
+	}
+	for i, l := range links {
+		g.bySource[l.source] = append(g.bySource[l.source], i)
+		g.byTarget[l.target] = append(g.byTarget[l.target], i)
+	}
+	return g, nil
+}
+
+// findRefs returns the names of the issues referenced by the first capture
+// group of reg in body. Bare "#N" references resolve to rID.
+func findRefs(reg *regexp.Regexp, body string, rID maintner.GitHubRepoID) []string {
+	var names []string
+	for _, m := range reg.FindAllStringSubmatch(body, -1) {
+		if n := refToName(m[1], rID); n != "" {
+			names = append(names, n)
+		}
+	}
+	return names
+}
+
+func refToName(ref string, rID maintner.GitHubRepoID) string {
+	m := issueRefReg.FindStringSubmatch(ref)
+	switch {
+	case m == nil:
+		return ""
+	case m[3] != "" && m[1] == "":
+		return fmt.Sprintf("%v/%v/issues/%v", rID.Owner, rID.Repo, m[3])
+	case m[3] != "":
+		return fmt.Sprintf("%v/%v/issues/%v", m[1], m[2], m[3])
+	default:
+		return fmt.Sprintf("%v/%v/issues/%v", m[4], m[5], m[6])
+	}
+}
+
+// linkedPullRequests returns the pull requests that fix the issue named name,
+// considering only links created at or before t unless t is zero.
+func (g *linkGraph) linkedPullRequests(name string, t time.Time) []string {
+	ret := make([]string, 0)
+	for _, i := range g.byTarget[name] {
+		l := g.links[i]
+		if l.linkType == drghs_v1.IssueLink_FIXES && (t.IsZero() || !l.created.After(t)) {
+			ret = append(ret, l.source)
+		}
+	}
+	return ret
+}
+
+// blockedBy returns the issues that block the issue named name,
+// considering only links created at or before t unless t is zero.
+func (g *linkGraph) blockedBy(name string, t time.Time) []string {
+	ret := make([]string, 0)
+	for _, i := range g.bySource[name] {
+		l := g.links[i]
+		if l.linkType == drghs_v1.IssueLink_BLOCKED_BY && (t.IsZero() || !l.created.After(t)) {
+			ret = append(ret, l.target)
+		}
+	}
+	return ret
+}
+
+// fillLinks sets the link fields of riss, the Issue named name, from g.
+func fillLinks(riss *drghs_v1.Issue, name string, g *linkGraph, t time.Time, fm *field_mask.FieldMask) {
+	if g == nil {
+		return
+	}
+	paths := fm.GetPaths()
+	if paths == nil || contains(paths, "linked_pull_requests") {
+		riss.LinkedPullRequests = g.linkedPullRequests(name, t)
+	}
+	if paths == nil || contains(paths, "blocked_by") {
+		riss.BlockedBy = g.blockedBy(name, t)
+	}
+}
+
+// issueLinkTexts returns the description and comments of issue of rID,
+// with the text store evicted from memory read back from it.
+func issueLinkTexts(rID maintner.GitHubRepoID, issue *maintner.GitHubIssue, store *retention.Store) ([]linkText, error) {
+	evicted, err := store.Text(rID, issue.Number)
+	if err != nil {
+		return nil, err
+	}
+	if evicted == nil {
+		evicted = &retention.Text{}
+	}
+
+	body := issue.Body
+	if body == "" {
+		body = evicted.Body
+	}
+	// GitHub only honors closing keywords in pull request descriptions.
+	texts := []linkText{{body: body, created: issue.Created, fixes: issue.PullRequest}}
+	issue.ForeachComment(func(co *maintner.GitHubComment) error {
+		body := co.Body
+		if body == "" {
+			body = evicted.Comments[co.ID]
+		}
+		texts = append(texts, linkText{body: body, created: co.Created})
+		return nil
+	})
+	return texts, nil
+}
+
+// linkCache keeps a recently built linkGraph per repository.
+type linkCache struct {
+	graphs map[maintner.GitHubRepoID]*linkGraph
+	// store holds the text evicted from the corpus. May be nil
+	store *retention.Store
+	mu    sync.Mutex
+}
+
+func (c *linkCache) get(repo *maintner.GitHubRepo) (*linkGraph, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if g, ok := c.graphs[repo.ID()]; ok && time.Since(g.built) < linkGraphTTL {
+		return g, nil
+	}
+	g, err := buildLinkGraph(repo, c.store)
+	if err != nil {
+		return nil, err
+	}
+	c.graphs[repo.ID()] = g
+	return g, nil
+}
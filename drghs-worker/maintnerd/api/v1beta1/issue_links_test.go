@@ -0,0 +1,157 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	"io/ioutil"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/devrel-services/drghs-worker/pkg/retention"
+	drghs_v1 "github.com/GoogleCloudPlatform/devrel-services/drghs/v1"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/build/maintner"
+)
+
+func TestFindRefs(t *testing.T) {
+	rID := maintner.GitHubRepoID{
+		Owner: "foo",
+		Repo:  "bar",
+	}
+
+	tests := []struct {
+		name string
+		reg  *regexp.Regexp
+		body string
+		want []string
+	}{
+		{
+			name: "Fixes bare number",
+			reg:  fixesReg,
+			body: "This PR fixes #12.",
+			want: []string{"foo/bar/issues/12"},
+		},
+		{
+			name: "Closing keywords",
+			reg:  fixesReg,
+			body: "Closes #1\nResolved: #2\nFIXED other/repo#3",
+			want: []string{"foo/bar/issues/1", "foo/bar/issues/2", "other/repo/issues/3"},
+		},
+		{
+			name: "Fixes URL",
+			reg:  fixesReg,
+			body: "fix https://github.com/other/repo/issues/4",
+			want: []string{"other/repo/issues/4"},
+		},
+		{
+			name: "Not a closing keyword",
+			reg:  fixesReg,
+			body: "prefixes #5 and suffixes #6",
+			want: nil,
+		},
+		{
+			name: "Blocked by",
+			reg:  blockedByReg,
+			body: "This is blocked by #7, see also #8",
+			want: []string{"foo/bar/issues/7"},
+		},
+		{
+			name: "Mentions",
+			reg:  mentionReg,
+			body: "#9 relates to other/repo#10 (and https://github.com/a/b/pull/11) but not a#12",
+			want: []string{"foo/bar/issues/9", "other/repo/issues/10", "a/b/issues/11"},
+		},
+	}
+
+	for _, test := range tests {
+		got := findRefs(test.reg, test.body, rID)
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("%v: findRefs() mismatch (-want +got):\n%s", test.name, diff)
+		}
+	}
+}
+
+func TestIssueLinkTextsReadsEvictedText(t *testing.T) {
+	dir, err := ioutil.TempDir("", "retention")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := retention.NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rID := maintner.GitHubRepoID{Owner: "foo", Repo: "bar"}
+	pr := &maintner.GitHubIssue{Number: 7, PullRequest: true, Body: "Fixes #1"}
+	if _, err := store.Evict(rID, pr); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []*retention.Store{store, nil} {
+		texts, err := issueLinkTexts(rID, pr, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, txt := range texts {
+			for _, target := range findRefs(fixesReg, txt.body, rID) {
+				got = append(got, target)
+			}
+		}
+		var want []string
+		if s != nil {
+			want = []string{"foo/bar/issues/1"}
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("issueLinkTexts() with store %v: FIXES targets mismatch (-want +got)\n%s", s != nil, diff)
+		}
+	}
+}
+
+func TestLinkGraphQueries(t *testing.T) {
+	now := time.Now()
+	links := []issueLink{
+		{source: "foo/bar/issues/2", target: "foo/bar/issues/1", linkType: drghs_v1.IssueLink_FIXES, created: now.Add(-time.Hour)},
+		{source: "foo/bar/issues/3", target: "foo/bar/issues/1", linkType: drghs_v1.IssueLink_FIXES, created: now},
+		{source: "foo/bar/issues/4", target: "foo/bar/issues/1", linkType: drghs_v1.IssueLink_MENTIONS, created: now},
+		{source: "foo/bar/issues/1", target: "foo/bar/issues/5", linkType: drghs_v1.IssueLink_BLOCKED_BY, created: now},
+	}
+	g := &linkGraph{
+		links:    links,
+		bySource: make(map[string][]int),
+		byTarget: make(map[string][]int),
+	}
+	for i, l := range links {
+		g.bySource[l.source] = append(g.bySource[l.source], i)
+		g.byTarget[l.target] = append(g.byTarget[l.target], i)
+	}
+
+	if diff := cmp.Diff([]string{"foo/bar/issues/2", "foo/bar/issues/3"}, g.linkedPullRequests("foo/bar/issues/1", time.Time{})); diff != "" {
+		t.Errorf("linkedPullRequests() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"foo/bar/issues/2"}, g.linkedPullRequests("foo/bar/issues/1", now.Add(-time.Minute))); diff != "" {
+		t.Errorf("linkedPullRequests() as of an hour ago mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"foo/bar/issues/5"}, g.blockedBy("foo/bar/issues/1", time.Time{})); diff != "" {
+		t.Errorf("blockedBy() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{}, g.blockedBy("foo/bar/issues/5", time.Time{})); diff != "" {
+		t.Errorf("blockedBy() mismatch (-want +got):\n%s", diff)
+	}
+}
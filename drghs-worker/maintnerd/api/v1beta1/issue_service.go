@@ -27,6 +27,8 @@ import (
 
 	"github.com/GoogleCloudPlatform/devrel-services/drghs-worker/maintnerd/api/filters"
 	"github.com/GoogleCloudPlatform/devrel-services/drghs-worker/pkg/googlers"
+	"github.com/GoogleCloudPlatform/devrel-services/drghs-worker/pkg/retention"
+	"github.com/GoogleCloudPlatform/devrel-services/drghs-worker/pkg/transfers"
 
 	"golang.org/x/build/maintner"
 
@@ -46,20 +48,47 @@ type IssueServiceV1 struct {
 	corpus          *maintner.Corpus
 	rp              *repoPaginator
 	ip              *issuePaginator
+	lp              *issueLinkPaginator
+	labp            *labelPaginator
+	sp              *staleIssuePaginator
+	links           *linkCache
+	store           *retention.Store
+	transfers       *transfers.Store
+	labelDetailer   LabelDetailer
 	googlerResolver googlers.Resolver
 }
 
 // NewIssueServiceV1 returns a service that implements
-// drghs_v1.IssueServiceServer
-func NewIssueServiceV1(corpus *maintner.Corpus, resolver googlers.Resolver) *IssueServiceV1 {
+// drghs_v1.IssueServiceServer. store holds the text evicted from corpus,
+// and may be nil if nothing is evicted. t holds where issues were
+// transferred to, and may be nil if none are recorded. labelDetailer looks
+// up the colors and descriptions of labels, and may be nil to leave them
+// unset.
+func NewIssueServiceV1(corpus *maintner.Corpus, resolver googlers.Resolver, store *retention.Store, t *transfers.Store, labelDetailer LabelDetailer) *IssueServiceV1 {
 	return &IssueServiceV1{
-		corpus: corpus,
+		corpus:        corpus,
+		store:         store,
+		transfers:     t,
+		labelDetailer: labelDetailer,
 		rp: &repoPaginator{
 			set: make(map[time.Time]repoPage),
 		},
 		ip: &issuePaginator{
 			set: make(map[time.Time]issuePage),
 		},
+		lp: &issueLinkPaginator{
+			set: make(map[time.Time]issueLinkPage),
+		},
+		labp: &labelPaginator{
+			set: make(map[time.Time]labelPage),
+		},
+		sp: &staleIssuePaginator{
+			set: make(map[time.Time]staleIssuePage),
+		},
+		links: &linkCache{
+			graphs: make(map[maintner.GitHubRepoID]*linkGraph),
+			store:  store,
+		},
 	}
 }
 
@@ -176,8 +205,13 @@ func (s *IssueServiceV1) ListIssues(ctx context.Context, r *drghs_v1.ListIssuesR
 				return nil
 			}
 
+			links, err := s.links.get(repo)
+			if err != nil {
+				return err
+			}
+
 			return repo.ForeachIssue(func(issue *maintner.GitHubIssue) error {
-				i, err := handleIssue(issue, repo.ID(), r, issues)
+				i, err := handleIssue(issue, repo.ID(), links, s.store, s.transfers, r, issues)
 				issues = i
 				return err
 			})
@@ -217,6 +251,16 @@ func (s *IssueServiceV1) GetIssue(ctx context.Context, r *drghs_v1.GetIssueReque
 	resp := &drghs_v1.GetIssueResponse{}
 	issueID := int32(getIssueID(r.Name))
 	var issueResp *drghs_v1.Issue = nil
+
+	var readTime time.Time
+	if r.ReadTime != nil {
+		rt, err := ptypes.Timestamp(r.ReadTime)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid read_time: %v", err)
+		}
+		readTime = rt
+	}
+
 	err := s.corpus.GitHub().ForeachRepo(func(repo *maintner.GitHubRepo) error {
 		repoID := getRepoPath(repo)
 		if !strings.HasPrefix(r.Name, repoID) {
@@ -226,7 +270,28 @@ func (s *IssueServiceV1) GetIssue(ctx context.Context, r *drghs_v1.GetIssueReque
 		}
 
 		issue := repo.GetIssue(issueID)
-		if issue == nil || issue.NotExist {
+		if issue == nil || !isServed(issue, repo.ID(), s.transfers) {
+			return nil
+		}
+
+		links, err := s.links.get(repo)
+		if err != nil {
+			return err
+		}
+
+		if !readTime.IsZero() {
+			re, err := makeIssuePBAsOf(repo, issue, readTime, r.Comments, r.Reviews, r.FieldMask)
+			if err != nil {
+				return err
+			}
+			if re != nil {
+				fillLinks(re, getIssueName(repo, issue), links, readTime, r.FieldMask)
+				if err := fillText(re, repo.ID(), issue.Number, s.store, r.FieldMask); err != nil {
+					return err
+				}
+				fillTransfer(re, repo.ID(), issue.Number, s.transfers, r.FieldMask)
+			}
+			issueResp = re
 			return nil
 		}
 
@@ -234,6 +299,11 @@ func (s *IssueServiceV1) GetIssue(ctx context.Context, r *drghs_v1.GetIssueReque
 		if err != nil {
 			return err
 		}
+		fillLinks(re, getIssueName(repo, issue), links, time.Time{}, r.FieldMask)
+		if err := fillText(re, repo.ID(), issue.Number, s.store, r.FieldMask); err != nil {
+			return err
+		}
+		fillTransfer(re, repo.ID(), issue.Number, s.transfers, r.FieldMask)
 		issueResp = re
 		return nil
 	})
@@ -251,6 +321,282 @@ func (s *IssueServiceV1) GetIssue(ctx context.Context, r *drghs_v1.GetIssueReque
 	return resp, err
 }
 
+// ListIssueLinks lists the links involving the issues of the repo in the
+// ListIssueLinksRequest
+func (s *IssueServiceV1) ListIssueLinks(ctx context.Context, r *drghs_v1.ListIssueLinksRequest) (*drghs_v1.ListIssueLinksResponse, error) {
+	var pg []*drghs_v1.IssueLink
+	var idx int
+	var err error
+	nextToken := ""
+
+	if r.PageToken != "" {
+		pageToken, err := decodePageToken(r.PageToken)
+		if err != nil {
+			return nil, err
+		}
+
+		ftime, err := ptypes.Timestamp(pageToken.FirstRequestTimeUsec)
+		if err != nil {
+			return nil, err
+		}
+
+		pagesize := getPageSize(int(r.PageSize))
+
+		pg, idx, err = s.lp.GetPage(ftime, pagesize)
+		if err != nil {
+			return nil, err
+		}
+		nextToken, err = makeNextPageToken(pageToken, idx)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		links := make([]*drghs_v1.IssueLink, 0)
+
+		err := s.corpus.GitHub().ForeachRepo(func(repo *maintner.GitHubRepo) error {
+			repoID := getRepoPath(repo)
+			if repoID != r.Parent {
+				// Not our repository... ignore
+				return nil
+			}
+
+			g, err := s.links.get(repo)
+			if err != nil {
+				return err
+			}
+
+			issueName := ""
+			if r.IssueId != 0 {
+				issueName = fmt.Sprintf("%v/issues/%v", repoID, r.IssueId)
+			}
+			for _, l := range g.links {
+				if issueName != "" && l.source != issueName && l.target != issueName {
+					continue
+				}
+				if r.LinkType != drghs_v1.IssueLink_LINK_TYPE_UNSPECIFIED && l.linkType != r.LinkType {
+					continue
+				}
+				links = append(links, l.proto())
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		t, err := s.lp.CreatePage(links)
+		if err != nil {
+			return nil, err
+		}
+
+		pagesize := getPageSize(int(r.PageSize))
+
+		pg, idx, err = s.lp.GetPage(t, pagesize)
+		if err != nil {
+			return nil, err
+		}
+
+		if idx > 0 {
+			nextToken, err = makeFirstPageToken(t, idx)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &drghs_v1.ListIssueLinksResponse{
+		IssueLinks:    pg,
+		NextPageToken: nextToken,
+	}, err
+}
+
+// ListLabels lists the labels of the repo in the ListLabelsRequest with
+// their usage counts
+func (s *IssueServiceV1) ListLabels(ctx context.Context, r *drghs_v1.ListLabelsRequest) (*drghs_v1.ListLabelsResponse, error) {
+	var pg []*drghs_v1.Label
+	var idx int
+	var err error
+	nextToken := ""
+
+	if r.PageToken != "" {
+		pageToken, err := decodePageToken(r.PageToken)
+		if err != nil {
+			return nil, err
+		}
+
+		ftime, err := ptypes.Timestamp(pageToken.FirstRequestTimeUsec)
+		if err != nil {
+			return nil, err
+		}
+
+		pagesize := getPageSize(int(r.PageSize))
+
+		pg, idx, err = s.labp.GetPage(ftime, pagesize)
+		if err != nil {
+			return nil, err
+		}
+		nextToken, err = makeNextPageToken(pageToken, idx)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		labels := make([]*drghs_v1.Label, 0)
+
+		err := s.corpus.GitHub().ForeachRepo(func(repo *maintner.GitHubRepo) error {
+			if getRepoPath(repo) != r.Parent {
+				// Not our repository... ignore
+				return nil
+			}
+
+			var details map[string]LabelDetail
+			if s.labelDetailer != nil {
+				d, err := s.labelDetailer.LabelDetails(ctx, repo.ID())
+				if err != nil {
+					// The counts are still useful without the details.
+					fmt.Printf("Could not get label details for repo: %v err: %v\n", r.Parent, err)
+				}
+				details = d
+			}
+
+			l, err := buildLabels(repo, details)
+			if err != nil {
+				return err
+			}
+			labels = append(labels, l...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		t, err := s.labp.CreatePage(labels)
+		if err != nil {
+			return nil, err
+		}
+
+		pagesize := getPageSize(int(r.PageSize))
+
+		pg, idx, err = s.labp.GetPage(t, pagesize)
+		if err != nil {
+			return nil, err
+		}
+
+		if idx > 0 {
+			nextToken, err = makeFirstPageToken(t, idx)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &drghs_v1.ListLabelsResponse{
+		Labels:        pg,
+		NextPageToken: nextToken,
+	}, err
+}
+
+// ListStaleIssues lists the open issues of the repo in the
+// ListStaleIssuesRequest that are stale or abandoned
+func (s *IssueServiceV1) ListStaleIssues(ctx context.Context, r *drghs_v1.ListStaleIssuesRequest) (*drghs_v1.ListStaleIssuesResponse, error) {
+	var pg []*drghs_v1.StaleIssue
+	var idx int
+	var err error
+	nextToken := ""
+
+	if r.PageToken != "" {
+		pageToken, err := decodePageToken(r.PageToken)
+		if err != nil {
+			return nil, err
+		}
+
+		ftime, err := ptypes.Timestamp(pageToken.FirstRequestTimeUsec)
+		if err != nil {
+			return nil, err
+		}
+
+		pagesize := getPageSize(int(r.PageSize))
+
+		pg, idx, err = s.sp.GetPage(ftime, pagesize)
+		if err != nil {
+			return nil, err
+		}
+		nextToken, err = makeNextPageToken(pageToken, idx)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		stale := make([]*drghs_v1.StaleIssue, 0)
+		cfg := newStaleConfig(r)
+		now := time.Now()
+
+		err := s.corpus.GitHub().ForeachRepo(func(repo *maintner.GitHubRepo) error {
+			if getRepoPath(repo) != r.Parent {
+				// Not our repository... ignore
+				return nil
+			}
+
+			links, err := s.links.get(repo)
+			if err != nil {
+				return err
+			}
+
+			return repo.ForeachIssue(func(issue *maintner.GitHubIssue) error {
+				if issue.NotExist || issue.Closed {
+					return nil
+				}
+				flags := staleFlags(issue, getIssueActivity(issue), cfg, now)
+				if len(flags) == 0 {
+					return nil
+				}
+				if r.Reason != drghs_v1.StaleIssue_REASON_UNSPECIFIED && !hasReason(flags, r.Reason) {
+					return nil
+				}
+
+				iss, err := makeIssuePB(issue, repo.ID(), false, false, r.FieldMask)
+				if err != nil {
+					return err
+				}
+				fillLinks(iss, getIssueName(repo, issue), links, time.Time{}, r.FieldMask)
+				if err := fillText(iss, repo.ID(), issue.Number, s.store, r.FieldMask); err != nil {
+					return err
+				}
+				stale = append(stale, &drghs_v1.StaleIssue{
+					Issue: iss,
+					Flags: flags,
+				})
+				return nil
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		t, err := s.sp.CreatePage(stale)
+		if err != nil {
+			return nil, err
+		}
+
+		pagesize := getPageSize(int(r.PageSize))
+
+		pg, idx, err = s.sp.GetPage(t, pagesize)
+		if err != nil {
+			return nil, err
+		}
+
+		if idx > 0 {
+			nextToken, err = makeFirstPageToken(t, idx)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &drghs_v1.ListStaleIssuesResponse{
+		StaleIssues:   pg,
+		NextPageToken: nextToken,
+	}, err
+}
+
 // Check is for health checking.
 func (s *IssueServiceV1) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
 	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
@@ -299,12 +645,21 @@ func getIssueName(ta *maintner.GitHubRepo, iss *maintner.GitHubIssue) string {
 	return fmt.Sprintf("%v/%v/issues/%v", ta.ID().Owner, ta.ID().Repo, iss.Number)
 }
 
-func handleIssue(issue *maintner.GitHubIssue, rid maintner.GitHubRepoID, r *drghs_v1.ListIssuesRequest, issues []*drghs_v1.Issue) ([]*drghs_v1.Issue, error) {
-	if issue.NotExist {
+func handleIssue(issue *maintner.GitHubIssue, rid maintner.GitHubRepoID, links *linkGraph, store *retention.Store, t *transfers.Store, r *drghs_v1.ListIssuesRequest, issues []*drghs_v1.Issue) ([]*drghs_v1.Issue, error) {
+	if !isServed(issue, rid, t) {
 		return issues, nil
 	}
 
+	name := fmt.Sprintf("%v/%v/issues/%v", rid.Owner, rid.Repo, issue.Number)
 	issClean, err := makeIssuePB(issue, rid, r.Comments, r.Reviews, nil)
+	if err != nil {
+		return issues, err
+	}
+	fillLinks(issClean, name, links, time.Time{}, nil)
+	if err := fillText(issClean, rid, issue.Number, store, nil); err != nil {
+		return issues, err
+	}
+	fillTransfer(issClean, rid, issue.Number, t, nil)
 
 	should, err := filters.FilterIssue(issClean, r)
 	if err != nil {
@@ -316,6 +671,11 @@ func handleIssue(issue *maintner.GitHubIssue, rid maintner.GitHubRepoID, r *drgh
 		if err != nil {
 			return issues, err
 		}
+		fillLinks(iss, name, links, time.Time{}, r.FieldMask)
+		if err := fillText(iss, rid, issue.Number, store, r.FieldMask); err != nil {
+			return issues, err
+		}
+		fillTransfer(iss, rid, issue.Number, t, r.FieldMask)
 		return append(issues, iss), nil
 	}
 	return issues, nil
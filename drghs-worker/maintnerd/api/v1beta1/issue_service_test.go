@@ -243,7 +243,7 @@ func TestHandlesIssue(t *testing.T) {
 	}
 
 	for _, c := range cases {
-		got, goterr := handleIssue(c.Issue, c.RepoID, c.Request, c.Issues)
+		got, goterr := handleIssue(c.Issue, c.RepoID, nil, nil, nil, c.Request, c.Issues)
 		if (c.WantErr && goterr == nil) || (!c.WantErr && goterr != nil) {
 			t.Errorf("test: %v, errors diff. WantErr: %v, GotErr: %v.", c.Name, c.WantErr, goterr)
 		}
@@ -0,0 +1,61 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	"github.com/GoogleCloudPlatform/devrel-services/drghs-worker/pkg/retention"
+	drghs_v1 "github.com/GoogleCloudPlatform/devrel-services/drghs/v1"
+	"google.golang.org/genproto/protobuf/field_mask"
+
+	"golang.org/x/build/maintner"
+)
+
+// fillText restores the text of riss, issue number of rID, that store has
+// evicted from memory. Only empty fields are filled, so text maintner has
+// re-fetched since the eviction wins.
+func fillText(riss *drghs_v1.Issue, rID maintner.GitHubRepoID, number int32, store *retention.Store, fm *field_mask.FieldMask) error {
+	txt, err := store.Text(rID, number)
+	if err != nil || txt == nil {
+		return err
+	}
+
+	paths := fm.GetPaths()
+	if (paths == nil || contains(paths, "body")) && riss.Body == "" {
+		riss.Body = txt.Body
+	}
+	for _, c := range riss.Comments {
+		if c.Body != "" {
+			continue
+		}
+		for id, b := range txt.Comments {
+			if int32(id) == c.Id {
+				c.Body = b
+				break
+			}
+		}
+	}
+	for _, r := range riss.Reviews {
+		if r.Body != "" {
+			continue
+		}
+		for id, b := range txt.Reviews {
+			if int32(id) == r.Id {
+				r.Body = b
+				break
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	"github.com/GoogleCloudPlatform/devrel-services/drghs-worker/pkg/transfers"
+	drghs_v1 "github.com/GoogleCloudPlatform/devrel-services/drghs/v1"
+	"google.golang.org/genproto/protobuf/field_mask"
+
+	"golang.org/x/build/maintner"
+)
+
+// isServed reports whether issue of rID is served. Tombstoned issues are
+// hidden, unless they were transferred: those are served closed, with where
+// they went, so clients can tell them from deleted ones and counts of open
+// issues leave them out.
+func isServed(issue *maintner.GitHubIssue, rID maintner.GitHubRepoID, t *transfers.Store) bool {
+	return !issue.NotExist || t.Destination(rID, issue.Number) != ""
+}
+
+// fillTransfer sets where riss, issue number of rID, was transferred to. A
+// transferred issue is no longer open in rID, so it is reported closed.
+func fillTransfer(riss *drghs_v1.Issue, rID maintner.GitHubRepoID, number int32, t *transfers.Store, fm *field_mask.FieldMask) {
+	dest := t.Destination(rID, number)
+	paths := fm.GetPaths()
+	if paths == nil || contains(paths, "transferred_to") {
+		riss.TransferredTo = dest
+	}
+	if dest != "" && (paths == nil || contains(paths, "closed")) {
+		riss.Closed = true
+	}
+}
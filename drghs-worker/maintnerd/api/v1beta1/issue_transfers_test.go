@@ -0,0 +1,99 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/devrel-services/drghs-worker/pkg/transfers"
+	drghs_v1 "github.com/GoogleCloudPlatform/devrel-services/drghs/v1"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/build/maintner"
+	"google.golang.org/genproto/protobuf/field_mask"
+)
+
+func TestHandlesTransferredIssue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "transfers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	ts, err := transfers.Open(ctx, transfers.FileStorage(filepath.Join(dir, "transfers.json")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rID := maintner.GitHubRepoID{Owner: "foo", Repo: "bar"}
+	if err := ts.Record(ctx, rID, map[int32]string{7: "foo/baz/issues/1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		Name    string
+		Issue   *maintner.GitHubIssue
+		Request *drghs_v1.ListIssuesRequest
+		Want    []*drghs_v1.Issue
+	}{
+		{
+			Name:    "Serves transferred",
+			Issue:   &maintner.GitHubIssue{Number: 7, Title: "Foobar", NotExist: true},
+			Request: &drghs_v1.ListIssuesRequest{FieldMask: &field_mask.FieldMask{Paths: []string{"title", "transferred_to"}}},
+			Want:    []*drghs_v1.Issue{{Title: "Foobar", TransferredTo: "foo/baz/issues/1"}},
+		},
+		{
+			Name:    "Skips deleted",
+			Issue:   &maintner.GitHubIssue{Number: 8, Title: "Foobar", NotExist: true},
+			Request: &drghs_v1.ListIssuesRequest{},
+			Want:    []*drghs_v1.Issue{},
+		},
+		{
+			Name:    "Reported closed",
+			Issue:   &maintner.GitHubIssue{Number: 7, Title: "Foobar", NotExist: true},
+			Request: &drghs_v1.ListIssuesRequest{FieldMask: &field_mask.FieldMask{Paths: []string{"title", "closed"}}},
+			Want:    []*drghs_v1.Issue{{Title: "Foobar", Closed: true}},
+		},
+		{
+			Name:  "Open filter skips transferred",
+			Issue: &maintner.GitHubIssue{Number: 7, Title: "Foobar", NotExist: true},
+			Request: &drghs_v1.ListIssuesRequest{
+				ClosedNullable: &drghs_v1.ListIssuesRequest_Closed{
+					Closed: false,
+				},
+			},
+			Want: []*drghs_v1.Issue{},
+		},
+		{
+			Name:    "Field mask",
+			Issue:   &maintner.GitHubIssue{Number: 7, Title: "Foobar", NotExist: true},
+			Request: &drghs_v1.ListIssuesRequest{FieldMask: &field_mask.FieldMask{Paths: []string{"title"}}},
+			Want:    []*drghs_v1.Issue{{Title: "Foobar"}},
+		},
+	}
+	for _, c := range cases {
+		got, err := handleIssue(c.Issue, rID, nil, nil, ts, c.Request, []*drghs_v1.Issue{})
+		if err != nil {
+			t.Errorf("test: %v, unexpected error: %v", c.Name, err)
+		}
+		if diff := cmp.Diff(c.Want, got, cmpopts.IgnoreUnexported(drghs_v1.Issue{})); diff != "" {
+			t.Errorf("test: %v, values diff. match (-want +got)\n%s", c.Name, diff)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	drghs_v1 "github.com/GoogleCloudPlatform/devrel-services/drghs/v1"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/shurcooL/githubv4"
+
+	"golang.org/x/build/maintner"
+)
+
+// labelDetailsTTL is how long the details of a repository's labels are
+// reused before they are fetched from GitHub again.
+const labelDetailsTTL = 10 * time.Minute
+
+// LabelDetail is what GitHub knows about a label beyond its name.
+type LabelDetail struct {
+	Color       string
+	Description string
+}
+
+// LabelDetailer looks up the details of the labels of a repository, which
+// the corpus does not track, keyed by label name.
+type LabelDetailer interface {
+	LabelDetails(ctx context.Context, rID maintner.GitHubRepoID) (map[string]LabelDetail, error)
+}
+
+// labelUsage accumulates how a label is used by the issues of a repository.
+type labelUsage struct {
+	label *drghs_v1.Label
+	first time.Time
+	last  time.Time
+}
+
+func (u *labelUsage) used(t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	if u.first.IsZero() || t.Before(u.first) {
+		u.first = t
+	}
+	if t.After(u.last) {
+		u.last = t
+	}
+}
+
+// labelCatalog accumulates the labels of a repository and their usage.
+type labelCatalog struct {
+	repoPath string
+	details  map[string]LabelDetail
+	usages   map[string]*labelUsage
+}
+
+func newLabelCatalog(repoPath string, details map[string]LabelDetail) *labelCatalog {
+	return &labelCatalog{
+		repoPath: repoPath,
+		details:  details,
+		usages:   make(map[string]*labelUsage),
+	}
+}
+
+// define adds the label name to c if it is not there yet.
+func (c *labelCatalog) define(name string) *labelUsage {
+	u, ok := c.usages[name]
+	if !ok {
+		u = &labelUsage{
+			label: &drghs_v1.Label{
+				Name:         name,
+				Repositories: []string{c.repoPath},
+			},
+		}
+		if d, ok := c.details[name]; ok {
+			u.label.Color = d.Color
+			u.label.Description = d.Description
+		}
+		c.usages[name] = u
+	}
+	return u
+}
+
+// addIssue counts the labels of issue. events are the issue's events.
+func (c *labelCatalog) addIssue(issue *maintner.GitHubIssue, events []*maintner.GitHubIssueEvent) {
+	for _, l := range issue.Labels {
+		c.define(l.Name)
+	}
+
+	labeled := make(map[string]bool)
+	for _, e := range events {
+		if e.Type != "labeled" {
+			continue
+		}
+		// Events also name labels that have since been deleted from the
+		// repository and are on no issue; those are not listed.
+		if u, ok := c.usages[e.Label]; ok {
+			u.used(e.Created)
+		}
+		labeled[e.Label] = true
+	}
+
+	for _, l := range issue.Labels {
+		u := c.usages[l.Name]
+		if !labeled[l.Name] {
+			// No event recorded the label being applied, so it must have
+			// been applied when the issue was created.
+			u.used(issue.Created)
+		}
+		switch {
+		case issue.PullRequest && issue.Closed:
+			u.label.ClosedPullRequestCount++
+		case issue.PullRequest:
+			u.label.OpenPullRequestCount++
+		case issue.Closed:
+			u.label.ClosedIssueCount++
+		default:
+			u.label.OpenIssueCount++
+		}
+	}
+}
+
+// labels returns the labels of c ordered by name.
+func (c *labelCatalog) labels() ([]*drghs_v1.Label, error) {
+	var err error
+	labels := make([]*drghs_v1.Label, 0, len(c.usages))
+	for _, u := range c.usages {
+		if !u.first.IsZero() {
+			if u.label.FirstUsedTime, err = ptypes.TimestampProto(u.first); err != nil {
+				return nil, err
+			}
+			if u.label.LastUsedTime, err = ptypes.TimestampProto(u.last); err != nil {
+				return nil, err
+			}
+		}
+		labels = append(labels, u.label)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].Name < labels[j].Name
+	})
+	return labels, nil
+}
+
+// buildLabels returns the labels of repo with their usage counts, ordered
+// by name. Labels that are defined in the repository but never used are
+// included with zero counts.
+func buildLabels(repo *maintner.GitHubRepo, details map[string]LabelDetail) ([]*drghs_v1.Label, error) {
+	c := newLabelCatalog(getRepoPath(repo), details)
+
+	repo.ForeachLabel(func(l *maintner.GitHubLabel) error {
+		c.define(l.Name)
+		return nil
+	})
+
+	err := repo.ForeachIssue(func(issue *maintner.GitHubIssue) error {
+		if issue.NotExist {
+			return nil
+		}
+		events := make([]*maintner.GitHubIssueEvent, 0)
+		issue.ForeachEvent(func(e *maintner.GitHubIssueEvent) error {
+			events = append(events, e)
+			return nil
+		})
+		c.addIssue(issue, events)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.labels()
+}
+
+type labelDetailsEntry struct {
+	details map[string]LabelDetail
+	fetched time.Time
+}
+
+// GitHubLabelDetailer is a LabelDetailer that queries the GitHub GraphQL
+// API, caching the result per repository.
+type GitHubLabelDetailer struct {
+	c     *githubv4.Client
+	cache map[maintner.GitHubRepoID]labelDetailsEntry
+	mu    sync.Mutex
+}
+
+// NewGitHubLabelDetailer returns a GitHubLabelDetailer that uses c.
+func NewGitHubLabelDetailer(c *githubv4.Client) *GitHubLabelDetailer {
+	return &GitHubLabelDetailer{
+		c:     c,
+		cache: make(map[maintner.GitHubRepoID]labelDetailsEntry),
+	}
+}
+
+type ghLabelsQuery struct {
+	Repository struct {
+		Labels struct {
+			Nodes []struct {
+				Name        string
+				Color       string
+				Description string
+			}
+			PageInfo struct {
+				EndCursor   githubv4.String
+				HasNextPage bool
+			}
+		} `graphql:"labels(first: 100, after: $cursor)"` // 100 per page.
+	} `graphql:"repository(owner: $repositoryOwner, name: $repositoryName)"`
+}
+
+// LabelDetails implements LabelDetailer.
+func (d *GitHubLabelDetailer) LabelDetails(ctx context.Context, rID maintner.GitHubRepoID) (map[string]LabelDetail, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if e, ok := d.cache[rID]; ok && time.Since(e.fetched) < labelDetailsTTL {
+		return e.details, nil
+	}
+
+	var q ghLabelsQuery
+	variables := map[string]interface{}{
+		"repositoryOwner": githubv4.String(rID.Owner),
+		"repositoryName":  githubv4.String(rID.Repo),
+		"cursor":          (*githubv4.String)(nil), // Null after argument to get first page.
+	}
+	details := make(map[string]LabelDetail)
+	for {
+		if err := d.c.Query(ctx, &q, variables); err != nil {
+			return nil, err
+		}
+		for _, n := range q.Repository.Labels.Nodes {
+			details[n.Name] = LabelDetail{Color: n.Color, Description: n.Description}
+		}
+		if !q.Repository.Labels.PageInfo.HasNextPage {
+			break
+		}
+		variables["cursor"] = githubv4.NewString(q.Repository.Labels.PageInfo.EndCursor)
+	}
+
+	d.cache[rID] = labelDetailsEntry{details: details, fetched: time.Now()}
+	return details, nil
+}
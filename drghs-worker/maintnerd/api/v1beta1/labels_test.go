@@ -0,0 +1,109 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	"testing"
+	"time"
+
+	drghs_v1 "github.com/GoogleCloudPlatform/devrel-services/drghs/v1"
+	"github.com/golang/protobuf/ptypes"
+	tspb "github.com/golang/protobuf/ptypes/timestamp"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/build/maintner"
+)
+
+func TestLabelCatalog(t *testing.T) {
+	day := func(n int) time.Time { return time.Date(2020, time.January, n, 0, 0, 0, 0, time.UTC) }
+	ts := func(t time.Time) *tspb.Timestamp {
+		p, _ := ptypes.TimestampProto(t)
+		return p
+	}
+
+	bug := &maintner.GitHubLabel{ID: 1, Name: "bug"}
+	p1 := &maintner.GitHubLabel{ID: 2, Name: "p1"}
+
+	c := newLabelCatalog("foo/bar", map[string]LabelDetail{
+		"bug": {Color: "d73a4a", Description: "Something isn't working"},
+	})
+	c.define("bug")
+	c.define("p1")
+	c.define("unused")
+
+	// An open issue labeled "bug" after creation.
+	c.addIssue(&maintner.GitHubIssue{
+		Created: day(1),
+		Labels:  map[int64]*maintner.GitHubLabel{1: bug},
+	}, []*maintner.GitHubIssueEvent{
+		{Type: "labeled", Label: "bug", Created: day(2)},
+	})
+	// A closed issue that had "p1" removed and was created with "bug".
+	c.addIssue(&maintner.GitHubIssue{
+		Created: day(3),
+		Closed:  true,
+		Labels:  map[int64]*maintner.GitHubLabel{1: bug},
+	}, []*maintner.GitHubIssueEvent{
+		{Type: "labeled", Label: "p1", Created: day(4)},
+		{Type: "unlabeled", Label: "p1", Created: day(5)},
+		{Type: "labeled", Label: "deleted", Created: day(5)},
+	})
+	// An open and a closed pull request.
+	c.addIssue(&maintner.GitHubIssue{
+		Created:     day(6),
+		PullRequest: true,
+		Labels:      map[int64]*maintner.GitHubLabel{2: p1},
+	}, nil)
+	c.addIssue(&maintner.GitHubIssue{
+		Created:     day(7),
+		PullRequest: true,
+		Closed:      true,
+		Labels:      map[int64]*maintner.GitHubLabel{2: p1},
+	}, nil)
+
+	want := []*drghs_v1.Label{
+		{
+			Name:             "bug",
+			Repositories:     []string{"foo/bar"},
+			Color:            "d73a4a",
+			Description:      "Something isn't working",
+			OpenIssueCount:   1,
+			ClosedIssueCount: 1,
+			FirstUsedTime:    ts(day(2)),
+			LastUsedTime:     ts(day(3)),
+		},
+		{
+			Name:                   "p1",
+			Repositories:           []string{"foo/bar"},
+			OpenPullRequestCount:   1,
+			ClosedPullRequestCount: 1,
+			FirstUsedTime:          ts(day(4)),
+			LastUsedTime:           ts(day(7)),
+		},
+		{
+			Name:         "unused",
+			Repositories: []string{"foo/bar"},
+		},
+	}
+
+	got, err := c.labels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreUnexported(tspb.Timestamp{}, drghs_v1.Label{})); diff != "" {
+		t.Errorf("labels() mismatch (-want +got):\n%s", diff)
+	}
+}
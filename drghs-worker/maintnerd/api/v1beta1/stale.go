@@ -0,0 +1,252 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	"strings"
+	"time"
+
+	drghs_v1 "github.com/GoogleCloudPlatform/devrel-services/drghs/v1"
+	"github.com/golang/protobuf/ptypes"
+
+	"golang.org/x/build/maintner"
+)
+
+const (
+	defaultInactiveDays         = 30
+	defaultAwaitingAuthorDays   = 14
+	defaultAwaitingReporterDays = 14
+)
+
+var defaultFeedbackLabels = []string{
+	"needs more info",
+	"needs-more-info",
+	"awaiting information",
+	"status: awaiting information",
+}
+
+// staleConfig holds the thresholds a ListStaleIssuesRequest asks for, with
+// the defaults applied.
+type staleConfig struct {
+	inactive         time.Duration
+	awaitingAuthor   time.Duration
+	awaitingReporter time.Duration
+	feedbackLabels   map[string]bool
+	bots             map[string]bool
+}
+
+func newStaleConfig(r *drghs_v1.ListStaleIssuesRequest) staleConfig {
+	days := func(n int32, def int) time.Duration {
+		if n <= 0 {
+			n = int32(def)
+		}
+		return time.Duration(n) * 24 * time.Hour
+	}
+	labels := r.FeedbackLabels
+	if len(labels) == 0 {
+		labels = defaultFeedbackLabels
+	}
+
+	c := staleConfig{
+		inactive:         days(r.InactiveDays, defaultInactiveDays),
+		awaitingAuthor:   days(r.AwaitingAuthorDays, defaultAwaitingAuthorDays),
+		awaitingReporter: days(r.AwaitingReporterDays, defaultAwaitingReporterDays),
+		feedbackLabels:   make(map[string]bool),
+		bots:             make(map[string]bool),
+	}
+	for _, l := range labels {
+		c.feedbackLabels[strings.ToLower(l)] = true
+	}
+	for _, b := range r.BotLogins {
+		c.bots[strings.ToLower(b)] = true
+	}
+	return c
+}
+
+func (c staleConfig) isBot(u *maintner.GitHubUser) bool {
+	if u == nil {
+		// maintner has no actor for some events, e.g. ones caused by
+		// GitHub itself.
+		return true
+	}
+	l := strings.ToLower(u.Login)
+	return strings.HasSuffix(l, "[bot]") || strings.HasSuffix(l, "-bot") || c.bots[l]
+}
+
+// issueActivity is what happened on an issue, in chronological order.
+type issueActivity struct {
+	events   []*maintner.GitHubIssueEvent
+	comments []*maintner.GitHubComment
+	reviews  []*maintner.GitHubReview
+}
+
+func getIssueActivity(issue *maintner.GitHubIssue) issueActivity {
+	a := issueActivity{}
+	issue.ForeachEvent(func(e *maintner.GitHubIssueEvent) error {
+		a.events = append(a.events, e)
+		return nil
+	})
+	issue.ForeachComment(func(co *maintner.GitHubComment) error {
+		a.comments = append(a.comments, co)
+		return nil
+	})
+	issue.ForeachReview(func(rev *maintner.GitHubReview) error {
+		a.reviews = append(a.reviews, rev)
+		return nil
+	})
+	return a
+}
+
+// respondedSince reports whether u commented, reviewed or acted on the issue
+// after t.
+func (a issueActivity) respondedSince(u *maintner.GitHubUser, t time.Time) bool {
+	if u == nil {
+		return false
+	}
+	same := func(o *maintner.GitHubUser) bool {
+		return o != nil && (o == u || (o.ID != 0 && o.ID == u.ID))
+	}
+	for _, co := range a.comments {
+		if co.Created.After(t) && same(co.User) {
+			return true
+		}
+	}
+	for _, rev := range a.reviews {
+		if rev.Created.After(t) && same(rev.Actor) {
+			return true
+		}
+	}
+	for _, e := range a.events {
+		if e.Created.After(t) && same(e.Actor) {
+			return true
+		}
+	}
+	return false
+}
+
+// staleFlags returns the reasons the open issue is stale at now.
+func staleFlags(issue *maintner.GitHubIssue, a issueActivity, c staleConfig, now time.Time) []*drghs_v1.StaleIssue_Flag {
+	if issue.NotExist || issue.Closed {
+		return nil
+	}
+
+	type flag struct {
+		reason drghs_v1.StaleIssue_Reason
+		since  time.Time
+	}
+	flags := make([]flag, 0)
+
+	if last := a.lastHumanActivity(issue, c); now.Sub(last) > c.inactive {
+		flags = append(flags, flag{drghs_v1.StaleIssue_INACTIVE, last})
+	}
+
+	if issue.PullRequest {
+		if t := a.changesRequested(); !t.IsZero() && now.Sub(t) > c.awaitingAuthor && !a.respondedSince(issue.User, t) {
+			flags = append(flags, flag{drghs_v1.StaleIssue_AWAITING_AUTHOR, t})
+		}
+	} else {
+		if t := a.feedbackRequested(issue, c); !t.IsZero() && now.Sub(t) > c.awaitingReporter && !a.respondedSince(issue.User, t) {
+			flags = append(flags, flag{drghs_v1.StaleIssue_AWAITING_REPORTER, t})
+		}
+	}
+
+	ret := make([]*drghs_v1.StaleIssue_Flag, 0, len(flags))
+	for _, f := range flags {
+		since, err := ptypes.TimestampProto(f.since)
+		if err != nil {
+			continue
+		}
+		ret = append(ret, &drghs_v1.StaleIssue_Flag{Reason: f.reason, Since: since})
+	}
+	return ret
+}
+
+// lastHumanActivity returns when the issue was created or last commented,
+// reviewed or acted on by someone other than a bot.
+func (a issueActivity) lastHumanActivity(issue *maintner.GitHubIssue, c staleConfig) time.Time {
+	last := issue.Created
+	bump := func(u *maintner.GitHubUser, t time.Time) {
+		if !c.isBot(u) && t.After(last) {
+			last = t
+		}
+	}
+	for _, co := range a.comments {
+		bump(co.User, co.Created)
+	}
+	for _, rev := range a.reviews {
+		bump(rev.Actor, rev.Created)
+	}
+	for _, e := range a.events {
+		bump(e.Actor, e.Created)
+	}
+	return last
+}
+
+// changesRequested returns when the most recent review that still requests
+// changes was submitted, or the zero time if no reviewer currently requests
+// changes. A reviewer's later approval or dismissed review lifts their
+// request; plain comments do not.
+func (a issueActivity) changesRequested() time.Time {
+	latest := make(map[int64]*maintner.GitHubReview)
+	for _, rev := range a.reviews {
+		if rev.Actor == nil {
+			continue
+		}
+		switch rev.State {
+		case "APPROVED", "CHANGES_REQUESTED", "DISMISSED":
+			latest[rev.Actor.ID] = rev
+		}
+	}
+	var t time.Time
+	for _, rev := range latest {
+		if rev.State == "CHANGES_REQUESTED" && rev.Created.After(t) {
+			t = rev.Created
+		}
+	}
+	return t
+}
+
+// feedbackRequested returns when one of the feedback labels the issue
+// carries was last applied, or the zero time if it carries none.
+func (a issueActivity) feedbackRequested(issue *maintner.GitHubIssue, c staleConfig) time.Time {
+	has := false
+	for _, l := range issue.Labels {
+		if c.feedbackLabels[strings.ToLower(l.Name)] {
+			has = true
+			break
+		}
+	}
+	if !has {
+		return time.Time{}
+	}
+
+	t := issue.Created
+	for _, e := range a.events {
+		if e.Type == "labeled" && c.feedbackLabels[strings.ToLower(e.Label)] && e.Created.After(t) {
+			t = e.Created
+		}
+	}
+	return t
+}
+
+// hasReason reports whether flags contains one for reason.
+func hasReason(flags []*drghs_v1.StaleIssue_Flag, reason drghs_v1.StaleIssue_Reason) bool {
+	for _, f := range flags {
+		if f.Reason == reason {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	"testing"
+	"time"
+
+	drghs_v1 "github.com/GoogleCloudPlatform/devrel-services/drghs/v1"
+	"github.com/golang/protobuf/ptypes"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/build/maintner"
+)
+
+func TestStaleFlags(t *testing.T) {
+	start := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	day := func(n int) time.Time { return start.Add(time.Duration(n) * 24 * time.Hour) }
+	now := day(100)
+
+	author := &maintner.GitHubUser{ID: 1, Login: "author"}
+	maintainer := &maintner.GitHubUser{ID: 2, Login: "maintainer"}
+	bot := &maintner.GitHubUser{ID: 3, Login: "renovate[bot]"}
+	custom := &maintner.GitHubUser{ID: 4, Login: "release-please"}
+
+	needsInfo := map[int64]*maintner.GitHubLabel{1: {ID: 1, Name: "Needs More Info"}}
+
+	cfg := newStaleConfig(&drghs_v1.ListStaleIssuesRequest{
+		BotLogins: []string{"Release-Please"},
+	})
+
+	type flag struct {
+		Reason drghs_v1.StaleIssue_Reason
+		Since  time.Time
+	}
+
+	tests := []struct {
+		name     string
+		issue    *maintner.GitHubIssue
+		activity issueActivity
+		want     []flag
+	}{
+		{
+			name:  "Recently created",
+			issue: &maintner.GitHubIssue{User: author, Created: day(90)},
+			want:  []flag{},
+		},
+		{
+			name:  "Closed",
+			issue: &maintner.GitHubIssue{User: author, Created: day(1), Closed: true},
+			want:  nil,
+		},
+		{
+			name:  "Only bots since creation",
+			issue: &maintner.GitHubIssue{User: author, Created: day(1)},
+			activity: issueActivity{
+				comments: []*maintner.GitHubComment{
+					{User: bot, Created: day(95)},
+					{User: custom, Created: day(96)},
+				},
+				events: []*maintner.GitHubIssueEvent{
+					{Type: "labeled", Label: "stale", Actor: bot, Created: day(97)},
+				},
+			},
+			want: []flag{{drghs_v1.StaleIssue_INACTIVE, day(1)}},
+		},
+		{
+			name:  "Human comment keeps it fresh",
+			issue: &maintner.GitHubIssue{User: author, Created: day(1)},
+			activity: issueActivity{
+				comments: []*maintner.GitHubComment{
+					{User: maintainer, Created: day(80)},
+				},
+			},
+			want: []flag{},
+		},
+		{
+			name:  "Changes requested and no reply",
+			issue: &maintner.GitHubIssue{User: author, Created: day(60), PullRequest: true},
+			activity: issueActivity{
+				reviews: []*maintner.GitHubReview{
+					{Actor: maintainer, State: "CHANGES_REQUESTED", Created: day(80)},
+					{Actor: maintainer, State: "COMMENTED", Created: day(81)},
+				},
+			},
+			want: []flag{{drghs_v1.StaleIssue_AWAITING_AUTHOR, day(80)}},
+		},
+		{
+			name:  "Changes requested and author replied",
+			issue: &maintner.GitHubIssue{User: author, Created: day(60), PullRequest: true},
+			activity: issueActivity{
+				reviews: []*maintner.GitHubReview{
+					{Actor: maintainer, State: "CHANGES_REQUESTED", Created: day(80)},
+				},
+				comments: []*maintner.GitHubComment{
+					{User: author, Created: day(82)},
+				},
+			},
+			want: []flag{},
+		},
+		{
+			name:  "Changes requested then approved",
+			issue: &maintner.GitHubIssue{User: author, Created: day(60), PullRequest: true},
+			activity: issueActivity{
+				reviews: []*maintner.GitHubReview{
+					{Actor: maintainer, State: "CHANGES_REQUESTED", Created: day(70)},
+					{Actor: maintainer, State: "APPROVED", Created: day(80)},
+				},
+			},
+			want: []flag{},
+		},
+		{
+			name:  "Waiting on the reporter",
+			issue: &maintner.GitHubIssue{User: author, Created: day(60), Labels: needsInfo},
+			activity: issueActivity{
+				events: []*maintner.GitHubIssueEvent{
+					{Type: "labeled", Label: "needs more info", Actor: maintainer, Created: day(80)},
+				},
+			},
+			want: []flag{{drghs_v1.StaleIssue_AWAITING_REPORTER, day(80)}},
+		},
+		{
+			name:  "Reporter answered",
+			issue: &maintner.GitHubIssue{User: author, Created: day(60), Labels: needsInfo},
+			activity: issueActivity{
+				events: []*maintner.GitHubIssueEvent{
+					{Type: "labeled", Label: "needs more info", Actor: maintainer, Created: day(80)},
+				},
+				comments: []*maintner.GitHubComment{
+					{User: author, Created: day(81)},
+				},
+			},
+			want: []flag{},
+		},
+		{
+			name:  "Abandoned while waiting on the reporter",
+			issue: &maintner.GitHubIssue{User: author, Created: day(10), Labels: needsInfo},
+			activity: issueActivity{
+				events: []*maintner.GitHubIssueEvent{
+					{Type: "labeled", Label: "needs more info", Actor: maintainer, Created: day(20)},
+				},
+			},
+			want: []flag{
+				{drghs_v1.StaleIssue_INACTIVE, day(20)},
+				{drghs_v1.StaleIssue_AWAITING_REPORTER, day(20)},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		var got []flag
+		if flags := staleFlags(test.issue, test.activity, cfg, now); flags != nil {
+			got = make([]flag, 0)
+			for _, f := range flags {
+				since, err := ptypes.Timestamp(f.Since)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, flag{f.Reason, since})
+			}
+		}
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("%v: staleFlags() mismatch (-want +got):\n%s", test.name, diff)
+		}
+	}
+}
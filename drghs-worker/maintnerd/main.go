@@ -21,8 +21,11 @@ import (
 	"log"
 	"net"
 	"os"
+	"path"
 	"path/filepath"
+	"runtime/debug"
 	"strings"
+	"sync"
 	"time"
 
 	maintner_internal "github.com/GoogleCloudPlatform/devrel-services/drghs-worker/internal"
@@ -31,10 +34,16 @@ import (
 	"github.com/GoogleCloudPlatform/devrel-services/drghs-worker/maintnerd/api/internalapi"
 	"github.com/GoogleCloudPlatform/devrel-services/drghs-worker/maintnerd/api/v1beta1"
 	"github.com/GoogleCloudPlatform/devrel-services/drghs-worker/pkg/googlers"
+	"github.com/GoogleCloudPlatform/devrel-services/drghs-worker/pkg/refetch"
+	"github.com/GoogleCloudPlatform/devrel-services/drghs-worker/pkg/retention"
+	"github.com/GoogleCloudPlatform/devrel-services/drghs-worker/pkg/transfers"
 
 	"cloud.google.com/go/errorreporting"
+	"github.com/google/go-github/github"
+	"github.com/shurcooL/githubv4"
 	"golang.org/x/build/maintner"
 	"golang.org/x/build/maintner/maintnerd/gcslog"
+	"golang.org/x/oauth2"
 	"golang.org/x/sync/errgroup"
 	"golang.org/x/time/rate"
 	"google.golang.org/grpc"
@@ -58,16 +67,26 @@ var (
 	sloAddress = flag.String("sloServer", "0.0.0:3009", "address of slo service")
 	verbose    = flag.Bool("verbose", false, "enable verbose debug output")
 	bucket     = flag.String("bucket", "cdpe-maintner", "Google Cloud Storage bucket to use for log storage")
-	token      = flag.String("token", "", "Token to Access GitHub with")
+	token      = flag.String("token", "", "Token to Access GitHub with. Defaults to the GITHUB_TOKEN environment variable, which unlike flags other users cannot read")
 	projectID  = flag.String("gcp-project", "", "The GCP Project this is using")
 	owner      = flag.String("owner", "", "The owner of the GitHub repository")
 	repo       = flag.String("repo", "", "The repository to track")
+
+	retainClosedDays = flag.Int("retain-closed-days", 0, "Keep the text of issues closed for more than this many days on disk instead of in memory. 0 keeps everything in memory")
+	retentionDir     = flag.String("retention-dir", filepath.Join("/tmp", "maintnr-retention"), "Directory the text of issues evicted from memory is kept in, under a subdirectory maintnerd owns")
 )
 
 var (
 	corpus          = &maintner.Corpus{}
 	googlerResolver googlers.Resolver
 	errorClient     *errorreporting.Client
+	retentionStore  *retention.Store
+	retentionPolicy retention.Policy
+	transferStore   *transfers.Store
+
+	// corpusMu keeps the gRPC servers from reading the corpus while
+	// applyRetention clears text from it.
+	corpusMu sync.RWMutex
 )
 
 func main() {
@@ -94,7 +113,10 @@ func main() {
 	defer errorClient.Close()
 
 	if *token == "" {
-		err := fmt.Errorf("must provide --token")
+		*token = os.Getenv("GITHUB_TOKEN")
+	}
+	if *token == "" {
+		err := fmt.Errorf("must provide --token or GITHUB_TOKEN")
 		logAndPrintError(err)
 		log.Fatal(err)
 	}
@@ -155,6 +177,25 @@ func main() {
 		log.Fatal(err)
 	}
 
+	// Transfers are kept next to the mutation log, which gcslog ignores.
+	bkt := strings.SplitN(*bucket, "/", 2)
+	object := fmt.Sprintf("%v/%v/transfers.json", *owner, *repo)
+	if len(bkt) == 2 {
+		object = path.Join(bkt[1], object)
+	}
+	ts, err := transfers.NewGCSStorage(ctx, bkt[0], object)
+	if err != nil {
+		err := fmt.Errorf("transfers.NewGCSStorage: %v", err)
+		logAndPrintError(err)
+		log.Fatal(err)
+	}
+	transferStore, err = transfers.Open(ctx, ts)
+	if err != nil {
+		err := fmt.Errorf("transfers.Open: %v", err)
+		logAndPrintError(err)
+		log.Fatal(err)
+	}
+
 	dataDir := filepath.Join("/tmp", "maintnr")
 	log.Printf("dataDir: %v", dataDir)
 	if err := os.MkdirAll(dataDir, 0755); err != nil {
@@ -164,7 +205,9 @@ func main() {
 
 	corpus.EnableLeaderMode(gl, dataDir)
 
-	if err := corpus.Initialize(ctx, gl); err != nil {
+	// Refetched issues reach the corpus through src, after the log.
+	src := refetch.NewSource(gl)
+	if err := corpus.Initialize(ctx, src); err != nil {
 		err := fmt.Errorf("Initalize: %v", err)
 		logAndPrintError(err)
 		log.Fatal(err)
@@ -173,6 +216,26 @@ func main() {
 	tkn := strings.TrimSpace(*token)
 	corpus.TrackGitHub(*owner, *repo, tkn)
 
+	refetcher := refetch.New(corpus, src, gl, github.NewClient(oauth2.NewClient(ctx, oauth2.StaticTokenSource(
+		&oauth2.Token{AccessToken: tkn},
+	))))
+
+	if *retainClosedDays < 0 {
+		err := fmt.Errorf("--retain-closed-days must not be negative")
+		logAndPrintError(err)
+		log.Fatal(err)
+	}
+	if *retainClosedDays > 0 {
+		retentionPolicy = retention.Policy{ClosedFor: time.Duration(*retainClosedDays) * 24 * time.Hour}
+		retentionStore, err = retention.NewStore(*retentionDir)
+		if err != nil {
+			err := fmt.Errorf("retention.NewStore: %v", err)
+			logAndPrintError(err)
+			log.Fatal(err)
+		}
+		applyRetention()
+	}
+
 	googlerResolver = googlers.NewStatic()
 
 	group, ctx := errgroup.WithContext(context.Background())
@@ -185,13 +248,13 @@ func main() {
 			ticker := time.NewTicker(10 * time.Minute)
 			for t := range ticker.C {
 				log.Printf("Corpus.SyncLoop at %v", t)
-				// Lock it for writes
-				// Sync
+				// Sync writes under the corpus's lock, which the gRPC
+				// servers hold for reads
 				if err := corpus.Sync(ctx); err != nil {
 					logAndPrintError(err)
 					log.Printf("Error during corpus sync %v", err)
 				}
-				// Unlock
+				applyRetention()
 			}
 			return nil
 		})
@@ -202,13 +265,19 @@ func main() {
 			grpc.UnaryInterceptor(
 				grpc_middleware.ChainUnaryServer(
 					grpctrace.UnaryServerInterceptor(global.Tracer("maintnerd")),
-					unaryInterceptorLog),
+					unaryInterceptorLog,
+					unaryInterceptorReadCorpus),
 			),
 			grpc.KeepaliveParams(keepalive.ServerParameters{
 				MaxConnectionIdle: 5 * time.Minute,
 			}),
 		)
-		s := v1beta1.NewIssueServiceV1(corpus, googlerResolver)
+		// The corpus does not track the colors and descriptions of labels,
+		// so ListLabels asks GitHub for them.
+		gqlc := githubv4.NewClient(oauth2.NewClient(ctx, oauth2.StaticTokenSource(
+			&oauth2.Token{AccessToken: tkn},
+		)))
+		s := v1beta1.NewIssueServiceV1(corpus, googlerResolver, retentionStore, transferStore, v1beta1.NewGitHubLabelDetailer(gqlc))
 		drghs_v1.RegisterIssueServiceServer(grpcServer, s)
 		healthpb.RegisterHealthServer(grpcServer, s)
 
@@ -223,8 +292,8 @@ func main() {
 
 	group.Go(func() error {
 		// Add gRPC service for internal
-		grpcServer := grpc.NewServer()
-		s := internalapi.NewTransferProxyServer(corpus)
+		grpcServer := grpc.NewServer(grpc.UnaryInterceptor(unaryInterceptorRetention))
+		s := internalapi.NewTransferProxyServer(corpus, transferStore, refetcher)
 		maintner_internal.RegisterInternalIssueServiceServer(grpcServer, s)
 
 		lis, err := net.Listen("tcp", *intListen)
@@ -336,3 +405,45 @@ func buildRetryInterceptor() grpc.UnaryClientInterceptor {
 	}
 	return grpc_retry.UnaryClientInterceptor(opts...)
 }
+
+// applyRetention evicts the text of the issues selected by the retention
+// policy from the corpus and hands the freed memory back to the OS.
+// unaryInterceptorReadCorpus holds the corpus's read lock, and corpusMu, for
+// each call, so neither Sync nor applyRetention changes the corpus while it
+// is read.
+func unaryInterceptorReadCorpus(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	corpusMu.RLock()
+	defer corpusMu.RUnlock()
+	corpus.RLock()
+	defer corpus.RUnlock()
+	return handler(ctx, req)
+}
+
+// unaryInterceptorRetention holds corpusMu for each call. Refetches update
+// the corpus, which takes its write lock, so they may not hold its read
+// lock.
+func unaryInterceptorRetention(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	corpusMu.RLock()
+	defer corpusMu.RUnlock()
+	return handler(ctx, req)
+}
+
+// applyRetention evicts the text the retention policy selects. It must not
+// run alongside Sync, which is left to its caller.
+func applyRetention() {
+	if retentionStore == nil {
+		return
+	}
+	corpusMu.Lock()
+	n, err := retentionStore.Apply(corpus, retentionPolicy, time.Now())
+	corpusMu.Unlock()
+	if err != nil {
+		logAndPrintError(err)
+		log.Printf("Error applying retention policy %v", err)
+		return
+	}
+	if n > 0 {
+		debug.FreeOSMemory()
+	}
+	log.Printf("Evicted the text of %v issues, %v evicted in total", n, retentionStore.Len())
+}
@@ -0,0 +1,107 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/devrel-services/drghs-worker/pkg/retention"
+	"github.com/golang/protobuf/ptypes"
+	"golang.org/x/build/maintner"
+	"golang.org/x/build/maintner/maintpb"
+	"google.golang.org/grpc"
+)
+
+type mutationSource []*maintpb.Mutation
+
+func (s mutationSource) GetMutations(ctx context.Context) <-chan maintner.MutationStreamEvent {
+	ch := make(chan maintner.MutationStreamEvent, len(s)+1)
+	for _, m := range s {
+		ch <- maintner.MutationStreamEvent{Mutation: m}
+	}
+	ch <- maintner.MutationStreamEvent{End: true}
+	return ch
+}
+
+// TestRetentionWhileReading evicts text while gRPC calls read it. Run with
+// -race to catch unguarded access.
+func TestRetentionWhileReading(t *testing.T) {
+	dir, err := ioutil.TempDir("", "maintnerd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	created, _ := ptypes.TimestampProto(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	closed, _ := ptypes.TimestampProto(time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC))
+	corpus = &maintner.Corpus{}
+	if err := corpus.Initialize(ctx, mutationSource{{
+		GithubIssue: &maintpb.GithubIssueMutation{
+			Owner:      "foo",
+			Repo:       "bar",
+			Number:     1,
+			Id:         101,
+			Created:    created,
+			BodyChange: &maintpb.StringChange{Val: "body"},
+			Closed:     &maintpb.BoolChange{Val: true},
+			ClosedAt:   closed,
+		},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	issue := corpus.GitHub().Repo("foo", "bar").Issue(1)
+
+	retentionPolicy = retention.Policy{ClosedFor: time.Hour}
+	retentionStore, err = retention.NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { retentionStore = nil }()
+
+	read := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return issue.Body, nil
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				unaryInterceptorReadCorpus(ctx, nil, &grpc.UnaryServerInfo{}, read)
+			}
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		// Stand in for a refetch putting the text back
+		corpusMu.Lock()
+		issue.Body = "body"
+		corpusMu.Unlock()
+		applyRetention()
+	}
+	wg.Wait()
+
+	if issue.Body != "" {
+		t.Errorf("applyRetention() left the body in memory: %q", issue.Body)
+	}
+	if retentionStore.Len() != 1 {
+		t.Errorf("retentionStore.Len() Wanted 1, Got %v", retentionStore.Len())
+	}
+}
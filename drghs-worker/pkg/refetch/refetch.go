@@ -0,0 +1,239 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package refetch replaces what a maintner corpus has of issues with what
+// GitHub has of them now.
+//
+// maintner only asks GitHub for the issues updated since its last sync, so
+// an issue whose mutation was lost stays missing or stale until it changes
+// again. A corpus takes mutations only from its syncs and from the
+// MutationSource it was initialized with, so a Source wraps that
+// MutationSource and hands the corpus the mutations of refetched issues
+// when it is updated.
+package refetch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/google/go-github/github"
+	"golang.org/x/build/maintner"
+	"golang.org/x/build/maintner/maintpb"
+)
+
+// Source is a maintner.MutationSource that reads its base MutationSource
+// once, when the corpus is initialized, and then the mutations of
+// refetched issues.
+type Source struct {
+	base maintner.MutationSource
+
+	mu      sync.Mutex
+	started bool
+	pending []*maintpb.Mutation
+}
+
+var _ maintner.MutationSource = &Source{}
+
+// NewSource returns a Source reading base.
+func NewSource(base maintner.MutationSource) *Source {
+	return &Source{base: base}
+}
+
+// GetMutations implements maintner.MutationSource.
+func (s *Source) GetMutations(ctx context.Context) <-chan maintner.MutationStreamEvent {
+	s.mu.Lock()
+	if !s.started {
+		s.started = true
+		s.mu.Unlock()
+		return s.base.GetMutations(ctx)
+	}
+	pending := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+
+	ch := make(chan maintner.MutationStreamEvent)
+	go func() {
+		for _, m := range pending {
+			select {
+			case ch <- maintner.MutationStreamEvent{Mutation: m}:
+			case <-ctx.Done():
+				return
+			}
+		}
+		select {
+		case ch <- maintner.MutationStreamEvent{End: true}:
+		case <-ctx.Done():
+		}
+	}()
+	return ch
+}
+
+// add queues ms for the next call to GetMutations.
+func (s *Source) add(ms ...*maintpb.Mutation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending = append(s.pending, ms...)
+}
+
+// Refetcher refetches the issues of a corpus from GitHub. It is safe for
+// concurrent use.
+type Refetcher struct {
+	c      *maintner.Corpus
+	src    *Source
+	logger maintner.MutationLogger
+	gh     *github.Client
+
+	// mu serializes the updates of the corpus.
+	mu sync.Mutex
+}
+
+// New returns a Refetcher of the issues of c, which must have been
+// initialized with src. The mutations of refetched issues are logged to
+// logger, if it is not nil, so they survive a restart.
+func New(c *maintner.Corpus, src *Source, logger maintner.MutationLogger, gh *github.Client) *Refetcher {
+	return &Refetcher{
+		c:      c,
+		src:    src,
+		logger: logger,
+		gh:     gh,
+	}
+}
+
+// Refetch fetches the issues of rID with the given numbers from GitHub and
+// replaces what the corpus has of them, adding those it lacks. Issues GitHub
+// no longer has are skipped. It returns the number of issues refetched.
+func (r *Refetcher) Refetch(ctx context.Context, rID maintner.GitHubRepoID, numbers []int32) (int32, error) {
+	repo := r.c.GitHub().Repo(rID.Owner, rID.Repo)
+	if repo == nil {
+		return 0, fmt.Errorf("refetch: repository %v is not tracked", rID)
+	}
+
+	ms := make([]*maintpb.Mutation, 0, len(numbers))
+	for _, n := range numbers {
+		gi, resp, err := r.gh.Issues.Get(ctx, rID.Owner, rID.Repo, int(n))
+		if resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone) {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("refetch: getting issue %v: %v", n, err)
+		}
+		if !inRepo(gi, rID) {
+			// GitHub redirects to the new location of transferred issues.
+			continue
+		}
+		ms = append(ms, &maintpb.Mutation{GithubIssue: issueMutation(rID, repo.Issue(n), gi)})
+	}
+	if len(ms) == 0 {
+		return 0, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.logger != nil {
+		for _, m := range ms {
+			if err := r.logger.Log(m); err != nil {
+				return 0, fmt.Errorf("refetch: logging mutation: %v", err)
+			}
+		}
+	}
+	r.src.add(ms...)
+	if err := r.c.Update(ctx); err != nil {
+		return 0, fmt.Errorf("refetch: updating corpus: %v", err)
+	}
+	return int32(len(ms)), nil
+}
+
+// inRepo reports whether gi is an issue of rID.
+func inRepo(gi *github.Issue, rID maintner.GitHubRepoID) bool {
+	if gi.RepositoryURL == nil {
+		return true
+	}
+	return strings.HasSuffix(strings.ToLower(gi.GetRepositoryURL()), strings.ToLower("/repos/"+rID.Owner+"/"+rID.Repo))
+}
+
+// issueMutation returns a mutation setting every field of existing, which
+// may be nil, tracked by the corpus to those of gi.
+func issueMutation(rID maintner.GitHubRepoID, existing *maintner.GitHubIssue, gi *github.Issue) *maintpb.GithubIssueMutation {
+	m := &maintpb.GithubIssueMutation{
+		Owner:       rID.Owner,
+		Repo:        rID.Repo,
+		Number:      int32(gi.GetNumber()),
+		Id:          gi.GetID(),
+		PullRequest: gi.IsPullRequest(),
+		Created:     timestampProto(gi.GetCreatedAt()),
+		Title:       gi.GetTitle(),
+		BodyChange:  &maintpb.StringChange{Val: gi.GetBody()},
+		Closed:      &maintpb.BoolChange{Val: gi.GetState() == "closed"},
+		Locked:      &maintpb.BoolChange{Val: gi.GetLocked()},
+		User:        userProto(gi.User),
+		ClosedBy:    userProto(gi.ClosedBy),
+	}
+	if gi.UpdatedAt != nil {
+		m.Updated = timestampProto(gi.GetUpdatedAt())
+	}
+	if gi.ClosedAt != nil {
+		m.ClosedAt = timestampProto(gi.GetClosedAt())
+	}
+
+	if ms := gi.Milestone; ms != nil {
+		m.MilestoneId = ms.GetID()
+		m.MilestoneNum = int64(ms.GetNumber())
+		m.MilestoneTitle = ms.GetTitle()
+	} else {
+		m.NoMilestone = true
+	}
+
+	assignees := make(map[int64]bool, len(gi.Assignees))
+	for _, u := range gi.Assignees {
+		assignees[u.GetID()] = true
+		m.Assignees = append(m.Assignees, userProto(u))
+	}
+	labels := make(map[int64]bool, len(gi.Labels))
+	for _, l := range gi.Labels {
+		labels[l.GetID()] = true
+		m.AddLabel = append(m.AddLabel, &maintpb.GithubLabel{Id: l.GetID(), Name: l.GetName()})
+	}
+	if existing != nil {
+		for _, u := range existing.Assignees {
+			if !assignees[u.ID] {
+				m.DeletedAssignees = append(m.DeletedAssignees, u.ID)
+			}
+		}
+		for id := range existing.Labels {
+			if !labels[id] {
+				m.RemoveLabel = append(m.RemoveLabel, id)
+			}
+		}
+	}
+	return m
+}
+
+func userProto(u *github.User) *maintpb.GithubUser {
+	if u == nil {
+		return nil
+	}
+	return &maintpb.GithubUser{Id: u.GetID(), Login: u.GetLogin()}
+}
+
+func timestampProto(t time.Time) *timestamp.Timestamp {
+	// Every time GitHub returns is in the range of a Timestamp.
+	ts, _ := ptypes.TimestampProto(t)
+	return ts
+}
@@ -0,0 +1,119 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package refetch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/go-github/github"
+	"golang.org/x/build/maintner"
+	"golang.org/x/build/maintner/maintpb"
+)
+
+// logSource is a maintner.MutationSource and maintner.MutationLogger of the
+// mutations in it.
+type logSource struct {
+	muts   []*maintpb.Mutation
+	logged []*maintpb.Mutation
+}
+
+func (l *logSource) GetMutations(ctx context.Context) <-chan maintner.MutationStreamEvent {
+	ch := make(chan maintner.MutationStreamEvent, len(l.muts)+1)
+	for _, m := range l.muts {
+		ch <- maintner.MutationStreamEvent{Mutation: m}
+	}
+	ch <- maintner.MutationStreamEvent{End: true}
+	return ch
+}
+
+func (l *logSource) Log(m *maintpb.Mutation) error {
+	l.logged = append(l.logged, m)
+	return nil
+}
+
+func TestRefetch(t *testing.T) {
+	ctx := context.Background()
+	created, _ := ptypes.TimestampProto(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	ls := &logSource{muts: []*maintpb.Mutation{{
+		GithubIssue: &maintpb.GithubIssueMutation{
+			Owner:    "foo",
+			Repo:     "bar",
+			Number:   1,
+			Id:       101,
+			Created:  created,
+			Title:    "Stale",
+			AddLabel: []*maintpb.GithubLabel{{Id: 11, Name: "gone"}},
+		},
+	}}}
+	src := NewSource(ls)
+	corpus := &maintner.Corpus{}
+	if err := corpus.Initialize(ctx, src); err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/foo/bar/issues/1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"number": 1, "id": 101, "title": "Fresh", "state": "closed", "created_at": "2020-01-01T00:00:00Z", "labels": [{"id": 12, "name": "kept"}]}`)
+	})
+	mux.HandleFunc("/repos/foo/bar/issues/2", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"number": 2, "id": 102, "title": "Missing", "state": "open", "created_at": "2020-01-02T00:00:00Z", "repository_url": "https://api.github.com/repos/Foo/Bar"}`)
+	})
+	mux.HandleFunc("/repos/foo/bar/issues/3", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("/repos/foo/bar/issues/4", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"number": 9, "id": 104, "title": "Transferred", "state": "open", "created_at": "2020-01-02T00:00:00Z", "repository_url": "https://api.github.com/repos/foo/baz"}`)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	gh := github.NewClient(ts.Client())
+	gh.BaseURL, _ = url.Parse(ts.URL + "/")
+
+	r := New(corpus, src, ls, gh)
+	rID := maintner.GitHubRepoID{Owner: "foo", Repo: "bar"}
+	n, err := r.Refetch(ctx, rID, []int32{1, 2, 3, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("Refetch() count. Wanted 2, Got %v", n)
+	}
+	if len(ls.logged) != 2 {
+		t.Errorf("Refetch() logged mutations. Wanted 2, Got %v", len(ls.logged))
+	}
+
+	repo := corpus.GitHub().Repo("foo", "bar")
+	iss := repo.Issue(1)
+	if iss.Title != "Fresh" || !iss.Closed || iss.HasLabel("gone") || !iss.HasLabel("kept") {
+		t.Errorf("Refetch() stale issue. Got title %q, closed %v, labels %v", iss.Title, iss.Closed, iss.Labels)
+	}
+	if iss := repo.Issue(2); iss == nil || iss.Title != "Missing" {
+		t.Errorf("Refetch() missing issue. Wanted title \"Missing\", Got %v", iss)
+	}
+	if iss := repo.Issue(4); iss != nil {
+		t.Errorf("Refetch() transferred issue. Wanted nil, Got %v", iss)
+	}
+
+	if _, err := r.Refetch(ctx, maintner.GitHubRepoID{Owner: "foo", Repo: "qux"}, []int32{1}); err == nil {
+		t.Errorf("Refetch() of an untracked repository. Wanted an error, Got nil")
+	}
+}
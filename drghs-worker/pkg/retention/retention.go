@@ -0,0 +1,270 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package retention bounds the memory held by a maintner corpus by moving
+// the text of long closed issues out of memory and onto disk.
+//
+// maintner keeps every issue body, comment and review of a repository in
+// memory for the life of the process. For large repositories the bulk of
+// that is text belonging to issues that were closed long ago and are rarely
+// read. A Store evicts that text: it writes it to a file per issue and
+// clears it from the corpus, keeping everything else (state, labels,
+// timestamps, events) in memory so filtering and SLO evaluation still work.
+// The text is read back from disk when an evicted issue is served.
+//
+// Eviction does not lower the peak reached while the corpus replays its
+// mutation log at startup; it lowers the steady state once Apply has run.
+// If maintner re-fetches an evicted issue, its text is in memory again until
+// the next Apply.
+package retention
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/build/maintner"
+)
+
+// Policy decides which issues have their text evicted.
+type Policy struct {
+	// ClosedFor is how long an issue must have been closed before its text
+	// is evicted. Zero disables eviction.
+	ClosedFor time.Duration
+}
+
+// Evictable reports whether the text of issue should be evicted at now.
+func (p Policy) Evictable(issue *maintner.GitHubIssue, now time.Time) bool {
+	if p.ClosedFor <= 0 || issue.NotExist || !issue.Closed || issue.ClosedAt.IsZero() {
+		return false
+	}
+	return now.Sub(issue.ClosedAt) > p.ClosedFor
+}
+
+// Text is the text of an issue kept on disk once evicted. Comments and
+// Reviews are keyed by their GitHub IDs.
+type Text struct {
+	Body     string           `json:"body,omitempty"`
+	Comments map[int64]string `json:"comments,omitempty"`
+	Reviews  map[int64]string `json:"reviews,omitempty"`
+}
+
+// storeDir is the subdirectory of the directory given to NewStore that a
+// Store keeps its files in.
+const storeDir = "evicted-text"
+
+// Store is an on-disk index of the text evicted from a corpus.
+// It is safe for concurrent use.
+type Store struct {
+	dir     string
+	mu      sync.RWMutex
+	evicted map[maintner.GitHubRepoID]map[int32]bool
+}
+
+// NewStore returns a Store that keeps evicted text in a subdirectory of
+// dir, which it owns. Anything already in that subdirectory is removed: the
+// corpus is rebuilt with its full text on every start, so text evicted by a
+// previous process is never needed. The rest of dir is left alone.
+func NewStore(dir string) (*Store, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("retention: no directory given")
+	}
+	dir = filepath.Join(dir, storeDir)
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Store{
+		dir:     dir,
+		evicted: make(map[maintner.GitHubRepoID]map[int32]bool),
+	}, nil
+}
+
+// Apply evicts the text of every issue in c selected by p and returns the
+// number of issues whose text was evicted. Issues evicted earlier are only
+// rewritten if maintner has since put text back into memory.
+//
+// Apply writes to c, so nothing may read or write c until it returns.
+func (s *Store) Apply(c *maintner.Corpus, p Policy, now time.Time) (int, error) {
+	if p.ClosedFor <= 0 {
+		return 0, nil
+	}
+	n := 0
+	err := c.GitHub().ForeachRepo(func(repo *maintner.GitHubRepo) error {
+		rID := repo.ID()
+		return repo.ForeachIssue(func(issue *maintner.GitHubIssue) error {
+			if !p.Evictable(issue, now) {
+				return nil
+			}
+			ok, err := s.Evict(rID, issue)
+			if err != nil {
+				return err
+			}
+			if ok {
+				n++
+			}
+			return nil
+		})
+	})
+	return n, err
+}
+
+// Evict writes the text of issue to disk and clears it from memory. It
+// reports whether there was any text to evict. Like Apply, it writes to
+// the corpus issue belongs to.
+func (s *Store) Evict(rID maintner.GitHubRepoID, issue *maintner.GitHubIssue) (bool, error) {
+	txt := &Text{
+		Body:     issue.Body,
+		Comments: make(map[int64]string),
+		Reviews:  make(map[int64]string),
+	}
+	issue.ForeachComment(func(co *maintner.GitHubComment) error {
+		if co.Body != "" {
+			txt.Comments[co.ID] = co.Body
+		}
+		return nil
+	})
+	issue.ForeachReview(func(rev *maintner.GitHubReview) error {
+		if rev.Body != "" {
+			txt.Reviews[rev.ID] = rev.Body
+		}
+		return nil
+	})
+	if txt.Body == "" && len(txt.Comments) == 0 && len(txt.Reviews) == 0 {
+		return false, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.evicted[rID][issue.Number] {
+		// Text still in memory was re-fetched and is newer than what is on
+		// disk; keep whatever was evicted before for everything else.
+		old, err := s.read(rID, issue.Number)
+		if err != nil {
+			return false, err
+		}
+		txt = merge(old, txt)
+	}
+
+	if err := s.write(rID, issue.Number, txt); err != nil {
+		return false, err
+	}
+	if s.evicted[rID] == nil {
+		s.evicted[rID] = make(map[int32]bool)
+	}
+	s.evicted[rID][issue.Number] = true
+
+	issue.Body = ""
+	issue.ForeachComment(func(co *maintner.GitHubComment) error {
+		co.Body = ""
+		return nil
+	})
+	issue.ForeachReview(func(rev *maintner.GitHubReview) error {
+		rev.Body = ""
+		return nil
+	})
+	return true, nil
+}
+
+// Text returns the evicted text of issue number of rID, or nil if none of
+// its text has been evicted.
+func (s *Store) Text(rID maintner.GitHubRepoID, number int32) (*Text, error) {
+	if s == nil {
+		return nil, nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.evicted[rID][number] {
+		return nil, nil
+	}
+	return s.read(rID, number)
+}
+
+// Len returns the number of issues whose text has been evicted.
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	n := 0
+	for _, m := range s.evicted {
+		n += len(m)
+	}
+	return n
+}
+
+func (s *Store) path(rID maintner.GitHubRepoID, number int32) string {
+	return filepath.Join(s.dir, rID.Owner, rID.Repo, fmt.Sprintf("%v.json", number))
+}
+
+func (s *Store) read(rID maintner.GitHubRepoID, number int32) (*Text, error) {
+	b, err := ioutil.ReadFile(s.path(rID, number))
+	if err != nil {
+		return nil, err
+	}
+	txt := &Text{}
+	if err := json.Unmarshal(b, txt); err != nil {
+		return nil, err
+	}
+	return txt, nil
+}
+
+func (s *Store) write(rID maintner.GitHubRepoID, number int32, txt *Text) error {
+	p := s.path(rID, number)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	b, err := json.Marshal(txt)
+	if err != nil {
+		return err
+	}
+	// Write then rename so a concurrent read never sees a partial file.
+	tmp := p + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p)
+}
+
+// merge returns old overlaid with the non-empty text of cur.
+func merge(old, cur *Text) *Text {
+	ret := &Text{
+		Body:     old.Body,
+		Comments: make(map[int64]string),
+		Reviews:  make(map[int64]string),
+	}
+	if cur.Body != "" {
+		ret.Body = cur.Body
+	}
+	for id, b := range old.Comments {
+		ret.Comments[id] = b
+	}
+	for id, b := range cur.Comments {
+		ret.Comments[id] = b
+	}
+	for id, b := range old.Reviews {
+		ret.Reviews[id] = b
+	}
+	for id, b := range cur.Reviews {
+		ret.Reviews[id] = b
+	}
+	return ret
+}
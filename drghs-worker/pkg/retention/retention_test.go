@@ -0,0 +1,190 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/build/maintner"
+)
+
+func TestEvictable(t *testing.T) {
+	now := time.Date(2020, time.June, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	p := Policy{ClosedFor: 30 * day}
+
+	tests := []struct {
+		name   string
+		policy Policy
+		issue  *maintner.GitHubIssue
+		want   bool
+	}{
+		{
+			name:   "Open",
+			policy: p,
+			issue:  &maintner.GitHubIssue{},
+			want:   false,
+		},
+		{
+			name:   "Recently closed",
+			policy: p,
+			issue:  &maintner.GitHubIssue{Closed: true, ClosedAt: now.Add(-29 * day)},
+			want:   false,
+		},
+		{
+			name:   "Closed long ago",
+			policy: p,
+			issue:  &maintner.GitHubIssue{Closed: true, ClosedAt: now.Add(-31 * day)},
+			want:   true,
+		},
+		{
+			name:   "Closed without a date",
+			policy: p,
+			issue:  &maintner.GitHubIssue{Closed: true},
+			want:   false,
+		},
+		{
+			name:   "Disabled",
+			policy: Policy{},
+			issue:  &maintner.GitHubIssue{Closed: true, ClosedAt: now.Add(-365 * day)},
+			want:   false,
+		},
+	}
+
+	for _, test := range tests {
+		if got := test.policy.Evictable(test.issue, now); got != test.want {
+			t.Errorf("%v: Evictable() Wanted %v, Got %v", test.name, test.want, got)
+		}
+	}
+}
+
+func TestEvictAndText(t *testing.T) {
+	dir, err := ioutil.TempDir("", "retention")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rID := maintner.GitHubRepoID{Owner: "foo", Repo: "bar"}
+	issue := &maintner.GitHubIssue{Number: 7, Body: "first body"}
+
+	if got, err := s.Text(rID, 7); err != nil || got != nil {
+		t.Errorf("Text() before eviction. Wanted nil, nil, Got %v, %v", got, err)
+	}
+
+	ok, err := s.Evict(rID, issue)
+	if err != nil || !ok {
+		t.Fatalf("Evict() Wanted true, nil, Got %v, %v", ok, err)
+	}
+	if issue.Body != "" {
+		t.Errorf("Evict() left the body in memory: %q", issue.Body)
+	}
+
+	want := &Text{Body: "first body"}
+	got, err := s.Text(rID, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Text() mismatch (-want +got):\n%s", diff)
+	}
+
+	// Nothing left in memory: nothing to evict.
+	if ok, err := s.Evict(rID, issue); err != nil || ok {
+		t.Errorf("Evict() of an evicted issue. Wanted false, nil, Got %v, %v", ok, err)
+	}
+
+	// A re-fetched body replaces the one on disk.
+	issue.Body = "second body"
+	if _, err := s.Evict(rID, issue); err != nil {
+		t.Fatal(err)
+	}
+	got, err = s.Text(rID, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Body != "second body" {
+		t.Errorf("Text() after re-eviction. Wanted %q, Got %q", "second body", got.Body)
+	}
+	if s.Len() != 1 {
+		t.Errorf("Len() Wanted 1, Got %v", s.Len())
+	}
+}
+
+func TestNewStoreKeepsOtherFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "retention")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	other := filepath.Join(dir, "other.txt")
+	if err := ioutil.WriteFile(other, []byte("keep me"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rID := maintner.GitHubRepoID{Owner: "foo", Repo: "bar"}
+	s, err := NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Evict(rID, &maintner.GitHubIssue{Number: 7, Body: "body"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new Store starts empty but only clears what it owns.
+	s, err = NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.Text(rID, 7); err != nil || got != nil {
+		t.Errorf("Text() from a new Store. Wanted nil, nil, Got %v, %v", got, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, storeDir, "foo", "bar", "7.json")); !os.IsNotExist(err) {
+		t.Errorf("NewStore() kept text evicted by an earlier Store: %v", err)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("NewStore() removed a file it does not own: %v", err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	old := &Text{
+		Body:     "old",
+		Comments: map[int64]string{1: "a", 2: "b"},
+		Reviews:  map[int64]string{3: "c"},
+	}
+	cur := &Text{
+		Comments: map[int64]string{2: "B", 4: "d"},
+	}
+	want := &Text{
+		Body:     "old",
+		Comments: map[int64]string{1: "a", 2: "B", 4: "d"},
+		Reviews:  map[int64]string{3: "c"},
+	}
+	if diff := cmp.Diff(want, merge(old, cur)); diff != "" {
+		t.Errorf("merge() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokens
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sync"
+)
+
+// Assigner assigns each repository one of a set of tokens by weighted
+// rendezvous hashing. A repository keeps its token as long as the tokens
+// and their weights do not change, whichever replica asks, and adding or
+// removing a token only moves the repositories assigned to it.
+type Assigner struct {
+	mu      sync.RWMutex
+	weights map[string]float64
+}
+
+// NewAssigner creates a new *Assigner giving each token the same share of
+// the repositories.
+func NewAssigner() *Assigner {
+	return &Assigner{weights: make(map[string]float64)}
+}
+
+// SetWeights replaces the weights of the tokens, by name. Each token gets
+// a share of the repositories proportional to its weight. Tokens without a
+// positive weight weigh 1.
+func (a *Assigner) SetWeights(weights map[string]float64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.weights = make(map[string]float64, len(weights))
+	for name, w := range weights {
+		a.weights[name] = w
+	}
+}
+
+// Assign returns which of the tokens names the repository repo uses, or an
+// error if there are none.
+func (a *Assigner) Assign(repo string, names []string) (string, error) {
+	if len(names) == 0 {
+		return "", fmt.Errorf("no tokens")
+	}
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	best, bestScore := "", math.Inf(-1)
+	for _, name := range names {
+		w, ok := a.weights[name]
+		if !ok || w <= 0 {
+			w = 1
+		}
+		// -w/ln(u) for u uniform in (0, 1) is highest for each token in
+		// proportion to w
+		score := -w / math.Log(hashUnit(repo, name))
+		// Break ties by name so the order of names does not matter
+		if score > bestScore || (score == bestScore && name < best) {
+			best, bestScore = name, score
+		}
+	}
+	return best, nil
+}
+
+// hashUnit hashes repo and name to a number in (0, 1).
+func hashUnit(repo, name string) float64 {
+	sum := sha256.Sum256([]byte(repo + "\x00" + name))
+	// The top 53 bits fill the mantissa of a float64
+	x := binary.BigEndian.Uint64(sum[:8]) >> 11
+	return (float64(x) + 0.5) / (1 << 53)
+}
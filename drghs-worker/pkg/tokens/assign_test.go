@@ -0,0 +1,92 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokens
+
+import (
+	"fmt"
+	"testing"
+)
+
+func assignAll(t *testing.T, a *Assigner, n int, names []string) map[string]string {
+	got := make(map[string]string, n)
+	for i := 0; i < n; i++ {
+		repo := fmt.Sprintf("owner/repo-%v", i)
+		name, err := a.Assign(repo, names)
+		if err != nil {
+			t.Fatalf("Assign returned an unexpected error: %v", err)
+		}
+		got[repo] = name
+	}
+	return got
+}
+
+func count(assigned map[string]string) map[string]int {
+	c := make(map[string]int)
+	for _, name := range assigned {
+		c[name]++
+	}
+	return c
+}
+
+func TestAssignIsStableAndBalanced(t *testing.T) {
+	a := NewAssigner()
+	names := []string{"one", "two", "three", "four"}
+	got := assignAll(t, a, 4000, names)
+
+	// The order of the tokens does not matter
+	reversed := []string{"four", "three", "two", "one"}
+	if again := assignAll(t, a, 4000, reversed); fmt.Sprint(again) != fmt.Sprint(got) {
+		t.Errorf("Assign depends on the order of the tokens")
+	}
+
+	// Every token, the last included, gets about a quarter
+	for _, name := range names {
+		if n := count(got)[name]; n < 800 || n > 1200 {
+			t.Errorf("Wanted about 1000 repositories on %v. Got %v", name, n)
+		}
+	}
+
+	// Removing a token only moves its repositories
+	without := assignAll(t, a, 4000, names[:3])
+	for repo, name := range got {
+		if name != "four" && without[repo] != name {
+			t.Errorf("Wanted %v to keep %v. Got %v", repo, name, without[repo])
+		}
+	}
+}
+
+func TestAssignWeights(t *testing.T) {
+	a := NewAssigner()
+	names := []string{"one", "two"}
+	before := assignAll(t, a, 4000, names)
+
+	a.SetWeights(map[string]float64{"one": 0.25})
+	after := assignAll(t, a, 4000, names)
+
+	// one gets a fifth, 0.25/(0.25+1), of the repositories
+	if n := count(after)["one"]; n < 600 || n > 1000 {
+		t.Errorf("Wanted about 800 repositories on one. Got %v", n)
+	}
+	// and only repositories of one move
+	for repo, name := range after {
+		if name == "one" && before[repo] != "one" {
+			t.Errorf("Wanted %v to stay on %v. Got one", repo, before[repo])
+		}
+	}
+
+	if _, err := a.Assign("owner/repo", nil); err == nil {
+		t.Errorf("Wanted an error assigning no tokens")
+	}
+}
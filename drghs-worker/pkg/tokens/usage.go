@@ -0,0 +1,126 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokens
+
+import (
+	"encoding/json"
+	"math"
+	"sync"
+	"time"
+)
+
+// minWeight is the least weight Usage gives a token, so a token near its
+// rate limit keeps some repositories rather than all moving to the others.
+const minWeight = 0.1
+
+// Usage keeps how much of its rate limit each token used over a window, to
+// weigh the tokens for an Assigner. It is saved with json.Marshal and
+// restored with json.Unmarshal, so the weights, and the repositories of each
+// token, outlive the process.
+type Usage struct {
+	window   time.Duration
+	maxUsage float64
+
+	mu      sync.Mutex
+	samples map[string][]usageSample
+}
+
+type usageSample struct {
+	At   time.Time `json:"at"`
+	Used float64   `json:"used"`
+}
+
+// NewUsage creates a new *Usage keeping the observations of the last
+// window. A token is only made lighter once it used more than maxUsage, a
+// share of its rate limit between 0 and 1, so tokens that are not busy keep
+// their repositories.
+func NewUsage(window time.Duration, maxUsage float64) *Usage {
+	return &Usage{
+		window:   window,
+		maxUsage: maxUsage,
+		samples:  make(map[string][]usageSample),
+	}
+}
+
+// Observe records that the token name had used used of its limit requests
+// at at.
+func (u *Usage) Observe(name string, used, limit int, at time.Time) {
+	if limit <= 0 {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.samples[name] = append(u.samples[name], usageSample{At: at, Used: float64(used) / float64(limit)})
+}
+
+// MarshalJSON returns the observations of u.
+func (u *Usage) MarshalJSON() ([]byte, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return json.Marshal(u.samples)
+}
+
+// UnmarshalJSON adds the observations b, returned by MarshalJSON, to u.
+func (u *Usage) UnmarshalJSON(b []byte) error {
+	var samples map[string][]usageSample
+	if err := json.Unmarshal(b, &samples); err != nil {
+		return err
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.samples == nil {
+		u.samples = make(map[string][]usageSample)
+	}
+	for name, s := range samples {
+		u.samples[name] = append(s, u.samples[name]...)
+	}
+	return nil
+}
+
+// Weights returns the weight of each token observed within the window
+// before now. A token whose peak usage stayed under maxUsage weighs 1.
+// Above it, the weight falls with the share of the rate limit left, to
+// minWeight for a token that ran out, rounded to tenths so the weights do
+// not move with every observation.
+func (u *Usage) Weights(now time.Time) map[string]float64 {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	weights := make(map[string]float64, len(u.samples))
+	for name, samples := range u.samples {
+		// Forget the samples older than the window
+		kept := samples[:0]
+		peak := 0.0
+		for _, s := range samples {
+			if now.Sub(s.At) > u.window {
+				continue
+			}
+			kept = append(kept, s)
+			peak = math.Max(peak, s.Used)
+		}
+		if len(kept) == 0 {
+			delete(u.samples, name)
+			continue
+		}
+		u.samples[name] = kept
+
+		w := 1.0
+		if peak > u.maxUsage {
+			w = math.Round(10*(1-peak)/(1-u.maxUsage)) / 10
+		}
+		weights[name] = math.Max(minWeight, math.Min(1, w))
+	}
+	return weights
+}
@@ -0,0 +1,108 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokens
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUsageWeights(t *testing.T) {
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	type observation struct {
+		name        string
+		used, limit int
+		ago         time.Duration
+	}
+	tests := []struct {
+		name         string
+		observations []observation
+		want         map[string]float64
+	}{
+		{
+			name: "Under the max usage",
+			observations: []observation{
+				{"one", 100, 5000, time.Minute},
+				{"two", 3900, 5000, time.Minute},
+			},
+			want: map[string]float64{"one": 1, "two": 1},
+		},
+		{
+			name: "Peak over the max usage",
+			observations: []observation{
+				{"one", 4500, 5000, 2 * time.Hour},
+				{"one", 100, 5000, time.Minute},
+			},
+			want: map[string]float64{"one": 0.5},
+		},
+		{
+			name: "Ran out",
+			observations: []observation{
+				{"one", 5000, 5000, time.Minute},
+			},
+			want: map[string]float64{"one": minWeight},
+		},
+		{
+			name: "Peak out of the window",
+			observations: []observation{
+				{"one", 5000, 5000, 25 * time.Hour},
+				{"one", 1000, 5000, time.Minute},
+				{"two", 5000, 5000, 25 * time.Hour},
+			},
+			want: map[string]float64{"one": 1},
+		},
+		{
+			name: "No limit",
+			observations: []observation{
+				{"one", 0, 0, time.Minute},
+			},
+			want: map[string]float64{},
+		},
+	}
+	for _, test := range tests {
+		u := NewUsage(24*time.Hour, 0.8)
+		for _, o := range test.observations {
+			u.Observe(o.name, o.used, o.limit, now.Add(-o.ago))
+		}
+		if got := u.Weights(now); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%v: Weights returned unexpected weights. Got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestUsageRestore(t *testing.T) {
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	u := NewUsage(24*time.Hour, 0.8)
+	u.Observe("one", 4500, 5000, now.Add(-2*time.Hour))
+	u.Observe("two", 100, 5000, now.Add(-time.Hour))
+
+	b, err := json.Marshal(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A restarted process only observed the latest usage
+	restored := NewUsage(24*time.Hour, 0.8)
+	restored.Observe("one", 100, 5000, now)
+	if err := json.Unmarshal(b, restored); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]float64{"one": 0.5, "two": 1}
+	if got := restored.Weights(now); !reflect.DeepEqual(got, want) {
+		t.Errorf("Weights returned unexpected weights after a restore. Got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package transfers records where the issues transferred out of a
+// repository went.
+//
+// GitHub keeps a transferred issue only in its new repository, so maintner
+// sees it go missing like a deleted one. The sweeper tells the two apart
+// and records the new location of transferred issues in a Store. maintner's
+// mutation log has no room for it, so a Store keeps the transfers in a
+// Storage of their own, such as an object next to the log.
+package transfers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sync"
+
+	"cloud.google.com/go/storage"
+	"golang.org/x/build/maintner"
+)
+
+// destinationRegex matches the name of the issue an issue was transferred
+// to, in the format owner/repository/issues/N.
+var destinationRegex = regexp.MustCompile(`^[\w.-]+\/[\w.-]+\/issues\/\d+$`)
+
+// ValidDestination reports whether name is a valid transfer destination.
+func ValidDestination(name string) bool {
+	return destinationRegex.MatchString(name)
+}
+
+// Storage holds the serialized transfers of a Store.
+type Storage interface {
+	// Read returns what was last written, or nil if nothing was.
+	Read(ctx context.Context) ([]byte, error)
+	Write(ctx context.Context, b []byte) error
+}
+
+// FileStorage is a Storage in the file at its path.
+type FileStorage string
+
+// Read implements Storage.
+func (f FileStorage) Read(ctx context.Context) ([]byte, error) {
+	b, err := ioutil.ReadFile(string(f))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return b, err
+}
+
+// Write implements Storage.
+func (f FileStorage) Write(ctx context.Context, b []byte) error {
+	return ioutil.WriteFile(string(f), b, 0644)
+}
+
+// GCSStorage is a Storage in a Google Cloud Storage object.
+type GCSStorage struct {
+	obj *storage.ObjectHandle
+}
+
+// NewGCSStorage returns a GCSStorage in object of bucket.
+func NewGCSStorage(ctx context.Context, bucket, object string) (*GCSStorage, error) {
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &GCSStorage{obj: client.Bucket(bucket).Object(object)}, nil
+}
+
+// Read implements Storage.
+func (g *GCSStorage) Read(ctx context.Context) ([]byte, error) {
+	rc, err := g.obj.NewReader(ctx)
+	if err == storage.ErrObjectNotExist {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return ioutil.ReadAll(rc)
+}
+
+// Write implements Storage.
+func (g *GCSStorage) Write(ctx context.Context, b []byte) error {
+	w := g.obj.NewWriter(ctx)
+	w.ContentType = "application/json"
+	if _, err := w.Write(b); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// Store holds the destinations of transferred issues, by repository and
+// issue number. It is safe for concurrent use.
+type Store struct {
+	storage Storage
+
+	mu sync.RWMutex
+	// transfers is keyed by owner/repository, then by issue number.
+	transfers map[string]map[int32]string
+}
+
+// Open returns a Store of the transfers in s.
+func Open(ctx context.Context, s Storage) (*Store, error) {
+	b, err := s.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("transfers: reading: %v", err)
+	}
+	t := make(map[string]map[int32]string)
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &t); err != nil {
+			return nil, fmt.Errorf("transfers: parsing: %v", err)
+		}
+	}
+	return &Store{storage: s, transfers: t}, nil
+}
+
+func repoKey(rID maintner.GitHubRepoID) string {
+	return rID.Owner + "/" + rID.Repo
+}
+
+// Record records the destinations of the issues of rID in transfers, keyed
+// by issue number, and writes every transfer of the Store to its Storage.
+// Nothing is recorded if the write fails.
+func (s *Store) Record(ctx context.Context, rID maintner.GitHubRepoID, transfers map[int32]string) error {
+	for n, to := range transfers {
+		if !ValidDestination(to) {
+			return fmt.Errorf("transfers: invalid destination %q for issue %v", to, n)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := repoKey(rID)
+	prev := s.transfers[k]
+	next := make(map[int32]string, len(prev)+len(transfers))
+	for n, to := range prev {
+		next[n] = to
+	}
+	for n, to := range transfers {
+		next[n] = to
+	}
+
+	s.transfers[k] = next
+	b, err := json.Marshal(s.transfers)
+	if err == nil {
+		err = s.storage.Write(ctx, b)
+	}
+	if err != nil {
+		if prev == nil {
+			delete(s.transfers, k)
+		} else {
+			s.transfers[k] = prev
+		}
+		return fmt.Errorf("transfers: writing: %v", err)
+	}
+	return nil
+}
+
+// Destination returns the name of the issue issue number of rID was
+// transferred to, or "" if it was not.
+func (s *Store) Destination(rID maintner.GitHubRepoID, number int32) string {
+	if s == nil {
+		return ""
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.transfers[repoKey(rID)][number]
+}
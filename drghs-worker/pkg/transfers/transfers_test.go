@@ -0,0 +1,108 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transfers
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/build/maintner"
+)
+
+// failingStorage is a Storage whose writes fail.
+type failingStorage struct{}
+
+func (failingStorage) Read(ctx context.Context) ([]byte, error) { return nil, nil }
+func (failingStorage) Write(ctx context.Context, b []byte) error {
+	return errors.New("unavailable")
+}
+
+func TestRecordAndDestination(t *testing.T) {
+	dir, err := ioutil.TempDir("", "transfers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ctx := context.Background()
+	fs := FileStorage(filepath.Join(dir, "transfers.json"))
+
+	s, err := Open(ctx, fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rID := maintner.GitHubRepoID{Owner: "foo", Repo: "bar"}
+	if got := s.Destination(rID, 7); got != "" {
+		t.Errorf("Destination() before Record(). Wanted \"\", Got %q", got)
+	}
+
+	if err := s.Record(ctx, rID, map[int32]string{7: "foo/baz/issues/1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Record(ctx, rID, map[int32]string{8: "qux/bar/issues/2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A reopened Store reads the transfers back.
+	s, err = Open(ctx, fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		RID    maintner.GitHubRepoID
+		Number int32
+		Want   string
+	}{
+		{rID, 7, "foo/baz/issues/1"},
+		{rID, 8, "qux/bar/issues/2"},
+		{rID, 9, ""},
+		{maintner.GitHubRepoID{Owner: "foo", Repo: "baz"}, 7, ""},
+	}
+	for _, c := range tests {
+		if got := s.Destination(c.RID, c.Number); got != c.Want {
+			t.Errorf("Destination(%v, %v) Wanted %q, Got %q", c.RID, c.Number, c.Want, got)
+		}
+	}
+
+	var nilStore *Store
+	if got := nilStore.Destination(rID, 7); got != "" {
+		t.Errorf("Destination() of a nil Store. Wanted \"\", Got %q", got)
+	}
+}
+
+func TestRecordInvalid(t *testing.T) {
+	ctx := context.Background()
+	rID := maintner.GitHubRepoID{Owner: "foo", Repo: "bar"}
+
+	s, err := Open(ctx, failingStorage{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Record(ctx, rID, map[int32]string{7: "foo/baz/issues/1"}); err == nil {
+		t.Errorf("Record() with a failing Storage. Wanted an error, Got nil")
+	}
+	if got := s.Destination(rID, 7); got != "" {
+		t.Errorf("Destination() after a failed Record(). Wanted \"\", Got %q", got)
+	}
+
+	for _, to := range []string{"", "foo/baz", "foo/baz/pulls/1", "https://github.com/foo/baz/issues/1"} {
+		if err := s.Record(ctx, rID, map[int32]string{7: to}); err == nil {
+			t.Errorf("Record() with destination %q. Wanted an error, Got nil", to)
+		}
+	}
+}
@@ -15,6 +15,8 @@
 package utils
 
 import (
+	"time"
+
 	"golang.org/x/build/maintner"
 )
 
@@ -23,6 +25,17 @@ import (
 // if the last review event was an "approved" event it returns true
 // if there are no reviews, this return false
 func IsApproved(issue *maintner.GitHubIssue) bool {
+	return isApproved(issue, time.Time{})
+}
+
+// IsApprovedAsOf is like IsApproved, but only considers the
+// reviews submitted at or before t
+func IsApprovedAsOf(issue *maintner.GitHubIssue, t time.Time) bool {
+	return isApproved(issue, t)
+}
+
+// isApproved implements IsApproved. A zero t considers all reviews
+func isApproved(issue *maintner.GitHubIssue, t time.Time) bool {
 	if issue == nil {
 		return false
 	}
@@ -34,6 +47,9 @@ func IsApproved(issue *maintner.GitHubIssue) bool {
 	// reviewer ever requests changes after approving
 	// this will still set the final review to 'false'
 	issue.ForeachReview(func(review *maintner.GitHubReview) error {
+		if !t.IsZero() && review.Created.After(t) {
+			return nil
+		}
 		reviewers[review.Actor] = review.State == "APPROVED"
 		return nil
 	})
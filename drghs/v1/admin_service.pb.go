@@ -7,6 +7,7 @@ import (
 	context "context"
 	fmt "fmt"
 	proto "github.com/golang/protobuf/proto"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
 	_ "google.golang.org/genproto/googleapis/api/annotations"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
@@ -89,9 +90,284 @@ func (m *UpdateTrackedReposResponse) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_UpdateTrackedReposResponse proto.InternalMessageInfo
 
+// Request message for [DevRelServicesAdmin.GetSupervisorStatus].
+type GetSupervisorStatusRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetSupervisorStatusRequest) Reset()         { *m = GetSupervisorStatusRequest{} }
+func (m *GetSupervisorStatusRequest) String() string { return proto.CompactTextString(m) }
+func (*GetSupervisorStatusRequest) ProtoMessage()    {}
+func (*GetSupervisorStatusRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f9bc00259dc38d22, []int{2}
+}
+
+func (m *GetSupervisorStatusRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetSupervisorStatusRequest.Unmarshal(m, b)
+}
+func (m *GetSupervisorStatusRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetSupervisorStatusRequest.Marshal(b, m, deterministic)
+}
+func (m *GetSupervisorStatusRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetSupervisorStatusRequest.Merge(m, src)
+}
+func (m *GetSupervisorStatusRequest) XXX_Size() int {
+	return xxx_messageInfo_GetSupervisorStatusRequest.Size(m)
+}
+func (m *GetSupervisorStatusRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetSupervisorStatusRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetSupervisorStatusRequest proto.InternalMessageInfo
+
+// Response message for [DevRelServicesAdmin.GetSupervisorStatus].
+type GetSupervisorStatusResponse struct {
+	// The status of each supervisor.
+	Supervisors          []*SupervisorStatus `protobuf:"bytes,1,rep,name=supervisors,proto3" json:"supervisors,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
+}
+
+func (m *GetSupervisorStatusResponse) Reset()         { *m = GetSupervisorStatusResponse{} }
+func (m *GetSupervisorStatusResponse) String() string { return proto.CompactTextString(m) }
+func (*GetSupervisorStatusResponse) ProtoMessage()    {}
+func (*GetSupervisorStatusResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f9bc00259dc38d22, []int{3}
+}
+
+func (m *GetSupervisorStatusResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetSupervisorStatusResponse.Unmarshal(m, b)
+}
+func (m *GetSupervisorStatusResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetSupervisorStatusResponse.Marshal(b, m, deterministic)
+}
+func (m *GetSupervisorStatusResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetSupervisorStatusResponse.Merge(m, src)
+}
+func (m *GetSupervisorStatusResponse) XXX_Size() int {
+	return xxx_messageInfo_GetSupervisorStatusResponse.Size(m)
+}
+func (m *GetSupervisorStatusResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetSupervisorStatusResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetSupervisorStatusResponse proto.InternalMessageInfo
+
+func (m *GetSupervisorStatusResponse) GetSupervisors() []*SupervisorStatus {
+	if m != nil {
+		return m.Supervisors
+	}
+	return nil
+}
+
+// The status of a supervisor and of the workloads it manages.
+type SupervisorStatus struct {
+	// The name of the supervisor, e.g. "maintnerd-sprvsr".
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// The error getting the status of the supervisor, if any.
+	Error string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// When the supervisor last reconciled its workloads.
+	LastReconcile *timestamp.Timestamp `protobuf:"bytes,3,opt,name=last_reconcile,json=lastReconcile,proto3" json:"last_reconcile,omitempty"`
+	// "ok", or the errors of the last reconcile.
+	LastResult string `protobuf:"bytes,4,opt,name=last_result,json=lastResult,proto3" json:"last_result,omitempty"`
+	// Why the deletions of the last reconcile were blocked, if they were.
+	Blocked string `protobuf:"bytes,5,opt,name=blocked,proto3" json:"blocked,omitempty"`
+	// The workloads of each repository.
+	Workloads            []*ManagedWorkload `protobuf:"bytes,6,rep,name=workloads,proto3" json:"workloads,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *SupervisorStatus) Reset()         { *m = SupervisorStatus{} }
+func (m *SupervisorStatus) String() string { return proto.CompactTextString(m) }
+func (*SupervisorStatus) ProtoMessage()    {}
+func (*SupervisorStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f9bc00259dc38d22, []int{4}
+}
+
+func (m *SupervisorStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SupervisorStatus.Unmarshal(m, b)
+}
+func (m *SupervisorStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SupervisorStatus.Marshal(b, m, deterministic)
+}
+func (m *SupervisorStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SupervisorStatus.Merge(m, src)
+}
+func (m *SupervisorStatus) XXX_Size() int {
+	return xxx_messageInfo_SupervisorStatus.Size(m)
+}
+func (m *SupervisorStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_SupervisorStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SupervisorStatus proto.InternalMessageInfo
+
+func (m *SupervisorStatus) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *SupervisorStatus) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *SupervisorStatus) GetLastReconcile() *timestamp.Timestamp {
+	if m != nil {
+		return m.LastReconcile
+	}
+	return nil
+}
+
+func (m *SupervisorStatus) GetLastResult() string {
+	if m != nil {
+		return m.LastResult
+	}
+	return ""
+}
+
+func (m *SupervisorStatus) GetBlocked() string {
+	if m != nil {
+		return m.Blocked
+	}
+	return ""
+}
+
+func (m *SupervisorStatus) GetWorkloads() []*ManagedWorkload {
+	if m != nil {
+		return m.Workloads
+	}
+	return nil
+}
+
+// What a supervisor runs for a repository.
+type ManagedWorkload struct {
+	// The repository, as owner/name.
+	Repository string `protobuf:"bytes,1,opt,name=repository,proto3" json:"repository,omitempty"`
+	// Whether the repository is still tracked.
+	Tracked bool `protobuf:"varint,2,opt,name=tracked,proto3" json:"tracked,omitempty"`
+	// The name of the Deployment of the repository.
+	Deployment string `protobuf:"bytes,3,opt,name=deployment,proto3" json:"deployment,omitempty"`
+	// The name of the Service of the repository.
+	Service string `protobuf:"bytes,4,opt,name=service,proto3" json:"service,omitempty"`
+	// The name of the process of the repository, for supervisors running
+	// them locally.
+	Process string `protobuf:"bytes,5,opt,name=process,proto3" json:"process,omitempty"`
+	// The images of the containers of the repository.
+	Images        []string `protobuf:"bytes,6,rep,name=images,proto3" json:"images,omitempty"`
+	Replicas      int32    `protobuf:"varint,7,opt,name=replicas,proto3" json:"replicas,omitempty"`
+	ReadyReplicas int32    `protobuf:"varint,8,opt,name=ready_replicas,json=readyReplicas,proto3" json:"ready_replicas,omitempty"`
+	// The number of times the containers of the repository restarted.
+	Restarts             int32    `protobuf:"varint,9,opt,name=restarts,proto3" json:"restarts,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ManagedWorkload) Reset()         { *m = ManagedWorkload{} }
+func (m *ManagedWorkload) String() string { return proto.CompactTextString(m) }
+func (*ManagedWorkload) ProtoMessage()    {}
+func (*ManagedWorkload) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f9bc00259dc38d22, []int{5}
+}
+
+func (m *ManagedWorkload) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ManagedWorkload.Unmarshal(m, b)
+}
+func (m *ManagedWorkload) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ManagedWorkload.Marshal(b, m, deterministic)
+}
+func (m *ManagedWorkload) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ManagedWorkload.Merge(m, src)
+}
+func (m *ManagedWorkload) XXX_Size() int {
+	return xxx_messageInfo_ManagedWorkload.Size(m)
+}
+func (m *ManagedWorkload) XXX_DiscardUnknown() {
+	xxx_messageInfo_ManagedWorkload.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ManagedWorkload proto.InternalMessageInfo
+
+func (m *ManagedWorkload) GetRepository() string {
+	if m != nil {
+		return m.Repository
+	}
+	return ""
+}
+
+func (m *ManagedWorkload) GetTracked() bool {
+	if m != nil {
+		return m.Tracked
+	}
+	return false
+}
+
+func (m *ManagedWorkload) GetDeployment() string {
+	if m != nil {
+		return m.Deployment
+	}
+	return ""
+}
+
+func (m *ManagedWorkload) GetService() string {
+	if m != nil {
+		return m.Service
+	}
+	return ""
+}
+
+func (m *ManagedWorkload) GetProcess() string {
+	if m != nil {
+		return m.Process
+	}
+	return ""
+}
+
+func (m *ManagedWorkload) GetImages() []string {
+	if m != nil {
+		return m.Images
+	}
+	return nil
+}
+
+func (m *ManagedWorkload) GetReplicas() int32 {
+	if m != nil {
+		return m.Replicas
+	}
+	return 0
+}
+
+func (m *ManagedWorkload) GetReadyReplicas() int32 {
+	if m != nil {
+		return m.ReadyReplicas
+	}
+	return 0
+}
+
+func (m *ManagedWorkload) GetRestarts() int32 {
+	if m != nil {
+		return m.Restarts
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*UpdateTrackedReposRequest)(nil), "drghs.v1.UpdateTrackedReposRequest")
 	proto.RegisterType((*UpdateTrackedReposResponse)(nil), "drghs.v1.UpdateTrackedReposResponse")
+	proto.RegisterType((*GetSupervisorStatusRequest)(nil), "drghs.v1.GetSupervisorStatusRequest")
+	proto.RegisterType((*GetSupervisorStatusResponse)(nil), "drghs.v1.GetSupervisorStatusResponse")
+	proto.RegisterType((*SupervisorStatus)(nil), "drghs.v1.SupervisorStatus")
+	proto.RegisterType((*ManagedWorkload)(nil), "drghs.v1.ManagedWorkload")
 }
 
 func init() {
@@ -99,19 +375,40 @@ func init() {
 }
 
 var fileDescriptor_f9bc00259dc38d22 = []byte{
-	// 186 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x12, 0x4e, 0x4c, 0xc9, 0xcd,
-	0xcc, 0x8b, 0x2f, 0x4e, 0x2d, 0x2a, 0xcb, 0x4c, 0x4e, 0xd5, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17,
-	0xe2, 0x48, 0x29, 0x4a, 0xcf, 0x28, 0xd6, 0x2b, 0x33, 0x94, 0x92, 0x49, 0xcf, 0xcf, 0x4f, 0xcf,
-	0x49, 0xd5, 0x4f, 0x2c, 0xc8, 0xd4, 0x4f, 0xcc, 0xcb, 0xcb, 0x2f, 0x49, 0x2c, 0xc9, 0xcc, 0xcf,
-	0x2b, 0x86, 0xa8, 0x53, 0x92, 0xe6, 0x92, 0x0c, 0x2d, 0x48, 0x49, 0x2c, 0x49, 0x0d, 0x29, 0x4a,
-	0x4c, 0xce, 0x4e, 0x4d, 0x09, 0x4a, 0x2d, 0xc8, 0x2f, 0x0e, 0x4a, 0x2d, 0x2c, 0x4d, 0x2d, 0x2e,
-	0x51, 0x92, 0xe1, 0x92, 0xc2, 0x26, 0x59, 0x5c, 0x90, 0x9f, 0x57, 0x9c, 0x6a, 0xd4, 0xc7, 0xc8,
-	0x25, 0xec, 0x92, 0x5a, 0x16, 0x94, 0x9a, 0x13, 0x0c, 0xb1, 0xba, 0xd8, 0x11, 0xe4, 0x10, 0xa1,
-	0x72, 0x2e, 0x21, 0x4c, 0x5d, 0x42, 0xca, 0x7a, 0x30, 0x17, 0xe9, 0xe1, 0xb4, 0x50, 0x4a, 0x05,
-	0xbf, 0x22, 0x88, 0xc5, 0x4a, 0x62, 0x4d, 0x97, 0x9f, 0x4c, 0x66, 0x12, 0x50, 0xe2, 0x03, 0xfb,
-	0xa9, 0xcc, 0x50, 0xbf, 0x14, 0xac, 0x36, 0x89, 0x0d, 0xec, 0x25, 0x63, 0x40, 0x00, 0x00, 0x00,
-	0xff, 0xff, 0x36, 0x99, 0x9a, 0xe0, 0x11, 0x01, 0x00, 0x00,
+	// 524 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x53, 0x41, 0x6f, 0xd3, 0x4c,
+	0x10, 0x95, 0xd3, 0x26, 0x4d, 0x26, 0x6a, 0xbe, 0x6a, 0xf3, 0xa9, 0x72, 0xdd, 0x8a, 0x46, 0x81,
+	0x4a, 0x39, 0x39, 0x6a, 0x39, 0x70, 0xe1, 0x52, 0x09, 0x89, 0x13, 0x97, 0x4d, 0x11, 0x07, 0x0e,
+	0xd1, 0xc6, 0x1e, 0x82, 0x55, 0xdb, 0x6b, 0x76, 0xd6, 0xa9, 0xc2, 0x91, 0x23, 0x57, 0x24, 0xfe,
+	0x18, 0x7f, 0x81, 0x7f, 0xc0, 0x1f, 0x40, 0xbb, 0xf6, 0x3a, 0xa1, 0x4d, 0xe1, 0xe6, 0x37, 0xef,
+	0xcd, 0xec, 0xbe, 0xe7, 0x59, 0x18, 0x8a, 0x38, 0x4b, 0xf2, 0x39, 0xa1, 0x5a, 0x25, 0x11, 0x86,
+	0x85, 0x92, 0x5a, 0xb2, 0x6e, 0xac, 0x96, 0x1f, 0x29, 0x5c, 0x5d, 0x06, 0x67, 0x4b, 0x29, 0x97,
+	0x29, 0x4e, 0x45, 0x91, 0x4c, 0x45, 0x9e, 0x4b, 0x2d, 0x74, 0x22, 0x73, 0xaa, 0x74, 0xc1, 0x79,
+	0xcd, 0x5a, 0xb4, 0x28, 0x3f, 0x4c, 0x75, 0x92, 0x21, 0x69, 0x91, 0x15, 0x95, 0x60, 0x7c, 0x0a,
+	0x27, 0x6f, 0x8b, 0x58, 0x68, 0xbc, 0x51, 0x22, 0xba, 0xc5, 0x98, 0x63, 0x21, 0x89, 0xe3, 0xa7,
+	0x12, 0x49, 0x8f, 0xcf, 0x20, 0xd8, 0x45, 0x52, 0x21, 0x73, 0x42, 0xc3, 0xbe, 0x46, 0x3d, 0x2b,
+	0x0b, 0x73, 0x33, 0x92, 0x6a, 0xa6, 0x85, 0x2e, 0x9b, 0xde, 0xf7, 0x70, 0xba, 0x93, 0xad, 0x9a,
+	0xd9, 0x4b, 0xe8, 0x53, 0xc3, 0x91, 0xef, 0x8d, 0xf6, 0x26, 0xfd, 0xab, 0x20, 0x74, 0xb6, 0xc2,
+	0x07, 0x8d, 0xdb, 0xf2, 0xf1, 0x2f, 0x0f, 0x8e, 0xee, 0x2b, 0x18, 0x83, 0xfd, 0x5c, 0x64, 0xe8,
+	0x7b, 0x23, 0x6f, 0xd2, 0xe3, 0xf6, 0x9b, 0xfd, 0x0f, 0x6d, 0x54, 0x4a, 0x2a, 0xbf, 0x65, 0x8b,
+	0x15, 0x60, 0xd7, 0x30, 0x48, 0x05, 0xe9, 0xb9, 0xc2, 0x48, 0xe6, 0x51, 0x92, 0xa2, 0xbf, 0x37,
+	0xf2, 0xec, 0xf9, 0x55, 0x5c, 0xa1, 0x8b, 0x2b, 0xbc, 0x71, 0x71, 0xf1, 0x43, 0xd3, 0xc1, 0x5d,
+	0x03, 0x3b, 0x87, 0x7e, 0x3d, 0x82, 0xca, 0x54, 0xfb, 0xfb, 0x76, 0x3c, 0x54, 0x1a, 0x53, 0x61,
+	0x3e, 0x1c, 0x2c, 0x52, 0x69, 0x52, 0xf3, 0xdb, 0x96, 0x74, 0x90, 0xbd, 0x80, 0xde, 0x9d, 0x54,
+	0xb7, 0xa9, 0x14, 0x31, 0xf9, 0x1d, 0x6b, 0xfc, 0x64, 0x63, 0xfc, 0x8d, 0xc8, 0xc5, 0x12, 0xe3,
+	0x77, 0xb5, 0x82, 0x6f, 0xb4, 0xe3, 0xef, 0x2d, 0xf8, 0xef, 0x1e, 0xcd, 0x9e, 0x00, 0x28, 0xf3,
+	0x57, 0x12, 0x2d, 0xd5, 0xba, 0xb6, 0xbe, 0x55, 0x31, 0xd7, 0xd0, 0xd5, 0xcf, 0xb3, 0x11, 0x74,
+	0xb9, 0x83, 0xa6, 0x33, 0xc6, 0x22, 0x95, 0xeb, 0x0c, 0x73, 0x6d, 0x03, 0xe8, 0xf1, 0xad, 0x8a,
+	0xe9, 0xac, 0x77, 0xae, 0x76, 0xe7, 0xa0, 0x61, 0x0a, 0x25, 0x23, 0x24, 0x72, 0xd6, 0x6a, 0xc8,
+	0x8e, 0xa1, 0x93, 0x64, 0x62, 0x89, 0x95, 0xaf, 0x1e, 0xaf, 0x11, 0x0b, 0xa0, 0xab, 0xb0, 0x48,
+	0x93, 0x48, 0x90, 0x7f, 0x30, 0xf2, 0x26, 0x6d, 0xde, 0x60, 0x76, 0x01, 0x03, 0x85, 0x22, 0x5e,
+	0xcf, 0x1b, 0x45, 0xd7, 0x2a, 0x0e, 0x6d, 0x95, 0x3b, 0x99, 0x1d, 0x41, 0x5a, 0x28, 0x4d, 0x7e,
+	0xcf, 0x8d, 0xa8, 0xf0, 0xd5, 0xd7, 0x16, 0x0c, 0x5f, 0xe1, 0x8a, 0x63, 0x3a, 0xab, 0xae, 0x48,
+	0xd7, 0xe6, 0xcd, 0xb0, 0x3b, 0x60, 0x0f, 0xf7, 0x97, 0x3d, 0xdd, 0x84, 0xfd, 0xe8, 0xea, 0x07,
+	0xcf, 0xfe, 0x2e, 0xaa, 0x9f, 0xc0, 0xf1, 0x97, 0x1f, 0x3f, 0xbf, 0xb5, 0x8e, 0xc6, 0x03, 0xfb,
+	0xfc, 0x56, 0x97, 0xd3, 0xd2, 0x6a, 0xd9, 0x67, 0x18, 0xee, 0x58, 0x7e, 0xb6, 0x35, 0xf4, 0xf1,
+	0x97, 0x13, 0x5c, 0xfc, 0x43, 0xf5, 0xe7, 0xd9, 0xac, 0x39, 0x9b, 0x2c, 0xbf, 0xe8, 0xd8, 0xe5,
+	0x7d, 0xfe, 0x7b, 0x00, 0xaf, 0x71, 0xbe, 0x48, 0x38, 0x04, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -127,6 +424,7 @@ const _ = grpc.SupportPackageIsVersion6
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type DevRelServicesAdminClient interface {
 	UpdateTrackedRepos(ctx context.Context, in *UpdateTrackedReposRequest, opts ...grpc.CallOption) (*UpdateTrackedReposResponse, error)
+	GetSupervisorStatus(ctx context.Context, in *GetSupervisorStatusRequest, opts ...grpc.CallOption) (*GetSupervisorStatusResponse, error)
 }
 
 type devRelServicesAdminClient struct {
@@ -146,9 +444,19 @@ func (c *devRelServicesAdminClient) UpdateTrackedRepos(ctx context.Context, in *
 	return out, nil
 }
 
+func (c *devRelServicesAdminClient) GetSupervisorStatus(ctx context.Context, in *GetSupervisorStatusRequest, opts ...grpc.CallOption) (*GetSupervisorStatusResponse, error) {
+	out := new(GetSupervisorStatusResponse)
+	err := c.cc.Invoke(ctx, "/drghs.v1.DevRelServicesAdmin/GetSupervisorStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DevRelServicesAdminServer is the server API for DevRelServicesAdmin service.
 type DevRelServicesAdminServer interface {
 	UpdateTrackedRepos(context.Context, *UpdateTrackedReposRequest) (*UpdateTrackedReposResponse, error)
+	GetSupervisorStatus(context.Context, *GetSupervisorStatusRequest) (*GetSupervisorStatusResponse, error)
 }
 
 // UnimplementedDevRelServicesAdminServer can be embedded to have forward compatible implementations.
@@ -158,6 +466,9 @@ type UnimplementedDevRelServicesAdminServer struct {
 func (*UnimplementedDevRelServicesAdminServer) UpdateTrackedRepos(ctx context.Context, req *UpdateTrackedReposRequest) (*UpdateTrackedReposResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateTrackedRepos not implemented")
 }
+func (*UnimplementedDevRelServicesAdminServer) GetSupervisorStatus(ctx context.Context, req *GetSupervisorStatusRequest) (*GetSupervisorStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSupervisorStatus not implemented")
+}
 
 func RegisterDevRelServicesAdminServer(s *grpc.Server, srv DevRelServicesAdminServer) {
 	s.RegisterService(&_DevRelServicesAdmin_serviceDesc, srv)
@@ -181,6 +492,24 @@ func _DevRelServicesAdmin_UpdateTrackedRepos_Handler(srv interface{}, ctx contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DevRelServicesAdmin_GetSupervisorStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSupervisorStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DevRelServicesAdminServer).GetSupervisorStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/drghs.v1.DevRelServicesAdmin/GetSupervisorStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DevRelServicesAdminServer).GetSupervisorStatus(ctx, req.(*GetSupervisorStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _DevRelServicesAdmin_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "drghs.v1.DevRelServicesAdmin",
 	HandlerType: (*DevRelServicesAdminServer)(nil),
@@ -189,6 +518,10 @@ var _DevRelServicesAdmin_serviceDesc = grpc.ServiceDesc{
 			MethodName: "UpdateTrackedRepos",
 			Handler:    _DevRelServicesAdmin_UpdateTrackedRepos_Handler,
 		},
+		{
+			MethodName: "GetSupervisorStatus",
+			Handler:    _DevRelServicesAdmin_GetSupervisorStatus_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "admin_service.proto",
@@ -7,6 +7,7 @@ import (
 	context "context"
 	fmt "fmt"
 	proto "github.com/golang/protobuf/proto"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
 	_ "google.golang.org/genproto/googleapis/api/annotations"
 	field_mask "google.golang.org/genproto/protobuf/field_mask"
 	grpc "google.golang.org/grpc"
@@ -296,10 +297,15 @@ type GetIssueRequest struct {
 	// If the FieldMask is NOT set or empty, all fields are returned. If the
 	// FieldMask is set, only the specified fields are returned. See
 	// https://pkg.go.dev/google.golang.org/genproto/protobuf/field_mask.
-	FieldMask            *field_mask.FieldMask `protobuf:"bytes,4,opt,name=field_mask,json=fieldMask,proto3" json:"field_mask,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
-	XXX_unrecognized     []byte                `json:"-"`
-	XXX_sizecache        int32                 `json:"-"`
+	FieldMask *field_mask.FieldMask `protobuf:"bytes,4,opt,name=field_mask,json=fieldMask,proto3" json:"field_mask,omitempty"`
+	// Optional. If set, the [Issue][] is returned as it was at this point in
+	// time. Its state, title, labels, assignees, comments and reviews are
+	// reconstructed from the [Issue][]'s events. Fields GitHub keeps no
+	// history for, such as the body, hold their current values.
+	ReadTime             *timestamp.Timestamp `protobuf:"bytes,5,opt,name=read_time,json=readTime,proto3" json:"read_time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
 }
 
 func (m *GetIssueRequest) Reset()         { *m = GetIssueRequest{} }
@@ -355,6 +361,13 @@ func (m *GetIssueRequest) GetFieldMask() *field_mask.FieldMask {
 	return nil
 }
 
+func (m *GetIssueRequest) GetReadTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.ReadTime
+	}
+	return nil
+}
+
 type GetIssueResponse struct {
 	Issue                *Issue   `protobuf:"bytes,1,opt,name=issue,proto3" json:"issue,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -394,11 +407,489 @@ func (m *GetIssueResponse) GetIssue() *Issue {
 	return nil
 }
 
+// Request message for [IssueService.ListIssueLinks][].
+type ListIssueLinksRequest struct {
+	// Required. The repository whose [Issues][Issue] the
+	// [IssueLinks][IssueLink] involve, in the format `owner/repository`.
+	Parent string `protobuf:"bytes,1,opt,name=parent,proto3" json:"parent,omitempty"`
+	// Optional. Limit the number of [IssueLinks][IssueLink] to include in the
+	// response. Fewer links than requested might be returned.
+	//
+	// The maximum page size is `500`. If unspecified, the page size will be the
+	// maximum.
+	PageSize int32 `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// Optional. To request the first page of results, `page_token` must be empty.
+	// To request the next page of results, page_token must be the value of
+	// [ListIssueLinksResponse.next_page_token][] returned by a previous call to
+	// [IssueService.ListIssueLinks][].
+	//
+	// The page token is valid for only 2 hours.
+	PageToken string `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// Optional. If set, only links whose source or target is the [Issue][] with
+	// this number are returned.
+	IssueId int32 `protobuf:"varint,4,opt,name=issue_id,json=issueId,proto3" json:"issue_id,omitempty"`
+	// Optional. If set, only links of this type are returned.
+	LinkType             IssueLink_LinkType `protobuf:"varint,5,opt,name=link_type,json=linkType,proto3,enum=drghs.v1.IssueLink_LinkType" json:"link_type,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *ListIssueLinksRequest) Reset()         { *m = ListIssueLinksRequest{} }
+func (m *ListIssueLinksRequest) String() string { return proto.CompactTextString(m) }
+func (*ListIssueLinksRequest) ProtoMessage()    {}
+func (*ListIssueLinksRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d4667488ad260932, []int{4}
+}
+
+func (m *ListIssueLinksRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListIssueLinksRequest.Unmarshal(m, b)
+}
+func (m *ListIssueLinksRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListIssueLinksRequest.Marshal(b, m, deterministic)
+}
+func (m *ListIssueLinksRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListIssueLinksRequest.Merge(m, src)
+}
+func (m *ListIssueLinksRequest) XXX_Size() int {
+	return xxx_messageInfo_ListIssueLinksRequest.Size(m)
+}
+func (m *ListIssueLinksRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListIssueLinksRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListIssueLinksRequest proto.InternalMessageInfo
+
+func (m *ListIssueLinksRequest) GetParent() string {
+	if m != nil {
+		return m.Parent
+	}
+	return ""
+}
+
+func (m *ListIssueLinksRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *ListIssueLinksRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
+func (m *ListIssueLinksRequest) GetIssueId() int32 {
+	if m != nil {
+		return m.IssueId
+	}
+	return 0
+}
+
+func (m *ListIssueLinksRequest) GetLinkType() IssueLink_LinkType {
+	if m != nil {
+		return m.LinkType
+	}
+	return IssueLink_LINK_TYPE_UNSPECIFIED
+}
+
+// Response message for [IssueService.ListIssueLinks][].
+type ListIssueLinksResponse struct {
+	// The list of [IssueLinks][IssueLink].
+	IssueLinks []*IssueLink `protobuf:"bytes,1,rep,name=issue_links,json=issueLinks,proto3" json:"issue_links,omitempty"`
+	// A token to retrieve the next page of results, or empty if there are no
+	// more results in the list. Pass this value in
+	// [ListIssueLinksRequest.page_token][] to retrieve the next page of
+	// results.
+	NextPageToken        string   `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListIssueLinksResponse) Reset()         { *m = ListIssueLinksResponse{} }
+func (m *ListIssueLinksResponse) String() string { return proto.CompactTextString(m) }
+func (*ListIssueLinksResponse) ProtoMessage()    {}
+func (*ListIssueLinksResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d4667488ad260932, []int{5}
+}
+
+func (m *ListIssueLinksResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListIssueLinksResponse.Unmarshal(m, b)
+}
+func (m *ListIssueLinksResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListIssueLinksResponse.Marshal(b, m, deterministic)
+}
+func (m *ListIssueLinksResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListIssueLinksResponse.Merge(m, src)
+}
+func (m *ListIssueLinksResponse) XXX_Size() int {
+	return xxx_messageInfo_ListIssueLinksResponse.Size(m)
+}
+func (m *ListIssueLinksResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListIssueLinksResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListIssueLinksResponse proto.InternalMessageInfo
+
+func (m *ListIssueLinksResponse) GetIssueLinks() []*IssueLink {
+	if m != nil {
+		return m.IssueLinks
+	}
+	return nil
+}
+
+func (m *ListIssueLinksResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
+// Request message for [IssueService.ListLabels][].
+type ListLabelsRequest struct {
+	// Required. The repository whose [Labels][Label] to list, in the format
+	// `owner/repository`. Either part may be the wildcard `-` (or `*`), e.g.
+	// `owner/-`, to list the [Labels][Label] of every tracked repository that
+	// matches, aggregated by name.
+	Parent string `protobuf:"bytes,1,opt,name=parent,proto3" json:"parent,omitempty"`
+	// Optional. Limit the number of [Labels][Label] to include in the
+	// response. Fewer labels than requested might be returned.
+	//
+	// The maximum page size is `500`. If unspecified, the page size will be the
+	// maximum.
+	PageSize int32 `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// Optional. To request the first page of results, `page_token` must be empty.
+	// To request the next page of results, page_token must be the value of
+	// [ListLabelsResponse.next_page_token][] returned by a previous call to
+	// [IssueService.ListLabels][].
+	//
+	// The page token is valid for only 2 hours.
+	PageToken            string   `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListLabelsRequest) Reset()         { *m = ListLabelsRequest{} }
+func (m *ListLabelsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListLabelsRequest) ProtoMessage()    {}
+func (*ListLabelsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d4667488ad260932, []int{6}
+}
+
+func (m *ListLabelsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListLabelsRequest.Unmarshal(m, b)
+}
+func (m *ListLabelsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListLabelsRequest.Marshal(b, m, deterministic)
+}
+func (m *ListLabelsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListLabelsRequest.Merge(m, src)
+}
+func (m *ListLabelsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListLabelsRequest.Size(m)
+}
+func (m *ListLabelsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListLabelsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListLabelsRequest proto.InternalMessageInfo
+
+func (m *ListLabelsRequest) GetParent() string {
+	if m != nil {
+		return m.Parent
+	}
+	return ""
+}
+
+func (m *ListLabelsRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *ListLabelsRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
+// Response message for [IssueService.ListLabels][].
+type ListLabelsResponse struct {
+	// The list of [Labels][Label], ordered by name.
+	Labels []*Label `protobuf:"bytes,1,rep,name=labels,proto3" json:"labels,omitempty"`
+	// A token to retrieve the next page of results, or empty if there are no
+	// more results in the list. Pass this value in
+	// [ListLabelsRequest.page_token][] to retrieve the next page of results.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	// The backends a router could not list labels from, when listing the
+	// [Labels][Label] of several repositories. Their labels are missing from
+	// the response.
+	UnreachableBackends  []*UnreachableBackend `protobuf:"bytes,3,rep,name=unreachable_backends,json=unreachableBackends,proto3" json:"unreachable_backends,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *ListLabelsResponse) Reset()         { *m = ListLabelsResponse{} }
+func (m *ListLabelsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListLabelsResponse) ProtoMessage()    {}
+func (*ListLabelsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d4667488ad260932, []int{7}
+}
+
+func (m *ListLabelsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListLabelsResponse.Unmarshal(m, b)
+}
+func (m *ListLabelsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListLabelsResponse.Marshal(b, m, deterministic)
+}
+func (m *ListLabelsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListLabelsResponse.Merge(m, src)
+}
+func (m *ListLabelsResponse) XXX_Size() int {
+	return xxx_messageInfo_ListLabelsResponse.Size(m)
+}
+func (m *ListLabelsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListLabelsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListLabelsResponse proto.InternalMessageInfo
+
+func (m *ListLabelsResponse) GetLabels() []*Label {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *ListLabelsResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
+func (m *ListLabelsResponse) GetUnreachableBackends() []*UnreachableBackend {
+	if m != nil {
+		return m.UnreachableBackends
+	}
+	return nil
+}
+
+// Request message for [IssueService.ListStaleIssues][].
+type ListStaleIssuesRequest struct {
+	// Required. The repository whose [Issues][Issue] to consider, in the
+	// format `owner/repository`.
+	Parent string `protobuf:"bytes,1,opt,name=parent,proto3" json:"parent,omitempty"`
+	// Optional. Limit the number of [StaleIssues][StaleIssue] to include in
+	// the response. Fewer than requested might be returned.
+	//
+	// The maximum page size is `500`. If unspecified, the page size will be the
+	// maximum.
+	PageSize int32 `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// Optional. To request the first page of results, `page_token` must be empty.
+	// To request the next page of results, page_token must be the value of
+	// [ListStaleIssuesResponse.next_page_token][] returned by a previous call to
+	// [IssueService.ListStaleIssues][].
+	//
+	// The page token is valid for only 2 hours.
+	PageToken string `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// Optional. The number of days without non-bot activity after which an
+	// open [Issue][] is `INACTIVE`. Defaults to 30.
+	InactiveDays int32 `protobuf:"varint,4,opt,name=inactive_days,json=inactiveDays,proto3" json:"inactive_days,omitempty"`
+	// Optional. The number of days a pull request with changes requested can
+	// go without a response from its author before it is `AWAITING_AUTHOR`.
+	// Defaults to 14.
+	AwaitingAuthorDays int32 `protobuf:"varint,5,opt,name=awaiting_author_days,json=awaitingAuthorDays,proto3" json:"awaiting_author_days,omitempty"`
+	// Optional. The number of days an [Issue][] labeled with one of
+	// `feedback_labels` can go without a response from its reporter before it
+	// is `AWAITING_REPORTER`. Defaults to 14.
+	AwaitingReporterDays int32 `protobuf:"varint,6,opt,name=awaiting_reporter_days,json=awaitingReporterDays,proto3" json:"awaiting_reporter_days,omitempty"`
+	// Optional. The labels, compared case-insensitively, that mark an
+	// [Issue][] as waiting on feedback from its reporter. Defaults to
+	// `needs more info`, `needs-more-info`, `awaiting information` and
+	// `status: awaiting information`.
+	FeedbackLabels []string `protobuf:"bytes,7,rep,name=feedback_labels,json=feedbackLabels,proto3" json:"feedback_labels,omitempty"`
+	// Optional. Logins whose activity does not count, in addition to those
+	// ending in `[bot]` or `-bot`.
+	BotLogins []string `protobuf:"bytes,8,rep,name=bot_logins,json=botLogins,proto3" json:"bot_logins,omitempty"`
+	// Optional. If set, only [Issues][Issue] flagged for this reason are
+	// returned.
+	Reason StaleIssue_Reason `protobuf:"varint,9,opt,name=reason,proto3,enum=drghs.v1.StaleIssue_Reason" json:"reason,omitempty"`
+	// If the FieldMask is NOT set or empty, all fields of the [Issues][Issue]
+	// are returned. If the FieldMask is set, only the specified fields are
+	// returned.
+	FieldMask            *field_mask.FieldMask `protobuf:"bytes,10,opt,name=field_mask,json=fieldMask,proto3" json:"field_mask,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *ListStaleIssuesRequest) Reset()         { *m = ListStaleIssuesRequest{} }
+func (m *ListStaleIssuesRequest) String() string { return proto.CompactTextString(m) }
+func (*ListStaleIssuesRequest) ProtoMessage()    {}
+func (*ListStaleIssuesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d4667488ad260932, []int{8}
+}
+
+func (m *ListStaleIssuesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListStaleIssuesRequest.Unmarshal(m, b)
+}
+func (m *ListStaleIssuesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListStaleIssuesRequest.Marshal(b, m, deterministic)
+}
+func (m *ListStaleIssuesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListStaleIssuesRequest.Merge(m, src)
+}
+func (m *ListStaleIssuesRequest) XXX_Size() int {
+	return xxx_messageInfo_ListStaleIssuesRequest.Size(m)
+}
+func (m *ListStaleIssuesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListStaleIssuesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListStaleIssuesRequest proto.InternalMessageInfo
+
+func (m *ListStaleIssuesRequest) GetParent() string {
+	if m != nil {
+		return m.Parent
+	}
+	return ""
+}
+
+func (m *ListStaleIssuesRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *ListStaleIssuesRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
+func (m *ListStaleIssuesRequest) GetInactiveDays() int32 {
+	if m != nil {
+		return m.InactiveDays
+	}
+	return 0
+}
+
+func (m *ListStaleIssuesRequest) GetAwaitingAuthorDays() int32 {
+	if m != nil {
+		return m.AwaitingAuthorDays
+	}
+	return 0
+}
+
+func (m *ListStaleIssuesRequest) GetAwaitingReporterDays() int32 {
+	if m != nil {
+		return m.AwaitingReporterDays
+	}
+	return 0
+}
+
+func (m *ListStaleIssuesRequest) GetFeedbackLabels() []string {
+	if m != nil {
+		return m.FeedbackLabels
+	}
+	return nil
+}
+
+func (m *ListStaleIssuesRequest) GetBotLogins() []string {
+	if m != nil {
+		return m.BotLogins
+	}
+	return nil
+}
+
+func (m *ListStaleIssuesRequest) GetReason() StaleIssue_Reason {
+	if m != nil {
+		return m.Reason
+	}
+	return StaleIssue_REASON_UNSPECIFIED
+}
+
+func (m *ListStaleIssuesRequest) GetFieldMask() *field_mask.FieldMask {
+	if m != nil {
+		return m.FieldMask
+	}
+	return nil
+}
+
+// Response message for [IssueService.ListStaleIssues][].
+type ListStaleIssuesResponse struct {
+	// The list of [StaleIssues][StaleIssue].
+	StaleIssues []*StaleIssue `protobuf:"bytes,1,rep,name=stale_issues,json=staleIssues,proto3" json:"stale_issues,omitempty"`
+	// A token to retrieve the next page of results, or empty if there are no
+	// more results in the list. Pass this value in
+	// [ListStaleIssuesRequest.page_token][] to retrieve the next page of
+	// results.
+	NextPageToken        string   `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListStaleIssuesResponse) Reset()         { *m = ListStaleIssuesResponse{} }
+func (m *ListStaleIssuesResponse) String() string { return proto.CompactTextString(m) }
+func (*ListStaleIssuesResponse) ProtoMessage()    {}
+func (*ListStaleIssuesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d4667488ad260932, []int{9}
+}
+
+func (m *ListStaleIssuesResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListStaleIssuesResponse.Unmarshal(m, b)
+}
+func (m *ListStaleIssuesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListStaleIssuesResponse.Marshal(b, m, deterministic)
+}
+func (m *ListStaleIssuesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListStaleIssuesResponse.Merge(m, src)
+}
+func (m *ListStaleIssuesResponse) XXX_Size() int {
+	return xxx_messageInfo_ListStaleIssuesResponse.Size(m)
+}
+func (m *ListStaleIssuesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListStaleIssuesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListStaleIssuesResponse proto.InternalMessageInfo
+
+func (m *ListStaleIssuesResponse) GetStaleIssues() []*StaleIssue {
+	if m != nil {
+		return m.StaleIssues
+	}
+	return nil
+}
+
+func (m *ListStaleIssuesResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*ListIssuesRequest)(nil), "drghs.v1.ListIssuesRequest")
 	proto.RegisterType((*ListIssuesResponse)(nil), "drghs.v1.ListIssuesResponse")
 	proto.RegisterType((*GetIssueRequest)(nil), "drghs.v1.GetIssueRequest")
 	proto.RegisterType((*GetIssueResponse)(nil), "drghs.v1.GetIssueResponse")
+	proto.RegisterType((*ListIssueLinksRequest)(nil), "drghs.v1.ListIssueLinksRequest")
+	proto.RegisterType((*ListIssueLinksResponse)(nil), "drghs.v1.ListIssueLinksResponse")
+	proto.RegisterType((*ListLabelsRequest)(nil), "drghs.v1.ListLabelsRequest")
+	proto.RegisterType((*ListLabelsResponse)(nil), "drghs.v1.ListLabelsResponse")
+	proto.RegisterType((*ListStaleIssuesRequest)(nil), "drghs.v1.ListStaleIssuesRequest")
+	proto.RegisterType((*ListStaleIssuesResponse)(nil), "drghs.v1.ListStaleIssuesResponse")
 }
 
 func init() {
@@ -406,49 +897,76 @@ func init() {
 }
 
 var fileDescriptor_d4667488ad260932 = []byte{
-	// 660 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0xcd, 0x6e, 0xd3, 0x40,
-	0x10, 0xc6, 0x6e, 0x93, 0x3a, 0xd3, 0x42, 0xda, 0x2d, 0xb4, 0xae, 0xdb, 0x4a, 0xc1, 0x85, 0x36,
-	0xea, 0xc1, 0x56, 0xc3, 0xa9, 0x07, 0x0e, 0xe4, 0xc0, 0x8f, 0x04, 0x12, 0x72, 0xcb, 0xd9, 0x72,
-	0xe2, 0x49, 0xb0, 0xe2, 0x78, 0xcd, 0xee, 0x3a, 0xa5, 0xad, 0x7a, 0xe1, 0x86, 0xc4, 0x0d, 0x89,
-	0x17, 0xe3, 0x15, 0x78, 0x00, 0x9e, 0x00, 0x21, 0xaf, 0xd7, 0xf9, 0x23, 0xad, 0xc4, 0xcd, 0x33,
-	0xdf, 0xec, 0x7c, 0xdf, 0x7e, 0x33, 0x6b, 0xd8, 0x8c, 0x38, 0xcf, 0xd0, 0xe7, 0xc8, 0x46, 0x51,
-	0x17, 0x9d, 0x94, 0x51, 0x41, 0x89, 0x11, 0xb2, 0xfe, 0x47, 0xee, 0x8c, 0x4e, 0xac, 0xbd, 0x3e,
-	0xa5, 0xfd, 0x18, 0xdd, 0x20, 0x8d, 0xdc, 0x20, 0x49, 0xa8, 0x08, 0x44, 0x44, 0x13, 0x5e, 0xd4,
-	0x59, 0x0d, 0x85, 0xca, 0xa8, 0x93, 0xf5, 0xdc, 0x5e, 0x84, 0x71, 0xe8, 0x0f, 0x03, 0x3e, 0x50,
-	0x15, 0x75, 0x86, 0x9c, 0x66, 0xac, 0x8b, 0xe5, 0x91, 0x6d, 0xc5, 0xe4, 0xcf, 0x03, 0x9b, 0x41,
-	0x38, 0x8c, 0x92, 0x59, 0x21, 0xf6, 0x1f, 0x1d, 0x36, 0xde, 0x46, 0x5c, 0xbc, 0xc9, 0x45, 0x72,
-	0x0f, 0x3f, 0x65, 0xc8, 0x05, 0xd9, 0x82, 0x6a, 0x1a, 0x30, 0x4c, 0x84, 0xa9, 0x35, 0xb4, 0x66,
-	0xcd, 0x53, 0x11, 0xd9, 0x85, 0x5a, 0x1a, 0xf4, 0xd1, 0xe7, 0xd1, 0x15, 0x9a, 0x7a, 0x43, 0x6b,
-	0x56, 0x3c, 0x23, 0x4f, 0x9c, 0x45, 0x57, 0x48, 0xf6, 0x01, 0x24, 0x28, 0xe8, 0x00, 0x13, 0x73,
-	0x49, 0x1e, 0x94, 0xe5, 0xe7, 0x79, 0x22, 0xef, 0xd9, 0x8b, 0x62, 0x81, 0xcc, 0x5c, 0x2e, 0x7a,
-	0x16, 0x11, 0xd9, 0x01, 0x83, 0xb2, 0x10, 0x99, 0xdf, 0xb9, 0x34, 0x2b, 0x12, 0x59, 0x91, 0x71,
-	0xfb, 0x92, 0x58, 0x60, 0x74, 0xe9, 0x70, 0x88, 0x89, 0xe0, 0x66, 0xb5, 0xa1, 0x35, 0x0d, 0x6f,
-	0x1c, 0x13, 0x13, 0x56, 0x18, 0x8e, 0x22, 0xbc, 0xe0, 0xe6, 0x8a, 0x84, 0xca, 0x90, 0x1c, 0xc1,
-	0x5a, 0x9a, 0xc5, 0xb1, 0xcf, 0x8a, 0xcb, 0x98, 0x46, 0x0e, 0xb7, 0x75, 0x53, 0x7b, 0x7d, 0xcf,
-	0x5b, 0xcd, 0x91, 0xf2, 0x96, 0x7b, 0x50, 0xed, 0xc6, 0x94, 0x63, 0x68, 0xd6, 0xc6, 0x25, 0x9a,
-	0xa7, 0x72, 0xe4, 0x14, 0x60, 0x62, 0xb6, 0x09, 0x0d, 0xad, 0xb9, 0xda, 0xb2, 0x9c, 0x62, 0x1e,
-	0x4e, 0x39, 0x0f, 0xe7, 0x65, 0x5e, 0xf2, 0x2e, 0xe0, 0x03, 0xaf, 0xd6, 0x2b, 0x3f, 0xdb, 0xdb,
-	0xf0, 0x68, 0x5a, 0x81, 0x9f, 0x64, 0x71, 0x1c, 0x74, 0x62, 0x6c, 0x6f, 0x40, 0xbd, 0xe8, 0x3e,
-	0x4e, 0xd9, 0xd7, 0x40, 0xa6, 0xfd, 0xe7, 0x29, 0x4d, 0x38, 0x92, 0x23, 0xa8, 0xca, 0xb5, 0xe1,
-	0xa6, 0xd6, 0x58, 0x6a, 0xae, 0xb6, 0xea, 0x4e, 0xb9, 0x30, 0x8e, 0xac, 0xf4, 0x14, 0x4c, 0x0e,
-	0xa1, 0x9e, 0xe0, 0x67, 0xe1, 0x4f, 0x39, 0xaf, 0x4b, 0x13, 0xef, 0xe7, 0xe9, 0xf7, 0x63, 0xf7,
-	0x1f, 0x42, 0x45, 0x50, 0x11, 0xc4, 0x72, 0x2e, 0x15, 0xaf, 0x08, 0xec, 0x1f, 0x1a, 0xd4, 0x5f,
-	0x61, 0x41, 0x5e, 0xba, 0x42, 0x60, 0x39, 0x09, 0x86, 0xa8, 0x26, 0x2f, 0xbf, 0x67, 0x06, 0xa1,
-	0xdf, 0x3e, 0x88, 0xa5, 0xd9, 0x41, 0xcc, 0x3a, 0xb8, 0xfc, 0x1f, 0x0e, 0xda, 0xa7, 0xb0, 0x3e,
-	0xd1, 0xa5, 0x3c, 0x79, 0x0a, 0x15, 0x79, 0x69, 0xa9, 0x6c, 0x81, 0x25, 0x05, 0xda, 0xfa, 0xad,
-	0xc3, 0x9a, 0x4c, 0x9c, 0x15, 0x8b, 0x4e, 0xbe, 0x6a, 0xb0, 0x9e, 0x5b, 0xec, 0x61, 0x4a, 0x79,
-	0x24, 0x28, 0x8b, 0x90, 0x93, 0xc7, 0x93, 0xd3, 0xf3, 0x98, 0x32, 0xc2, 0xb2, 0xef, 0x2a, 0x29,
-	0x34, 0xd9, 0xce, 0x97, 0x9f, 0xbf, 0xbe, 0xeb, 0x4d, 0x72, 0x28, 0xdf, 0xef, 0xe8, 0xc4, 0xbd,
-	0x2e, 0x5e, 0xca, 0x73, 0x7a, 0x91, 0x20, 0xe3, 0xee, 0xf1, 0x8d, 0xcb, 0xa6, 0x69, 0x63, 0x80,
-	0xc9, 0xb4, 0xc9, 0xee, 0x2c, 0xc3, 0xcc, 0x1b, 0xb4, 0xf6, 0x16, 0x83, 0x8a, 0xf8, 0x40, 0x12,
-	0xef, 0x93, 0xdd, 0x79, 0xe2, 0xe3, 0x9c, 0x53, 0x2d, 0x47, 0x0f, 0x8c, 0xd2, 0x45, 0xb2, 0x33,
-	0x69, 0x37, 0x37, 0x71, 0xcb, 0x5a, 0x04, 0xdd, 0xca, 0x93, 0x2f, 0x44, 0xce, 0xa2, 0x48, 0xdc,
-	0xe3, 0x9b, 0xd6, 0x37, 0x0d, 0x36, 0xa6, 0x2d, 0x7f, 0x91, 0xff, 0x68, 0xc8, 0x05, 0x90, 0x0f,
-	0x69, 0x18, 0x08, 0x3c, 0x67, 0x41, 0x77, 0x80, 0xa1, 0x34, 0x90, 0x1c, 0x4c, 0xc8, 0xfe, 0x45,
-	0x4b, 0x45, 0x4f, 0xee, 0x2e, 0x52, 0xda, 0xb6, 0xa4, 0xb6, 0x75, 0xfb, 0x41, 0xa9, 0x2d, 0x93,
-	0xb5, 0x9d, 0xaa, 0xdc, 0xad, 0x67, 0x7f, 0x03, 0x00, 0x00, 0xff, 0xff, 0x74, 0xc5, 0x57, 0xfb,
-	0x7a, 0x05, 0x00, 0x00,
+	// 1093 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x56, 0xcd, 0x4e, 0x23, 0x47,
+	0x10, 0xce, 0x18, 0x6c, 0xc6, 0x05, 0x8b, 0xa1, 0x61, 0x61, 0x18, 0x58, 0x61, 0x86, 0x64, 0xb1,
+	0x38, 0xd8, 0x59, 0xef, 0x4a, 0xab, 0x3d, 0xe4, 0xb0, 0x28, 0x4a, 0xb2, 0x12, 0x51, 0xa2, 0x81,
+	0x3d, 0x8f, 0xda, 0x9e, 0xb6, 0x69, 0x79, 0x3c, 0x33, 0xe9, 0x6e, 0x9b, 0x98, 0xcd, 0x4a, 0x51,
+	0x6e, 0x91, 0x72, 0xcb, 0x93, 0xe4, 0x92, 0x7b, 0x9e, 0x20, 0x87, 0x3c, 0x40, 0x2e, 0x79, 0x8e,
+	0x28, 0xea, 0x9f, 0xb1, 0xc7, 0xc6, 0x46, 0x44, 0x2b, 0x2e, 0xd6, 0x74, 0xd5, 0xd7, 0xfd, 0x55,
+	0x7d, 0x55, 0x5d, 0x6d, 0xd8, 0xa2, 0x9c, 0x0f, 0x48, 0xc0, 0x09, 0x1b, 0xd2, 0x36, 0xa9, 0xa7,
+	0x2c, 0x11, 0x09, 0xb2, 0x43, 0xd6, 0xbd, 0xe2, 0xf5, 0xe1, 0x33, 0xf7, 0xa0, 0x9b, 0x24, 0xdd,
+	0x88, 0x34, 0x70, 0x4a, 0x1b, 0x38, 0x8e, 0x13, 0x81, 0x05, 0x4d, 0x62, 0xae, 0x71, 0x6e, 0xd5,
+	0x78, 0xd5, 0xaa, 0x35, 0xe8, 0x34, 0x3a, 0x94, 0x44, 0x61, 0xd0, 0xc7, 0xbc, 0x67, 0x10, 0x87,
+	0xb3, 0x08, 0x41, 0xfb, 0x84, 0x0b, 0xdc, 0x4f, 0x0d, 0xa0, 0xc2, 0x08, 0x4f, 0x06, 0xac, 0x4d,
+	0xb2, 0x33, 0x77, 0x4d, 0x28, 0xc1, 0xac, 0x63, 0x0b, 0x87, 0x7d, 0x1a, 0x4f, 0x47, 0xea, 0xfd,
+	0x5b, 0x80, 0xcd, 0x73, 0xca, 0xc5, 0x1b, 0x99, 0x05, 0xf7, 0xc9, 0x77, 0x03, 0xc2, 0x05, 0xda,
+	0x81, 0x52, 0x8a, 0x19, 0x89, 0x85, 0x63, 0x55, 0xad, 0x5a, 0xd9, 0x37, 0x2b, 0xb4, 0x0f, 0xe5,
+	0x14, 0x77, 0x49, 0xc0, 0xe9, 0x0d, 0x71, 0x0a, 0x55, 0xab, 0x56, 0xf4, 0x6d, 0x69, 0xb8, 0xa0,
+	0x37, 0x04, 0x3d, 0x01, 0x50, 0x4e, 0x91, 0xf4, 0x48, 0xec, 0x2c, 0xa9, 0x8d, 0x0a, 0x7e, 0x29,
+	0x0d, 0xf2, 0xcc, 0x0e, 0x8d, 0x04, 0x61, 0xce, 0xb2, 0x3e, 0x53, 0xaf, 0xd0, 0x1e, 0xd8, 0x09,
+	0x0b, 0x09, 0x0b, 0x5a, 0x23, 0xa7, 0xa8, 0x3c, 0x2b, 0x6a, 0x7d, 0x36, 0x42, 0x2e, 0xd8, 0xed,
+	0xa4, 0xdf, 0x27, 0xb1, 0xe0, 0x4e, 0xa9, 0x6a, 0xd5, 0x6c, 0x7f, 0xbc, 0x46, 0x0e, 0xac, 0x30,
+	0x32, 0xa4, 0xe4, 0x9a, 0x3b, 0x2b, 0xca, 0x95, 0x2d, 0xd1, 0x09, 0xac, 0xa5, 0x83, 0x28, 0x0a,
+	0x98, 0x4e, 0xc6, 0xb1, 0xa5, 0xfb, 0xac, 0xe0, 0x58, 0x5f, 0x7d, 0xe4, 0xaf, 0x4a, 0x4f, 0x96,
+	0xe5, 0x01, 0x94, 0xda, 0x51, 0xc2, 0x49, 0xe8, 0x94, 0xc7, 0x10, 0xcb, 0x37, 0x36, 0xf4, 0x0a,
+	0x60, 0x52, 0x0d, 0x07, 0xaa, 0x56, 0x6d, 0xb5, 0xe9, 0xd6, 0x75, 0x39, 0xea, 0x59, 0x39, 0xea,
+	0x5f, 0x48, 0xc8, 0xd7, 0x98, 0xf7, 0xfc, 0x72, 0x27, 0xfb, 0x3c, 0xdb, 0x85, 0xc7, 0xf9, 0x08,
+	0x82, 0x78, 0x10, 0x45, 0xb8, 0x15, 0x91, 0xb3, 0x4d, 0xa8, 0xe8, 0xd3, 0xc7, 0x26, 0xef, 0x1d,
+	0xa0, 0xbc, 0xfe, 0x3c, 0x4d, 0x62, 0x4e, 0xd0, 0x09, 0x94, 0x54, 0x5f, 0x71, 0xc7, 0xaa, 0x2e,
+	0xd5, 0x56, 0x9b, 0x95, 0x7a, 0xd6, 0x51, 0x75, 0x85, 0xf4, 0x8d, 0x1b, 0x3d, 0x85, 0x4a, 0x4c,
+	0xbe, 0x17, 0x41, 0x4e, 0xf9, 0x82, 0x12, 0xf1, 0x91, 0x34, 0x7f, 0x3b, 0x56, 0x7f, 0x1b, 0x8a,
+	0x22, 0x11, 0x38, 0x52, 0x75, 0x29, 0xfa, 0x7a, 0xe1, 0xfd, 0x69, 0x41, 0xe5, 0x4b, 0xa2, 0xc9,
+	0x33, 0x55, 0x10, 0x2c, 0xc7, 0xb8, 0x4f, 0x4c, 0xe5, 0xd5, 0xf7, 0x54, 0x21, 0x0a, 0x8b, 0x0b,
+	0xb1, 0x34, 0x5d, 0x88, 0x69, 0x05, 0x97, 0xff, 0x87, 0x82, 0xe8, 0x25, 0x94, 0x19, 0xc1, 0x61,
+	0x20, 0xbb, 0xdd, 0x29, 0x2e, 0xd8, 0x79, 0x99, 0x5d, 0x05, 0xdf, 0x96, 0x60, 0xb9, 0xf4, 0x5e,
+	0xc1, 0xc6, 0x24, 0x21, 0x23, 0xe6, 0x27, 0x50, 0x54, 0x6a, 0xa9, 0x94, 0xe6, 0x68, 0xa9, 0xbd,
+	0xde, 0x1f, 0x16, 0x3c, 0x1e, 0x97, 0xe2, 0x9c, 0xc6, 0xbd, 0x07, 0xbd, 0x0e, 0x7b, 0x60, 0xeb,
+	0xc9, 0x41, 0x43, 0x25, 0x4d, 0xd1, 0x5f, 0x51, 0xeb, 0x37, 0xb2, 0xf3, 0xca, 0x11, 0x8d, 0x7b,
+	0x81, 0x18, 0xa5, 0x3a, 0xf9, 0xf5, 0xe6, 0xc1, 0x4c, 0xcc, 0x32, 0xbc, 0xba, 0xfc, 0xb9, 0x1c,
+	0xa5, 0xc4, 0xb7, 0x23, 0xf3, 0xe5, 0x0d, 0x61, 0x67, 0x36, 0x05, 0x23, 0xc2, 0x0b, 0x58, 0xd5,
+	0x7c, 0x12, 0x9b, 0xb5, 0xd5, 0xd6, 0x9c, 0x63, 0x7d, 0xa0, 0xe3, 0xdd, 0xf7, 0x6d, 0x2f, 0xaf,
+	0xab, 0xa7, 0xc8, 0x39, 0x6e, 0x91, 0xe8, 0x21, 0x65, 0xf3, 0x7e, 0xb7, 0x00, 0xe5, 0x99, 0x26,
+	0xf7, 0x25, 0x52, 0x96, 0xdb, 0xf7, 0x45, 0x21, 0x7d, 0xe3, 0xbe, 0xf7, 0x7d, 0xf9, 0x06, 0xb6,
+	0x07, 0x31, 0x23, 0xb8, 0x7d, 0x25, 0x6f, 0x69, 0xd0, 0xc2, 0xed, 0x1e, 0x89, 0x43, 0xd9, 0xe2,
+	0xf2, 0xf8, 0x5c, 0x39, 0xde, 0x4e, 0x50, 0x67, 0x1a, 0xe4, 0x6f, 0x0d, 0x6e, 0xd9, 0xb8, 0xf7,
+	0xdb, 0x92, 0x2e, 0xcd, 0x85, 0xc0, 0x11, 0x79, 0xf8, 0x69, 0x7b, 0x0c, 0x8f, 0x68, 0x8c, 0xdb,
+	0x82, 0x0e, 0x49, 0x10, 0xe2, 0x11, 0x37, 0x3d, 0xb6, 0x96, 0x19, 0x3f, 0xc7, 0x23, 0x8e, 0x3e,
+	0x85, 0x6d, 0x7c, 0x8d, 0xa9, 0xa0, 0x71, 0x37, 0xc0, 0x03, 0x71, 0x95, 0x30, 0x8d, 0x2d, 0x2a,
+	0x2c, 0xca, 0x7c, 0xaf, 0x95, 0x4b, 0xed, 0x78, 0x01, 0x3b, 0xe3, 0x1d, 0x8c, 0xa4, 0x09, 0x13,
+	0xc4, 0xec, 0x29, 0xa9, 0x3d, 0xe3, 0xf3, 0x7c, 0xe3, 0x54, 0xbb, 0x4e, 0xa0, 0xd2, 0x21, 0x24,
+	0x94, 0x22, 0x06, 0xa6, 0x4c, 0x2b, 0xd5, 0xa5, 0x5a, 0xd9, 0x5f, 0xcf, 0xcc, 0xba, 0x9c, 0x32,
+	0xa9, 0x56, 0x22, 0x82, 0x28, 0xe9, 0xd2, 0x98, 0x3b, 0xb6, 0xc2, 0x94, 0x5b, 0x89, 0x38, 0x57,
+	0x06, 0xf4, 0x1c, 0x4a, 0x8c, 0x60, 0x9e, 0xc4, 0x6a, 0x60, 0xaf, 0x37, 0xf7, 0x27, 0x65, 0x98,
+	0xc8, 0x5a, 0xf7, 0x15, 0xc4, 0x37, 0xd0, 0x0f, 0x98, 0xe3, 0xde, 0x0d, 0xec, 0xde, 0x2a, 0x99,
+	0x69, 0xb8, 0x97, 0xb0, 0xc6, 0xa5, 0x39, 0x98, 0x1a, 0xd3, 0xdb, 0xf3, 0x02, 0xf2, 0x57, 0xf9,
+	0xe4, 0x80, 0xfb, 0x36, 0x60, 0xf3, 0xef, 0x22, 0xac, 0xa9, 0x2d, 0x17, 0xfa, 0xbd, 0x46, 0x3f,
+	0x5b, 0xb0, 0x21, 0xa3, 0x91, 0xca, 0x72, 0x2a, 0x12, 0x46, 0x09, 0x47, 0x47, 0xb9, 0x3e, 0x9f,
+	0xf1, 0x99, 0xee, 0x72, 0xbd, 0xbb, 0x20, 0x3a, 0x1b, 0xaf, 0xfe, 0xd3, 0x5f, 0xff, 0xfc, 0x5a,
+	0xa8, 0xa1, 0xa7, 0xea, 0x7f, 0xca, 0xf0, 0x59, 0xe3, 0x9d, 0x6e, 0xc1, 0xcf, 0x92, 0xeb, 0x98,
+	0x30, 0xde, 0x38, 0x7d, 0xdf, 0x60, 0x79, 0xda, 0x08, 0x60, 0xf2, 0x68, 0xa1, 0xfd, 0x69, 0x86,
+	0xa9, 0xe6, 0x76, 0x0f, 0xe6, 0x3b, 0x0d, 0xf1, 0xb1, 0x22, 0x7e, 0x82, 0xf6, 0x67, 0x89, 0x4f,
+	0x25, 0xa7, 0x79, 0xe3, 0x3a, 0x60, 0x67, 0x33, 0x1d, 0xed, 0x4d, 0x8e, 0x9b, 0x79, 0xb8, 0x5c,
+	0x77, 0x9e, 0x6b, 0x21, 0x8f, 0x7c, 0xd7, 0x24, 0x8b, 0x21, 0x69, 0x9c, 0xbe, 0x47, 0x3f, 0xc0,
+	0xfa, 0xf4, 0xf0, 0x44, 0x87, 0x73, 0x82, 0xcf, 0xbf, 0x0c, 0x6e, 0x75, 0x31, 0xc0, 0x30, 0x9f,
+	0x28, 0xe6, 0x23, 0x74, 0xb8, 0x38, 0x43, 0xcd, 0x65, 0x34, 0x35, 0x37, 0x61, 0x46, 0xd3, 0xa9,
+	0xc1, 0xea, 0x1e, 0xcc, 0x77, 0xde, 0x4b, 0x53, 0x33, 0x07, 0x7f, 0xb4, 0xa0, 0x32, 0xd3, 0xdb,
+	0x68, 0x26, 0x99, 0xdb, 0x93, 0xca, 0x3d, 0xba, 0x03, 0x61, 0xd8, 0x6b, 0x8a, 0xdd, 0x43, 0xd5,
+	0xb9, 0xec, 0xb9, 0x9b, 0xd0, 0xfc, 0xc5, 0x82, 0xcd, 0x7c, 0x87, 0xbf, 0x96, 0x7f, 0x4f, 0xd1,
+	0x35, 0xa0, 0xb7, 0x69, 0x88, 0x05, 0xb9, 0x64, 0x72, 0x74, 0x86, 0xaa, 0x5f, 0xd1, 0x71, 0x6e,
+	0xe0, 0xde, 0xf2, 0x66, 0xd1, 0x7d, 0x7c, 0x37, 0xc8, 0x04, 0xb8, 0xa3, 0x02, 0xdc, 0xf0, 0xd6,
+	0xb3, 0x00, 0x07, 0x0a, 0xdb, 0x2a, 0xa9, 0x59, 0xf0, 0xfc, 0xbf, 0x01, 0x00, 0x50, 0xb7, 0xd7,
+	0x86, 0xd1, 0x0b, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -469,6 +987,14 @@ type IssueServiceClient interface {
 	ListIssues(ctx context.Context, in *ListIssuesRequest, opts ...grpc.CallOption) (*ListIssuesResponse, error)
 	// Gets a [Issue][].
 	GetIssue(ctx context.Context, in *GetIssueRequest, opts ...grpc.CallOption) (*GetIssueResponse, error)
+	// Lists the [IssueLinks][IssueLink] involving the [Issues][Issue] of a
+	// repository.
+	ListIssueLinks(ctx context.Context, in *ListIssueLinksRequest, opts ...grpc.CallOption) (*ListIssueLinksResponse, error)
+	// Lists the [Labels][Label] of a repository with their usage counts.
+	ListLabels(ctx context.Context, in *ListLabelsRequest, opts ...grpc.CallOption) (*ListLabelsResponse, error)
+	// Lists the open [Issues][Issue] and pull requests of a repository that
+	// are stale or abandoned.
+	ListStaleIssues(ctx context.Context, in *ListStaleIssuesRequest, opts ...grpc.CallOption) (*ListStaleIssuesResponse, error)
 }
 
 type issueServiceClient struct {
@@ -506,6 +1032,33 @@ func (c *issueServiceClient) GetIssue(ctx context.Context, in *GetIssueRequest,
 	return out, nil
 }
 
+func (c *issueServiceClient) ListIssueLinks(ctx context.Context, in *ListIssueLinksRequest, opts ...grpc.CallOption) (*ListIssueLinksResponse, error) {
+	out := new(ListIssueLinksResponse)
+	err := c.cc.Invoke(ctx, "/drghs.v1.IssueService/ListIssueLinks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *issueServiceClient) ListLabels(ctx context.Context, in *ListLabelsRequest, opts ...grpc.CallOption) (*ListLabelsResponse, error) {
+	out := new(ListLabelsResponse)
+	err := c.cc.Invoke(ctx, "/drghs.v1.IssueService/ListLabels", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *issueServiceClient) ListStaleIssues(ctx context.Context, in *ListStaleIssuesRequest, opts ...grpc.CallOption) (*ListStaleIssuesResponse, error) {
+	out := new(ListStaleIssuesResponse)
+	err := c.cc.Invoke(ctx, "/drghs.v1.IssueService/ListStaleIssues", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // IssueServiceServer is the server API for IssueService service.
 type IssueServiceServer interface {
 	// Lists [Repositories][Repository].
@@ -514,6 +1067,14 @@ type IssueServiceServer interface {
 	ListIssues(context.Context, *ListIssuesRequest) (*ListIssuesResponse, error)
 	// Gets a [Issue][].
 	GetIssue(context.Context, *GetIssueRequest) (*GetIssueResponse, error)
+	// Lists the [IssueLinks][IssueLink] involving the [Issues][Issue] of a
+	// repository.
+	ListIssueLinks(context.Context, *ListIssueLinksRequest) (*ListIssueLinksResponse, error)
+	// Lists the [Labels][Label] of a repository with their usage counts.
+	ListLabels(context.Context, *ListLabelsRequest) (*ListLabelsResponse, error)
+	// Lists the open [Issues][Issue] and pull requests of a repository that
+	// are stale or abandoned.
+	ListStaleIssues(context.Context, *ListStaleIssuesRequest) (*ListStaleIssuesResponse, error)
 }
 
 // UnimplementedIssueServiceServer can be embedded to have forward compatible implementations.
@@ -529,6 +1090,15 @@ func (*UnimplementedIssueServiceServer) ListIssues(ctx context.Context, req *Lis
 func (*UnimplementedIssueServiceServer) GetIssue(ctx context.Context, req *GetIssueRequest) (*GetIssueResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetIssue not implemented")
 }
+func (*UnimplementedIssueServiceServer) ListIssueLinks(ctx context.Context, req *ListIssueLinksRequest) (*ListIssueLinksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListIssueLinks not implemented")
+}
+func (*UnimplementedIssueServiceServer) ListLabels(ctx context.Context, req *ListLabelsRequest) (*ListLabelsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLabels not implemented")
+}
+func (*UnimplementedIssueServiceServer) ListStaleIssues(ctx context.Context, req *ListStaleIssuesRequest) (*ListStaleIssuesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListStaleIssues not implemented")
+}
 
 func RegisterIssueServiceServer(s *grpc.Server, srv IssueServiceServer) {
 	s.RegisterService(&_IssueService_serviceDesc, srv)
@@ -588,6 +1158,60 @@ func _IssueService_GetIssue_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _IssueService_ListIssueLinks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListIssueLinksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IssueServiceServer).ListIssueLinks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/drghs.v1.IssueService/ListIssueLinks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IssueServiceServer).ListIssueLinks(ctx, req.(*ListIssueLinksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IssueService_ListLabels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLabelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IssueServiceServer).ListLabels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/drghs.v1.IssueService/ListLabels",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IssueServiceServer).ListLabels(ctx, req.(*ListLabelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IssueService_ListStaleIssues_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListStaleIssuesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IssueServiceServer).ListStaleIssues(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/drghs.v1.IssueService/ListStaleIssues",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IssueServiceServer).ListStaleIssues(ctx, req.(*ListStaleIssuesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _IssueService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "drghs.v1.IssueService",
 	HandlerType: (*IssueServiceServer)(nil),
@@ -604,6 +1228,18 @@ var _IssueService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetIssue",
 			Handler:    _IssueService_GetIssue_Handler,
 		},
+		{
+			MethodName: "ListIssueLinks",
+			Handler:    _IssueService_ListIssueLinks_Handler,
+		},
+		{
+			MethodName: "ListLabels",
+			Handler:    _IssueService_ListLabels_Handler,
+		},
+		{
+			MethodName: "ListStaleIssues",
+			Handler:    _IssueService_ListStaleIssues_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "issue_service.proto",
@@ -157,6 +157,118 @@ func (Issue_IssueType) EnumDescriptor() ([]byte, []int) {
 	return file_resources_proto_rawDescGZIP(), []int{5, 1}
 }
 
+type IssueLink_LinkType int32
+
+const (
+	IssueLink_LINK_TYPE_UNSPECIFIED IssueLink_LinkType = 0
+	// The source pull request fixes the target [Issue][].
+	IssueLink_FIXES IssueLink_LinkType = 1
+	// The source [Issue][] mentions the target [Issue][].
+	IssueLink_MENTIONS IssueLink_LinkType = 2
+	// The source [Issue][] is blocked by the target [Issue][].
+	IssueLink_BLOCKED_BY IssueLink_LinkType = 3
+)
+
+// Enum value maps for IssueLink_LinkType.
+var (
+	IssueLink_LinkType_name = map[int32]string{
+		0: "LINK_TYPE_UNSPECIFIED",
+		1: "FIXES",
+		2: "MENTIONS",
+		3: "BLOCKED_BY",
+	}
+	IssueLink_LinkType_value = map[string]int32{
+		"LINK_TYPE_UNSPECIFIED": 0,
+		"FIXES":                 1,
+		"MENTIONS":              2,
+		"BLOCKED_BY":            3,
+	}
+)
+
+func (x IssueLink_LinkType) Enum() *IssueLink_LinkType {
+	p := new(IssueLink_LinkType)
+	*p = x
+	return p
+}
+
+func (x IssueLink_LinkType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (IssueLink_LinkType) Descriptor() protoreflect.EnumDescriptor {
+	return file_resources_proto_enumTypes[2].Descriptor()
+}
+
+func (IssueLink_LinkType) Type() protoreflect.EnumType {
+	return &file_resources_proto_enumTypes[2]
+}
+
+func (x IssueLink_LinkType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use IssueLink_LinkType.Descriptor instead.
+func (IssueLink_LinkType) EnumDescriptor() ([]byte, []int) {
+	return file_resources_proto_rawDescGZIP(), []int{6, 0}
+}
+
+type StaleIssue_Reason int32
+
+const (
+	StaleIssue_REASON_UNSPECIFIED StaleIssue_Reason = 0
+	// There has been no activity by anyone but bots for too long.
+	StaleIssue_INACTIVE StaleIssue_Reason = 1
+	// The pull request has changes requested and its author has not
+	// responded for too long.
+	StaleIssue_AWAITING_AUTHOR StaleIssue_Reason = 2
+	// The [Issue][] is labeled as waiting on feedback and its reporter has
+	// not responded for too long.
+	StaleIssue_AWAITING_REPORTER StaleIssue_Reason = 3
+)
+
+// Enum value maps for StaleIssue_Reason.
+var (
+	StaleIssue_Reason_name = map[int32]string{
+		0: "REASON_UNSPECIFIED",
+		1: "INACTIVE",
+		2: "AWAITING_AUTHOR",
+		3: "AWAITING_REPORTER",
+	}
+	StaleIssue_Reason_value = map[string]int32{
+		"REASON_UNSPECIFIED": 0,
+		"INACTIVE":           1,
+		"AWAITING_AUTHOR":    2,
+		"AWAITING_REPORTER":  3,
+	}
+)
+
+func (x StaleIssue_Reason) Enum() *StaleIssue_Reason {
+	p := new(StaleIssue_Reason)
+	*p = x
+	return p
+}
+
+func (x StaleIssue_Reason) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (StaleIssue_Reason) Descriptor() protoreflect.EnumDescriptor {
+	return file_resources_proto_enumTypes[3].Descriptor()
+}
+
+func (StaleIssue_Reason) Type() protoreflect.EnumType {
+	return &file_resources_proto_enumTypes[3]
+}
+
+func (x StaleIssue_Reason) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use StaleIssue_Reason.Descriptor instead.
+func (StaleIssue_Reason) EnumDescriptor() ([]byte, []int) {
+	return file_resources_proto_rawDescGZIP(), []int{8, 0}
+}
+
 type Repository struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -575,6 +687,16 @@ type Issue struct {
 	Repo            string               `protobuf:"bytes,23,opt,name=repo,proto3" json:"repo,omitempty"`
 	Blocked         bool                 `protobuf:"varint,24,opt,name=blocked,proto3" json:"blocked,omitempty"`
 	ReleaseBlocking bool                 `protobuf:"varint,25,opt,name=release_blocking,json=releaseBlocking,proto3" json:"release_blocking,omitempty"`
+	// Output only. The names of the pull requests that declare they fix this
+	// [Issue][], e.g. with "Fixes #N" in their description.
+	LinkedPullRequests []string `protobuf:"bytes,26,rep,name=linked_pull_requests,json=linkedPullRequests,proto3" json:"linked_pull_requests,omitempty"`
+	// Output only. The names of the [Issues][Issue] this [Issue][] is blocked
+	// by, parsed from "blocked by #N" in its body and comments.
+	BlockedBy []string `protobuf:"bytes,27,rep,name=blocked_by,json=blockedBy,proto3" json:"blocked_by,omitempty"`
+	// Output only. The name of the [Issue][] this [Issue][] was transferred to,
+	// in the format `owner/repository/issues/N`. Empty if it was not
+	// transferred. A transferred [Issue][] is reported closed.
+	TransferredTo string `protobuf:"bytes,28,opt,name=transferred_to,json=transferredTo,proto3" json:"transferred_to,omitempty"`
 }
 
 func (x *Issue) Reset() {
@@ -785,6 +907,293 @@ func (x *Issue) GetReleaseBlocking() bool {
 	return false
 }
 
+func (x *Issue) GetLinkedPullRequests() []string {
+	if x != nil {
+		return x.LinkedPullRequests
+	}
+	return nil
+}
+
+func (x *Issue) GetBlockedBy() []string {
+	if x != nil {
+		return x.BlockedBy
+	}
+	return nil
+}
+
+func (x *Issue) GetTransferredTo() string {
+	if x != nil {
+		return x.TransferredTo
+	}
+	return ""
+}
+
+// A directed link between two [Issues][Issue]. Both ends are named in the
+// format `owner/repository/issues/N`.
+type IssueLink struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Source   string             `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Target   string             `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`
+	LinkType IssueLink_LinkType `protobuf:"varint,3,opt,name=link_type,json=linkType,proto3,enum=drghs.v1.IssueLink_LinkType" json:"link_type,omitempty"`
+	// Whether the source is a merged pull request.
+	Merged bool `protobuf:"varint,4,opt,name=merged,proto3" json:"merged,omitempty"`
+}
+
+func (x *IssueLink) Reset() {
+	*x = IssueLink{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_resources_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IssueLink) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IssueLink) ProtoMessage() {}
+
+func (x *IssueLink) ProtoReflect() protoreflect.Message {
+	mi := &file_resources_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IssueLink.ProtoReflect.Descriptor instead.
+func (*IssueLink) Descriptor() ([]byte, []int) {
+	return file_resources_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *IssueLink) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *IssueLink) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *IssueLink) GetLinkType() IssueLink_LinkType {
+	if x != nil {
+		return x.LinkType
+	}
+	return IssueLink_LINK_TYPE_UNSPECIFIED
+}
+
+func (x *IssueLink) GetMerged() bool {
+	if x != nil {
+		return x.Merged
+	}
+	return false
+}
+
+// A label and how it is used by the [Issues][Issue] of one or more
+// repositories.
+type Label struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The name of the label, e.g. `type: bug`.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// The repositories the label is used in, in the format
+	// `owner/repository`. It has more than one entry only when the label was
+	// listed with a wildcard parent, in which case the counts and times below
+	// are aggregated across all of them.
+	Repositories []string `protobuf:"bytes,2,rep,name=repositories,proto3" json:"repositories,omitempty"`
+	// The label's color, as a hex RGB value without a leading `#`.
+	Color       string `protobuf:"bytes,3,opt,name=color,proto3" json:"color,omitempty"`
+	Description string `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	// The number of open [Issues][Issue] that currently carry the label.
+	OpenIssueCount int32 `protobuf:"varint,5,opt,name=open_issue_count,json=openIssueCount,proto3" json:"open_issue_count,omitempty"`
+	// The number of closed [Issues][Issue] that currently carry the label.
+	ClosedIssueCount int32 `protobuf:"varint,6,opt,name=closed_issue_count,json=closedIssueCount,proto3" json:"closed_issue_count,omitempty"`
+	// The number of open pull requests that currently carry the label.
+	OpenPullRequestCount int32 `protobuf:"varint,7,opt,name=open_pull_request_count,json=openPullRequestCount,proto3" json:"open_pull_request_count,omitempty"`
+	// The number of closed pull requests that currently carry the label.
+	ClosedPullRequestCount int32 `protobuf:"varint,8,opt,name=closed_pull_request_count,json=closedPullRequestCount,proto3" json:"closed_pull_request_count,omitempty"`
+	// When the label was first applied to an [Issue][] or pull request.
+	// Unset if it never was.
+	FirstUsedTime *timestamp.Timestamp `protobuf:"bytes,9,opt,name=first_used_time,json=firstUsedTime,proto3" json:"first_used_time,omitempty"`
+	// When the label was last applied to an [Issue][] or pull request.
+	// Unset if it never was.
+	LastUsedTime *timestamp.Timestamp `protobuf:"bytes,10,opt,name=last_used_time,json=lastUsedTime,proto3" json:"last_used_time,omitempty"`
+}
+
+func (x *Label) Reset() {
+	*x = Label{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_resources_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Label) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Label) ProtoMessage() {}
+
+func (x *Label) ProtoReflect() protoreflect.Message {
+	mi := &file_resources_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Label.ProtoReflect.Descriptor instead.
+func (*Label) Descriptor() ([]byte, []int) {
+	return file_resources_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Label) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Label) GetRepositories() []string {
+	if x != nil {
+		return x.Repositories
+	}
+	return nil
+}
+
+func (x *Label) GetColor() string {
+	if x != nil {
+		return x.Color
+	}
+	return ""
+}
+
+func (x *Label) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Label) GetOpenIssueCount() int32 {
+	if x != nil {
+		return x.OpenIssueCount
+	}
+	return 0
+}
+
+func (x *Label) GetClosedIssueCount() int32 {
+	if x != nil {
+		return x.ClosedIssueCount
+	}
+	return 0
+}
+
+func (x *Label) GetOpenPullRequestCount() int32 {
+	if x != nil {
+		return x.OpenPullRequestCount
+	}
+	return 0
+}
+
+func (x *Label) GetClosedPullRequestCount() int32 {
+	if x != nil {
+		return x.ClosedPullRequestCount
+	}
+	return 0
+}
+
+func (x *Label) GetFirstUsedTime() *timestamp.Timestamp {
+	if x != nil {
+		return x.FirstUsedTime
+	}
+	return nil
+}
+
+func (x *Label) GetLastUsedTime() *timestamp.Timestamp {
+	if x != nil {
+		return x.LastUsedTime
+	}
+	return nil
+}
+
+// An open [Issue][] or pull request flagged for grooming, with the reasons
+// it was flagged.
+type StaleIssue struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Issue *Issue             `protobuf:"bytes,1,opt,name=issue,proto3" json:"issue,omitempty"`
+	Flags []*StaleIssue_Flag `protobuf:"bytes,2,rep,name=flags,proto3" json:"flags,omitempty"`
+}
+
+func (x *StaleIssue) Reset() {
+	*x = StaleIssue{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_resources_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StaleIssue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StaleIssue) ProtoMessage() {}
+
+func (x *StaleIssue) ProtoReflect() protoreflect.Message {
+	mi := &file_resources_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StaleIssue.ProtoReflect.Descriptor instead.
+func (*StaleIssue) Descriptor() ([]byte, []int) {
+	return file_resources_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *StaleIssue) GetIssue() *Issue {
+	if x != nil {
+		return x.Issue
+	}
+	return nil
+}
+
+func (x *StaleIssue) GetFlags() []*StaleIssue_Flag {
+	if x != nil {
+		return x.Flags
+	}
+	return nil
+}
+
 type File struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -800,7 +1209,7 @@ type File struct {
 func (x *File) Reset() {
 	*x = File{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_resources_proto_msgTypes[6]
+		mi := &file_resources_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -813,7 +1222,7 @@ func (x *File) String() string {
 func (*File) ProtoMessage() {}
 
 func (x *File) ProtoReflect() protoreflect.Message {
-	mi := &file_resources_proto_msgTypes[6]
+	mi := &file_resources_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -826,7 +1235,7 @@ func (x *File) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use File.ProtoReflect.Descriptor instead.
 func (*File) Descriptor() ([]byte, []int) {
-	return file_resources_proto_rawDescGZIP(), []int{6}
+	return file_resources_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *File) GetFilepath() string {
@@ -865,7 +1274,7 @@ type SnippetVersionMeta struct {
 func (x *SnippetVersionMeta) Reset() {
 	*x = SnippetVersionMeta{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_resources_proto_msgTypes[7]
+		mi := &file_resources_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -878,7 +1287,7 @@ func (x *SnippetVersionMeta) String() string {
 func (*SnippetVersionMeta) ProtoMessage() {}
 
 func (x *SnippetVersionMeta) ProtoReflect() protoreflect.Message {
-	mi := &file_resources_proto_msgTypes[7]
+	mi := &file_resources_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -891,7 +1300,7 @@ func (x *SnippetVersionMeta) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SnippetVersionMeta.ProtoReflect.Descriptor instead.
 func (*SnippetVersionMeta) Descriptor() ([]byte, []int) {
-	return file_resources_proto_rawDescGZIP(), []int{7}
+	return file_resources_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *SnippetVersionMeta) GetTitle() string {
@@ -939,7 +1348,7 @@ type SnippetVersion struct {
 func (x *SnippetVersion) Reset() {
 	*x = SnippetVersion{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_resources_proto_msgTypes[8]
+		mi := &file_resources_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -952,7 +1361,7 @@ func (x *SnippetVersion) String() string {
 func (*SnippetVersion) ProtoMessage() {}
 
 func (x *SnippetVersion) ProtoReflect() protoreflect.Message {
-	mi := &file_resources_proto_msgTypes[8]
+	mi := &file_resources_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -965,7 +1374,7 @@ func (x *SnippetVersion) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SnippetVersion.ProtoReflect.Descriptor instead.
 func (*SnippetVersion) Descriptor() ([]byte, []int) {
-	return file_resources_proto_rawDescGZIP(), []int{8}
+	return file_resources_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *SnippetVersion) GetName() string {
@@ -1023,7 +1432,7 @@ type Snippet struct {
 func (x *Snippet) Reset() {
 	*x = Snippet{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_resources_proto_msgTypes[9]
+		mi := &file_resources_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1036,7 +1445,7 @@ func (x *Snippet) String() string {
 func (*Snippet) ProtoMessage() {}
 
 func (x *Snippet) ProtoReflect() protoreflect.Message {
-	mi := &file_resources_proto_msgTypes[9]
+	mi := &file_resources_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1049,7 +1458,7 @@ func (x *Snippet) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Snippet.ProtoReflect.Descriptor instead.
 func (*Snippet) Descriptor() ([]byte, []int) {
-	return file_resources_proto_rawDescGZIP(), []int{9}
+	return file_resources_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *Snippet) GetName() string {
@@ -1084,7 +1493,7 @@ type Owner struct {
 func (x *Owner) Reset() {
 	*x = Owner{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_resources_proto_msgTypes[10]
+		mi := &file_resources_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1097,7 +1506,7 @@ func (x *Owner) String() string {
 func (*Owner) ProtoMessage() {}
 
 func (x *Owner) ProtoReflect() protoreflect.Message {
-	mi := &file_resources_proto_msgTypes[10]
+	mi := &file_resources_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1110,7 +1519,7 @@ func (x *Owner) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Owner.ProtoReflect.Descriptor instead.
 func (*Owner) Descriptor() ([]byte, []int) {
-	return file_resources_proto_rawDescGZIP(), []int{10}
+	return file_resources_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *Owner) GetName() string {
@@ -1138,7 +1547,7 @@ type SLO struct {
 func (x *SLO) Reset() {
 	*x = SLO{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_resources_proto_msgTypes[11]
+		mi := &file_resources_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1151,7 +1560,7 @@ func (x *SLO) String() string {
 func (*SLO) ProtoMessage() {}
 
 func (x *SLO) ProtoReflect() protoreflect.Message {
-	mi := &file_resources_proto_msgTypes[11]
+	mi := &file_resources_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1164,7 +1573,7 @@ func (x *SLO) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SLO.ProtoReflect.Descriptor instead.
 func (*SLO) Descriptor() ([]byte, []int) {
-	return file_resources_proto_rawDescGZIP(), []int{11}
+	return file_resources_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *SLO) GetGithubLabels() []string {
@@ -1223,6 +1632,65 @@ func (x *SLO) GetResponders() []string {
 	return nil
 }
 
+// One reason an [Issue][] is stale.
+type StaleIssue_Flag struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Reason StaleIssue_Reason `protobuf:"varint,1,opt,name=reason,proto3,enum=drghs.v1.StaleIssue_Reason" json:"reason,omitempty"`
+	// When the condition began: the last non-bot activity for `INACTIVE`,
+	// the review requesting changes for `AWAITING_AUTHOR`, and the labeling
+	// for `AWAITING_REPORTER`.
+	Since *timestamp.Timestamp `protobuf:"bytes,2,opt,name=since,proto3" json:"since,omitempty"`
+}
+
+func (x *StaleIssue_Flag) Reset() {
+	*x = StaleIssue_Flag{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_resources_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StaleIssue_Flag) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StaleIssue_Flag) ProtoMessage() {}
+
+func (x *StaleIssue_Flag) ProtoReflect() protoreflect.Message {
+	mi := &file_resources_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StaleIssue_Flag.ProtoReflect.Descriptor instead.
+func (*StaleIssue_Flag) Descriptor() ([]byte, []int) {
+	return file_resources_proto_rawDescGZIP(), []int{8, 0}
+}
+
+func (x *StaleIssue_Flag) GetReason() StaleIssue_Reason {
+	if x != nil {
+		return x.Reason
+	}
+	return StaleIssue_REASON_UNSPECIFIED
+}
+
+func (x *StaleIssue_Flag) GetSince() *timestamp.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
 var File_resources_proto protoreflect.FileDescriptor
 
 var file_resources_proto_rawDesc = []byte{
@@ -1289,7 +1757,7 @@ var file_resources_proto_rawDesc = []byte{
 	0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x73,
 	0x74, 0x61, 0x74, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x5f, 0x69,
 	0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x49,
-	0x64, 0x22, 0x82, 0x09, 0x0a, 0x05, 0x49, 0x73, 0x73, 0x75, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e,
+	0x64, 0x22, 0xfa, 0x09, 0x0a, 0x05, 0x49, 0x73, 0x73, 0x75, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e,
 	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12,
 	0x14, 0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
 	0x74, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x18, 0x03, 0x20,
@@ -1349,78 +1817,148 @@ var file_resources_proto_rawDesc = []byte{
 	0x18, 0x18, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x12,
 	0x29, 0x0a, 0x10, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b,
 	0x69, 0x6e, 0x67, 0x18, 0x19, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x72, 0x65, 0x6c, 0x65, 0x61,
-	0x73, 0x65, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x69, 0x6e, 0x67, 0x22, 0x4c, 0x0a, 0x08, 0x50, 0x72,
-	0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x18, 0x0a, 0x14, 0x50, 0x52, 0x49, 0x4f, 0x52, 0x49,
-	0x54, 0x59, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00,
-	0x12, 0x06, 0x0a, 0x02, 0x50, 0x30, 0x10, 0x01, 0x12, 0x06, 0x0a, 0x02, 0x50, 0x31, 0x10, 0x02,
-	0x12, 0x06, 0x0a, 0x02, 0x50, 0x32, 0x10, 0x03, 0x12, 0x06, 0x0a, 0x02, 0x50, 0x33, 0x10, 0x04,
-	0x12, 0x06, 0x0a, 0x02, 0x50, 0x34, 0x10, 0x05, 0x22, 0x6c, 0x0a, 0x09, 0x49, 0x73, 0x73, 0x75,
-	0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x21, 0x0a, 0x1d, 0x47, 0x49, 0x54, 0x48, 0x55, 0x42, 0x5f,
-	0x49, 0x53, 0x53, 0x55, 0x45, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45,
-	0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x07, 0x0a, 0x03, 0x42, 0x55, 0x47, 0x10,
-	0x01, 0x12, 0x0b, 0x0a, 0x07, 0x46, 0x45, 0x41, 0x54, 0x55, 0x52, 0x45, 0x10, 0x02, 0x12, 0x0c,
-	0x0a, 0x08, 0x51, 0x55, 0x45, 0x53, 0x54, 0x49, 0x4f, 0x4e, 0x10, 0x03, 0x12, 0x0b, 0x0a, 0x07,
-	0x43, 0x4c, 0x45, 0x41, 0x4e, 0x55, 0x50, 0x10, 0x04, 0x12, 0x0b, 0x0a, 0x07, 0x50, 0x52, 0x4f,
-	0x43, 0x45, 0x53, 0x53, 0x10, 0x05, 0x22, 0x6a, 0x0a, 0x04, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x1a,
-	0x0a, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x70, 0x61, 0x74, 0x68, 0x12, 0x32, 0x0a, 0x0a, 0x67, 0x69,
-	0x74, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13,
-	0x2e, 0x64, 0x72, 0x67, 0x68, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x69, 0x74, 0x43, 0x6f, 0x6d,
-	0x6d, 0x69, 0x74, 0x52, 0x09, 0x67, 0x69, 0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x12, 0x12,
-	0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x73, 0x69,
-	0x7a, 0x65, 0x22, 0x83, 0x01, 0x0a, 0x12, 0x53, 0x6e, 0x69, 0x70, 0x70, 0x65, 0x74, 0x56, 0x65,
-	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x4d, 0x65, 0x74, 0x61, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x69, 0x74,
-	0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x12,
-	0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f,
-	0x6e, 0x12, 0x14, 0x0a, 0x05, 0x75, 0x73, 0x61, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x05, 0x75, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x61, 0x70, 0x69, 0x5f, 0x76,
-	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x61, 0x70,
-	0x69, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0xaa, 0x01, 0x0a, 0x0e, 0x53, 0x6e, 0x69,
-	0x70, 0x70, 0x65, 0x74, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x6e,
-	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12,
-	0x22, 0x0a, 0x04, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e,
-	0x64, 0x72, 0x67, 0x68, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x04, 0x66,
-	0x69, 0x6c, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03,
-	0x28, 0x09, 0x52, 0x05, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6e,
-	0x74, 0x65, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74,
-	0x65, 0x6e, 0x74, 0x12, 0x30, 0x0a, 0x04, 0x6d, 0x65, 0x74, 0x61, 0x18, 0x05, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x1c, 0x2e, 0x64, 0x72, 0x67, 0x68, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x6e, 0x69,
-	0x70, 0x70, 0x65, 0x74, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x4d, 0x65, 0x74, 0x61, 0x52,
-	0x04, 0x6d, 0x65, 0x74, 0x61, 0x22, 0x6d, 0x0a, 0x07, 0x53, 0x6e, 0x69, 0x70, 0x70, 0x65, 0x74,
+	0x73, 0x65, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x69, 0x6e, 0x67, 0x12, 0x30, 0x0a, 0x14, 0x6c, 0x69,
+	0x6e, 0x6b, 0x65, 0x64, 0x5f, 0x70, 0x75, 0x6c, 0x6c, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x73, 0x18, 0x1a, 0x20, 0x03, 0x28, 0x09, 0x52, 0x12, 0x6c, 0x69, 0x6e, 0x6b, 0x65, 0x64,
+	0x50, 0x75, 0x6c, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x12, 0x1d, 0x0a, 0x0a,
+	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x5f, 0x62, 0x79, 0x18, 0x1b, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x09, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x42, 0x79, 0x12, 0x25, 0x0a, 0x0e, 0x74,
+	0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x72, 0x65, 0x64, 0x5f, 0x74, 0x6f, 0x18, 0x1c, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0d, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x72, 0x65, 0x64,
+	0x54, 0x6f, 0x22, 0x4c, 0x0a, 0x08, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x18,
+	0x0a, 0x14, 0x50, 0x52, 0x49, 0x4f, 0x52, 0x49, 0x54, 0x59, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45,
+	0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x06, 0x0a, 0x02, 0x50, 0x30, 0x10, 0x01,
+	0x12, 0x06, 0x0a, 0x02, 0x50, 0x31, 0x10, 0x02, 0x12, 0x06, 0x0a, 0x02, 0x50, 0x32, 0x10, 0x03,
+	0x12, 0x06, 0x0a, 0x02, 0x50, 0x33, 0x10, 0x04, 0x12, 0x06, 0x0a, 0x02, 0x50, 0x34, 0x10, 0x05,
+	0x22, 0x6c, 0x0a, 0x09, 0x49, 0x73, 0x73, 0x75, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x21, 0x0a,
+	0x1d, 0x47, 0x49, 0x54, 0x48, 0x55, 0x42, 0x5f, 0x49, 0x53, 0x53, 0x55, 0x45, 0x5f, 0x54, 0x59,
+	0x50, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00,
+	0x12, 0x07, 0x0a, 0x03, 0x42, 0x55, 0x47, 0x10, 0x01, 0x12, 0x0b, 0x0a, 0x07, 0x46, 0x45, 0x41,
+	0x54, 0x55, 0x52, 0x45, 0x10, 0x02, 0x12, 0x0c, 0x0a, 0x08, 0x51, 0x55, 0x45, 0x53, 0x54, 0x49,
+	0x4f, 0x4e, 0x10, 0x03, 0x12, 0x0b, 0x0a, 0x07, 0x43, 0x4c, 0x45, 0x41, 0x4e, 0x55, 0x50, 0x10,
+	0x04, 0x12, 0x0b, 0x0a, 0x07, 0x50, 0x52, 0x4f, 0x43, 0x45, 0x53, 0x53, 0x10, 0x05, 0x22, 0xde,
+	0x01, 0x0a, 0x09, 0x49, 0x73, 0x73, 0x75, 0x65, 0x4c, 0x69, 0x6e, 0x6b, 0x12, 0x16, 0x0a, 0x06,
+	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x6f,
+	0x75, 0x72, 0x63, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x12, 0x39, 0x0a, 0x09,
+	0x6c, 0x69, 0x6e, 0x6b, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x1c, 0x2e, 0x64, 0x72, 0x67, 0x68, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x73, 0x73, 0x75, 0x65,
+	0x4c, 0x69, 0x6e, 0x6b, 0x2e, 0x4c, 0x69, 0x6e, 0x6b, 0x54, 0x79, 0x70, 0x65, 0x52, 0x08, 0x6c,
+	0x69, 0x6e, 0x6b, 0x54, 0x79, 0x70, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65, 0x72, 0x67, 0x65,
+	0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x6d, 0x65, 0x72, 0x67, 0x65, 0x64, 0x22,
+	0x4e, 0x0a, 0x08, 0x4c, 0x69, 0x6e, 0x6b, 0x54, 0x79, 0x70, 0x65, 0x12, 0x19, 0x0a, 0x15, 0x4c,
+	0x49, 0x4e, 0x4b, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49,
+	0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x09, 0x0a, 0x05, 0x46, 0x49, 0x58, 0x45, 0x53, 0x10,
+	0x01, 0x12, 0x0c, 0x0a, 0x08, 0x4d, 0x45, 0x4e, 0x54, 0x49, 0x4f, 0x4e, 0x53, 0x10, 0x02, 0x12,
+	0x0e, 0x0a, 0x0a, 0x42, 0x4c, 0x4f, 0x43, 0x4b, 0x45, 0x44, 0x5f, 0x42, 0x59, 0x10, 0x03, 0x22,
+	0xc7, 0x03, 0x0a, 0x05, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x22, 0x0a,
+	0x0c, 0x72, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x6f, 0x72, 0x69, 0x65, 0x73, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x0c, 0x72, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x6f, 0x72, 0x69, 0x65,
+	0x73, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x6c, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x63, 0x6f, 0x6c, 0x6f, 0x72, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72,
+	0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65,
+	0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x28, 0x0a, 0x10, 0x6f, 0x70, 0x65,
+	0x6e, 0x5f, 0x69, 0x73, 0x73, 0x75, 0x65, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x0e, 0x6f, 0x70, 0x65, 0x6e, 0x49, 0x73, 0x73, 0x75, 0x65, 0x43, 0x6f,
+	0x75, 0x6e, 0x74, 0x12, 0x2c, 0x0a, 0x12, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x64, 0x5f, 0x69, 0x73,
+	0x73, 0x75, 0x65, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x10, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x64, 0x49, 0x73, 0x73, 0x75, 0x65, 0x43, 0x6f, 0x75, 0x6e,
+	0x74, 0x12, 0x35, 0x0a, 0x17, 0x6f, 0x70, 0x65, 0x6e, 0x5f, 0x70, 0x75, 0x6c, 0x6c, 0x5f, 0x72,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x14, 0x6f, 0x70, 0x65, 0x6e, 0x50, 0x75, 0x6c, 0x6c, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x39, 0x0a, 0x19, 0x63, 0x6c, 0x6f, 0x73,
+	0x65, 0x64, 0x5f, 0x70, 0x75, 0x6c, 0x6c, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f,
+	0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x52, 0x16, 0x63, 0x6c, 0x6f,
+	0x73, 0x65, 0x64, 0x50, 0x75, 0x6c, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x43, 0x6f,
+	0x75, 0x6e, 0x74, 0x12, 0x42, 0x0a, 0x0f, 0x66, 0x69, 0x72, 0x73, 0x74, 0x5f, 0x75, 0x73, 0x65,
+	0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0d, 0x66, 0x69, 0x72, 0x73, 0x74, 0x55,
+	0x73, 0x65, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x40, 0x0a, 0x0e, 0x6c, 0x61, 0x73, 0x74, 0x5f,
+	0x75, 0x73, 0x65, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0c, 0x6c, 0x61, 0x73,
+	0x74, 0x55, 0x73, 0x65, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x22, 0xaf, 0x02, 0x0a, 0x0a, 0x53, 0x74,
+	0x61, 0x6c, 0x65, 0x49, 0x73, 0x73, 0x75, 0x65, 0x12, 0x25, 0x0a, 0x05, 0x69, 0x73, 0x73, 0x75,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x64, 0x72, 0x67, 0x68, 0x73, 0x2e,
+	0x76, 0x31, 0x2e, 0x49, 0x73, 0x73, 0x75, 0x65, 0x52, 0x05, 0x69, 0x73, 0x73, 0x75, 0x65, 0x12,
+	0x2f, 0x0a, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19,
+	0x2e, 0x64, 0x72, 0x67, 0x68, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x61, 0x6c, 0x65, 0x49,
+	0x73, 0x73, 0x75, 0x65, 0x2e, 0x46, 0x6c, 0x61, 0x67, 0x52, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73,
+	0x1a, 0x6d, 0x0a, 0x04, 0x46, 0x6c, 0x61, 0x67, 0x12, 0x33, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73,
+	0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1b, 0x2e, 0x64, 0x72, 0x67, 0x68, 0x73,
+	0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x61, 0x6c, 0x65, 0x49, 0x73, 0x73, 0x75, 0x65, 0x2e, 0x52,
+	0x65, 0x61, 0x73, 0x6f, 0x6e, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x30, 0x0a,
+	0x05, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x05, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x22,
+	0x5a, 0x0a, 0x06, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x12, 0x52, 0x45, 0x41,
+	0x53, 0x4f, 0x4e, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10,
+	0x00, 0x12, 0x0c, 0x0a, 0x08, 0x49, 0x4e, 0x41, 0x43, 0x54, 0x49, 0x56, 0x45, 0x10, 0x01, 0x12,
+	0x13, 0x0a, 0x0f, 0x41, 0x57, 0x41, 0x49, 0x54, 0x49, 0x4e, 0x47, 0x5f, 0x41, 0x55, 0x54, 0x48,
+	0x4f, 0x52, 0x10, 0x02, 0x12, 0x15, 0x0a, 0x11, 0x41, 0x57, 0x41, 0x49, 0x54, 0x49, 0x4e, 0x47,
+	0x5f, 0x52, 0x45, 0x50, 0x4f, 0x52, 0x54, 0x45, 0x52, 0x10, 0x03, 0x22, 0x6a, 0x0a, 0x04, 0x46,
+	0x69, 0x6c, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x70, 0x61, 0x74, 0x68, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x70, 0x61, 0x74, 0x68, 0x12,
+	0x32, 0x0a, 0x0a, 0x67, 0x69, 0x74, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x64, 0x72, 0x67, 0x68, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47,
+	0x69, 0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x52, 0x09, 0x67, 0x69, 0x74, 0x43, 0x6f, 0x6d,
+	0x6d, 0x69, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x22, 0x83, 0x01, 0x0a, 0x12, 0x53, 0x6e, 0x69, 0x70,
+	0x70, 0x65, 0x74, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x4d, 0x65, 0x74, 0x61, 0x12, 0x14,
+	0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74,
+	0x69, 0x74, 0x6c, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72,
+	0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x75, 0x73, 0x61, 0x67, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x75, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1f, 0x0a, 0x0b,
+	0x61, 0x70, 0x69, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0a, 0x61, 0x70, 0x69, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0xaa, 0x01,
+	0x0a, 0x0e, 0x53, 0x6e, 0x69, 0x70, 0x70, 0x65, 0x74, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
 	0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
-	0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65,
-	0x12, 0x32, 0x0a, 0x07, 0x70, 0x72, 0x69, 0x6d, 0x61, 0x72, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x18, 0x2e, 0x64, 0x72, 0x67, 0x68, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x6e, 0x69,
-	0x70, 0x70, 0x65, 0x74, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x70, 0x72, 0x69,
-	0x6d, 0x61, 0x72, 0x79, 0x22, 0x1b, 0x0a, 0x05, 0x4f, 0x77, 0x6e, 0x65, 0x72, 0x12, 0x12, 0x0a,
-	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d,
-	0x65, 0x22, 0x83, 0x03, 0x0a, 0x03, 0x53, 0x4c, 0x4f, 0x12, 0x23, 0x0a, 0x0d, 0x67, 0x69, 0x74,
-	0x68, 0x75, 0x62, 0x5f, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09,
-	0x52, 0x0c, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x12, 0x34,
-	0x0a, 0x16, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x64, 0x5f, 0x67, 0x69, 0x74, 0x68, 0x75,
-	0x62, 0x5f, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x14,
-	0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x64, 0x47, 0x69, 0x74, 0x68, 0x75, 0x62, 0x4c, 0x61,
-	0x62, 0x65, 0x6c, 0x73, 0x12, 0x2a, 0x0a, 0x11, 0x61, 0x70, 0x70, 0x6c, 0x69, 0x65, 0x73, 0x5f,
-	0x74, 0x6f, 0x5f, 0x69, 0x73, 0x73, 0x75, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52,
-	0x0f, 0x61, 0x70, 0x70, 0x6c, 0x69, 0x65, 0x73, 0x54, 0x6f, 0x49, 0x73, 0x73, 0x75, 0x65, 0x73,
-	0x12, 0x24, 0x0a, 0x0e, 0x61, 0x70, 0x70, 0x6c, 0x69, 0x65, 0x73, 0x5f, 0x74, 0x6f, 0x5f, 0x70,
-	0x72, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x61, 0x70, 0x70, 0x6c, 0x69, 0x65,
-	0x73, 0x54, 0x6f, 0x50, 0x72, 0x73, 0x12, 0x3e, 0x0a, 0x0d, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e,
-	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
-	0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0c, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x42, 0x0a, 0x0f, 0x72, 0x65, 0x73, 0x6f, 0x6c, 0x75,
-	0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
-	0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0e, 0x72, 0x65, 0x73, 0x6f,
-	0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x2b, 0x0a, 0x11, 0x72, 0x65,
-	0x71, 0x75, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x73, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x65, 0x18,
-	0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x10, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x73, 0x41,
-	0x73, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x72, 0x65, 0x73, 0x70, 0x6f,
-	0x6e, 0x64, 0x65, 0x72, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x72, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x64, 0x65, 0x72, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x6e, 0x61, 0x6d, 0x65, 0x12, 0x22, 0x0a, 0x04, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x64, 0x72, 0x67, 0x68, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69,
+	0x6c, 0x65, 0x52, 0x04, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6e, 0x65,
+	0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x12, 0x18,
+	0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x12, 0x30, 0x0a, 0x04, 0x6d, 0x65, 0x74, 0x61,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x64, 0x72, 0x67, 0x68, 0x73, 0x2e, 0x76,
+	0x31, 0x2e, 0x53, 0x6e, 0x69, 0x70, 0x70, 0x65, 0x74, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x4d, 0x65, 0x74, 0x61, 0x52, 0x04, 0x6d, 0x65, 0x74, 0x61, 0x22, 0x6d, 0x0a, 0x07, 0x53, 0x6e,
+	0x69, 0x70, 0x70, 0x65, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x61, 0x6e,
+	0x67, 0x75, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6c, 0x61, 0x6e,
+	0x67, 0x75, 0x61, 0x67, 0x65, 0x12, 0x32, 0x0a, 0x07, 0x70, 0x72, 0x69, 0x6d, 0x61, 0x72, 0x79,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x64, 0x72, 0x67, 0x68, 0x73, 0x2e, 0x76,
+	0x31, 0x2e, 0x53, 0x6e, 0x69, 0x70, 0x70, 0x65, 0x74, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x52, 0x07, 0x70, 0x72, 0x69, 0x6d, 0x61, 0x72, 0x79, 0x22, 0x1b, 0x0a, 0x05, 0x4f, 0x77, 0x6e,
+	0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0x83, 0x03, 0x0a, 0x03, 0x53, 0x4c, 0x4f, 0x12, 0x23,
+	0x0a, 0x0d, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x5f, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x4c, 0x61, 0x62,
+	0x65, 0x6c, 0x73, 0x12, 0x34, 0x0a, 0x16, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x64, 0x5f,
+	0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x5f, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x14, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x64, 0x47, 0x69, 0x74,
+	0x68, 0x75, 0x62, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x12, 0x2a, 0x0a, 0x11, 0x61, 0x70, 0x70,
+	0x6c, 0x69, 0x65, 0x73, 0x5f, 0x74, 0x6f, 0x5f, 0x69, 0x73, 0x73, 0x75, 0x65, 0x73, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x61, 0x70, 0x70, 0x6c, 0x69, 0x65, 0x73, 0x54, 0x6f, 0x49,
+	0x73, 0x73, 0x75, 0x65, 0x73, 0x12, 0x24, 0x0a, 0x0e, 0x61, 0x70, 0x70, 0x6c, 0x69, 0x65, 0x73,
+	0x5f, 0x74, 0x6f, 0x5f, 0x70, 0x72, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x61,
+	0x70, 0x70, 0x6c, 0x69, 0x65, 0x73, 0x54, 0x6f, 0x50, 0x72, 0x73, 0x12, 0x3e, 0x0a, 0x0d, 0x72,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0c, 0x72,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x42, 0x0a, 0x0f, 0x72,
+	0x65, 0x73, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x0e, 0x72, 0x65, 0x73, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x12,
+	0x2b, 0x0a, 0x11, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x73, 0x73, 0x69,
+	0x67, 0x6e, 0x65, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x10, 0x72, 0x65, 0x71, 0x75,
+	0x69, 0x72, 0x65, 0x73, 0x41, 0x73, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x65, 0x12, 0x1e, 0x0a, 0x0a,
+	0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x64, 0x65, 0x72, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x0a, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x64, 0x65, 0x72, 0x73, 0x62, 0x06, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1435,56 +1973,69 @@ func file_resources_proto_rawDescGZIP() []byte {
 	return file_resources_proto_rawDescData
 }
 
-var file_resources_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_resources_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_resources_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_resources_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
 var file_resources_proto_goTypes = []interface{}{
 	(Issue_Priority)(0),         // 0: drghs.v1.Issue.Priority
 	(Issue_IssueType)(0),        // 1: drghs.v1.Issue.IssueType
-	(*Repository)(nil),          // 2: drghs.v1.Repository
-	(*GitCommit)(nil),           // 3: drghs.v1.GitCommit
-	(*GitHubUser)(nil),          // 4: drghs.v1.GitHubUser
-	(*GitHubComment)(nil),       // 5: drghs.v1.GitHubComment
-	(*GitHubReview)(nil),        // 6: drghs.v1.GitHubReview
-	(*Issue)(nil),               // 7: drghs.v1.Issue
-	(*File)(nil),                // 8: drghs.v1.File
-	(*SnippetVersionMeta)(nil),  // 9: drghs.v1.SnippetVersionMeta
-	(*SnippetVersion)(nil),      // 10: drghs.v1.SnippetVersion
-	(*Snippet)(nil),             // 11: drghs.v1.Snippet
-	(*Owner)(nil),               // 12: drghs.v1.Owner
-	(*SLO)(nil),                 // 13: drghs.v1.SLO
-	(*timestamp.Timestamp)(nil), // 14: google.protobuf.Timestamp
-	(*duration.Duration)(nil),   // 15: google.protobuf.Duration
+	(IssueLink_LinkType)(0),     // 2: drghs.v1.IssueLink.LinkType
+	(StaleIssue_Reason)(0),      // 3: drghs.v1.StaleIssue.Reason
+	(*Repository)(nil),          // 4: drghs.v1.Repository
+	(*GitCommit)(nil),           // 5: drghs.v1.GitCommit
+	(*GitHubUser)(nil),          // 6: drghs.v1.GitHubUser
+	(*GitHubComment)(nil),       // 7: drghs.v1.GitHubComment
+	(*GitHubReview)(nil),        // 8: drghs.v1.GitHubReview
+	(*Issue)(nil),               // 9: drghs.v1.Issue
+	(*IssueLink)(nil),           // 10: drghs.v1.IssueLink
+	(*Label)(nil),               // 11: drghs.v1.Label
+	(*StaleIssue)(nil),          // 12: drghs.v1.StaleIssue
+	(*File)(nil),                // 13: drghs.v1.File
+	(*SnippetVersionMeta)(nil),  // 14: drghs.v1.SnippetVersionMeta
+	(*SnippetVersion)(nil),      // 15: drghs.v1.SnippetVersion
+	(*Snippet)(nil),             // 16: drghs.v1.Snippet
+	(*Owner)(nil),               // 17: drghs.v1.Owner
+	(*SLO)(nil),                 // 18: drghs.v1.SLO
+	(*StaleIssue_Flag)(nil),     // 19: drghs.v1.StaleIssue.Flag
+	(*timestamp.Timestamp)(nil), // 20: google.protobuf.Timestamp
+	(*duration.Duration)(nil),   // 21: google.protobuf.Duration
 }
 var file_resources_proto_depIdxs = []int32{
-	14, // 0: drghs.v1.GitCommit.authored_time:type_name -> google.protobuf.Timestamp
-	14, // 1: drghs.v1.GitCommit.committed_time:type_name -> google.protobuf.Timestamp
-	4,  // 2: drghs.v1.GitHubComment.user:type_name -> drghs.v1.GitHubUser
-	14, // 3: drghs.v1.GitHubComment.created_at:type_name -> google.protobuf.Timestamp
-	14, // 4: drghs.v1.GitHubComment.updated_at:type_name -> google.protobuf.Timestamp
-	4,  // 5: drghs.v1.GitHubReview.actor:type_name -> drghs.v1.GitHubUser
-	14, // 6: drghs.v1.GitHubReview.created_at:type_name -> google.protobuf.Timestamp
+	20, // 0: drghs.v1.GitCommit.authored_time:type_name -> google.protobuf.Timestamp
+	20, // 1: drghs.v1.GitCommit.committed_time:type_name -> google.protobuf.Timestamp
+	6,  // 2: drghs.v1.GitHubComment.user:type_name -> drghs.v1.GitHubUser
+	20, // 3: drghs.v1.GitHubComment.created_at:type_name -> google.protobuf.Timestamp
+	20, // 4: drghs.v1.GitHubComment.updated_at:type_name -> google.protobuf.Timestamp
+	6,  // 5: drghs.v1.GitHubReview.actor:type_name -> drghs.v1.GitHubUser
+	20, // 6: drghs.v1.GitHubReview.created_at:type_name -> google.protobuf.Timestamp
 	0,  // 7: drghs.v1.Issue.priority:type_name -> drghs.v1.Issue.Priority
 	1,  // 8: drghs.v1.Issue.issue_type:type_name -> drghs.v1.Issue.IssueType
-	14, // 9: drghs.v1.Issue.created_at:type_name -> google.protobuf.Timestamp
-	14, // 10: drghs.v1.Issue.updated_at:type_name -> google.protobuf.Timestamp
-	14, // 11: drghs.v1.Issue.closed_at:type_name -> google.protobuf.Timestamp
-	4,  // 12: drghs.v1.Issue.closed_by:type_name -> drghs.v1.GitHubUser
-	3,  // 13: drghs.v1.Issue.git_commit:type_name -> drghs.v1.GitCommit
-	4,  // 14: drghs.v1.Issue.assignees:type_name -> drghs.v1.GitHubUser
-	4,  // 15: drghs.v1.Issue.reporter:type_name -> drghs.v1.GitHubUser
-	5,  // 16: drghs.v1.Issue.comments:type_name -> drghs.v1.GitHubComment
-	6,  // 17: drghs.v1.Issue.reviews:type_name -> drghs.v1.GitHubReview
-	3,  // 18: drghs.v1.File.git_commit:type_name -> drghs.v1.GitCommit
-	8,  // 19: drghs.v1.SnippetVersion.file:type_name -> drghs.v1.File
-	9,  // 20: drghs.v1.SnippetVersion.meta:type_name -> drghs.v1.SnippetVersionMeta
-	10, // 21: drghs.v1.Snippet.primary:type_name -> drghs.v1.SnippetVersion
-	15, // 22: drghs.v1.SLO.response_time:type_name -> google.protobuf.Duration
-	15, // 23: drghs.v1.SLO.resolution_time:type_name -> google.protobuf.Duration
-	24, // [24:24] is the sub-list for method output_type
-	24, // [24:24] is the sub-list for method input_type
-	24, // [24:24] is the sub-list for extension type_name
-	24, // [24:24] is the sub-list for extension extendee
-	0,  // [0:24] is the sub-list for field type_name
+	20, // 9: drghs.v1.Issue.created_at:type_name -> google.protobuf.Timestamp
+	20, // 10: drghs.v1.Issue.updated_at:type_name -> google.protobuf.Timestamp
+	20, // 11: drghs.v1.Issue.closed_at:type_name -> google.protobuf.Timestamp
+	6,  // 12: drghs.v1.Issue.closed_by:type_name -> drghs.v1.GitHubUser
+	5,  // 13: drghs.v1.Issue.git_commit:type_name -> drghs.v1.GitCommit
+	6,  // 14: drghs.v1.Issue.assignees:type_name -> drghs.v1.GitHubUser
+	6,  // 15: drghs.v1.Issue.reporter:type_name -> drghs.v1.GitHubUser
+	7,  // 16: drghs.v1.Issue.comments:type_name -> drghs.v1.GitHubComment
+	8,  // 17: drghs.v1.Issue.reviews:type_name -> drghs.v1.GitHubReview
+	2,  // 18: drghs.v1.IssueLink.link_type:type_name -> drghs.v1.IssueLink.LinkType
+	20, // 19: drghs.v1.Label.first_used_time:type_name -> google.protobuf.Timestamp
+	20, // 20: drghs.v1.Label.last_used_time:type_name -> google.protobuf.Timestamp
+	9,  // 21: drghs.v1.StaleIssue.issue:type_name -> drghs.v1.Issue
+	19, // 22: drghs.v1.StaleIssue.flags:type_name -> drghs.v1.StaleIssue.Flag
+	5,  // 23: drghs.v1.File.git_commit:type_name -> drghs.v1.GitCommit
+	13, // 24: drghs.v1.SnippetVersion.file:type_name -> drghs.v1.File
+	14, // 25: drghs.v1.SnippetVersion.meta:type_name -> drghs.v1.SnippetVersionMeta
+	15, // 26: drghs.v1.Snippet.primary:type_name -> drghs.v1.SnippetVersion
+	21, // 27: drghs.v1.SLO.response_time:type_name -> google.protobuf.Duration
+	21, // 28: drghs.v1.SLO.resolution_time:type_name -> google.protobuf.Duration
+	3,  // 29: drghs.v1.StaleIssue.Flag.reason:type_name -> drghs.v1.StaleIssue.Reason
+	20, // 30: drghs.v1.StaleIssue.Flag.since:type_name -> google.protobuf.Timestamp
+	31, // [31:31] is the sub-list for method output_type
+	31, // [31:31] is the sub-list for method input_type
+	31, // [31:31] is the sub-list for extension type_name
+	31, // [31:31] is the sub-list for extension extendee
+	0,  // [0:31] is the sub-list for field type_name
 }
 
 func init() { file_resources_proto_init() }
@@ -1566,7 +2117,7 @@ func file_resources_proto_init() {
 			}
 		}
 		file_resources_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*File); i {
+			switch v := v.(*IssueLink); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1578,7 +2129,7 @@ func file_resources_proto_init() {
 			}
 		}
 		file_resources_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SnippetVersionMeta); i {
+			switch v := v.(*Label); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1590,7 +2141,7 @@ func file_resources_proto_init() {
 			}
 		}
 		file_resources_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SnippetVersion); i {
+			switch v := v.(*StaleIssue); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1602,7 +2153,7 @@ func file_resources_proto_init() {
 			}
 		}
 		file_resources_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Snippet); i {
+			switch v := v.(*File); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1614,7 +2165,7 @@ func file_resources_proto_init() {
 			}
 		}
 		file_resources_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Owner); i {
+			switch v := v.(*SnippetVersionMeta); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1626,6 +2177,42 @@ func file_resources_proto_init() {
 			}
 		}
 		file_resources_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SnippetVersion); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_resources_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Snippet); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_resources_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Owner); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_resources_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*SLO); i {
 			case 0:
 				return &v.state
@@ -1637,14 +2224,26 @@ func file_resources_proto_init() {
 				return nil
 			}
 		}
+		file_resources_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StaleIssue_Flag); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_resources_proto_rawDesc,
-			NumEnums:      2,
-			NumMessages:   12,
+			NumEnums:      4,
+			NumMessages:   16,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -25,13 +25,16 @@ import (
 	"github.com/GoogleCloudPlatform/devrel-services/leif"
 	filter "github.com/GoogleCloudPlatform/devrel-services/leif/leifd/leifapi/filters"
 	paginator "github.com/GoogleCloudPlatform/devrel-services/leif/leifd/leifapi/pagination"
+	"github.com/GoogleCloudPlatform/devrel-services/rtr/auth"
 
 	"github.com/golang/protobuf/ptypes"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
-var reposParent = regexp.MustCompile(`owners/([\w-_]+|\*)`)
-var slosParent = regexp.MustCompile(`owners/([\w-_]+|\*)/repositories/([\w-_]+|\*)`)
+var reposParent = regexp.MustCompile(`^owners/([\w.-]+|\*)$`)
+var slosParent = regexp.MustCompile(`^owners/([\w.-]+|\*)/repositories/([\w.-]+|\*)$`)
 
 var log *logrus.Logger
 
@@ -57,12 +60,19 @@ type SLOServiceServer struct {
 	ownerPaginator *paginator.Strings
 	repoPaginator  *paginator.Strings
 	sloPaginator   *paginator.Slo
+
+	// auth checks callers may read the repositories and owners returned.
+	// It may be nil.
+	auth *auth.Authorizer
 }
 
-// NewSLOServiceServer builds and returns a new SLOServiceServer
-func NewSLOServiceServer(c *leif.Corpus) *SLOServiceServer {
+// NewSLOServiceServer builds and returns a new SLOServiceServer. It only
+// returns the repositories, and SLOs, a lets the caller read; a may be nil
+// to return all of them.
+func NewSLOServiceServer(c *leif.Corpus, a *auth.Authorizer) *SLOServiceServer {
 	s := &SLOServiceServer{
 		c:              c,
+		auth:           a,
 		ownerPaginator: &paginator.Strings{Log: log},
 		repoPaginator:  &paginator.Strings{Log: log},
 		sloPaginator:   &paginator.Slo{Log: log},
@@ -206,7 +216,7 @@ func (s *SLOServiceServer) ListRepositories(ctx context.Context, req *drghs_v1.L
 		return nil, fmt.Errorf("Invalid parent: %v", req.Parent)
 	}
 
-	repos, nextToken, err := s.handleRepoPagination(req.PageToken, req.PageSize, req.OrderBy, req.Parent)
+	repos, nextToken, err := s.handleRepoPagination(ctx, req.PageToken, req.PageSize, req.OrderBy, req.Parent)
 	if err != nil {
 		return nil, err
 	}
@@ -242,7 +252,7 @@ func (s *SLOServiceServer) ListRepositories(ctx context.Context, req *drghs_v1.L
 	}, err
 }
 
-func (s *SLOServiceServer) handleRepoPagination(pToken string, pSize int32, orderBy string, parent string) ([]string, string, error) {
+func (s *SLOServiceServer) handleRepoPagination(ctx context.Context, pToken string, pSize int32, orderBy string, parent string) ([]string, string, error) {
 	var pg []string
 	var index int
 	var err error
@@ -274,11 +284,11 @@ func (s *SLOServiceServer) handleRepoPagination(pToken string, pSize int32, orde
 		owner := parts[1]
 
 		filter := func(repo leif.Repository) bool {
-			return repo.OwnerName() == owner
+			return repo.OwnerName() == owner && s.auth.CanRead(ctx, repo.OwnerName(), repo.RepoName())
 		}
 		if owner == "*" {
 			filter = func(repo leif.Repository) bool {
-				return true
+				return s.auth.CanRead(ctx, repo.OwnerName(), repo.RepoName())
 			}
 		}
 
@@ -340,14 +350,20 @@ func (s *SLOServiceServer) handleRepoPagination(pToken string, pSize int32, orde
 	return pg, nextToken, err
 }
 
-// ListOwnerSLOs returns the list of slos for an owner tracked by the Corpus
+// ListOwnerSLOs returns the list of slos for an owner tracked by the Corpus.
+// They apply to all of the owner's repositories, so the caller must be
+// able to read all of them.
 func (s *SLOServiceServer) ListOwnerSLOs(ctx context.Context, req *drghs_v1.ListSLOsRequest) (*drghs_v1.ListSLOsResponse, error) {
 
-	if !reposParent.MatchString(req.Parent) {
+	parts := reposParent.FindStringSubmatch(req.Parent)
+	if parts == nil {
 		return nil, fmt.Errorf("Invalid parent: %v", req.Parent)
 	}
+	if owner := parts[1]; owner != "*" && !s.auth.CanReadAll(ctx, owner) {
+		return nil, status.Error(codes.PermissionDenied, fmt.Sprintf("may not read every repository of %v", owner))
+	}
 
-	slos, nextToken, err := s.handleOwnerSloPagination(req.PageToken, req.PageSize, req.Parent)
+	slos, nextToken, err := s.handleOwnerSloPagination(ctx, req.PageToken, req.PageSize, req.Parent)
 	if err != nil {
 		return nil, err
 	}
@@ -390,7 +406,7 @@ func (s *SLOServiceServer) ListSLOs(ctx context.Context, req *drghs_v1.ListSLOsR
 		return nil, fmt.Errorf("Invalid parent: %v", req.Parent)
 	}
 
-	slos, nextToken, err := s.handleRepoSloPagination(req.PageToken, req.PageSize, req.Parent)
+	slos, nextToken, err := s.handleRepoSloPagination(ctx, req.PageToken, req.PageSize, req.Parent)
 	if err != nil {
 		return nil, err
 	}
@@ -426,7 +442,7 @@ func (s *SLOServiceServer) ListSLOs(ctx context.Context, req *drghs_v1.ListSLOsR
 	}, err
 }
 
-func (s *SLOServiceServer) handleOwnerSloPagination(pToken string, pSize int32, parent string) ([]*leif.SLORule, string, error) {
+func (s *SLOServiceServer) handleOwnerSloPagination(ctx context.Context, pToken string, pSize int32, parent string) ([]*leif.SLORule, string, error) {
 	var pg []*leif.SLORule
 	var index int
 	var err error
@@ -463,8 +479,9 @@ func (s *SLOServiceServer) handleOwnerSloPagination(pToken string, pSize int32,
 		}
 
 		if owner == "*" {
+			// Leave out the owners the caller may not read all of
 			parentFilter = func(o leif.Owner) bool {
-				return true
+				return s.auth.CanReadAll(ctx, o.Name())
 			}
 		}
 
@@ -496,7 +513,7 @@ func (s *SLOServiceServer) handleOwnerSloPagination(pToken string, pSize int32,
 	return pg, nextToken, err
 }
 
-func (s *SLOServiceServer) handleRepoSloPagination(pToken string, pSize int32, parent string) ([]*leif.SLORule, string, error) {
+func (s *SLOServiceServer) handleRepoSloPagination(ctx context.Context, pToken string, pSize int32, parent string) ([]*leif.SLORule, string, error) {
 	var pg []*leif.SLORule
 	var index int
 	var err error
@@ -552,7 +569,9 @@ func (s *SLOServiceServer) handleRepoSloPagination(pToken string, pSize int32, p
 		err = s.c.ForEachRepoF(func(repo leif.Repository) error {
 			slos = append(slos, repo.SLORules...)
 			return nil
-		}, parentFilter)
+		}, func(r leif.Repository) bool {
+			return parentFilter(r) && s.auth.CanRead(ctx, r.OwnerName(), r.RepoName())
+		})
 
 		// Create Page
 		t, err := s.sloPaginator.CreatePage(slos)
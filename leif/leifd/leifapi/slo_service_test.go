@@ -0,0 +1,143 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leifapi
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	drghs_v1 "github.com/GoogleCloudPlatform/devrel-services/drghs/v1"
+	"github.com/GoogleCloudPlatform/devrel-services/leif"
+	"github.com/GoogleCloudPlatform/devrel-services/leif/githubservices"
+	"github.com/GoogleCloudPlatform/devrel-services/rtr/auth"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/github"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var sloRules = `[{
+	"appliesTo": {"gitHubLabels": ["bug"]},
+	"complianceSettings": {"responseTime": 0, "resolutionTime": 0}
+}]`
+
+type fakeGitHub struct{}
+
+func (fakeGitHub) Get(ctx context.Context, owner, repo string) (*github.Repository, *github.Response, error) {
+	return &github.Repository{}, nil, nil
+}
+
+func (fakeGitHub) GetContents(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentGetOptions) (*github.RepositoryContent, []*github.RepositoryContent, *github.Response, error) {
+	return &github.RepositoryContent{Type: github.String("file"), Content: github.String(sloRules)}, nil, nil, nil
+}
+
+func (fakeGitHub) ListByOrg(ctx context.Context, org string, opts *github.RepositoryListByOrgOptions) ([]*github.Repository, *github.Response, error) {
+	return nil, nil, nil
+}
+
+func (fakeGitHub) ListCollaborators(ctx context.Context, owner, repo string, opts *github.ListCollaboratorsOptions) ([]*github.User, *github.Response, error) {
+	return nil, nil, nil
+}
+
+type fakeUsers struct{}
+
+func (fakeUsers) Get(ctx context.Context, user string) (*github.User, *github.Response, error) {
+	return &github.User{}, nil, nil
+}
+
+func TestOwnerRequestsOnlyReturnReadable(t *testing.T) {
+	ctx := context.Background()
+	client := githubservices.NewClient(nil, fakeGitHub{}, fakeUsers{})
+	corpus := &leif.Corpus{}
+	for _, name := range []string{"a", "b"} {
+		if err := corpus.TrackRepo(ctx, "X", name, &client); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := corpus.Initialize(ctx, &client); err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "leifapi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "policy.yaml")
+	yml := `principals:
+- name: one-repo
+  subjects: [one@example.com]
+  allow: [{repo: X/a}]
+- name: owner
+  subjects: [owner@example.com]
+  allow: [{owner: X}]
+`
+	if err := ioutil.WriteFile(path, []byte(yml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := auth.LoadPolicy(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewSLOServiceServer(corpus, auth.NewAuthorizer(policy))
+
+	tests := []struct {
+		Name      string
+		Principal *auth.Principal
+		WantRepos []string
+		WantSLOs  int
+		WantCode  codes.Code
+	}{
+		{
+			Name:      "Repository grant",
+			Principal: &policy.Principals[0],
+			WantRepos: []string{"owners/X/repositories/a"},
+			WantCode:  codes.PermissionDenied,
+		},
+		{
+			Name:      "Owner grant",
+			Principal: &policy.Principals[1],
+			WantRepos: []string{"owners/X/repositories/a", "owners/X/repositories/b"},
+			WantSLOs:  1,
+			WantCode:  codes.OK,
+		},
+	}
+	for _, c := range tests {
+		ctx := auth.NewContext(ctx, c.Principal)
+
+		repos, err := s.ListRepositories(ctx, &drghs_v1.ListRepositoriesRequest{Parent: "owners/X"})
+		if err != nil {
+			t.Fatalf("Test: %v ListRepositories() Wanted nil error, Got %v", c.Name, err)
+		}
+		got := make([]string, 0)
+		for _, r := range repos.Repositories {
+			got = append(got, r.Name)
+		}
+		if diff := cmp.Diff(c.WantRepos, got); diff != "" {
+			t.Errorf("Test: %v ListRepositories() mismatch (-want +got):\n%s", c.Name, diff)
+		}
+
+		slos, err := s.ListOwnerSLOs(ctx, &drghs_v1.ListSLOsRequest{Parent: "owners/X"})
+		if code := status.Code(err); code != c.WantCode {
+			t.Fatalf("Test: %v ListOwnerSLOs() Wanted code %v, Got %v", c.Name, c.WantCode, err)
+		}
+		if err == nil && len(slos.Slos) != c.WantSLOs {
+			t.Errorf("Test: %v ListOwnerSLOs() Wanted %v SLOs, Got %v", c.Name, c.WantSLOs, len(slos.Slos))
+		}
+	}
+}
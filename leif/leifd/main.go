@@ -29,6 +29,7 @@ import (
 	"github.com/GoogleCloudPlatform/devrel-services/leif/githubservices"
 	"github.com/GoogleCloudPlatform/devrel-services/leif/leifd/leifapi"
 	"github.com/GoogleCloudPlatform/devrel-services/repos"
+	"github.com/GoogleCloudPlatform/devrel-services/rtr/auth"
 
 	"github.com/gregjones/httpcache"
 	"github.com/sirupsen/logrus"
@@ -46,6 +47,7 @@ var (
 	reposFile    = flag.String("repos", "", "File that contains the list of repositories")
 	syncInterval = flag.Int("sync", 10, "Update interval in minutes")
 	verbose      = flag.Bool("verbose", false, "Verbose logs")
+	authPolicy   = flag.String("auth-policy", "", "YAML file mapping API keys and JWT subjects to the repositories they may read. Empty trusts every caller")
 )
 
 var log *logrus.Logger
@@ -152,8 +154,19 @@ func main() {
 			log.Fatalf("failed to listen %v", err)
 		}
 
-		grpcServer := grpc.NewServer()
-		drghs_v1.RegisterSLOServiceServer(grpcServer, leifapi.NewSLOServiceServer(corpus))
+		var opts []grpc.ServerOption
+		var authz *auth.Authorizer
+		if *authPolicy != "" {
+			policy, err := auth.LoadPolicy(*authPolicy)
+			if err != nil {
+				log.Fatalf("invalid --auth-policy: %v", err)
+			}
+			authz = auth.NewAuthorizer(policy).RejectWildcards()
+			opts = append(opts, grpc.UnaryInterceptor(authz.UnaryServerInterceptor()))
+		}
+
+		grpcServer := grpc.NewServer(opts...)
+		drghs_v1.RegisterSLOServiceServer(grpcServer, leifapi.NewSLOServiceServer(corpus, authz))
 
 		go func() {
 			select {
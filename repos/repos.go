@@ -17,6 +17,7 @@ package repos
 import (
 	"context"
 	"crypto/sha256"
+	"encoding/json"
 	"fmt"
 )
 
@@ -35,6 +36,54 @@ type TrackedRepository struct {
 	DefaultBranch     string `json:"defaultBranch"`
 	IsTrackingIssues  bool   `json:"isTrackingIssues"`
 	IsTrackingSamples bool   `json:"isTrackingSamples"`
+
+	// Resources overrides the resource requests and limits of the
+	// repository's workloads. Empty fields keep the supervisor's defaults.
+	Resources Resources `json:"resources,omitempty"`
+	// ImageTag pins the tag of the image the repository's workloads run.
+	// Empty runs the supervisor's image as is.
+	ImageTag string `json:"imageTag,omitempty"`
+	// ExtraFlags are appended to the command line of the repository's
+	// workloads, as a JSON array so a flag may contain spaces. It is a
+	// string rather than a slice so TrackedRepository stays comparable, as
+	// the supervisors key maps with it. Use Flags and SetFlags.
+	ExtraFlags string `json:"extraFlags,omitempty"`
+	// Token names the GitHub token the repository's workloads use, among
+	// those the supervisor has. Empty lets the supervisor pick one.
+	Token string `json:"token,omitempty"`
+}
+
+// Resources are the compute resources of a repository's workloads, as
+// Kubernetes quantities, e.g. "250m" or "4G".
+type Resources struct {
+	CPURequest    string `json:"cpuRequest,omitempty"`
+	MemoryRequest string `json:"memoryRequest,omitempty"`
+	CPULimit      string `json:"cpuLimit,omitempty"`
+	MemoryLimit   string `json:"memoryLimit,omitempty"`
+}
+
+// Flags returns the ExtraFlags of the TrackedRepository, one per element
+func (t TrackedRepository) Flags() []string {
+	if t.ExtraFlags == "" {
+		return nil
+	}
+	var flags []string
+	if err := json.Unmarshal([]byte(t.ExtraFlags), &flags); err != nil {
+		// Not set by SetFlags: pass it on whole
+		return []string{t.ExtraFlags}
+	}
+	return flags
+}
+
+// SetFlags sets the ExtraFlags of the TrackedRepository
+func (t *TrackedRepository) SetFlags(flags []string) {
+	t.ExtraFlags = ""
+	if len(flags) == 0 {
+		return
+	}
+	// Marshaling a []string can't fail
+	b, _ := json.Marshal(flags)
+	t.ExtraFlags = string(b)
 }
 
 // RepoSha Creates a Sum224 of the TrackedRepository's name
@@ -69,6 +69,15 @@ type bucketRepo struct {
 	DefaultBranch     string `json:"default_branch"`
 	IsTrackingIssues  bool   `json:"is_tracking_issues"`
 	IsTrackingSamples bool   `json:"is_tracking_samples"`
+	Resources         struct {
+		CPURequest    string `json:"cpu_request"`
+		MemoryRequest string `json:"memory_request"`
+		CPULimit      string `json:"cpu_limit"`
+		MemoryLimit   string `json:"memory_limit"`
+	} `json:"resources"`
+	ImageTag   string   `json:"image_tag"`
+	ExtraFlags []string `json:"extra_flags"`
+	Token      string   `json:"token"`
 }
 
 func (r *bucketRepoList) getRepos(ctx context.Context) ([]TrackedRepository, error) {
@@ -113,7 +122,16 @@ func (r *bucketRepoList) getRepos(ctx context.Context) ([]TrackedRepository, err
 			IsTrackingIssues:  re.IsTrackingIssues,
 			IsTrackingSamples: re.IsTrackingSamples,
 			DefaultBranch:     re.DefaultBranch,
+			Resources: Resources{
+				CPURequest:    re.Resources.CPURequest,
+				MemoryRequest: re.Resources.MemoryRequest,
+				CPULimit:      re.Resources.CPULimit,
+				MemoryLimit:   re.Resources.MemoryLimit,
+			},
+			ImageTag: re.ImageTag,
+			Token:    re.Token,
 		}
+		tr.SetFlags(re.ExtraFlags)
 		reps[i] = tr
 	}
 
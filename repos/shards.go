@@ -0,0 +1,74 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repos
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// ShardOwner is the Owner of the TrackedRepository that stands for a shard,
+// so a shard is deployed, named and resolved like a repository.
+const ShardOwner = "shards"
+
+// ShardMap assigns repositories to the shards serving them. The supervisors
+// write it and the routers read it, as JSON.
+type ShardMap struct {
+	// Shards is the number of shards
+	Shards int `json:"shards"`
+	// Assignments maps each repository, as owner/name, to its shard
+	Assignments map[string]int `json:"assignments"`
+}
+
+// ShardRepository returns the TrackedRepository standing for shard i
+func ShardRepository(i int) TrackedRepository {
+	return TrackedRepository{
+		Owner:             ShardOwner,
+		Name:              fmt.Sprintf("shard-%v", i),
+		IsTrackingIssues:  true,
+		IsTrackingSamples: true,
+	}
+}
+
+// Shard returns the TrackedRepository standing for the shard serving the
+// repository owner/name, and false if no shard serves it. Repositories are
+// matched case-insensitively, as GitHub does.
+func (m *ShardMap) Shard(owner, name string) (TrackedRepository, bool) {
+	want := strings.ToLower(owner + "/" + name)
+	if i, ok := m.Assignments[want]; ok {
+		return ShardRepository(i), true
+	}
+	for repo, i := range m.Assignments {
+		if strings.ToLower(repo) == want {
+			return ShardRepository(i), true
+		}
+	}
+	return TrackedRepository{}, false
+}
+
+// LoadShardMap reads the ShardMap in the JSON file at path
+func LoadShardMap(path string) (*ShardMap, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m := &ShardMap{}
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, fmt.Errorf("parsing %v: %v", path, err)
+	}
+	return m, nil
+}
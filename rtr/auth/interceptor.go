@@ -0,0 +1,245 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Metadata keys credentials are read from.
+const (
+	apiKeyHeader = "x-api-key"
+	authzHeader  = "authorization"
+)
+
+var (
+	// owners/OWNER[/repositories/REPO[/...]], as samplr-rtr and leifd use.
+	ownersPath = regexp.MustCompile(`^owners/([^/]+)(?:/repositories/([^/]+))?(?:/.*)?$`)
+	// OWNER/REPO[/...], as maintner-rtr uses.
+	repoPath = regexp.MustCompile(`^([^/]+)/([^/]+)(?:/.*)?$`)
+)
+
+// Authorizer authenticates the callers of a gRPC server against a Policy and
+// checks they may read the repository each request names.
+//
+// Requests that name a single repository need a grant for it, and requests
+// that name an owner need a grant for some repository of it. Requests that
+// span owners or repositories are only authenticated. Servers answering
+// requests for owners, or spanning them, filter what they return with
+// CanRead and CanReadAll. Servers that do not filter refuse the requests
+// spanning owners with RejectWildcards.
+//
+// A nil *Authorizer allows everything.
+type Authorizer struct {
+	policy   *Policy
+	verifier *verifier
+	// public are full method name prefixes served without credentials.
+	public []string
+	// rejectWildcards refuses requests spanning owners or repositories.
+	rejectWildcards bool
+}
+
+// NewAuthorizer returns an Authorizer enforcing p. The health checking
+// service stays public.
+func NewAuthorizer(p *Policy) *Authorizer {
+	return &Authorizer{
+		policy:   p,
+		verifier: newVerifier(p.Issuers, &http.Client{Timeout: 10 * time.Second}),
+		public:   []string{"/grpc.health.v1.Health/"},
+	}
+}
+
+// RejectWildcards makes a refuse the requests whose owner or repository is
+// a wildcard, for servers that do not filter what they return with
+// CanRead. It returns a.
+func (a *Authorizer) RejectWildcards() *Authorizer {
+	a.rejectWildcards = true
+	return a
+}
+
+type principalKey struct{}
+
+// NewContext returns a copy of ctx carrying pr.
+func NewContext(ctx context.Context, pr *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, pr)
+}
+
+// FromContext returns the principal the Authorizer authenticated the
+// request in ctx as, or nil.
+func FromContext(ctx context.Context) *Principal {
+	pr, _ := ctx.Value(principalKey{}).(*Principal)
+	return pr
+}
+
+// CanRead reports whether the caller in ctx may read the repository
+// owner/name.
+func (a *Authorizer) CanRead(ctx context.Context, owner, name string) bool {
+	if a == nil {
+		return true
+	}
+	return a.policy.CanRead(FromContext(ctx), owner, name)
+}
+
+// CanReadAll reports whether the caller in ctx may read every repository of
+// owner, e.g. to see what the owner sets for all of them.
+func (a *Authorizer) CanReadAll(ctx context.Context, owner string) bool {
+	if a == nil {
+		return true
+	}
+	return a.policy.CanReadAll(FromContext(ctx), owner)
+}
+
+// Authenticate returns the principal whose API key or bearer JWT ctx's
+// incoming metadata carries.
+func (a *Authorizer) Authenticate(ctx context.Context) (*Principal, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if keys := md.Get(apiKeyHeader); len(keys) > 0 {
+		if pr := a.policy.principalForKey(keys[0]); pr != nil {
+			return pr, nil
+		}
+		return nil, status.Error(codes.Unauthenticated, "unknown api key")
+	}
+	for _, h := range md.Get(authzHeader) {
+		const prefix = "bearer "
+		if len(h) <= len(prefix) || !strings.EqualFold(h[:len(prefix)], prefix) {
+			continue
+		}
+		c, err := a.verifier.verify(ctx, h[len(prefix):])
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		for _, sub := range c.subjects() {
+			if pr := a.policy.principalForSubject(sub); pr != nil {
+				return pr, nil
+			}
+		}
+		return nil, status.Error(codes.PermissionDenied, "no access granted to the token's subject")
+	}
+	return nil, status.Error(codes.Unauthenticated, "missing api key or bearer token")
+}
+
+// authorize checks pr may read the repository or owner req names.
+func (a *Authorizer) authorize(pr *Principal, req interface{}) error {
+	owner, name := resource(req)
+	if a.rejectWildcards && (isWildcard(owner) || isWildcard(name)) {
+		return status.Error(codes.PermissionDenied, "requests may not span owners or repositories")
+	}
+	switch {
+	case owner == "" || isWildcard(owner):
+		return nil
+	case name == "" || isWildcard(name):
+		if !a.policy.CanReadOwner(pr, owner) {
+			return status.Error(codes.PermissionDenied, fmt.Sprintf("%v may not read %v", pr.Name, owner))
+		}
+	default:
+		if !a.policy.CanRead(pr, owner, name) {
+			return status.Error(codes.PermissionDenied, fmt.Sprintf("%v may not read %v/%v", pr.Name, owner, name))
+		}
+	}
+	return nil
+}
+
+func (a *Authorizer) isPublic(method string) bool {
+	for _, p := range a.public {
+		if strings.HasPrefix(method, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// UnaryServerInterceptor authenticates and authorizes every unary call.
+func (a *Authorizer) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if a.isPublic(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		pr, err := a.Authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := a.authorize(pr, req); err != nil {
+			return nil, err
+		}
+		return handler(NewContext(ctx, pr), req)
+	}
+}
+
+// StreamServerInterceptor authenticates every streaming call and authorizes
+// every message the client sends on it.
+func (a *Authorizer) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if a.isPublic(info.FullMethod) {
+			return handler(srv, ss)
+		}
+		pr, err := a.Authenticate(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authorizedStream{ServerStream: ss, a: a, pr: pr})
+	}
+}
+
+type authorizedStream struct {
+	grpc.ServerStream
+	a  *Authorizer
+	pr *Principal
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return NewContext(s.ServerStream.Context(), s.pr)
+}
+
+func (s *authorizedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return s.a.authorize(s.pr, m)
+}
+
+// resource returns the owner and repository named by req's name or parent
+// field. Either is empty if req names none.
+func resource(req interface{}) (string, string) {
+	var path string
+	if r, ok := req.(interface{ GetName() string }); ok {
+		path = r.GetName()
+	}
+	if r, ok := req.(interface{ GetParent() string }); ok && path == "" {
+		path = r.GetParent()
+	}
+	path = strings.Trim(path, "/")
+
+	if m := ownersPath.FindStringSubmatch(path); m != nil {
+		return m[1], m[2]
+	}
+	if m := repoPath.FindStringSubmatch(path); m != nil {
+		return m[1], m[2]
+	}
+	return "", ""
+}
+
+func isWildcard(s string) bool {
+	return s == "-" || s == Wildcard
+}
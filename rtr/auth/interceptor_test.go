@@ -0,0 +1,211 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type parentRequest struct{ parent string }
+
+func (r parentRequest) GetParent() string { return r.parent }
+
+type nameRequest struct{ name string }
+
+func (r nameRequest) GetName() string { return r.name }
+
+func TestResource(t *testing.T) {
+	tests := []struct {
+		Input     interface{}
+		WantOwner string
+		WantName  string
+	}{
+		{Input: parentRequest{"foo/bar"}, WantOwner: "foo", WantName: "bar"},
+		{Input: nameRequest{"foo/bar/issues/12"}, WantOwner: "foo", WantName: "bar"},
+		{Input: parentRequest{"owners/foo/repositories/bar/gitCommits"}, WantOwner: "foo", WantName: "bar"},
+		{Input: parentRequest{"owners/foo"}, WantOwner: "foo", WantName: ""},
+		{Input: parentRequest{"owners/*/repositories/*"}, WantOwner: "*", WantName: "*"},
+		{Input: parentRequest{"foo/-"}, WantOwner: "foo", WantName: "-"},
+		{Input: parentRequest{""}, WantOwner: "", WantName: ""},
+		{Input: struct{}{}, WantOwner: "", WantName: ""},
+	}
+	for _, c := range tests {
+		owner, name := resource(c.Input)
+		if owner != c.WantOwner || name != c.WantName {
+			t.Errorf("resource(%v) Wanted %q, %q. Got %q, %q", c.Input, c.WantOwner, c.WantName, owner, name)
+		}
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	p := loadTestPolicy(t)
+	srv := newTestIssuerServer(t, "k1")
+	defer srv.Close()
+	p.Issuers = []Issuer{{Issuer: testIssuer, JWKSURL: srv.URL}}
+	a := NewAuthorizer(p)
+
+	bearer := func(sub string) string {
+		return "Bearer " + srv.sign(t, "RS256", "k1", map[string]interface{}{
+			"iss": testIssuer,
+			"sub": sub,
+			"exp": time.Now().Add(time.Hour).Unix(),
+		})
+	}
+
+	tests := []struct {
+		Name     string
+		Method   string
+		MD       metadata.MD
+		Req      interface{}
+		Want     codes.Code
+		WantName string
+	}{
+		{
+			Name:     "API key",
+			MD:       metadata.Pairs("x-api-key", "dashboards-key"),
+			Req:      parentRequest{"GoogleCloudPlatform/golang-samples"},
+			Want:     codes.OK,
+			WantName: "dashboards",
+		},
+		{
+			Name: "API key for an ungranted repository",
+			MD:   metadata.Pairs("x-api-key", "dashboards-key"),
+			Req:  parentRequest{"googleapis/google-cloud-java"},
+			Want: codes.PermissionDenied,
+		},
+		{
+			Name: "Unknown API key",
+			MD:   metadata.Pairs("x-api-key", "wrong"),
+			Req:  parentRequest{"GoogleCloudPlatform/golang-samples"},
+			Want: codes.Unauthenticated,
+		},
+		{
+			Name: "No credentials",
+			Req:  parentRequest{"GoogleCloudPlatform/golang-samples"},
+			Want: codes.Unauthenticated,
+		},
+		{
+			Name:     "Bearer token for an embargoed repository",
+			MD:       metadata.Pairs("authorization", bearer("1234567890")),
+			Req:      nameRequest{"googleapis/security-fixes/issues/1"},
+			Want:     codes.OK,
+			WantName: "security-team",
+		},
+		{
+			Name: "Bearer token without the embargo grant",
+			MD:   metadata.Pairs("authorization", bearer("carol@example.com")),
+			Req:  nameRequest{"googleapis/security-fixes/issues/1"},
+			Want: codes.PermissionDenied,
+		},
+		{
+			Name: "Bearer token of an unknown subject",
+			MD:   metadata.Pairs("authorization", bearer("mallory@example.com")),
+			Req:  parentRequest{"GoogleCloudPlatform/golang-samples"},
+			Want: codes.PermissionDenied,
+		},
+		{
+			Name: "Invalid bearer token",
+			MD:   metadata.Pairs("authorization", "Bearer not-a-jwt"),
+			Req:  parentRequest{"GoogleCloudPlatform/golang-samples"},
+			Want: codes.Unauthenticated,
+		},
+		{
+			Name: "Owner the principal has no grant in",
+			MD:   metadata.Pairs("x-api-key", "dashboards-key"),
+			Req:  parentRequest{"owners/golang"},
+			Want: codes.PermissionDenied,
+		},
+		{
+			Name:     "Spanning owners is only authenticated",
+			MD:       metadata.Pairs("x-api-key", "dashboards-key"),
+			Req:      parentRequest{"-/-"},
+			Want:     codes.OK,
+			WantName: "dashboards",
+		},
+		{
+			Name:   "Health checks are public",
+			Method: "/grpc.health.v1.Health/Check",
+			Req:    struct{}{},
+			Want:   codes.OK,
+		},
+	}
+
+	for _, c := range tests {
+		ctx := context.Background()
+		if c.MD != nil {
+			ctx = metadata.NewIncomingContext(ctx, c.MD)
+		}
+		method := c.Method
+		if method == "" {
+			method = "/drghs.v1.IssueService/ListIssues"
+		}
+
+		var gotName string
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			if pr := FromContext(ctx); pr != nil {
+				gotName = pr.Name
+			}
+			return nil, nil
+		}
+		_, err := a.UnaryServerInterceptor()(ctx, c.Req, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		if got := status.Code(err); got != c.Want {
+			t.Errorf("%v: Wanted %v, Got %v (%v)", c.Name, c.Want, got, err)
+		}
+		if gotName != c.WantName {
+			t.Errorf("%v: principal Wanted %q, Got %q", c.Name, c.WantName, gotName)
+		}
+	}
+}
+
+func TestRejectWildcards(t *testing.T) {
+	a := NewAuthorizer(loadTestPolicy(t)).RejectWildcards()
+	md := metadata.Pairs("x-api-key", "dashboards-key")
+
+	tests := []struct {
+		Req  interface{}
+		Want codes.Code
+	}{
+		{Req: parentRequest{"owners/GoogleCloudPlatform/repositories/golang-samples"}, Want: codes.OK},
+		{Req: parentRequest{"owners/GoogleCloudPlatform"}, Want: codes.OK},
+		{Req: parentRequest{"owners/*/repositories/*"}, Want: codes.PermissionDenied},
+		{Req: parentRequest{"owners/*"}, Want: codes.PermissionDenied},
+		{Req: parentRequest{"owners/googleapis/repositories/*"}, Want: codes.PermissionDenied},
+		{Req: parentRequest{"-/-"}, Want: codes.PermissionDenied},
+		// Lists owners
+		{Req: parentRequest{""}, Want: codes.OK},
+	}
+	for _, c := range tests {
+		ctx := metadata.NewIncomingContext(context.Background(), md)
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+		_, err := a.UnaryServerInterceptor()(ctx, c.Req, &grpc.UnaryServerInfo{FullMethod: "/drghs.v1.SLOService/ListSLOs"}, handler)
+		if got := status.Code(err); got != c.Want {
+			t.Errorf("%v: Wanted %v, Got %v (%v)", c.Req, c.Want, got, err)
+		}
+	}
+}
+
+func TestNilAuthorizerCanRead(t *testing.T) {
+	var a *Authorizer
+	if !a.CanRead(context.Background(), "googleapis", "security-fixes") {
+		t.Errorf("CanRead() of a nil Authorizer. Wanted true, Got false")
+	}
+}
@@ -0,0 +1,258 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// clockSkew is how far the exp and nbf claims may be off.
+	clockSkew = time.Minute
+	// jwksTTL is how long fetched signing keys are used before they are
+	// fetched again.
+	jwksTTL = time.Hour
+	// jwksMinRefresh bounds how often an unknown key ID makes the keys be
+	// fetched again.
+	jwksMinRefresh = time.Minute
+)
+
+var errInvalidToken = errors.New("invalid token")
+
+// claims are the JWT claims the verifier looks at.
+type claims struct {
+	Issuer        string   `json:"iss"`
+	Subject       string   `json:"sub"`
+	Audience      audience `json:"aud"`
+	Email         string   `json:"email"`
+	EmailVerified *bool    `json:"email_verified"`
+	Expires       int64    `json:"exp"`
+	NotBefore     int64    `json:"nbf"`
+}
+
+// subjects returns the identities the token vouches for, most specific
+// first.
+func (c claims) subjects() []string {
+	ret := make([]string, 0, 2)
+	if c.Email != "" && (c.EmailVerified == nil || *c.EmailVerified) {
+		ret = append(ret, c.Email)
+	}
+	if c.Subject != "" {
+		ret = append(ret, c.Subject)
+	}
+	return ret
+}
+
+// audience is the aud claim, which is either a string or a list of them.
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*a = audience{s}
+		return nil
+	}
+	var l []string
+	if err := json.Unmarshal(b, &l); err != nil {
+		return err
+	}
+	*a = l
+	return nil
+}
+
+func (a audience) contains(s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// verifier checks RS256 JWTs of the Policy's issuers.
+type verifier struct {
+	issuers map[string]*keySet
+	client  *http.Client
+	now     func() time.Time
+}
+
+func newVerifier(issuers []Issuer, client *http.Client) *verifier {
+	v := &verifier{
+		issuers: make(map[string]*keySet),
+		client:  client,
+		now:     time.Now,
+	}
+	for _, iss := range issuers {
+		v.issuers[iss.Issuer] = &keySet{issuer: iss}
+	}
+	return v
+}
+
+// verify checks the signature and claims of token and returns its claims.
+func (v *verifier) verify(ctx context.Context, token string) (claims, error) {
+	var c claims
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return c, errInvalidToken
+	}
+
+	var hdr struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &hdr); err != nil {
+		return c, errInvalidToken
+	}
+	if err := decodeSegment(parts[1], &c); err != nil {
+		return c, errInvalidToken
+	}
+	if hdr.Alg != "RS256" {
+		return c, fmt.Errorf("unsupported token algorithm %q", hdr.Alg)
+	}
+
+	ks, ok := v.issuers[c.Issuer]
+	if !ok {
+		return c, fmt.Errorf("untrusted token issuer %q", c.Issuer)
+	}
+	if ks.issuer.Audience != "" && !c.Audience.contains(ks.issuer.Audience) {
+		return c, errors.New("token has the wrong audience")
+	}
+	now := v.now()
+	if c.Expires == 0 || now.After(time.Unix(c.Expires, 0).Add(clockSkew)) {
+		return c, errors.New("token expired")
+	}
+	if c.NotBefore != 0 && now.Add(clockSkew).Before(time.Unix(c.NotBefore, 0)) {
+		return c, errors.New("token not valid yet")
+	}
+
+	key, err := ks.key(ctx, v.client, hdr.Kid, now)
+	if err != nil {
+		return c, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return c, errInvalidToken
+	}
+	h := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, h[:], sig); err != nil {
+		return c, errInvalidToken
+	}
+	return c, nil
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// keySet caches the signing keys of an issuer.
+type keySet struct {
+	issuer Issuer
+
+	mu      sync.Mutex
+	keys    map[string]*rsa.PublicKey
+	fetched time.Time
+}
+
+// key returns the issuer's key with ID kid, fetching the keys again if they
+// are old or kid is new to them.
+func (ks *keySet) key(ctx context.Context, client *http.Client, kid string, now time.Time) (*rsa.PublicKey, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	k, ok := ks.keys[kid]
+	stale := now.Sub(ks.fetched) > jwksTTL
+	if ok && !stale {
+		return k, nil
+	}
+	if stale || now.Sub(ks.fetched) > jwksMinRefresh {
+		keys, err := fetchJWKS(ctx, client, ks.issuer.JWKSURL)
+		if err != nil {
+			if ok {
+				// Keep using what we had rather than lock everyone out.
+				return k, nil
+			}
+			return nil, err
+		}
+		ks.keys, ks.fetched = keys, now
+	}
+	if k, ok := ks.keys[kid]; ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// fetchJWKS returns the RSA keys of the JSON Web Key Set at url by key ID.
+func fetchJWKS(ctx context.Context, client *http.Client, url string) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %v: %v", url, resp.Status)
+	}
+
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("decoding %v: %v", url, err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,201 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testIssuer = "https://issuer.example.com"
+
+// testIssuerServer serves the JWKS of an issuer and signs tokens with it.
+type testIssuerServer struct {
+	*httptest.Server
+	keys    map[string]*rsa.PrivateKey
+	fetches int32
+}
+
+func newTestIssuerServer(t *testing.T, kids ...string) *testIssuerServer {
+	t.Helper()
+	s := &testIssuerServer{keys: make(map[string]*rsa.PrivateKey)}
+	for _, kid := range kids {
+		s.addKey(t, kid)
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&s.fetches, 1)
+		type jwk struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		}
+		set := struct {
+			Keys []jwk `json:"keys"`
+		}{}
+		for kid, k := range s.keys {
+			set.Keys = append(set.Keys, jwk{
+				Kty: "RSA",
+				Kid: kid,
+				N:   base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+			})
+		}
+		json.NewEncoder(w).Encode(set)
+	}))
+	return s
+}
+
+func (s *testIssuerServer) addKey(t *testing.T, kid string) {
+	t.Helper()
+	k, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.keys[kid] = k
+}
+
+func (s *testIssuerServer) sign(t *testing.T, alg, kid string, c map[string]interface{}) string {
+	t.Helper()
+	enc := func(v interface{}) string {
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	signed := enc(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"}) + "." + enc(c)
+	h := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.keys[kid], crypto.SHA256, h[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestVerify(t *testing.T) {
+	srv := newTestIssuerServer(t, "k1")
+	defer srv.Close()
+
+	now := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	v := newVerifier([]Issuer{{Issuer: testIssuer, Audience: "devrel", JWKSURL: srv.URL}}, srv.Client())
+	v.now = func() time.Time { return now }
+
+	valid := func() map[string]interface{} {
+		return map[string]interface{}{
+			"iss":   testIssuer,
+			"aud":   "devrel",
+			"sub":   "1234567890",
+			"email": "alice@example.com",
+			"exp":   now.Add(time.Hour).Unix(),
+		}
+	}
+	with := func(k string, val interface{}) map[string]interface{} {
+		c := valid()
+		if val == nil {
+			delete(c, k)
+		} else {
+			c[k] = val
+		}
+		return c
+	}
+
+	other := newTestIssuerServer(t, "k1", "k9")
+	defer other.Close()
+
+	tests := []struct {
+		Name    string
+		Token   string
+		WantErr bool
+	}{
+		{Name: "Valid", Token: srv.sign(t, "RS256", "k1", valid())},
+		{Name: "Audience list", Token: srv.sign(t, "RS256", "k1", with("aud", []string{"other", "devrel"}))},
+		{Name: "Expired within skew", Token: srv.sign(t, "RS256", "k1", with("exp", now.Add(-30*time.Second).Unix()))},
+		{Name: "Expired", Token: srv.sign(t, "RS256", "k1", with("exp", now.Add(-time.Hour).Unix())), WantErr: true},
+		{Name: "No expiry", Token: srv.sign(t, "RS256", "k1", with("exp", nil)), WantErr: true},
+		{Name: "Not yet valid", Token: srv.sign(t, "RS256", "k1", with("nbf", now.Add(time.Hour).Unix())), WantErr: true},
+		{Name: "Wrong audience", Token: srv.sign(t, "RS256", "k1", with("aud", "other")), WantErr: true},
+		{Name: "Untrusted issuer", Token: srv.sign(t, "RS256", "k1", with("iss", "https://evil.example.com")), WantErr: true},
+		{Name: "Unknown key", Token: other.sign(t, "RS256", "k9", valid()), WantErr: true},
+		{Name: "Signed by another key", Token: other.sign(t, "RS256", "k1", valid()), WantErr: true},
+		{Name: "Unsupported algorithm", Token: srv.sign(t, "HS256", "k1", valid()), WantErr: true},
+		{Name: "Malformed", Token: "not-a-jwt", WantErr: true},
+	}
+	for _, c := range tests {
+		got, err := v.verify(context.Background(), c.Token)
+		if (err != nil) != c.WantErr {
+			t.Errorf("%v: verify() Wanted error %v, Got %v", c.Name, c.WantErr, err)
+			continue
+		}
+		if err == nil && got.Email != "alice@example.com" {
+			t.Errorf("%v: verify() Wanted email alice@example.com, Got %q", c.Name, got.Email)
+		}
+	}
+}
+
+func TestVerifyRotatedKeys(t *testing.T) {
+	srv := newTestIssuerServer(t, "k1")
+	defer srv.Close()
+
+	now := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	v := newVerifier([]Issuer{{Issuer: testIssuer, JWKSURL: srv.URL}}, srv.Client())
+	v.now = func() time.Time { return now }
+	c := map[string]interface{}{"iss": testIssuer, "sub": "x", "exp": now.Add(time.Hour).Unix()}
+
+	if _, err := v.verify(context.Background(), srv.sign(t, "RS256", "k1", c)); err != nil {
+		t.Fatal(err)
+	}
+
+	// A token with a new key right after the last fetch is refused without
+	// fetching again.
+	srv.addKey(t, "k2")
+	if _, err := v.verify(context.Background(), srv.sign(t, "RS256", "k2", c)); err == nil {
+		t.Errorf("verify() with a key added right after the last fetch. Wanted an error, Got nil")
+	}
+	if n := atomic.LoadInt32(&srv.fetches); n != 1 {
+		t.Errorf("verify() fetched the keys %v times. Wanted 1", n)
+	}
+
+	now = now.Add(2 * jwksMinRefresh)
+	if _, err := v.verify(context.Background(), srv.sign(t, "RS256", "k2", c)); err != nil {
+		t.Errorf("verify() with a rotated key. Wanted no error, Got %v", err)
+	}
+	if n := atomic.LoadInt32(&srv.fetches); n != 2 {
+		t.Errorf("verify() fetched the keys %v times. Wanted 2", n)
+	}
+}
+
+func TestClaimsSubjects(t *testing.T) {
+	f := false
+	c := claims{Subject: "123", Email: "alice@example.com", EmailVerified: &f}
+	if got := c.subjects(); len(got) != 1 || got[0] != "123" {
+		t.Errorf("subjects() with an unverified email. Wanted [123], Got %v", got)
+	}
+	c.EmailVerified = nil
+	if got := c.subjects(); len(got) != 2 || got[0] != "alice@example.com" {
+		t.Errorf("subjects() Wanted [alice@example.com 123], Got %v", got)
+	}
+}
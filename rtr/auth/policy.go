@@ -0,0 +1,219 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package auth authorizes the requests the routers serve.
+//
+// Callers present either an API key or a bearer JWT. A Policy, read from a
+// local YAML file, maps the principals those identify to the owners and
+// repositories they may read. Repositories listed as embargoed, e.g. ones
+// holding undisclosed security issues, are readable only by principals that
+// name them explicitly; a grant for their owner or for every owner does not
+// cover them.
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Wildcard grants every owner.
+const Wildcard = "*"
+
+// Policy maps principals to the repositories they may read.
+//
+// An example policy file:
+//
+//	issuers:
+//	- issuer: https://accounts.google.com
+//	  audience: devrel-services
+//	  jwks_url: https://www.googleapis.com/oauth2/v3/certs
+//	embargoed:
+//	- googleapis/security-fixes
+//	principals:
+//	- name: dashboards
+//	  # SHA-256 of the API key, hex encoded.
+//	  api_keys: [9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08]
+//	  allow:
+//	  - owner: GoogleCloudPlatform
+//	  - repo: googleapis/google-cloud-go
+//	- name: security-team
+//	  # email or sub claims of JWTs.
+//	  subjects: [alice@example.com, bob@example.com]
+//	  allow:
+//	  - owner: "*"
+//	  - repo: googleapis/security-fixes
+type Policy struct {
+	Issuers    []Issuer    `yaml:"issuers"`
+	Embargoed  []string    `yaml:"embargoed"`
+	Principals []Principal `yaml:"principals"`
+
+	embargoed map[string]bool
+	byKey     map[string]*Principal
+	bySubject map[string]*Principal
+}
+
+// Issuer is an issuer of JWTs the Policy accepts.
+type Issuer struct {
+	// Issuer must equal the iss claim.
+	Issuer string `yaml:"issuer"`
+	// Audience, if set, must be one of the aud claims.
+	Audience string `yaml:"audience"`
+	// JWKSURL serves the issuer's RS256 signing keys.
+	JWKSURL string `yaml:"jwks_url"`
+}
+
+// Principal is a caller and what it may read.
+type Principal struct {
+	Name string `yaml:"name"`
+	// APIKeys are hex encoded SHA-256 digests of the principal's API keys,
+	// so the policy file does not hold the keys themselves.
+	APIKeys []string `yaml:"api_keys"`
+	// Subjects are the email or sub claims of the principal's JWTs.
+	Subjects []string `yaml:"subjects"`
+	Allow    []Grant  `yaml:"allow"`
+}
+
+// Grant allows reading every repository of Owner, or the single repository
+// Repo in the form owner/name. Owner may be Wildcard.
+type Grant struct {
+	Owner string `yaml:"owner"`
+	Repo  string `yaml:"repo"`
+}
+
+// LoadPolicy reads the Policy in the YAML file at path.
+func LoadPolicy(path string) (*Policy, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p := &Policy{}
+	if err := yaml.UnmarshalStrict(b, p); err != nil {
+		return nil, fmt.Errorf("parsing %v: %v", path, err)
+	}
+	if err := p.init(); err != nil {
+		return nil, fmt.Errorf("%v: %v", path, err)
+	}
+	return p, nil
+}
+
+func (p *Policy) init() error {
+	p.embargoed = make(map[string]bool)
+	p.byKey = make(map[string]*Principal)
+	p.bySubject = make(map[string]*Principal)
+
+	for _, r := range p.Embargoed {
+		p.embargoed[strings.ToLower(r)] = true
+	}
+	for i := range p.Principals {
+		pr := &p.Principals[i]
+		if pr.Name == "" {
+			return fmt.Errorf("principal %d has no name", i)
+		}
+		for _, k := range pr.APIKeys {
+			k = strings.ToLower(k)
+			if _, err := hex.DecodeString(k); err != nil || len(k) != sha256.Size*2 {
+				return fmt.Errorf("principal %v: api key %q is not a hex encoded SHA-256 digest", pr.Name, k)
+			}
+			if o, ok := p.byKey[k]; ok {
+				return fmt.Errorf("principals %v and %v share an api key", o.Name, pr.Name)
+			}
+			p.byKey[k] = pr
+		}
+		for _, s := range pr.Subjects {
+			s = strings.ToLower(s)
+			if o, ok := p.bySubject[s]; ok {
+				return fmt.Errorf("principals %v and %v share subject %v", o.Name, pr.Name, s)
+			}
+			p.bySubject[s] = pr
+		}
+		for _, g := range pr.Allow {
+			if (g.Owner == "") == (g.Repo == "") {
+				return fmt.Errorf("principal %v: a grant needs exactly one of owner or repo", pr.Name)
+			}
+			if g.Repo != "" && len(strings.Split(g.Repo, "/")) != 2 {
+				return fmt.Errorf("principal %v: repo %q is not of the form owner/name", pr.Name, g.Repo)
+			}
+		}
+	}
+	return nil
+}
+
+// principalForKey returns the principal holding the API key, or nil.
+func (p *Policy) principalForKey(key string) *Principal {
+	sum := sha256.Sum256([]byte(key))
+	return p.byKey[hex.EncodeToString(sum[:])]
+}
+
+// principalForSubject returns the principal with the JWT subject, or nil.
+func (p *Policy) principalForSubject(sub string) *Principal {
+	return p.bySubject[strings.ToLower(sub)]
+}
+
+// CanRead reports whether pr may read the repository owner/name. Owners and
+// names are compared case-insensitively, as GitHub does.
+func (p *Policy) CanRead(pr *Principal, owner, name string) bool {
+	if pr == nil {
+		return false
+	}
+	repo := strings.ToLower(owner + "/" + name)
+	embargoed := p.embargoed[repo]
+	for _, g := range pr.Allow {
+		switch {
+		case g.Repo != "":
+			if strings.ToLower(g.Repo) == repo {
+				return true
+			}
+		case embargoed:
+			// Only an explicit grant opens an embargoed repository.
+		case g.Owner == Wildcard || strings.EqualFold(g.Owner, owner):
+			return true
+		}
+	}
+	return false
+}
+
+// CanReadOwner reports whether pr may read some repository of owner.
+func (p *Policy) CanReadOwner(pr *Principal, owner string) bool {
+	if pr == nil {
+		return false
+	}
+	for _, g := range pr.Allow {
+		if g.Owner == Wildcard || strings.EqualFold(g.Owner, owner) {
+			return true
+		}
+		if g.Repo != "" && strings.EqualFold(strings.Split(g.Repo, "/")[0], owner) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanReadAll reports whether pr may read every repository of owner that is
+// not embargoed, i.e. holds a grant for owner or for every owner.
+func (p *Policy) CanReadAll(pr *Principal, owner string) bool {
+	if pr == nil {
+		return false
+	}
+	for _, g := range pr.Allow {
+		if g.Owner == Wildcard || (g.Owner != "" && strings.EqualFold(g.Owner, owner)) {
+			return true
+		}
+	}
+	return false
+}
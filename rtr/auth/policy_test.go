@@ -0,0 +1,181 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func keyHash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+const testPolicy = `
+embargoed:
+- googleapis/Security-Fixes
+principals:
+- name: dashboards
+  api_keys: [%DASHBOARDS%]
+  allow:
+  - owner: GoogleCloudPlatform
+  - repo: googleapis/google-cloud-go
+- name: everyone
+  subjects: [carol@example.com]
+  allow:
+  - owner: "*"
+- name: security-team
+  subjects: [Alice@example.com, "1234567890"]
+  allow:
+  - owner: "*"
+  - repo: googleapis/security-fixes
+`
+
+func writePolicy(t *testing.T, dir, yml string) string {
+	t.Helper()
+	path := filepath.Join(dir, "policy.yaml")
+	if err := ioutil.WriteFile(path, []byte(yml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func tempDir(t *testing.T) (string, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func loadTestPolicy(t *testing.T) *Policy {
+	t.Helper()
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	yml := strings.ReplaceAll(testPolicy, "%DASHBOARDS%", keyHash("dashboards-key"))
+	p, err := LoadPolicy(writePolicy(t, dir, yml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestPolicyCanRead(t *testing.T) {
+	p := loadTestPolicy(t)
+	dashboards := p.principalForKey("dashboards-key")
+	everyone := p.principalForSubject("carol@example.com")
+	security := p.principalForSubject("alice@EXAMPLE.com")
+	if dashboards == nil || everyone == nil || security == nil {
+		t.Fatalf("principals not found: %v %v %v", dashboards, everyone, security)
+	}
+	if p.principalForKey("wrong-key") != nil {
+		t.Errorf("principalForKey() of an unknown key. Wanted nil")
+	}
+
+	tests := []struct {
+		Principal *Principal
+		Owner     string
+		Name      string
+		Want      bool
+	}{
+		{dashboards, "GoogleCloudPlatform", "golang-samples", true},
+		{dashboards, "googlecloudplatform", "golang-samples", true},
+		{dashboards, "googleapis", "google-cloud-go", true},
+		{dashboards, "googleapis", "google-cloud-java", false},
+		{dashboards, "googleapis", "security-fixes", false},
+		{everyone, "googleapis", "google-cloud-java", true},
+		// A wildcard grant does not open an embargoed repository.
+		{everyone, "googleapis", "security-fixes", false},
+		{security, "googleapis", "security-fixes", true},
+		{security, "GoogleAPIs", "Security-Fixes", true},
+		{nil, "googleapis", "google-cloud-go", false},
+	}
+	for _, c := range tests {
+		name := "<nil>"
+		if c.Principal != nil {
+			name = c.Principal.Name
+		}
+		if got := p.CanRead(c.Principal, c.Owner, c.Name); got != c.Want {
+			t.Errorf("CanRead(%v, %v/%v) Wanted %v, Got %v", name, c.Owner, c.Name, c.Want, got)
+		}
+	}
+
+	ownerTests := []struct {
+		Principal *Principal
+		Owner     string
+		Want      bool
+	}{
+		{dashboards, "GoogleCloudPlatform", true},
+		{dashboards, "googleapis", true},
+		{dashboards, "golang", false},
+		{everyone, "golang", true},
+		{nil, "golang", false},
+	}
+	for _, c := range ownerTests {
+		if got := p.CanReadOwner(c.Principal, c.Owner); got != c.Want {
+			t.Errorf("CanReadOwner(%v) Wanted %v, Got %v", c.Owner, c.Want, got)
+		}
+	}
+
+	allTests := []struct {
+		Principal *Principal
+		Owner     string
+		Want      bool
+	}{
+		{dashboards, "GoogleCloudPlatform", true},
+		{dashboards, "googlecloudplatform", true},
+		// A grant for one repository does not cover the others.
+		{dashboards, "googleapis", false},
+		{everyone, "golang", true},
+		{nil, "golang", false},
+	}
+	for _, c := range allTests {
+		if got := p.CanReadAll(c.Principal, c.Owner); got != c.Want {
+			t.Errorf("CanReadAll(%v) Wanted %v, Got %v", c.Owner, c.Want, got)
+		}
+	}
+}
+
+func TestLoadPolicyInvalid(t *testing.T) {
+	tests := []struct {
+		Name string
+		YAML string
+	}{
+		{Name: "Unknown field", YAML: "principal: []"},
+		{Name: "Missing name", YAML: "principals: [{subjects: [a]}]"},
+		{Name: "Plain api key", YAML: "principals: [{name: a, api_keys: [secret]}]"},
+		{Name: "Shared subject", YAML: "principals: [{name: a, subjects: [x]}, {name: b, subjects: [X]}]"},
+		{Name: "Empty grant", YAML: "principals: [{name: a, allow: [{}]}]"},
+		{Name: "Both in grant", YAML: "principals: [{name: a, allow: [{owner: o, repo: o/r}]}]"},
+		{Name: "Bad repo", YAML: "principals: [{name: a, allow: [{repo: o}]}]"},
+	}
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	for _, c := range tests {
+		if _, err := LoadPolicy(writePolicy(t, dir, c.YAML)); err == nil {
+			t.Errorf("%v: LoadPolicy() Wanted an error, Got nil", c.Name)
+		}
+	}
+	if _, err := LoadPolicy("/does/not/exist.yaml"); err == nil {
+		t.Errorf("LoadPolicy() of a missing file. Wanted an error, Got nil")
+	}
+}
@@ -17,8 +17,15 @@ module github.com/GoogleCloudPlatform/devrel-services/rtr
 go 1.12
 
 require (
-	cloud.google.com/go v0.40.0
+	github.com/golang/protobuf v1.4.2
 	github.com/gorilla/mux v1.7.2
 	github.com/sirupsen/logrus v1.4.2
 	github.com/urfave/negroni v1.0.0
+	golang.org/x/net v0.0.0-20190503192946-f4e77d36d62c // indirect
+	golang.org/x/sys v0.0.0-20190507160741-ecd444e8653b // indirect
+	golang.org/x/text v0.3.2 // indirect
+	golang.org/x/time v0.0.0-20200630173020-3af7569d3a1e
+	google.golang.org/genproto v0.0.0-20200730144737-007c33dbd381
+	google.golang.org/grpc v1.30.0
+	gopkg.in/yaml.v2 v2.2.7
 )
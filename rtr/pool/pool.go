@@ -0,0 +1,241 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pool provides long-lived gRPC connections to the backends a
+// router proxies to.
+//
+// A Pool keeps one *grpc.ClientConn per backend address and hands it out to
+// every caller, so requests no longer pay for a handshake each. Connections
+// that have not been used for a while are closed, connections whose backend
+// was unreachable are retried right away on their next use rather than after
+// gRPC's backoff, and the number of calls in flight to any one backend can
+// be bounded.
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// DefaultIdleTimeout is how long a connection may go unused before it is
+// closed when Config.IdleTimeout is not set.
+const DefaultIdleTimeout = 5 * time.Minute
+
+// ErrClosed is returned by Get once the Pool has been closed.
+var ErrClosed = errors.New("pool: closed")
+
+// Config configures a Pool.
+type Config struct {
+	// IdleTimeout is how long a connection may go unused before it is
+	// closed. Defaults to DefaultIdleTimeout.
+	IdleTimeout time.Duration
+
+	// MaxConcurrentPerBackend bounds the number of connections handed out
+	// for one backend that have not been released yet. Get blocks until one
+	// is released. Zero means unbounded.
+	MaxConcurrentPerBackend int
+
+	// DialOptions are used for every connection the Pool dials.
+	DialOptions []grpc.DialOption
+}
+
+// Pool is a set of gRPC connections keyed by backend address.
+// It is safe for concurrent use.
+type Pool struct {
+	cfg Config
+
+	mu     sync.Mutex
+	conns  map[string]*entry
+	closed bool
+	done   chan struct{}
+}
+
+type entry struct {
+	conn     *grpc.ClientConn
+	sem      chan struct{}
+	inUse    int
+	lastUsed time.Time
+}
+
+// New returns a Pool configured by cfg. Its idle connections are closed in
+// the background until Close is called.
+func New(cfg Config) *Pool {
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = DefaultIdleTimeout
+	}
+	p := &Pool{
+		cfg:   cfg,
+		conns: make(map[string]*entry),
+		done:  make(chan struct{}),
+	}
+	go p.evictLoop()
+	return p
+}
+
+// Get returns a connection to addr, dialing one if there is none yet. The
+// returned release func must be called once the caller is done with the
+// connection; the connection itself must not be closed.
+func (p *Pool) Get(ctx context.Context, addr string) (*grpc.ClientConn, func(), error) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, nil, ErrClosed
+	}
+	e, ok := p.conns[addr]
+	if !ok {
+		e = &entry{}
+		if p.cfg.MaxConcurrentPerBackend > 0 {
+			e.sem = make(chan struct{}, p.cfg.MaxConcurrentPerBackend)
+		}
+		p.conns[addr] = e
+	}
+	// Count the caller before waiting so the entry is not evicted while
+	// the caller waits for a slot.
+	e.inUse++
+	sem := e.sem
+	p.mu.Unlock()
+
+	if sem != nil {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			p.mu.Lock()
+			e.inUse--
+			e.lastUsed = time.Now()
+			p.mu.Unlock()
+			return nil, nil, ctx.Err()
+		}
+	}
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			p.mu.Lock()
+			e.inUse--
+			e.lastUsed = time.Now()
+			p.mu.Unlock()
+			if sem != nil {
+				<-sem
+			}
+		})
+	}
+
+	conn, err := p.conn(ctx, addr, e)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	return conn, release, nil
+}
+
+// conn returns the connection of e, dialing addr if e has none or its
+// connection was shut down.
+func (p *Pool) conn(ctx context.Context, addr string, e *entry) (*grpc.ClientConn, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil, ErrClosed
+	}
+	if e.conn != nil {
+		switch e.conn.GetState() {
+		case connectivity.Shutdown:
+			e.conn = nil
+		case connectivity.TransientFailure:
+			// The backend was unreachable the last time gRPC tried. Retry
+			// now rather than wait out the backoff; closing the connection
+			// instead would fail the calls other callers have in flight.
+			e.conn.ResetConnectBackoff()
+		}
+	}
+	if e.conn == nil {
+		// Dialing is non-blocking, so holding the lock here is cheap.
+		conn, err := grpc.DialContext(ctx, addr, p.cfg.DialOptions...)
+		if err != nil {
+			return nil, err
+		}
+		e.conn = conn
+	}
+	return e.conn, nil
+}
+
+// Len returns the number of backends the Pool holds a connection to.
+func (p *Pool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := 0
+	for _, e := range p.conns {
+		if e.conn != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// Close closes every connection in the Pool. Connections still in use are
+// closed too, failing their calls.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+	close(p.done)
+	var err error
+	for addr, e := range p.conns {
+		if e.conn != nil {
+			if cerr := e.conn.Close(); cerr != nil && err == nil {
+				err = cerr
+			}
+		}
+		delete(p.conns, addr)
+	}
+	return err
+}
+
+func (p *Pool) evictLoop() {
+	// Checking twice per timeout bounds how long past its timeout an idle
+	// connection lingers.
+	ticker := time.NewTicker(p.cfg.IdleTimeout / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.done:
+			return
+		case now := <-ticker.C:
+			p.evictIdle(now)
+		}
+	}
+}
+
+// evictIdle closes the connections not in use that were last used more
+// than the idle timeout before now.
+func (p *Pool) evictIdle(now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for addr, e := range p.conns {
+		if e.inUse > 0 || now.Sub(e.lastUsed) < p.cfg.IdleTimeout {
+			continue
+		}
+		if e.conn != nil {
+			e.conn.Close()
+		}
+		delete(p.conns, addr)
+	}
+}
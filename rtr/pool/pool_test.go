@@ -0,0 +1,160 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pool
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newTestPool(t *testing.T, cfg Config) (*Pool, func()) {
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	healthpb.RegisterHealthServer(s, health.NewServer())
+	go s.Serve(lis)
+
+	cfg.DialOptions = append(cfg.DialOptions,
+		grpc.WithInsecure(),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.Dial()
+		}),
+	)
+	p := New(cfg)
+	return p, func() {
+		p.Close()
+		s.Stop()
+	}
+}
+
+func TestPoolReusesConnections(t *testing.T) {
+	p, cleanup := newTestPool(t, Config{})
+	defer cleanup()
+	ctx := context.Background()
+
+	c1, release1, err := p.Get(ctx, "backend-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := healthpb.NewHealthClient(c1).Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("Check() through pooled connection failed: %v", err)
+	}
+	release1()
+
+	c2, release2, err := p.Get(ctx, "backend-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release2()
+	if c1 != c2 {
+		t.Errorf("Get() dialed a new connection for the same backend")
+	}
+
+	c3, release3, err := p.Get(ctx, "backend-b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release3()
+	if c3 == c1 {
+		t.Errorf("Get() returned the same connection for different backends")
+	}
+	if p.Len() != 2 {
+		t.Errorf("Len() Wanted 2, Got %v", p.Len())
+	}
+}
+
+func TestPoolEvictsIdleConnections(t *testing.T) {
+	p, cleanup := newTestPool(t, Config{IdleTimeout: time.Hour})
+	defer cleanup()
+	ctx := context.Background()
+
+	_, releaseA, err := p.Get(ctx, "backend-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, releaseB, err := p.Get(ctx, "backend-b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	releaseA()
+	// backend-b is still in use.
+	defer releaseB()
+
+	p.evictIdle(time.Now().Add(30 * time.Minute))
+	if p.Len() != 2 {
+		t.Errorf("Len() before the idle timeout. Wanted 2, Got %v", p.Len())
+	}
+
+	p.evictIdle(time.Now().Add(2 * time.Hour))
+	if p.Len() != 1 {
+		t.Errorf("Len() after the idle timeout. Wanted 1, Got %v", p.Len())
+	}
+}
+
+func TestPoolBoundsConcurrency(t *testing.T) {
+	p, cleanup := newTestPool(t, Config{MaxConcurrentPerBackend: 1})
+	defer cleanup()
+
+	_, release, err := p.Get(context.Background(), "backend-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, _, err := p.Get(ctx, "backend-a"); err != context.DeadlineExceeded {
+		t.Errorf("Get() at the concurrency limit. Wanted %v, Got %v", context.DeadlineExceeded, err)
+	}
+
+	// Other backends are not affected.
+	_, releaseB, err := p.Get(context.Background(), "backend-b")
+	if err != nil {
+		t.Errorf("Get() of another backend failed: %v", err)
+	} else {
+		releaseB()
+	}
+
+	release()
+	// Releasing twice must not free a second slot.
+	release()
+
+	_, release2, err := p.Get(context.Background(), "backend-a")
+	if err != nil {
+		t.Fatalf("Get() after release failed: %v", err)
+	}
+	defer release2()
+
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel2()
+	if _, _, err := p.Get(ctx2, "backend-a"); err != context.DeadlineExceeded {
+		t.Errorf("Get() after a double release. Wanted %v, Got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestPoolClosed(t *testing.T) {
+	p, cleanup := newTestPool(t, Config{})
+	defer cleanup()
+
+	p.Close()
+	if _, _, err := p.Get(context.Background(), "backend-a"); err != ErrClosed {
+		t.Errorf("Get() after Close() Wanted %v, Got %v", ErrClosed, err)
+	}
+}
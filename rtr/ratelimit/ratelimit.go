@@ -0,0 +1,334 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ratelimit throttles the callers of a gRPC server.
+//
+// Every caller gets a token bucket per method, so one runaway client can't
+// starve the others nor the backends behind the server. Callers are told
+// apart by the principal the auth package authenticated them as or, without
+// one, by their address. Behind a proxy such as ESP every call comes from
+// the proxy's address, so calls from a trusted proxy are told apart by the
+// API key the proxy validated or the address it forwards in
+// x-forwarded-for instead. Other callers could set those to anything, so
+// they are ignored. Throttled calls fail with RESOURCE_EXHAUSTED and a
+// RetryInfo detail saying when to try again.
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"expvar"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoogleCloudPlatform/devrel-services/rtr/auth"
+	"github.com/golang/protobuf/ptypes"
+	"golang.org/x/time/rate"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"gopkg.in/yaml.v2"
+)
+
+// idleTimeout is how long a caller's buckets are kept after its last call.
+const idleTimeout = 10 * time.Minute
+
+// exempt are full method name prefixes never throttled.
+var exempt = []string{"/grpc.health.v1.Health/"}
+
+// Metrics, published on /debug/vars. They are kept by method only: callers
+// are unbounded, so counting by caller would grow without limit.
+var (
+	allowed   = expvar.NewMap("ratelimit_allowed")
+	throttled = expvar.NewMap("ratelimit_throttled")
+)
+
+// Quota is a token bucket refilled at RPS tokens per second that holds at
+// most Burst tokens. A zero RPS means unlimited.
+type Quota struct {
+	RPS   float64 `yaml:"rps"`
+	Burst int     `yaml:"burst"`
+}
+
+func (q Quota) unlimited() bool {
+	return q.RPS <= 0
+}
+
+// Quotas are the quotas of a caller: one per method, by full method name,
+// and a default for every other method.
+type Quotas struct {
+	Default Quota            `yaml:"default"`
+	Methods map[string]Quota `yaml:"methods"`
+}
+
+// Config holds the quotas of every caller. Principals override the quotas
+// of the callers authenticated as them, method by method.
+//
+// TrustedProxies are the addresses, or CIDR ranges, of the proxies whose
+// x-api-key and x-forwarded-for headers are trusted. The proxies must
+// validate the API keys they pass on. Without any, only proxies on the
+// loopback interface are trusted, such as ESP running beside the server.
+//
+// An example config file:
+//
+//	default: {rps: 5, burst: 10}
+//	methods:
+//	  /drghs.v1.IssueService/ListIssues: {rps: 1, burst: 5}
+//	principals:
+//	  dashboards:
+//	    default: {rps: 50, burst: 100}
+//	trusted_proxies: [10.0.0.0/8]
+type Config struct {
+	Quotas         `yaml:",inline"`
+	Principals     map[string]Quotas `yaml:"principals"`
+	TrustedProxies []string          `yaml:"trusted_proxies"`
+
+	trusted []*net.IPNet
+}
+
+// LoadConfig reads the Config in the YAML file at path.
+func LoadConfig(path string) (*Config, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &Config{}
+	if err := yaml.UnmarshalStrict(b, c); err != nil {
+		return nil, fmt.Errorf("parsing %v: %v", path, err)
+	}
+	for _, p := range c.TrustedProxies {
+		if !strings.Contains(p, "/") {
+			if ip := net.ParseIP(p); ip != nil && ip.To4() != nil {
+				p += "/32"
+			} else {
+				p += "/128"
+			}
+		}
+		_, n, err := net.ParseCIDR(p)
+		if err != nil {
+			return nil, fmt.Errorf("%v: invalid trusted proxy: %v", path, err)
+		}
+		c.trusted = append(c.trusted, n)
+	}
+	return c, nil
+}
+
+// Load returns the Config in the YAML file at path, if any, giving def to
+// callers and methods it sets no quota for. It returns nil if neither path
+// nor def limit anything.
+func Load(path string, def Quota) (*Config, error) {
+	c := &Config{}
+	if path != "" {
+		var err error
+		if c, err = LoadConfig(path); err != nil {
+			return nil, err
+		}
+	}
+	if c.Default == (Quota{}) {
+		c.Default = def
+	}
+	if c.Default.unlimited() && len(c.Methods) == 0 && len(c.Principals) == 0 {
+		return nil, nil
+	}
+	return c, nil
+}
+
+// trustedProxy reports whether the headers of calls from ip are trusted.
+func (c *Config) trustedProxy(ip net.IP) bool {
+	if len(c.trusted) == 0 {
+		return ip.IsLoopback()
+	}
+	for _, n := range c.trusted {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// quota returns the quota of principal, which may be empty, for method.
+func (c *Config) quota(principal, method string) Quota {
+	if p, ok := c.Principals[principal]; ok && principal != "" {
+		if q, ok := p.Methods[method]; ok {
+			return q
+		}
+		if p.Default != (Quota{}) {
+			return p.Default
+		}
+	}
+	if q, ok := c.Methods[method]; ok {
+		return q
+	}
+	return c.Default
+}
+
+// Limiter holds a token bucket per caller and method.
+// It is safe for concurrent use.
+type Limiter struct {
+	cfg *Config
+	now func() time.Time
+
+	mu      sync.Mutex
+	buckets map[bucketKey]*bucket
+	swept   time.Time
+}
+
+type bucketKey struct {
+	caller string
+	method string
+}
+
+type bucket struct {
+	lim      *rate.Limiter
+	lastUsed time.Time
+}
+
+// NewLimiter returns a Limiter enforcing cfg.
+func NewLimiter(cfg *Config) *Limiter {
+	return &Limiter{
+		cfg:     cfg,
+		now:     time.Now,
+		buckets: make(map[bucketKey]*bucket),
+	}
+}
+
+// allow takes a token from the bucket of caller for method. If there is
+// none, it returns how long until there is.
+func (l *Limiter) allow(caller, principal, method string) (bool, time.Duration) {
+	q := l.cfg.quota(principal, method)
+	if q.unlimited() {
+		return true, 0
+	}
+
+	now := l.now()
+	l.mu.Lock()
+	l.sweep(now)
+	k := bucketKey{caller, method}
+	b, ok := l.buckets[k]
+	if !ok {
+		burst := q.Burst
+		if burst < 1 {
+			burst = 1
+		}
+		b = &bucket{lim: rate.NewLimiter(rate.Limit(q.RPS), burst)}
+		l.buckets[k] = b
+	}
+	b.lastUsed = now
+	l.mu.Unlock()
+
+	r := b.lim.ReserveN(now, 1)
+	if d := r.DelayFrom(now); d > 0 {
+		r.CancelAt(now)
+		return false, d
+	}
+	return true, 0
+}
+
+// sweep drops the buckets of callers idle for a while. l.mu must be held.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < idleTimeout {
+		return
+	}
+	l.swept = now
+	for k, b := range l.buckets {
+		if now.Sub(b.lastUsed) > idleTimeout {
+			delete(l.buckets, k)
+		}
+	}
+}
+
+// UnaryServerInterceptor throttles unary calls. It must run after the auth
+// interceptor, if any, to tell callers apart by principal.
+func (l *Limiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := l.check(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor throttles the start of streaming calls.
+func (l *Limiter) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := l.check(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+func (l *Limiter) check(ctx context.Context, method string) error {
+	for _, p := range exempt {
+		if strings.HasPrefix(method, p) {
+			return nil
+		}
+	}
+	caller, principal := l.callerOf(ctx)
+	ok, delay := l.allow(caller, principal, method)
+	if ok {
+		allowed.Add(method, 1)
+		return nil
+	}
+	throttled.Add(method, 1)
+
+	st := status.New(codes.ResourceExhausted, fmt.Sprintf("rate limit exceeded for %v on %v, retry in %v", caller, method, delay.Round(time.Millisecond)))
+	if d, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: ptypes.DurationProto(delay)}); err == nil {
+		st = d
+	}
+	return st.Err()
+}
+
+// callerOf identifies the caller in ctx: by principal if it was
+// authenticated, then, for calls from a trusted proxy, by the API key and
+// the address it forwarded, and by host. API keys are hashed so they don't
+// show in errors.
+func (l *Limiter) callerOf(ctx context.Context) (caller, principal string) {
+	if pr := auth.FromContext(ctx); pr != nil {
+		return "principal:" + pr.Name, pr.Name
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return "unknown", ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+	if ip := net.ParseIP(host); ip == nil || !l.cfg.trustedProxy(ip) {
+		return "addr:" + host, ""
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	if keys := md.Get("x-api-key"); len(keys) > 0 && keys[0] != "" {
+		sum := sha256.Sum256([]byte(keys[0]))
+		return fmt.Sprintf("key:%x", sum[:8]), ""
+	}
+	if fwd := md.Get("x-forwarded-for"); len(fwd) > 0 {
+		// The proxy appends the address it was called from; the entries
+		// before it were sent by the caller and can't be trusted.
+		hops := strings.Split(fwd[len(fwd)-1], ",")
+		if h := strings.TrimSpace(hops[len(hops)-1]); h != "" {
+			return "addr:" + h, ""
+		}
+	}
+	return "addr:" + host, ""
+}
@@ -0,0 +1,258 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/devrel-services/rtr/auth"
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	listIssues = "/drghs.v1.IssueService/ListIssues"
+	getIssue   = "/drghs.v1.IssueService/GetIssue"
+)
+
+const testConfig = `
+default: {rps: 1, burst: 2}
+methods:
+  /drghs.v1.IssueService/ListIssues: {rps: 1, burst: 1}
+principals:
+  dashboards:
+    default: {rps: 10, burst: 5}
+  batch:
+    methods:
+      /drghs.v1.IssueService/GetIssue: {rps: 0}
+`
+
+func loadTestConfig(t *testing.T, yml string) (*Config, error) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "ratelimit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "ratelimit.yaml")
+	if err := ioutil.WriteFile(path, []byte(yml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return LoadConfig(path)
+}
+
+func TestConfigQuota(t *testing.T) {
+	cfg, err := loadTestConfig(t, testConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		Principal string
+		Method    string
+		Want      Quota
+	}{
+		{"", getIssue, Quota{RPS: 1, Burst: 2}},
+		{"", listIssues, Quota{RPS: 1, Burst: 1}},
+		{"unknown", getIssue, Quota{RPS: 1, Burst: 2}},
+		{"dashboards", getIssue, Quota{RPS: 10, Burst: 5}},
+		{"dashboards", listIssues, Quota{RPS: 10, Burst: 5}},
+		{"batch", getIssue, Quota{}},
+		{"batch", listIssues, Quota{RPS: 1, Burst: 1}},
+	}
+	for _, c := range tests {
+		if got := cfg.quota(c.Principal, c.Method); got != c.Want {
+			t.Errorf("quota(%q, %v) Wanted %v, Got %v", c.Principal, c.Method, c.Want, got)
+		}
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	if _, err := loadTestConfig(t, "defaults: {rps: 1}"); err == nil {
+		t.Errorf("LoadConfig() with an unknown field. Wanted an error, Got nil")
+	}
+	if _, err := loadTestConfig(t, "trusted_proxies: [not-an-address]"); err == nil {
+		t.Errorf("LoadConfig() with an invalid trusted proxy. Wanted an error, Got nil")
+	}
+	if _, err := LoadConfig("/does/not/exist.yaml"); err == nil {
+		t.Errorf("LoadConfig() of a missing file. Wanted an error, Got nil")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	def := Quota{RPS: 3, Burst: 3}
+	cfg, err := Load("", def)
+	if err != nil || cfg == nil || cfg.Default != def {
+		t.Errorf("Load() without a file. Wanted default %v, Got %v (%v)", def, cfg, err)
+	}
+	if cfg, err := Load("", Quota{}); err != nil || cfg != nil {
+		t.Errorf("Load() without limits. Wanted nil, Got %v (%v)", cfg, err)
+	}
+	if _, err := Load("/does/not/exist.yaml", def); err == nil {
+		t.Errorf("Load() of a missing file. Wanted an error, Got nil")
+	}
+}
+
+func TestLimiterAllow(t *testing.T) {
+	now := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	l := NewLimiter(&Config{Quotas: Quotas{Default: Quota{RPS: 2, Burst: 2}}})
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.allow("a", "", getIssue); !ok {
+			t.Fatalf("allow() call %v within the burst. Wanted true, Got false", i)
+		}
+	}
+	ok, delay := l.allow("a", "", getIssue)
+	if ok {
+		t.Fatalf("allow() past the burst. Wanted false, Got true")
+	}
+	if delay != 500*time.Millisecond {
+		t.Errorf("allow() delay Wanted %v, Got %v", 500*time.Millisecond, delay)
+	}
+
+	// Other callers and other methods have buckets of their own.
+	if ok, _ := l.allow("b", "", getIssue); !ok {
+		t.Errorf("allow() for another caller. Wanted true, Got false")
+	}
+	if ok, _ := l.allow("a", "", listIssues); !ok {
+		t.Errorf("allow() for another method. Wanted true, Got false")
+	}
+
+	// Refused calls don't use tokens up.
+	now = now.Add(delay)
+	if ok, _ := l.allow("a", "", getIssue); !ok {
+		t.Errorf("allow() after waiting the delay. Wanted true, Got false")
+	}
+}
+
+func TestLimiterSweep(t *testing.T) {
+	now := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	l := NewLimiter(&Config{Quotas: Quotas{Default: Quota{RPS: 1, Burst: 1}}})
+	l.now = func() time.Time { return now }
+
+	l.allow("a", "", getIssue)
+	now = now.Add(idleTimeout / 2)
+	l.allow("b", "", getIssue)
+	now = now.Add(idleTimeout/2 + time.Second)
+	l.allow("c", "", getIssue)
+
+	if _, ok := l.buckets[bucketKey{"a", getIssue}]; ok {
+		t.Errorf("bucket of an idle caller was kept")
+	}
+	if got := len(l.buckets); got != 2 {
+		t.Errorf("len(buckets) Wanted 2, Got %v", got)
+	}
+}
+
+func TestCallerOf(t *testing.T) {
+	from := func(ip string, kv ...string) context.Context {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 1234}})
+		return metadata.NewIncomingContext(ctx, metadata.Pairs(kv...))
+	}
+	def := NewLimiter(&Config{})
+	cfg, err := loadTestConfig(t, "trusted_proxies: [10.0.0.0/8, 192.168.1.1]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	configured := NewLimiter(cfg)
+
+	tests := []struct {
+		Name          string
+		Limiter       *Limiter
+		Ctx           context.Context
+		Want          string
+		WantPrincipal string
+	}{
+		{Name: "Principal", Limiter: def, Ctx: auth.NewContext(from("1.2.3.4", "x-api-key", "secret"), &auth.Principal{Name: "dashboards"}), Want: "principal:dashboards", WantPrincipal: "dashboards"},
+		{Name: "API key", Limiter: def, Ctx: from("127.0.0.1", "x-api-key", "secret", "x-forwarded-for", "10.0.0.1"), Want: "key:2bb80d537b1da3e3"},
+		{Name: "Forwarded", Limiter: def, Ctx: from("127.0.0.1", "x-forwarded-for", "10.0.0.1"), Want: "addr:10.0.0.1"},
+		{Name: "Forwarded through proxies", Limiter: def, Ctx: from("127.0.0.1", "x-forwarded-for", "1.2.3.4, 10.0.0.2"), Want: "addr:10.0.0.2"},
+		{Name: "Peer", Limiter: def, Ctx: from("127.0.0.1"), Want: "addr:127.0.0.1"},
+		{Name: "Untrusted API key", Limiter: def, Ctx: from("1.2.3.4", "x-api-key", "secret"), Want: "addr:1.2.3.4"},
+		{Name: "Untrusted forwarded", Limiter: def, Ctx: from("1.2.3.4", "x-forwarded-for", "10.0.0.1"), Want: "addr:1.2.3.4"},
+		{Name: "Configured proxy range", Limiter: configured, Ctx: from("10.1.2.3", "x-api-key", "secret"), Want: "key:2bb80d537b1da3e3"},
+		{Name: "Configured proxy address", Limiter: configured, Ctx: from("192.168.1.1", "x-forwarded-for", "1.2.3.4"), Want: "addr:1.2.3.4"},
+		{Name: "Loopback not configured", Limiter: configured, Ctx: from("127.0.0.1", "x-forwarded-for", "1.2.3.4"), Want: "addr:127.0.0.1"},
+		{Name: "Unknown", Limiter: def, Ctx: context.Background(), Want: "unknown"},
+	}
+	for _, c := range tests {
+		got, principal := c.Limiter.callerOf(c.Ctx)
+		if got != c.Want || principal != c.WantPrincipal {
+			t.Errorf("%v: Wanted %v, %q Got %v, %q", c.Name, c.Want, c.WantPrincipal, got, principal)
+		}
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	l := NewLimiter(&Config{Quotas: Quotas{Default: Quota{RPS: 1, Burst: 1}}})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	call := func(ctx context.Context, method string) error {
+		_, err := l.UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+
+	addr := func(ip string) context.Context {
+		return peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 1234}})
+	}
+	tests := []struct {
+		Name string
+		Ctx  context.Context
+		Want codes.Code
+	}{
+		{Name: "First call", Ctx: addr("10.0.0.1"), Want: codes.OK},
+		{Name: "Same host, other port", Ctx: peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 4321}}), Want: codes.ResourceExhausted},
+		{Name: "Other host", Ctx: addr("10.0.0.2"), Want: codes.OK},
+		{Name: "Principal", Ctx: auth.NewContext(addr("10.0.0.1"), &auth.Principal{Name: "dashboards"}), Want: codes.OK},
+		{Name: "Principal again", Ctx: auth.NewContext(addr("10.0.0.2"), &auth.Principal{Name: "dashboards"}), Want: codes.ResourceExhausted},
+	}
+	for _, c := range tests {
+		if got := status.Code(call(c.Ctx, getIssue)); got != c.Want {
+			t.Errorf("%v: Wanted %v, Got %v", c.Name, c.Want, got)
+		}
+	}
+
+	err := call(addr("10.0.0.1"), getIssue)
+	var info *errdetails.RetryInfo
+	for _, d := range status.Convert(err).Details() {
+		if ri, ok := d.(*errdetails.RetryInfo); ok {
+			info = ri
+		}
+	}
+	if info == nil {
+		t.Fatalf("throttled error has no RetryInfo: %v", err)
+	}
+	if d, err := ptypes.Duration(info.RetryDelay); err != nil || d <= 0 || d > time.Second {
+		t.Errorf("RetryInfo delay Wanted in (0, 1s], Got %v (%v)", d, err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := call(addr("10.0.0.1"), "/grpc.health.v1.Health/Check"); err != nil {
+			t.Errorf("health check %v was throttled: %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package resolver finds the address of the backend serving a repository.
+//
+// The routers and the sweeper used to hash repository names into Kubernetes
+// Service names themselves. A Resolver hides that, so the same binaries can
+// also run against backends listed in a file, e.g. maintnerd and samplrd
+// processes started locally, or against backends published as DNS SRV
+// records.
+package resolver
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Names of the ports a backend serves. They are the names of the ports of
+// the Services the supervisors create.
+const (
+	// PortGRPC is the port of the public gRPC API.
+	PortGRPC = "http"
+	// PortInternal is the port of the internal gRPC API.
+	PortInternal = "internal"
+)
+
+// ErrNotFound is returned when a Resolver knows no backend for a repository
+// or port.
+var ErrNotFound = errors.New("resolver: no backend found")
+
+// Resolver finds the address of the backend serving a repository.
+type Resolver interface {
+	// Resolve returns the host:port to dial for the named port of the
+	// backend serving the repository owner/name.
+	Resolve(ctx context.Context, owner, name, port string) (string, error)
+}
+
+// DefaultPorts are the port numbers backends serve on in Kubernetes.
+var DefaultPorts = map[string]string{
+	PortGRPC:     "80",
+	PortInternal: "8080",
+}
+
+// K8s resolves repositories to the Services the supervisors create for
+// them, named Prefix followed by the repository's hash.
+type K8s struct {
+	Prefix string
+	// Ports maps port names to numbers. Defaults to DefaultPorts.
+	Ports map[string]string
+}
+
+// NewK8s returns a K8s Resolver for the Services named with prefix.
+func NewK8s(prefix string) *K8s {
+	return &K8s{Prefix: prefix, Ports: DefaultPorts}
+}
+
+// Resolve implements Resolver.
+func (k *K8s) Resolve(ctx context.Context, owner, name, port string) (string, error) {
+	p, ok := k.ports()[port]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return net.JoinHostPort(ServiceName(k.Prefix, owner, name), p), nil
+}
+
+func (k *K8s) ports() map[string]string {
+	if k.Ports == nil {
+		return DefaultPorts
+	}
+	return k.Ports
+}
+
+// ServiceName returns the name of the Kubernetes Service for the repository
+// owner/name, e.g. "mtr-s-<sha>" for prefix "mtr-s-". It matches the names
+// the supervisors give the Services they create.
+func ServiceName(prefix, owner, name string) string {
+	sh := sha256.Sum224([]byte(fmt.Sprintf("%v/%v", owner, name)))
+	return strings.ToLower(fmt.Sprintf("%s%x", prefix, sh))
+}
+
+// Static resolves repositories from a fixed map. It is keyed by repository,
+// in the form owner/name, then by port name.
+type Static map[string]map[string]string
+
+// LoadStatic reads a Static Resolver from the YAML file at path, e.g.
+//
+//	googleapis/google-cloud-go:
+//	  http: localhost:6344
+//	  internal: localhost:6345
+func LoadStatic(path string) (Static, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := make(Static)
+	if err := yaml.UnmarshalStrict(b, &s); err != nil {
+		return nil, fmt.Errorf("parsing %v: %v", path, err)
+	}
+	return s, nil
+}
+
+// Resolve implements Resolver. Repositories are matched case-insensitively,
+// as GitHub does.
+func (s Static) Resolve(ctx context.Context, owner, name, port string) (string, error) {
+	want := strings.ToLower(owner + "/" + name)
+	for repo, ports := range s {
+		if strings.ToLower(repo) != want {
+			continue
+		}
+		if addr, ok := ports[port]; ok {
+			return addr, nil
+		}
+	}
+	return "", ErrNotFound
+}
+
+// SRV resolves repositories by looking up the DNS SRV record
+// _<port>._tcp.<service>.<Domain>, where <service> is the repository's
+// Kubernetes Service name. Kubernetes publishes such a record for every
+// named port of a Service.
+type SRV struct {
+	Prefix string
+	Domain string
+
+	// lookup is net.DefaultResolver.LookupSRV, swapped out in tests.
+	lookup func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+// NewSRV returns a SRV Resolver for the Services named with prefix in
+// domain, e.g. "default.svc.cluster.local".
+func NewSRV(prefix, domain string) *SRV {
+	return &SRV{
+		Prefix: prefix,
+		Domain: domain,
+		lookup: net.DefaultResolver.LookupSRV,
+	}
+}
+
+// Resolve implements Resolver. It returns the target of the record with the
+// highest priority.
+func (s *SRV) Resolve(ctx context.Context, owner, name, port string) (string, error) {
+	host := ServiceName(s.Prefix, owner, name)
+	if s.Domain != "" {
+		host += "." + strings.Trim(s.Domain, ".")
+	}
+	_, addrs, err := s.lookup(ctx, port, "tcp", host)
+	if err != nil {
+		return "", err
+	}
+	if len(addrs) == 0 {
+		return "", ErrNotFound
+	}
+	// LookupSRV sorts the records by priority and weight.
+	a := addrs[0]
+	return net.JoinHostPort(strings.TrimSuffix(a.Target, "."), fmt.Sprint(a.Port)), nil
+}
+
+// Sharded resolves repositories to the backend of the shard serving them,
+// which Inner resolves like a repository. It lets the routers reach backends
+// that each serve several repositories.
+type Sharded struct {
+	Inner Resolver
+	// Shard returns the owner and name Inner resolves the shard serving
+	// the repository owner/name as, and false if no shard serves it.
+	Shard func(owner, name string) (string, string, bool)
+}
+
+// Resolve implements Resolver.
+func (s *Sharded) Resolve(ctx context.Context, owner, name, port string) (string, error) {
+	so, sn, ok := s.Shard(owner, name)
+	if !ok {
+		return "", ErrNotFound
+	}
+	return s.Inner.Resolve(ctx, so, sn, port)
+}
+
+// Parse returns the Resolver described by spec, for backends whose
+// Kubernetes Services are named with prefix. spec is one of
+//
+//	k8s               the Services the supervisors create (the default)
+//	static:<path>     the YAML file at path, see LoadStatic
+//	srv:<domain>      DNS SRV records of the Services in domain
+func Parse(spec, prefix string) (Resolver, error) {
+	kind, arg := spec, ""
+	if i := strings.Index(spec, ":"); i >= 0 {
+		kind, arg = spec[:i], spec[i+1:]
+	}
+	switch kind {
+	case "", "k8s":
+		return NewK8s(prefix), nil
+	case "static":
+		if arg == "" {
+			return nil, fmt.Errorf("resolver %q: missing path", spec)
+		}
+		return LoadStatic(arg)
+	case "srv":
+		return NewSRV(prefix, arg), nil
+	}
+	return nil, fmt.Errorf("unknown resolver %q", spec)
+}
@@ -0,0 +1,202 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestK8s(t *testing.T) {
+	r := NewK8s("mtr-s-")
+	ctx := context.Background()
+
+	tests := []struct {
+		Owner string
+		Name  string
+		Port  string
+		Want  string
+		Err   error
+	}{
+		// The Service maintner-sprvsr creates for foo/bar.
+		{Owner: "foo", Name: "bar", Port: PortGRPC, Want: "mtr-s-9bec779ac30c3c91e5e8055c0f5cb25f39ed7ce0ecb5f9a8f64fdab0:80"},
+		{Owner: "foo", Name: "bar", Port: PortInternal, Want: "mtr-s-9bec779ac30c3c91e5e8055c0f5cb25f39ed7ce0ecb5f9a8f64fdab0:8080"},
+		// The hash is of the name as given.
+		{Owner: "Foo", Name: "Bar", Port: PortGRPC, Want: "mtr-s-03248dbd65d04afcc4b97fa3841be23cabe544bc743ea0f3e80cdcd4:80"},
+		{Owner: "foo", Name: "bar", Port: "nope", Err: ErrNotFound},
+	}
+	for _, c := range tests {
+		got, err := r.Resolve(ctx, c.Owner, c.Name, c.Port)
+		if got != c.Want || err != c.Err {
+			t.Errorf("Resolve(%v, %v, %v) Wanted %v, %v. Got %v, %v", c.Owner, c.Name, c.Port, c.Want, c.Err, got, err)
+		}
+	}
+}
+
+func TestStatic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "resolver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "backends.yaml")
+	yml := `
+foo/bar:
+  http: localhost:6344
+  internal: localhost:6345
+foo/Baz:
+  http: 127.0.0.1:7000
+`
+	if err := ioutil.WriteFile(path, []byte(yml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := LoadStatic(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	tests := []struct {
+		Owner string
+		Name  string
+		Port  string
+		Want  string
+		Err   error
+	}{
+		{Owner: "foo", Name: "bar", Port: PortGRPC, Want: "localhost:6344"},
+		{Owner: "foo", Name: "bar", Port: PortInternal, Want: "localhost:6345"},
+		{Owner: "FOO", Name: "baz", Port: PortGRPC, Want: "127.0.0.1:7000"},
+		{Owner: "foo", Name: "baz", Port: PortInternal, Err: ErrNotFound},
+		{Owner: "foo", Name: "qux", Port: PortGRPC, Err: ErrNotFound},
+	}
+	for _, c := range tests {
+		got, err := r.Resolve(ctx, c.Owner, c.Name, c.Port)
+		if got != c.Want || err != c.Err {
+			t.Errorf("Resolve(%v, %v, %v) Wanted %v, %v. Got %v, %v", c.Owner, c.Name, c.Port, c.Want, c.Err, got, err)
+		}
+	}
+
+	if err := ioutil.WriteFile(path, []byte("foo/bar: [not, a, map]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadStatic(path); err == nil {
+		t.Errorf("LoadStatic() of a malformed file. Wanted an error, Got nil")
+	}
+}
+
+func TestSharded(t *testing.T) {
+	r := &Sharded{
+		Inner: Static{
+			"shards/shard-0": {PortGRPC: "localhost:7000"},
+		},
+		Shard: func(owner, name string) (string, string, bool) {
+			if owner+"/"+name == "foo/bar" {
+				return "shards", "shard-0", true
+			}
+			return "", "", false
+		},
+	}
+
+	ctx := context.Background()
+	tests := []struct {
+		Owner string
+		Name  string
+		Port  string
+		Want  string
+		Err   error
+	}{
+		{Owner: "foo", Name: "bar", Port: PortGRPC, Want: "localhost:7000"},
+		{Owner: "foo", Name: "bar", Port: PortInternal, Err: ErrNotFound},
+		{Owner: "foo", Name: "qux", Port: PortGRPC, Err: ErrNotFound},
+	}
+	for _, c := range tests {
+		got, err := r.Resolve(ctx, c.Owner, c.Name, c.Port)
+		if got != c.Want || err != c.Err {
+			t.Errorf("Resolve(%v, %v, %v) Wanted %v, %v. Got %v, %v", c.Owner, c.Name, c.Port, c.Want, c.Err, got, err)
+		}
+	}
+}
+
+func TestSRV(t *testing.T) {
+	var gotService, gotName string
+	r := NewSRV("mtr-s-", "default.svc.cluster.local.")
+	r.lookup = func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+		gotService, gotName = service, name
+		if service == "missing" {
+			return "", nil, errors.New("no such host")
+		}
+		return "", []*net.SRV{
+			{Target: "10-0-0-1.mtr.default.svc.cluster.local.", Port: 6343},
+			{Target: "10-0-0-2.mtr.default.svc.cluster.local.", Port: 6343},
+		}, nil
+	}
+
+	ctx := context.Background()
+	got, err := r.Resolve(ctx, "foo", "bar", PortGRPC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "10-0-0-1.mtr.default.svc.cluster.local:6343"; got != want {
+		t.Errorf("Resolve() Wanted %v, Got %v", want, got)
+	}
+	if gotService != PortGRPC {
+		t.Errorf("Resolve() looked up service %v. Wanted %v", gotService, PortGRPC)
+	}
+	if want := "mtr-s-9bec779ac30c3c91e5e8055c0f5cb25f39ed7ce0ecb5f9a8f64fdab0.default.svc.cluster.local"; gotName != want {
+		t.Errorf("Resolve() looked up name %v. Wanted %v", gotName, want)
+	}
+
+	if _, err := r.Resolve(ctx, "foo", "bar", "missing"); err == nil {
+		t.Errorf("Resolve() of a missing record. Wanted an error, Got nil")
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		Spec    string
+		Want    string
+		WantErr bool
+	}{
+		{Spec: "", Want: "*resolver.K8s"},
+		{Spec: "k8s", Want: "*resolver.K8s"},
+		{Spec: "srv:default.svc.cluster.local", Want: "*resolver.SRV"},
+		{Spec: "static:", WantErr: true},
+		{Spec: "static:/does/not/exist.yaml", WantErr: true},
+		{Spec: "consul", WantErr: true},
+	}
+	for _, c := range tests {
+		got, err := Parse(c.Spec, "mtr-s-")
+		if c.WantErr {
+			if err == nil {
+				t.Errorf("Parse(%q) Wanted an error, Got nil", c.Spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q) Wanted no error, Got %v", c.Spec, err)
+			continue
+		}
+		if typ := fmt.Sprintf("%T", got); typ != c.Want {
+			t.Errorf("Parse(%q) Wanted %v, Got %v", c.Spec, c.Want, typ)
+		}
+	}
+}
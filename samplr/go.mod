@@ -5,7 +5,7 @@ require (
 	github.com/GoogleCloudPlatform/devrel-services/drghs v0.0.0
 	github.com/GoogleCloudPlatform/devrel-services/git-go v0.0.0
 	github.com/GoogleCloudPlatform/devrel-services/repos v0.0.0
-	github.com/GoogleCloudPlatform/devrel-services/rtr v0.0.0 // indirect
+	github.com/GoogleCloudPlatform/devrel-services/rtr v0.0.0
 	github.com/GoogleCloudPlatform/devrel-services/sprvsr v0.0.0
 	github.com/GoogleCloudPlatform/opentelemetry-operations-go/exporter/trace v0.12.0 // indirect
 	github.com/cespare/trie v0.0.0-20150610204604-3fe1a95cbba9 // indirect
@@ -24,7 +24,7 @@ require (
 	google.golang.org/grpc v1.32.0
 	gopkg.in/src-d/enry.v1 v1.6.7
 	gopkg.in/toqueteos/substring.v1 v1.0.2 // indirect
-	gopkg.in/yaml.v2 v2.2.2
+	gopkg.in/yaml.v2 v2.2.7
 	k8s.io/api v0.0.0-20190528154508-67ef80593b24
 	k8s.io/apimachinery v0.0.0-20190528154326-e59c2fb0a8e5
 	k8s.io/client-go v0.0.0-20190528154735-79226fe1949a
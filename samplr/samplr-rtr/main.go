@@ -16,16 +16,21 @@ package main
 
 import (
 	"context"
+	_ "expvar"
 	"flag"
-	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"regexp"
-	"strings"
+	"sync"
 	"time"
 
 	drghs_v1 "github.com/GoogleCloudPlatform/devrel-services/drghs/v1"
 	"github.com/GoogleCloudPlatform/devrel-services/repos"
+	"github.com/GoogleCloudPlatform/devrel-services/rtr/auth"
+	"github.com/GoogleCloudPlatform/devrel-services/rtr/pool"
+	"github.com/GoogleCloudPlatform/devrel-services/rtr/ratelimit"
+	"github.com/GoogleCloudPlatform/devrel-services/rtr/resolver"
 
 	"cloud.google.com/go/errorreporting"
 	"cloud.google.com/go/profiler"
@@ -42,6 +47,19 @@ import (
 var (
 	listen  = flag.String("listen", ":6343", "listen address")
 	verbose = flag.Bool("verbose", false, "enable verbose debug output")
+	resolve = flag.String("resolver", "k8s", "how to find the samplr instance of a repository: k8s, static:<yaml file> or srv:<domain>")
+	shards  = flag.String("shard-map", "", "JSON file of the shard serving each repository, kept up to date by samplr-sprvsr --shards. The resolver then finds the instance of the shard. Empty gives each repository its own instance")
+
+	authPolicy = flag.String("auth-policy", "", "YAML file mapping API keys and JWT subjects to the repositories they may read. Empty trusts every caller")
+
+	rateLimitConfig = flag.String("rate-limit-config", "", "YAML file of the per-caller and per-method quotas")
+	rateLimitRPS    = flag.Float64("rate-limit-rps", 0, "calls per second each caller may make to each method, unless --rate-limit-config says otherwise. 0 is unlimited")
+	rateLimitBurst  = flag.Int("rate-limit-burst", 20, "calls each caller may make to each method at once, unless --rate-limit-config says otherwise")
+
+	debugListen = flag.String("debug-listen", "", "listen address for /debug/vars, which includes the rate limiting metrics. Empty disables it")
+
+	backendIdleTimeout    = flag.Duration("backend-idle-timeout", pool.DefaultIdleTimeout, "close connections to a samplr instance after they have been unused for this long")
+	backendMaxConcurrency = flag.Int("backend-max-concurrency", 0, "maximum number of calls in flight to one samplr instance. 0 is unbounded")
 )
 
 var (
@@ -54,6 +72,10 @@ const (
 	// is "guarenteed" to fail
 	// Using a reserved TLD https://tools.ietf.org/html/rfc2606
 	DEVNULL = "devnul.invalid"
+
+	// How often the --shard-map file is read again. Kubernetes takes
+	// about as long to update a mounted ConfigMap.
+	shardMapReloadInterval = time.Minute
 )
 
 // Log
@@ -91,20 +113,78 @@ func main() {
 		log.Errorf("error staring profiler: %v", err)
 	}
 
+	backendResolver, err := resolver.Parse(*resolve, "smp-s-")
+	if err != nil {
+		log.Fatalf("error: invalid --resolver: %v", err)
+	}
+	if *shards != "" {
+		sm := &shardMap{path: *shards}
+		if err := sm.load(); err != nil {
+			log.Fatalf("error: invalid --shard-map: %v", err)
+		}
+		go sm.reload(shardMapReloadInterval)
+		backendResolver = &resolver.Sharded{Inner: backendResolver, Shard: sm.shard}
+	}
+
 	lis, err := net.Listen("tcp", *listen)
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
 
-	reverseProxy := &reverseProxyServer{}
-	grpcServer := grpc.NewServer()
+	backends := pool.New(pool.Config{
+		IdleTimeout:             *backendIdleTimeout,
+		MaxConcurrentPerBackend: *backendMaxConcurrency,
+		DialOptions:             []grpc.DialOption{grpc.WithInsecure()},
+	})
+	defer backends.Close()
+
+	reverseProxy := &reverseProxyServer{
+		pool:     backends,
+		resolver: backendResolver,
+	}
+	var opts []grpc.ServerOption
+	if *authPolicy != "" {
+		policy, err := auth.LoadPolicy(*authPolicy)
+		if err != nil {
+			log.Fatalf("error: invalid --auth-policy: %v", err)
+		}
+		authorizer := auth.NewAuthorizer(policy)
+		opts = append(opts,
+			grpc.ChainUnaryInterceptor(authorizer.UnaryServerInterceptor()),
+			grpc.ChainStreamInterceptor(authorizer.StreamServerInterceptor()),
+		)
+	}
+	quotas, err := ratelimit.Load(*rateLimitConfig, ratelimit.Quota{RPS: *rateLimitRPS, Burst: *rateLimitBurst})
+	if err != nil {
+		log.Fatalf("error: invalid --rate-limit-config: %v", err)
+	}
+	if quotas != nil {
+		// After auth, to throttle callers by principal.
+		limiter := ratelimit.NewLimiter(quotas)
+		opts = append(opts,
+			grpc.ChainUnaryInterceptor(limiter.UnaryServerInterceptor()),
+			grpc.ChainStreamInterceptor(limiter.StreamServerInterceptor()),
+		)
+	}
+
+	if *debugListen != "" {
+		go func() {
+			// expvar serves /debug/vars on the default mux.
+			log.Errorf("debug server stopped: %v", http.ListenAndServe(*debugListen, nil))
+		}()
+	}
+
+	grpcServer := grpc.NewServer(opts...)
 	drghs_v1.RegisterSampleServiceServer(grpcServer, reverseProxy)
 	healthpb.RegisterHealthServer(grpcServer, reverseProxy)
 	log.Printf("gRPC server listening on: %s", *listen)
 	grpcServer.Serve(lis)
 }
 
-type reverseProxyServer struct{}
+type reverseProxyServer struct {
+	pool     *pool.Pool
+	resolver resolver.Resolver
+}
 
 // Check is for health checking.
 func (s *reverseProxyServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
@@ -124,11 +204,11 @@ func (s *reverseProxyServer) ListRepositories(ctx context.Context, req *drghs_v1
 }
 
 func (s *reverseProxyServer) ListGitCommits(ctx context.Context, req *drghs_v1.ListGitCommitsRequest) (*drghs_v1.ListGitCommitsResponse, error) {
-	pth, err := calculateHost(req.Parent)
+	pth, err := s.calculateHost(ctx, req.Parent)
 	if err != nil {
 		return nil, err
 	}
-	conn, err := grpc.Dial(pth, grpc.WithInsecure())
+	conn, release, err := s.pool.Get(ctx, pth)
 	if err != nil {
 		// If we attempt to dial and the service is "unavailable" that probably
 		// means there isn't a service to dial to (and therefore repository).
@@ -138,18 +218,18 @@ func (s *reverseProxyServer) ListGitCommits(ctx context.Context, req *drghs_v1.L
 		}
 		return nil, err
 	}
-	defer conn.Close()
+	defer release()
 
 	client := drghs_v1.NewSampleServiceClient(conn)
 	return client.ListGitCommits(ctx, req)
 }
 
 func (s *reverseProxyServer) GetGitCommit(ctx context.Context, req *drghs_v1.GetGitCommitRequest) (*drghs_v1.GitCommit, error) {
-	pth, err := calculateHost(req.Name)
+	pth, err := s.calculateHost(ctx, req.Name)
 	if err != nil {
 		return nil, err
 	}
-	conn, err := grpc.Dial(pth, grpc.WithInsecure())
+	conn, release, err := s.pool.Get(ctx, pth)
 	if err != nil {
 		// If we attempt to dial and the service is "unavailable" that probably
 		// means there isn't a service to dial to (and therefore repository).
@@ -159,18 +239,18 @@ func (s *reverseProxyServer) GetGitCommit(ctx context.Context, req *drghs_v1.Get
 		}
 		return nil, err
 	}
-	defer conn.Close()
+	defer release()
 
 	client := drghs_v1.NewSampleServiceClient(conn)
 	return client.GetGitCommit(ctx, req)
 }
 
 func (s *reverseProxyServer) ListFiles(ctx context.Context, req *drghs_v1.ListFilesRequest) (*drghs_v1.ListFilesResponse, error) {
-	pth, err := calculateHost(req.Parent)
+	pth, err := s.calculateHost(ctx, req.Parent)
 	if err != nil {
 		return nil, err
 	}
-	conn, err := grpc.Dial(pth, grpc.WithInsecure())
+	conn, release, err := s.pool.Get(ctx, pth)
 	if err != nil {
 		// If we attempt to dial and the service is "unavailable" that probably
 		// means there isn't a service to dial to (and therefore repository).
@@ -180,18 +260,18 @@ func (s *reverseProxyServer) ListFiles(ctx context.Context, req *drghs_v1.ListFi
 		}
 		return nil, err
 	}
-	defer conn.Close()
+	defer release()
 
 	client := drghs_v1.NewSampleServiceClient(conn)
 	return client.ListFiles(ctx, req)
 }
 
 func (s *reverseProxyServer) ListSnippets(ctx context.Context, req *drghs_v1.ListSnippetsRequest) (*drghs_v1.ListSnippetsResponse, error) {
-	pth, err := calculateHost(req.Parent)
+	pth, err := s.calculateHost(ctx, req.Parent)
 	if err != nil {
 		return nil, err
 	}
-	conn, err := grpc.Dial(pth, grpc.WithInsecure())
+	conn, release, err := s.pool.Get(ctx, pth)
 	if err != nil {
 		// If we attempt to dial and the service is "unavailable" that probably
 		// means there isn't a service to dial to (and therefore repository).
@@ -201,18 +281,18 @@ func (s *reverseProxyServer) ListSnippets(ctx context.Context, req *drghs_v1.Lis
 		}
 		return nil, err
 	}
-	defer conn.Close()
+	defer release()
 
 	client := drghs_v1.NewSampleServiceClient(conn)
 	return client.ListSnippets(ctx, req)
 }
 
 func (s *reverseProxyServer) ListSnippetVersions(ctx context.Context, req *drghs_v1.ListSnippetVersionsRequest) (*drghs_v1.ListSnippetVersionsResponse, error) {
-	pth, err := calculateHost(req.Parent)
+	pth, err := s.calculateHost(ctx, req.Parent)
 	if err != nil {
 		return nil, err
 	}
-	conn, err := grpc.Dial(pth, grpc.WithInsecure())
+	conn, release, err := s.pool.Get(ctx, pth)
 	if err != nil {
 		// If we attempt to dial and the service is "unavailable" that probably
 		// means there isn't a service to dial to (and therefore repository).
@@ -222,13 +302,13 @@ func (s *reverseProxyServer) ListSnippetVersions(ctx context.Context, req *drghs
 		}
 		return nil, err
 	}
-	defer conn.Close()
+	defer release()
 
 	client := drghs_v1.NewSampleServiceClient(conn)
 	return client.ListSnippetVersions(ctx, req)
 }
 
-func calculateHost(path string) (string, error) {
+func (s *reverseProxyServer) calculateHost(ctx context.Context, path string) (string, error) {
 	// We might need to put some more real "smarts" to this logic
 	// in the event we need to handle the /v1/owners/*/repositories
 	// call, which asks for a list of all repositories in a given org.
@@ -253,18 +333,52 @@ func calculateHost(path string) (string, error) {
 			Name:  mtches[0][2],
 		}
 
-		sn, err := serviceName(ta)
+		host, err := s.resolver.Resolve(ctx, ta.Owner, ta.Name, resolver.PortGRPC)
 		if err != nil {
 			return "", err
 		}
 
-		log.Tracef("New Host: %v", sn)
-		return sn + ":80", nil
+		log.Tracef("New Host: %v", host)
+		return host, nil
 	}
 	log.Tracef("No match... returning null: %v", DEVNULL)
 	return DEVNULL, nil
 }
 
-func serviceName(t repos.TrackedRepository) (string, error) {
-	return strings.ToLower(fmt.Sprintf("smp-s-%s", t.RepoSha())), nil
+// shardMap is the repos.ShardMap in a file, read again as the supervisor
+// updates it.
+type shardMap struct {
+	path string
+
+	mu sync.RWMutex
+	m  *repos.ShardMap
+}
+
+func (s *shardMap) load() error {
+	m, err := repos.LoadShardMap(s.path)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.m = m
+	s.mu.Unlock()
+	return nil
+}
+
+// reload reads the file every interval, keeping the last ShardMap read if
+// it cannot be.
+func (s *shardMap) reload(interval time.Duration) {
+	for range time.Tick(interval) {
+		if err := s.load(); err != nil {
+			log.Errorf("could not reload the shard map: %v", err)
+		}
+	}
+}
+
+// shard implements resolver.Sharded.Shard.
+func (s *shardMap) shard(owner, name string) (string, string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	tr, ok := s.m.Shard(owner, name)
+	return tr.Owner, tr.Name, ok
 }
@@ -16,14 +16,19 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io/ioutil"
+	"net/http"
 	"os"
+	"os/exec"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/GoogleCloudPlatform/devrel-services/repos"
+	"github.com/GoogleCloudPlatform/devrel-services/rtr/resolver"
 	"github.com/GoogleCloudPlatform/devrel-services/sprvsr"
 
 	"cloud.google.com/go/errorreporting"
@@ -48,11 +53,29 @@ var (
 	projectID      = flag.String("gcp-project", "", "The GCP Project this is using")
 	simagename     = flag.String("samplr-image-name", "", "The name of the image to run samplr")
 	sasecretname   = flag.String("service-account-secret", "", "The name of the ServiceAccount for our Pods to run as")
+	backend        = flag.String("backend", "k8s", "Where to run samplrd: k8s deploys it to the cluster, local runs it as a child process")
+	samplrdPath    = flag.String("samplrd-path", "samplrd", "The samplrd binary to run with --backend=local")
+	addressFile    = flag.String("address-file", "", "With --backend=local, the file to write the addresses of each samplrd to, for the routers' static resolver")
+	dryRun         = flag.Bool("dry-run", false, "With --backend=k8s, log the changes an update would make to the cluster instead of making them")
+	maxDeletions   = flag.Int("max-deletions", 0, "The most deployments an update may delete. 0 sets no limit")
+	maxDeletePct   = flag.Float64("max-deletion-percent", 0, "The most deployments an update may delete, as a percentage of those managed. 0 sets no limit")
+	allowEmpty     = flag.Bool("allow-empty-repos", false, "Let an update delete every deployment when the list of repositories is empty")
+	deleteGrace    = flag.Duration("deletion-grace-period", 0, "How long a repository has to be untracked before its deployment is deleted")
+	reconcileEvery = flag.Duration("reconcile-interval", 0, "How often to repair deployments and services edited or deleted by hand. 0 only updates the cluster when the list of repositories changes")
+	leaseName      = flag.String("leader-election-lease", "", "With --backend=k8s, the Lease the replicas of the supervisor compete for, so only one updates the cluster. Empty runs a single replica")
+	deployTemplate = flag.String("deployment-template", "", "With --backend=k8s, a YAML template to render the Deployment of each repository from, instead of the built-in one. Needs --service-template")
+	svcTemplate    = flag.String("service-template", "", "With --backend=k8s, a YAML template to render the Service of each repository from, instead of the built-in one. Needs --deployment-template")
+	leaderID       = flag.String("leader-election-id", "", "The host:port the other replicas reach this one at. Defaults to the POD_IP environment variable and the --listen port")
+	nshards        = flag.Int("shards", 0, "Pack the repositories into this many samplrd instances. 0 runs one instance per repository")
+	shardBy        = flag.String("shard-by", "count", "How to balance the shards: count packs the same number of repositories into each, size weighs them by the size GitHub reports")
+	shardConfigMap = flag.String("shard-map-configmap", "samplr-shards", "With --backend=k8s and --shards, the ConfigMap to keep the shard of each repository in, for samplr-rtr --shard-map")
+	shardMapFile   = flag.String("shard-map-file", "", "With --backend=local and --shards, the file to keep the shard of each repository in, for samplr-rtr --shard-map")
 )
 
 // Config
 var (
 	repoList    repos.RepoList
+	shardList   *sprvsr.ShardedRepoList
 	errorClient *errorreporting.Client
 	config      *rest.Config
 	mu          sync.RWMutex
@@ -126,55 +149,195 @@ func main() {
 		log.Fatal(err)
 	}
 
-	if *sasecretname == "" {
-		err := fmt.Errorf("must provide --service-account-secret")
+	repoList = repos.NewBucketRepo(*settingsBucket, *reposFileName)
+
+	var super sprvsr.Supervisor
+	switch *backend {
+	case "k8s":
+		super, err = newK8sSupervisor()
+	case "local":
+		super, err = newLocalSupervisor()
+	default:
+		err = fmt.Errorf("unknown --backend %q. must be k8s or local", *backend)
+	}
+	if err != nil {
 		logAndPrintError(err)
 		log.Fatal(err)
 	}
 
+	log.Fatal(super.Supervise(*listen, logAndPrintError))
+}
+
+func newK8sSupervisor() (sprvsr.Supervisor, error) {
+	if *sasecretname == "" {
+		return nil, fmt.Errorf("must provide --service-account-secret")
+	}
+
 	if *simagename == "" {
-		err := fmt.Errorf("must provide --maint-image-name")
-		logAndPrintError(err)
-		log.Fatal(err)
+		return nil, fmt.Errorf("must provide --maint-image-name")
 	}
 
 	// Init k8s info
 	// creates the in-cluster config
+	var err error
 	config, err = rest.InClusterConfig()
 	if err != nil {
-		logAndPrintError(err)
-		log.Fatal(err)
+		return nil, err
 	}
 
 	// We need to interface with the k8s api
 	cs, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		logAndPrintError(err)
-		log.Fatal(err)
+		return nil, err
 	}
 
-	repoList = repos.NewBucketRepo(*settingsBucket, *reposFileName)
-
-	bd := func(ta repos.TrackedRepository) (*appsv1.Deployment, error) {
-		return buildDeployment(ta)
+	bd, bs, err := templateBuilders(buildDeployment, buildService)
+	if err != nil {
+		return nil, err
 	}
 
 	kcfg := sprvsr.K8sConfiguration{
 		ServiceNamer:      serviceName,
 		DeploymentNamer:   deploymentName,
-		ServiceBuilder:    buildService,
+		ServiceBuilder:    bs,
 		DeploymentBuilder: bd,
 		PreDeploy:         preDeploy,
 		ShouldDeploy:      shouldDeploy,
+		DryRun:            *dryRun,
+		Guard: sprvsr.DeletionGuard{
+			MaxDeletions:       *maxDeletions,
+			MaxDeletionPercent: *maxDeletePct,
+			AllowEmpty:         *allowEmpty,
+			GracePeriod:        *deleteGrace,
+		},
+		ReconcileInterval: *reconcileEvery,
+	}
+	if *leaseName != "" {
+		id := *leaderID
+		if id == "" {
+			var err error
+			if id, err = sprvsr.PodIdentity(*listen); err != nil {
+				return nil, fmt.Errorf("could not derive --leader-election-id: %v", err)
+			}
+		}
+		kcfg.LeaderElection = &sprvsr.LeaderElection{
+			LeaseName: *leaseName,
+			Identity:  id,
+		}
 	}
 
-	super, err := sprvsr.NewK8sSupervisor(log, cs, kcfg, repoList, "samplr")
+	rl, err := shardRepoList(sprvsr.NewConfigMapShardStore(cs, apiv1.NamespaceDefault, *shardConfigMap))
 	if err != nil {
-		logAndPrintError(err)
-		log.Fatal(err)
+		return nil, err
 	}
 
-	log.Fatal(super.Supervise(*listen, logAndPrintError))
+	return sprvsr.NewK8sSupervisor(log, cs, kcfg, rl, "samplr")
+}
+
+// newLocalSupervisor runs a samplrd process on this machine for each
+// repository.
+func newLocalSupervisor() (sprvsr.Supervisor, error) {
+	bin, err := exec.LookPath(*samplrdPath)
+	if err != nil {
+		return nil, err
+	}
+	if *nshards > 0 && *shardMapFile == "" {
+		return nil, fmt.Errorf("must provide --shard-map-file with --shards")
+	}
+	rl, err := shardRepoList(sprvsr.NewFileShardStore(*shardMapFile))
+	if err != nil {
+		return nil, err
+	}
+
+	lcfg := sprvsr.LocalConfiguration{
+		ProcessNamer: deploymentName,
+		ProcessBuilder: func(ta repos.TrackedRepository, ports map[string]int) (*exec.Cmd, error) {
+			command := samplrdCommand(bin, fmt.Sprintf("localhost:%v", ports[resolver.PortGRPC]), ta)
+			return exec.Command(command[0], command[1:]...), nil
+		},
+		PreDeploy:    preDeploy,
+		ShouldDeploy: shouldDeploy,
+		Ports:        []string{resolver.PortGRPC},
+		AddressFile:  *addressFile,
+	}
+
+	return sprvsr.NewLocalSupervisor(log, lcfg, rl)
+}
+
+// shardRepoList returns repoList, or with --shards the shards its
+// repositories are packed into, keeping the shard map in store.
+func shardRepoList(store sprvsr.ShardStore) (repos.RepoList, error) {
+	if *nshards == 0 {
+		return repoList, nil
+	}
+	var weigher sprvsr.Weigher
+	switch *shardBy {
+	case "count":
+	case "size":
+		weigher = newSizeWeigher(os.Getenv("GITHUB_TOKEN")).weigh
+	default:
+		return nil, fmt.Errorf("unknown --shard-by %q. must be count or size", *shardBy)
+	}
+	var err error
+	shardList, err = sprvsr.NewShardedRepoList(repoList, sprvsr.ShardConfiguration{
+		Shards:      *nshards,
+		Weigher:     weigher,
+		ShouldShard: shouldDeploy,
+		Store:       store,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return shardList, nil
+}
+
+// sizeWeigher weighs repositories by the size GitHub reports for them, in
+// kilobytes. The size of each repository is only fetched once.
+type sizeWeigher struct {
+	token string
+
+	mu    sync.Mutex
+	sizes map[string]int64
+}
+
+func newSizeWeigher(token string) *sizeWeigher {
+	return &sizeWeigher{token: token, sizes: make(map[string]int64)}
+}
+
+func (w *sizeWeigher) weigh(ta repos.TrackedRepository) (int64, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if size, ok := w.sizes[ta.String()]; ok {
+		return size, nil
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("https://api.github.com/repos/%v/%v", ta.Owner, ta.Name), nil)
+	if err != nil {
+		return 0, err
+	}
+	if w.token != "" {
+		req.Header.Set("Authorization", "token "+w.token)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("getting the size of %v: %v", ta, res.Status)
+	}
+	var repo struct {
+		Size int64 `json:"size"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&repo); err != nil {
+		return 0, err
+	}
+	// An empty repository still takes some of a shard
+	if repo.Size < 1 {
+		repo.Size = 1
+	}
+	w.sizes[ta.String()] = repo.Size
+	return repo.Size, nil
 }
 
 func logAndPrintError(err error) {
@@ -228,11 +391,11 @@ func buildDeployment(ta repos.TrackedRepository) (*appsv1.Deployment, error) {
 	if err != nil {
 		return nil, err
 	}
-	defaultBranch := "master"
-	if ta.DefaultBranch != "" {
-		defaultBranch = ta.DefaultBranch
-	}
 	enableServiceLinks := false
+	resources, err := sprvsr.WithResources(defaultResources(), ta.Resources)
+	if err != nil {
+		return nil, fmt.Errorf("resources of %v: %v", ta, err)
+	}
 	return &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: dep,
@@ -251,24 +414,18 @@ func buildDeployment(ta repos.TrackedRepository) (*appsv1.Deployment, error) {
 				ObjectMeta: metav1.ObjectMeta{
 					Labels: map[string]string{
 						"app":    dep,
-						"branch": defaultBranch,
+						"branch": trackedBranch(ta),
 					},
 				},
 				Spec: apiv1.PodSpec{
 					EnableServiceLinks: &enableServiceLinks,
-					Volumes: []apiv1.Volume{},
+					Volumes:            []apiv1.Volume{},
 					Containers: []apiv1.Container{
 						apiv1.Container{
 							Name:            "samplrd",
-							Image:           *simagename,
+							Image:           sprvsr.ImageWithTag(*simagename, ta.ImageTag),
 							ImagePullPolicy: "Always",
-							Command: []string{
-								"/samplrd",
-								fmt.Sprintf("--listen=:%v", samplrbackendport),
-								fmt.Sprintf("--owner=%v", ta.Owner),
-								fmt.Sprintf("--repo=%v", ta.Name),
-								fmt.Sprintf("--branch=%v", defaultBranch),
-							},
+							Command:         samplrdCommand("/samplrd", fmt.Sprintf(":%v", samplrbackendport), ta),
 							Ports: []apiv1.ContainerPort{
 								{
 									Name:          "http",
@@ -278,17 +435,7 @@ func buildDeployment(ta repos.TrackedRepository) (*appsv1.Deployment, error) {
 							},
 							Env:          []apiv1.EnvVar{},
 							VolumeMounts: []apiv1.VolumeMount{},
-							Resources: apiv1.ResourceRequirements{
-								Requests: apiv1.ResourceList{
-									// Our application does not need "that" much CPU.
-									// For context, if unspecified, k8s applies a default request of "100m"
-									apiv1.ResourceCPU:    resource.MustParse("50m"),
-									apiv1.ResourceMemory: resource.MustParse("160M"),
-								},
-								Limits: apiv1.ResourceList{
-									apiv1.ResourceMemory: resource.MustParse("2.5G"),
-								},
-							},
+							Resources:    resources,
 						},
 					},
 				},
@@ -297,6 +444,104 @@ func buildDeployment(ta repos.TrackedRepository) (*appsv1.Deployment, error) {
 	}, nil
 }
 
+// templateBuilders returns the builders rendering --deployment-template and
+// --service-template, or bd and bs if they are not set.
+func templateBuilders(bd sprvsr.DeploymentBuilder, bs sprvsr.ServiceBuilder) (sprvsr.DeploymentBuilder, sprvsr.ServiceBuilder, error) {
+	if *deployTemplate == "" && *svcTemplate == "" {
+		return bd, bs, nil
+	}
+	if *deployTemplate == "" || *svcTemplate == "" {
+		return nil, nil, fmt.Errorf("must provide both --deployment-template and --service-template")
+	}
+	dtmpl, err := ioutil.ReadFile(*deployTemplate)
+	if err != nil {
+		return nil, nil, err
+	}
+	stmpl, err := ioutil.ReadFile(*svcTemplate)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sprvsr.NewTemplateBuilders(sprvsr.TemplateConfiguration{
+		Deployment:      string(dtmpl),
+		Service:         string(stmpl),
+		DeploymentNamer: deploymentName,
+		ServiceNamer:    serviceName,
+		Values:          templateValues,
+	})
+}
+
+// templateValues returns the .Values of the templates of ta: what
+// buildDeployment would set.
+func templateValues(ta repos.TrackedRepository) (map[string]interface{}, error) {
+	resources, err := sprvsr.WithResources(defaultResources(), ta.Resources)
+	if err != nil {
+		return nil, fmt.Errorf("resources of %v: %v", ta, err)
+	}
+	return map[string]interface{}{
+		"Image":     sprvsr.ImageWithTag(*simagename, ta.ImageTag),
+		"Command":   samplrdCommand("/samplrd", fmt.Sprintf(":%v", samplrbackendport), ta),
+		"Port":      samplrbackendport,
+		"Branch":    trackedBranch(ta),
+		"Resources": resources,
+	}, nil
+}
+
+// defaultResources returns the resources of a repository's samplrd that the
+// repos file does not override.
+func defaultResources() apiv1.ResourceRequirements {
+	return apiv1.ResourceRequirements{
+		Requests: apiv1.ResourceList{
+			// Our application does not need "that" much CPU.
+			// For context, if unspecified, k8s applies a default request of "100m"
+			apiv1.ResourceCPU:    resource.MustParse("50m"),
+			apiv1.ResourceMemory: resource.MustParse("160M"),
+		},
+		Limits: apiv1.ResourceList{
+			apiv1.ResourceMemory: resource.MustParse("2.5G"),
+		},
+	}
+}
+
+// samplrdCommand returns the command line of the samplrd binary bin for ta.
+func samplrdCommand(bin, listen string, ta repos.TrackedRepository) []string {
+	if members, ok := shardMembers(ta); ok {
+		trs := make([]string, 0, len(members))
+		for _, m := range members {
+			trs = append(trs, fmt.Sprintf("%v@%v", m, trackedBranch(m)))
+		}
+		return []string{
+			bin,
+			fmt.Sprintf("--listen=%v", listen),
+			fmt.Sprintf("--repos=%v", strings.Join(trs, ",")),
+		}
+	}
+	command := []string{
+		bin,
+		fmt.Sprintf("--listen=%v", listen),
+		fmt.Sprintf("--owner=%v", ta.Owner),
+		fmt.Sprintf("--repo=%v", ta.Name),
+		fmt.Sprintf("--branch=%v", trackedBranch(ta)),
+	}
+	return append(command, ta.Flags()...)
+}
+
+// shardMembers returns the repositories of the shard ta stands for, and
+// false if ta is not a shard.
+func shardMembers(ta repos.TrackedRepository) ([]repos.TrackedRepository, bool) {
+	if shardList == nil {
+		return nil, false
+	}
+	return shardList.Members(ta)
+}
+
+// trackedBranch returns the branch samplrd tracks for ta.
+func trackedBranch(ta repos.TrackedRepository) string {
+	if ta.DefaultBranch != "" {
+		return ta.DefaultBranch
+	}
+	return "master"
+}
+
 func preDeploy(ta repos.TrackedRepository) error {
 	return nil
 }
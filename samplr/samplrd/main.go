@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"strings"
 	"time"
 
 	drghs_v1 "github.com/GoogleCloudPlatform/devrel-services/drghs/v1"
@@ -37,6 +38,7 @@ var (
 	owner         = flag.String("owner", "", "Google Cloud Storage bucket to use for settings storage")
 	repo          = flag.String("repo", "", "File that contains the list of repositories")
 	defaultBranch = flag.String("branch", "master", "Branch to track snippets from")
+	repoList      = flag.String("repos", "", "Comma separated repositories to track instead of --owner and --repo, as owner/name or owner/name@branch, for an instance serving a shard of repositories")
 	verbose       = flag.Bool("verbose", false, "Verbose logs")
 )
 
@@ -66,19 +68,31 @@ func main() {
 		samplr.VerboseLog()
 	}
 
-	if *owner == "" {
-		err := fmt.Errorf("must provide --owner")
-		log.Fatal(err)
-	}
+	var tracked []trackedRepo
+	service := "samplrd-shard"
+	if *repoList != "" {
+		var err error
+		tracked, err = parseRepos(*repoList, *defaultBranch)
+		if err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		if *owner == "" {
+			err := fmt.Errorf("must provide --owner")
+			log.Fatal(err)
+		}
 
-	if *repo == "" {
-		err := fmt.Errorf("must provide --repos")
-		log.Fatal(err)
+		if *repo == "" {
+			err := fmt.Errorf("must provide --repo or --repos")
+			log.Fatal(err)
+		}
+		tracked = []trackedRepo{{owner: *owner, name: *repo, branch: *defaultBranch}}
+		service = fmt.Sprintf("samplrd-%v-%v", *owner, *repo)
 	}
 
 	// Profiler initialization, best done as early as possible.
 	if err := profiler.Start(profiler.Config{
-		Service:        fmt.Sprintf("samplrd-%v-%v", *owner, *repo),
+		Service:        service,
 		ServiceVersion: "0.0.1",
 		MutexProfiling: true,
 	}); err != nil {
@@ -89,11 +103,14 @@ func main() {
 
 	loadGroup, _ := errgroup.WithContext(context.Background())
 
-	repoPath := fmt.Sprintf("https://github.com/%v/%v", *owner, *repo)
-	loadGroup.Go(func() error {
-		log.Printf("Tracking repo: %s", repoPath)
-		return corpus.TrackGit(repoPath, *defaultBranch)
-	})
+	for _, tr := range tracked {
+		repoPath := fmt.Sprintf("https://github.com/%v/%v", tr.owner, tr.name)
+		branch := tr.branch
+		loadGroup.Go(func() error {
+			log.Printf("Tracking repo: %s", repoPath)
+			return corpus.TrackGit(repoPath, branch)
+		})
+	}
 
 	if err := loadGroup.Wait(); err != nil {
 		log.Fatal(err)
@@ -135,3 +152,37 @@ func main() {
 	err = group.Wait()
 	log.Fatal(err)
 }
+
+// trackedRepo is a repository and the branch to track snippets from.
+type trackedRepo struct {
+	owner  string
+	name   string
+	branch string
+}
+
+// parseRepos parses the --repos flag. Repositories without a branch track
+// defaultBranch.
+func parseRepos(list, defaultBranch string) ([]trackedRepo, error) {
+	var trs []trackedRepo
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		r := entry
+		tr := trackedRepo{branch: defaultBranch}
+		if i := strings.Index(r, "@"); i >= 0 {
+			r, tr.branch = r[:i], r[i+1:]
+		}
+		parts := strings.Split(r, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" || tr.branch == "" {
+			return nil, fmt.Errorf("bad repository %q in --repos. must be owner/name or owner/name@branch", entry)
+		}
+		tr.owner, tr.name = parts[0], parts[1]
+		trs = append(trs, tr)
+	}
+	if len(trs) == 0 {
+		return nil, fmt.Errorf("--repos lists no repositories")
+	}
+	return trs, nil
+}
@@ -37,6 +37,7 @@ require (
 	k8s.io/apimachinery v0.0.0-20190528154326-e59c2fb0a8e5
 	k8s.io/client-go v0.0.0-20190528154735-79226fe1949a
 	k8s.io/utils v0.0.0-20190529001817-6999998975a7 // indirect
+	sigs.k8s.io/yaml v1.1.0
 )
 
 replace github.com/GoogleCloudPlatform/devrel-services/repos => ../repos
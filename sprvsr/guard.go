@@ -0,0 +1,146 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprvsr
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DeletionGuard limits the deletions an update makes, so a repos file that
+// is truncated or briefly unreadable does not take every repository's
+// Deployment down with it. The zero value refuses to act on an empty list
+// of repositories and sets no other limit.
+type DeletionGuard struct {
+	// MaxDeletions is the most Deployments an update may delete. 0 sets
+	// no limit.
+	MaxDeletions int
+	// MaxDeletionPercent is the most Deployments an update may delete, as
+	// a percentage of those the supervisor manages. 0 sets no limit.
+	MaxDeletionPercent float64
+	// AllowEmpty lets an update delete everything when the list of
+	// repositories is empty.
+	AllowEmpty bool
+	// GracePeriod is how long a Deployment or Service has to be
+	// untracked before it is deleted.
+	GracePeriod time.Duration
+}
+
+// DeletionsBlockedError is reported through the error handler when a
+// DeletionGuard blocks the deletions of an update.
+type DeletionsBlockedError struct {
+	// Deletions is the number of Deployments and Services not deleted.
+	Deletions int
+	Reason    string
+}
+
+func (e *DeletionsBlockedError) Error() string {
+	return fmt.Sprintf("sprvsr: blocked %v deletions: %v", e.Deletions, e.Reason)
+}
+
+// guardDeletions removes the deletions of p that g does not allow yet.
+// untracked is when each deletion planned before was first planned, by
+// kind and name, and is updated to those of p. It returns the deletions
+// waiting for the grace period, and an error if the deletions were
+// blocked.
+func guardDeletions(g DeletionGuard, p *Plan, nrepos, managed int, untracked map[string]time.Time, now time.Time) ([]*PendingDeletion, error) {
+	ndeployments := 0
+	ndeletions := 0
+	for _, c := range p.Deployments {
+		if c.Action == ActionDelete {
+			ndeployments++
+			ndeletions++
+		}
+	}
+	for _, c := range p.Services {
+		if c.Action == ActionDelete {
+			ndeletions++
+		}
+	}
+	if ndeletions == 0 {
+		for k := range untracked {
+			delete(untracked, k)
+		}
+		return nil, nil
+	}
+
+	reason := ""
+	switch {
+	case nrepos == 0 && !g.AllowEmpty:
+		reason = "the list of repositories is empty"
+	case g.MaxDeletions > 0 && ndeployments > g.MaxDeletions:
+		reason = fmt.Sprintf("%v deployments to delete exceeds the maximum of %v", ndeployments, g.MaxDeletions)
+	case g.MaxDeletionPercent > 0 && managed > 0 && float64(ndeployments)*100/float64(managed) > g.MaxDeletionPercent:
+		reason = fmt.Sprintf("%v of %v deployments to delete exceeds the maximum of %v%%", ndeployments, managed, g.MaxDeletionPercent)
+	}
+	if reason != "" {
+		p.Deployments = withoutDeletions(p.Deployments, nil)
+		p.Services = withoutDeletions(p.Services, nil)
+		// The grace period starts over once the deletions are allowed.
+		for k := range untracked {
+			delete(untracked, k)
+		}
+		return nil, &DeletionsBlockedError{Deletions: ndeletions, Reason: reason}
+	}
+
+	planned := make(map[string]bool)
+	pending := make([]*PendingDeletion, 0)
+	wait := func(kind string) func(c *Change) bool {
+		return func(c *Change) bool {
+			key := kind + "/" + c.Name
+			planned[key] = true
+			first, ok := untracked[key]
+			if !ok {
+				first = now
+				untracked[key] = now
+			}
+			due := first.Add(g.GracePeriod)
+			if now.Before(due) {
+				pending = append(pending, &PendingDeletion{Kind: kind, Name: c.Name, Repository: c.Repository, Due: due})
+				return true
+			}
+			return false
+		}
+	}
+	p.Deployments = withoutDeletions(p.Deployments, wait("deployment"))
+	p.Services = withoutDeletions(p.Services, wait("service"))
+	for k := range untracked {
+		if !planned[k] {
+			delete(untracked, k)
+		}
+	}
+
+	sort.Slice(pending, func(i, j int) bool {
+		if !pending[i].Due.Equal(pending[j].Due) {
+			return pending[i].Due.Before(pending[j].Due)
+		}
+		return pending[i].Kind+pending[i].Name < pending[j].Kind+pending[j].Name
+	})
+	return pending, nil
+}
+
+// withoutDeletions returns the changes of cs without the deletions remove
+// reports true for, or without every deletion if remove is nil.
+func withoutDeletions(cs []*Change, remove func(*Change) bool) []*Change {
+	kept := make([]*Change, 0, len(cs))
+	for _, c := range cs {
+		if c.Action == ActionDelete && (remove == nil || remove(c)) {
+			continue
+		}
+		kept = append(kept, c)
+	}
+	return kept
+}
@@ -0,0 +1,254 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprvsr
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/devrel-services/repos"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testPlan(ndelete int) *Plan {
+	p := &Plan{
+		Deployments: []*Change{{Action: ActionCreate, Name: "d-new"}},
+		Services:    []*Change{{Action: ActionCreate, Name: "s-new"}},
+	}
+	for i := 0; i < ndelete; i++ {
+		p.Deployments = append(p.Deployments, &Change{Action: ActionDelete, Name: fmt.Sprintf("d-%v", i)})
+		p.Services = append(p.Services, &Change{Action: ActionDelete, Name: fmt.Sprintf("s-%v", i)})
+	}
+	return p
+}
+
+func TestGuardDeletions(t *testing.T) {
+	cases := []struct {
+		Name        string
+		Guard       DeletionGuard
+		Deletions   int
+		Repos       int
+		Managed     int
+		WantDeletes int
+		WantBlocked bool
+	}{
+		{
+			Name:        "No limits allows deletions",
+			Guard:       DeletionGuard{},
+			Deletions:   3,
+			Repos:       1,
+			Managed:     4,
+			WantDeletes: 6,
+		},
+		{
+			Name:        "Empty repo list blocks deletions",
+			Guard:       DeletionGuard{},
+			Deletions:   4,
+			Repos:       0,
+			Managed:     4,
+			WantBlocked: true,
+		},
+		{
+			Name:        "Empty repo list allowed",
+			Guard:       DeletionGuard{AllowEmpty: true},
+			Deletions:   4,
+			Repos:       0,
+			Managed:     4,
+			WantDeletes: 8,
+		},
+		{
+			Name:        "Under the maximum",
+			Guard:       DeletionGuard{MaxDeletions: 2},
+			Deletions:   2,
+			Repos:       2,
+			Managed:     4,
+			WantDeletes: 4,
+		},
+		{
+			Name:        "Over the maximum",
+			Guard:       DeletionGuard{MaxDeletions: 2},
+			Deletions:   3,
+			Repos:       1,
+			Managed:     4,
+			WantBlocked: true,
+		},
+		{
+			Name:        "Under the maximum percentage",
+			Guard:       DeletionGuard{MaxDeletionPercent: 50},
+			Deletions:   2,
+			Repos:       2,
+			Managed:     4,
+			WantDeletes: 4,
+		},
+		{
+			Name:        "Over the maximum percentage",
+			Guard:       DeletionGuard{MaxDeletionPercent: 50},
+			Deletions:   3,
+			Repos:       1,
+			Managed:     4,
+			WantBlocked: true,
+		},
+		{
+			Name:        "Grace period defers deletions",
+			Guard:       DeletionGuard{GracePeriod: time.Hour},
+			Deletions:   1,
+			Repos:       1,
+			Managed:     2,
+			WantDeletes: 0,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			p := testPlan(c.Deletions)
+			_, err := guardDeletions(c.Guard, p, c.Repos, c.Managed, make(map[string]time.Time), time.Now())
+			if _, ok := err.(*DeletionsBlockedError); ok != c.WantBlocked {
+				t.Errorf("Test: %v blocked. Wanted %v, Got %v", c.Name, c.WantBlocked, err)
+			}
+			ndelete := 0
+			for _, ch := range append(p.Deployments, p.Services...) {
+				if ch.Action == ActionDelete {
+					ndelete++
+				}
+			}
+			if ndelete != c.WantDeletes {
+				t.Errorf("Test: %v deletions. Wanted %v, Got %v", c.Name, c.WantDeletes, ndelete)
+			}
+			if len(p.Deployments)+len(p.Services)-ndelete != 2 {
+				t.Errorf("Test: %v did not expect the creates to be removed. Got %v", c.Name, p)
+			}
+		})
+	}
+}
+
+func TestGuardDeletionsGracePeriod(t *testing.T) {
+	g := DeletionGuard{GracePeriod: time.Hour}
+	untracked := make(map[string]time.Time)
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	p := testPlan(1)
+	waiting, err := guardDeletions(g, p, 1, 2, untracked, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*PendingDeletion{
+		{Kind: "deployment", Name: "d-0", Due: start.Add(time.Hour)},
+		{Kind: "service", Name: "s-0", Due: start.Add(time.Hour)},
+	}
+	if diff := cmp.Diff(want, waiting); diff != "" {
+		t.Errorf("Pending deletions differ (-want +got)\n%s", diff)
+	}
+
+	// Still within the grace period
+	p = testPlan(1)
+	if waiting, _ = guardDeletions(g, p, 1, 2, untracked, start.Add(30*time.Minute)); len(waiting) != 2 {
+		t.Errorf("Wanted 2 deletions waiting. Got %v", len(waiting))
+	}
+
+	// The repository is tracked again, so its timer is reset
+	p = testPlan(0)
+	guardDeletions(g, p, 2, 2, untracked, start.Add(45*time.Minute))
+	if len(untracked) != 0 {
+		t.Errorf("Wanted no untracked objects. Got %v", untracked)
+	}
+
+	p = testPlan(1)
+	if waiting, _ = guardDeletions(g, p, 1, 2, untracked, start.Add(90*time.Minute)); len(waiting) != 2 {
+		t.Errorf("Wanted 2 deletions waiting after the repository was tracked again. Got %v", len(waiting))
+	}
+
+	p = testPlan(1)
+	if waiting, _ = guardDeletions(g, p, 1, 2, untracked, start.Add(150*time.Minute)); len(waiting) != 0 {
+		t.Errorf("Wanted no deletions waiting after the grace period. Got %v", len(waiting))
+	}
+	if len(p.Deployments) != 2 || len(p.Services) != 2 {
+		t.Errorf("Wanted the deletions to be kept after the grace period. Got %v", p)
+	}
+}
+
+func TestTruncatedRepoListIsNotDeleted(t *testing.T) {
+	log := logrus.New()
+	clientSet := fake.NewSimpleClientset()
+	config := K8sConfiguration{
+		ServiceNamer: func(a repos.TrackedRepository) (string, error) {
+			return fmt.Sprintf("s-%v", a.RepoSha()), nil
+		},
+		DeploymentNamer: func(a repos.TrackedRepository) (string, error) {
+			return fmt.Sprintf("d-%v", a.RepoSha()), nil
+		},
+		ServiceBuilder: func(a repos.TrackedRepository) (*apiv1.Service, error) {
+			return &apiv1.Service{ObjectMeta: metav1.ObjectMeta{
+				Name: fmt.Sprintf("s-%v", a.RepoSha()),
+			}}, nil
+		},
+		DeploymentBuilder: func(a repos.TrackedRepository) (*appsv1.Deployment, error) {
+			return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+				Name: fmt.Sprintf("d-%v", a.RepoSha()),
+			}}, nil
+		},
+		PreDeploy:    func(repos.TrackedRepository) error { return nil },
+		ShouldDeploy: func(tr repos.TrackedRepository) bool { return tr.IsTrackingSamples },
+		Guard:        DeletionGuard{MaxDeletionPercent: 50},
+	}
+	repoList := &fakeRepoList{
+		idx: -1,
+		RepoStack: [][]repos.TrackedRepository{
+			{
+				repos.TrackedRepository{Owner: "foo", Name: "bar", IsTrackingSamples: true, DefaultBranch: "main"},
+				repos.TrackedRepository{Owner: "baz", Name: "biz", IsTrackingSamples: true, DefaultBranch: "main"},
+				repos.TrackedRepository{Owner: "beep", Name: "boop", IsTrackingSamples: true, DefaultBranch: "main"},
+			},
+			// Truncated
+			{
+				repos.TrackedRepository{Owner: "foo", Name: "bar", IsTrackingSamples: true, DefaultBranch: "main"},
+			},
+			// Unreadable
+			{},
+		},
+	}
+
+	spr, err := newK8sSupervisor(log, clientSet, config, repoList, "testapp")
+	if err != nil {
+		t.Errorf("Got an error making a new supervisor: %v", err)
+	}
+
+	ctx := context.Background()
+	spr.updateCorpusRepoList(ctx, func(err error) { t.Errorf("Got an error updating: %v", err) })
+
+	for i := 0; i < 2; i++ {
+		var blocked error
+		spr.updateCorpusRepoList(ctx, func(err error) { blocked = err })
+		if _, ok := blocked.(*DeletionsBlockedError); !ok {
+			t.Errorf("Wanted a DeletionsBlockedError. Got %v", blocked)
+		}
+		if spr.status.Blocked == "" {
+			t.Errorf("Wanted the status to say why the deletions were blocked")
+		}
+	}
+
+	for _, a := range clientSet.Actions() {
+		if a.GetVerb() == "delete" {
+			t.Errorf("Did not expect to delete a resource: %v", a)
+		}
+	}
+}
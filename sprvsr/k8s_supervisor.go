@@ -16,10 +16,12 @@ package sprvsr
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/GoogleCloudPlatform/devrel-services/repos"
 
@@ -60,6 +62,33 @@ type k8supervisor struct {
 
 	// A unique name (per k8s cluster) for your application to supervise
 	labelgenkey string
+
+	// Log the changes of an update instead of making them
+	dryRun bool
+	// Whether the tracked repositories changed since the cluster was
+	// last updated
+	pending bool
+
+	// Limits on the deletions of an update
+	guard DeletionGuard
+	// When each deletion waiting for its grace period was first planned
+	untracked map[string]time.Time
+
+	// How often to repair drift between the cluster and the tracked
+	// repositories. 0 only reconciles on /update
+	reconcileInterval time.Duration
+
+	statusMu sync.Mutex
+	status   Status
+	// The repositories that should have a Deployment, by name
+	tracked map[string]bool
+
+	// The election of the replica updating the cluster, nil if there is
+	// only one replica
+	election *LeaderElection
+	leaderMu sync.RWMutex
+	leader   string
+	leading  bool
 }
 
 // ServiceNamer is called to determine what to name a Service Given a TrackedRepository
@@ -91,6 +120,18 @@ type K8sConfiguration struct {
 	DeploymentBuilder DeploymentBuilder
 	PreDeploy         DeploymentPrep
 	ShouldDeploy      DeploymentCheck
+	// DryRun makes /update log the changes it would make to the cluster
+	// instead of making them
+	DryRun bool
+	// Guard limits the deletions of an update
+	Guard DeletionGuard
+	// ReconcileInterval is how often to repair Deployments and Services
+	// edited or deleted by hand. Deletions are also repaired as they
+	// happen. 0 only reconciles when the tracked repositories change
+	ReconcileInterval time.Duration
+	// LeaderElection, if set, lets several replicas of the supervisor
+	// run, of which only the leader updates the cluster
+	LeaderElection *LeaderElection
 }
 
 // NewK8sSupervisor creates a new supervisor backed by Kubernetes
@@ -117,57 +158,212 @@ func newK8sSupervisor(log *logrus.Logger, clientset kubernetes.Interface, kconfi
 		deploymentCheck:   kconfig.ShouldDeploy,
 		repoList:          rl,
 		labelgenkey:       lblkey,
+		dryRun:            kconfig.DryRun,
+		guard:             kconfig.Guard,
+		untracked:         make(map[string]time.Time),
+		reconcileInterval: kconfig.ReconcileInterval,
+		election:          kconfig.LeaderElection,
 	}, nil
 }
 
 // Supervise registers an http server on the given address
 // and error handler. This watches the Kubernetes cluster for
-// changes and enforces them with the /update route
+// changes and enforces them with the /update route. The /plan
+// route returns the changes /update would make as JSON, and
+// the /status route the state of the supervisor. With a
+// LeaderElection, only the leader updates the cluster, the
+// others proxy /update to it, and the /leader route says
+// which replica leads
 func (s *k8supervisor) Supervise(address string, handle func(error)) error {
-	go s.updateCorpusRepoList(context.Background(), handle)
+	ctx := context.Background()
+	if s.election != nil {
+		le, err := s.newElector(handle)
+		if err != nil {
+			return err
+		}
+		go s.campaign(ctx, le)
+	} else {
+		go s.updateCorpusRepoList(ctx, handle)
+		if s.reconcileInterval > 0 {
+			go s.reconcileLoop(ctx, handle)
+		}
+	}
+
+	// Add middleware support
+	n := negroni.New()
+	l := negroni.NewLogger()
+	n.Use(l)
+	n.Use(negroni.NewRecovery())
+	n.UseHandler(s.router(handle))
 
+	return http.ListenAndServe(address, n)
+}
+
+// router returns the routes of the supervisor.
+func (s *k8supervisor) router(handle func(error)) *mux.Router {
 	s.log.Debugf("creating router")
 	// Send everything through Mux
 	r := mux.NewRouter()
 
 	s.log.Debugf("handling update")
 	r.HandleFunc("/update", func(w http.ResponseWriter, r *http.Request) {
+		if s.follow(w, r) {
+			return
+		}
 		s.updateCorpusRepoList(r.Context(), handle)
 	}).Methods("GET", "POST")
 
+	s.log.Debugf("handling plan")
+	r.HandleFunc("/plan", func(w http.ResponseWriter, r *http.Request) {
+		p, err := s.planRepoList(r.Context())
+		if err != nil {
+			handle(err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(p)
+	}).Methods("GET")
+
+	s.log.Debugf("handling status")
+	r.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		st, err := s.currentStatus()
+		if err != nil {
+			handle(err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		serveStatus(w, st)
+	}).Methods("GET")
+
+	s.log.Debugf("handling leader")
+	r.HandleFunc("/leader", func(w http.ResponseWriter, r *http.Request) {
+		serveLeadership(w, http.StatusOK, s.leadership())
+	}).Methods("GET")
+
 	s.log.Debugf("handling healthz")
 	r.HandleFunc("/_healthz", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(200)
 		w.Write([]byte("ok"))
 	})
 
-	// Add middleware support
-	n := negroni.New()
-	l := negroni.NewLogger()
-	n.Use(l)
-	n.Use(negroni.NewRecovery())
-	n.UseHandler(r)
-
-	return http.ListenAndServe(address, n)
+	return r
 }
 
 func (s *k8supervisor) updateCorpusRepoList(ctx context.Context, handle func(error)) {
-	s.mu.RLock()
-	changed, err := s.repoList.UpdateTrackedRepos(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed, err := s.refresh(ctx)
 	if err != nil {
 		handle(err)
-		s.mu.RUnlock()
 		return
 	}
-	s.mu.RUnlock()
 	if !changed {
 		s.log.Debug("skipping updating corpus repo list. unchanged")
 		return
 	}
+	s.reconcileLocked(handle)
+}
+
+// reconcile makes the cluster match the tracked repositories, whether or not
+// they changed, repairing Deployments and Services edited or deleted by hand.
+func (s *k8supervisor) reconcile(ctx context.Context, handle func(error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Repair the cluster from the last list read if it cannot be read now
+	if _, err := s.refresh(ctx); err != nil {
+		handle(err)
+	}
+	s.reconcileLocked(handle)
+}
+
+// reconcileLocked plans and applies the changes that make the cluster match
+// the tracked repositories. s.mu must be held.
+func (s *k8supervisor) reconcileLocked(handle func(error)) {
+	now := time.Now()
+	var errs []string
+	report := func(err error) {
+		errs = append(errs, err.Error())
+		handle(err)
+	}
+	blocked := ""
+	var waiting []*PendingDeletion
+	defer func() { s.setStatus(now, result(errs), blocked, waiting) }()
+
+	p, err := s.plan()
+	if err != nil {
+		report(err)
+		return
+	}
+
+	waiting, err = guardDeletions(s.guard, p, len(s.repoList.GetTrackedRepos()), p.managed, s.untracked, now)
+	if err != nil {
+		report(err)
+		blocked = err.Error()
+	}
 
+	if s.dryRun {
+		s.log.Infof("dry run. not applying plan: %v", p)
+		return
+	}
+	s.apply(p, report)
+	// Blocked and waiting deletions are looked at again by the next update
+	s.pending = blocked != "" || len(waiting) > 0
+}
+
+// setStatus records the outcome of a reconcile at now. s.mu must be held.
+func (s *k8supervisor) setStatus(now time.Time, result, blocked string, waiting []*PendingDeletion) {
+	tracked := make(map[string]bool)
+	for _, tr := range s.repoList.GetTrackedRepos() {
+		if s.deploymentCheck(tr) {
+			tracked[tr.String()] = true
+		}
+	}
+
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	if !s.dryRun {
+		s.status.LastUpdate = now
+		s.status.LastResult = result
+	}
+	s.status.Blocked = blocked
+	if waiting == nil {
+		waiting = make([]*PendingDeletion, 0)
+	}
+	s.status.PendingDeletions = waiting
+	s.tracked = tracked
+}
+
+// planRepoList refreshes the list of tracked repositories and returns the
+// changes an update would make to the cluster, without making them.
+func (s *k8supervisor) planRepoList(ctx context.Context) (*Plan, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if _, err := s.refresh(ctx); err != nil {
+		return nil, err
+	}
+	return s.plan()
+}
+
+// refresh updates the list of tracked repositories and reports whether it
+// changed since the cluster was last updated. s.mu must be held.
+func (s *k8supervisor) refresh(ctx context.Context) (bool, error) {
+	changed, err := s.repoList.UpdateTrackedRepos(ctx)
+	if err != nil {
+		return false, err
+	}
+	// A change seen by a plan still has to be applied by the next update.
+	s.pending = s.pending || changed
+	return s.pending, nil
+}
+
+// plan compares the tracked repositories with the Deployments and Services
+// in the cluster and returns the changes that reconcile them. s.mu must be
+// held.
+func (s *k8supervisor) plan() (*Plan, error) {
 	trackedRepos := s.repoList.GetTrackedRepos()
 	filteredRepos := make([]repos.TrackedRepository, 0)
 	for _, tr := range trackedRepos {
@@ -180,18 +376,22 @@ func (s *k8supervisor) updateCorpusRepoList(ctx context.Context, handle func(err
 	s.log.Debugf("got n tracked repos: %v", len(filteredRepos))
 
 	trSet := make(map[string]repos.TrackedRepository)
+	desired := make(map[repos.TrackedRepository]*appsv1.Deployment)
+	desiredByKey := make(map[string]*appsv1.Deployment)
 	for _, tr := range filteredRepos {
 		d, err := s.deploymentBuilder(tr)
 		if err != nil {
-			handle(err)
-			return
+			return nil, err
 		}
+		labelDeployment(d, s.labelgenkey, tr)
+		desired[tr] = d
 		imgs := make([]string, 0)
 		for _, i := range d.Spec.Template.Spec.Containers {
 			imgs = append(imgs, i.Image)
 		}
-		name := fmt.Sprintf("%v-%v-%v", tr.String(), strings.Join(imgs, "/"), tr.DefaultBranch)
+		name := fmt.Sprintf("%v-%v-%v", tr.String(), strings.Join(imgs, "/"), trackedBranch(tr))
 		trSet[name] = tr
+		desiredByKey[name] = d
 	}
 
 	s.log.Debugf("trSet: %v", trSet)
@@ -205,6 +405,9 @@ func (s *k8supervisor) updateCorpusRepoList(ctx context.Context, handle func(err
 
 	deploymentsSet := make(map[string]repos.TrackedRepository)
 	servicesSet := make(map[string]repos.TrackedRepository)
+	existing := make(map[repos.TrackedRepository]*appsv1.Deployment)
+	existingByKey := make(map[string]*appsv1.Deployment)
+	existingServices := make(map[string]*apiv1.Service)
 
 	// Store this as a variable here in the event we want this configurable
 	ns := apiv1.NamespaceDefault
@@ -214,10 +417,9 @@ func (s *k8supervisor) updateCorpusRepoList(ctx context.Context, handle func(err
 		LabelSelector: labelSelector,
 	})
 	if err != nil {
-		handle(err)
-		return
+		return nil, err
 	}
-	for _, deployment := range deployments.Items {
+	for i, deployment := range deployments.Items {
 		// Now we have the deployment.... inspect the
 		// labels in the deployment to get the owner and repository
 		s.log.Infof("processing deployment: %v. Labels: %v", deployment.Name, deployment.Labels)
@@ -243,8 +445,10 @@ func (s *k8supervisor) updateCorpusRepoList(ctx context.Context, handle func(err
 		for _, c := range deployment.Spec.Template.Spec.Containers {
 			imgs = append(imgs, c.Image)
 		}
-		name := fmt.Sprintf("%v-%v-%v", tr.String(), strings.Join(imgs, "/"), tr.DefaultBranch)
+		name := fmt.Sprintf("%v-%v-%v", tr.String(), strings.Join(imgs, "/"), trackedBranch(tr))
 		deploymentsSet[name] = tr
+		existing[tr] = &deployments.Items[i]
+		existingByKey[name] = &deployments.Items[i]
 	}
 
 	s.log.Debugf("have deployments from k8s: %v", deploymentsSet)
@@ -253,10 +457,9 @@ func (s *k8supervisor) updateCorpusRepoList(ctx context.Context, handle func(err
 		LabelSelector: labelSelector,
 	})
 	if err != nil {
-		handle(err)
-		return
+		return nil, err
 	}
-	for _, service := range services.Items {
+	for i, service := range services.Items {
 		// Now we have the service... inspect the
 		// labels on the service to get the owner and repository
 		if _, ok := service.Labels["owner"]; !ok {
@@ -273,78 +476,242 @@ func (s *k8supervisor) updateCorpusRepoList(ctx context.Context, handle func(err
 			Name:  r,
 		}
 		servicesSet[tr.String()] = tr
+		existingServices[tr.String()] = &services.Items[i]
 	}
 
 	s.log.Debugf("have services from k8s: %v", servicesSet)
 
-	// Delete Services before deployments
+	p := &Plan{
+		Deployments: make([]*Change, 0),
+		Services:    make([]*Change, 0),
+		managed:     len(deploymentsSet),
+	}
+
 	servicesToDelete := setDifference(servicesSet, tServicesSet)
 	s.log.Debugf("have services to delete: %v", servicesToDelete)
 	for td := range servicesToDelete {
-		// Delete the service
 		sn, err := s.serviceNamer(td)
 		if err != nil {
-			handle(err)
-			continue
+			return nil, err
 		}
+		p.Services = append(p.Services, &Change{Action: ActionDelete, Name: sn, Repository: td.String(), tr: td})
+	}
 
-		err = s.clientset.CoreV1().Services(ns).Delete(sn, &metav1.DeleteOptions{})
+	// A deployment to add with the name of one to delete replaces it.
+	deploymentsToAdd := setDifference(trSet, deploymentsSet)
+	s.log.Debugf("have deployments to add: %v", deploymentsToAdd)
+	adds := make(map[string]*Change)
+	for ta := range deploymentsToAdd {
+		dn, err := s.deploymentNamer(ta)
 		if err != nil {
-			handle(err)
+			return nil, err
 		}
+		adds[dn] = &Change{Action: ActionCreate, Name: dn, Repository: ta.String(), tr: ta, deployment: desired[ta]}
 	}
 
 	deploymentsToDelete := setDifference(deploymentsSet, trSet)
 	s.log.Debugf("have deployments to delete: %v", deploymentsToDelete)
 	for td := range deploymentsToDelete {
-		// Delete the deployment
 		dn, err := s.deploymentNamer(td)
 		if err != nil {
-			handle(err)
+			return nil, err
+		}
+		c, ok := adds[dn]
+		if !ok {
+			p.Deployments = append(p.Deployments, &Change{Action: ActionDelete, Name: dn, Repository: td.String(), tr: td})
+			continue
+		}
+		c.Action = ActionUpdate
+		if c.Diff, err = diffFields(existing[td], c.deployment); err != nil {
+			return nil, err
+		}
+	}
+
+	// Deployments edited by hand drifted from what the builder makes
+	for key, d := range desiredByKey {
+		e, ok := existingByKey[key]
+		if !ok {
+			continue
+		}
+		diff, err := diffFields(e, d)
+		if err != nil {
+			return nil, err
+		}
+		if len(diff) == 0 {
+			continue
+		}
+		tr := trSet[key]
+		dn, err := s.deploymentNamer(tr)
+		if err != nil {
+			return nil, err
+		}
+		p.Deployments = append(p.Deployments, &Change{Action: ActionUpdate, Name: dn, Repository: tr.String(), Diff: diff, tr: tr, deployment: d})
+	}
+
+	for _, c := range adds {
+		if c.Action == ActionCreate {
+			if c.Diff, err = diffFields(nil, c.deployment); err != nil {
+				return nil, err
+			}
+		}
+		p.Deployments = append(p.Deployments, c)
+	}
+
+	servicesToAdd := setDifference(tServicesSet, servicesSet)
+	s.log.Debugf("have services to add: %v", servicesToAdd)
+	for ta := range servicesToAdd {
+		svc, err := s.serviceBuilder(ta)
+		if err != nil {
+			return nil, err
+		}
+		labelService(svc, s.labelgenkey, ta)
+		sn, err := s.serviceNamer(ta)
+		if err != nil {
+			return nil, err
+		}
+		c := &Change{Action: ActionCreate, Name: sn, Repository: ta.String(), tr: ta, service: svc}
+		if c.Diff, err = diffFields(nil, svc); err != nil {
+			return nil, err
+		}
+		p.Services = append(p.Services, c)
+	}
+
+	// Services edited by hand drifted from what the builder makes
+	for key, tr := range tServicesSet {
+		e, ok := existingServices[key]
+		if !ok {
+			continue
+		}
+		svc, err := s.serviceBuilder(tr)
+		if err != nil {
+			return nil, err
+		}
+		labelService(svc, s.labelgenkey, tr)
+		diff, err := diffFields(e, svc)
+		if err != nil {
+			return nil, err
+		}
+		if len(diff) == 0 {
 			continue
 		}
-		err = s.clientset.AppsV1().Deployments(ns).Delete(dn, &metav1.DeleteOptions{})
+		sn, err := s.serviceNamer(tr)
 		if err != nil {
+			return nil, err
+		}
+		p.Services = append(p.Services, &Change{Action: ActionUpdate, Name: sn, Repository: tr.String(), Diff: diff, tr: tr, service: svc})
+	}
+
+	sortChanges(p.Deployments)
+	sortChanges(p.Services)
+	return p, nil
+}
+
+// apply makes the changes of p to the cluster. Objects are updated in
+// place, so a Deployment only rolls its pods if its pod template changed,
+// and an update the cluster rejects leaves the live object as it was.
+func (s *k8supervisor) apply(p *Plan, handle func(error)) {
+	ns := apiv1.NamespaceDefault
+
+	// Delete Services before deployments
+	for _, c := range p.Services {
+		if c.Action != ActionDelete {
+			continue
+		}
+		if err := s.clientset.CoreV1().Services(ns).Delete(c.Name, &metav1.DeleteOptions{}); err != nil {
+			handle(err)
+		}
+	}
+
+	for _, c := range p.Deployments {
+		if c.Action != ActionDelete {
+			continue
+		}
+		if err := s.clientset.AppsV1().Deployments(ns).Delete(c.Name, &metav1.DeleteOptions{}); err != nil {
 			handle(err)
 		}
 	}
 
 	// Add deployments before services
-	deploymentsToAdd := setDifference(trSet, deploymentsSet)
-	s.log.Debugf("have deployments to add: %v", deploymentsToAdd)
-	for ta := range deploymentsToAdd {
-		// Add the deployment
-		if err := s.deploymentPrep(ta); err != nil {
+	for _, c := range p.Deployments {
+		if c.Action == ActionDelete {
+			continue
+		}
+		if err := s.deploymentPrep(c.tr); err != nil {
 			handle(err)
 			continue
 		}
 
-		if err := createDeployment(s.clientset, ns, s.labelgenkey, s.deploymentBuilder, s.deploymentNamer, ta); err != nil {
+		var err error
+		if c.Action == ActionUpdate {
+			err = updateDeployment(s.clientset, ns, c.deployment, c.Name)
+		} else {
+			err = createDeployment(s.clientset, ns, c.deployment, c.Name)
+		}
+		if err != nil {
 			handle(err)
 		}
 	}
 
-	servicesToAdd := setDifference(tServicesSet, servicesSet)
-	s.log.Debugf("have services to add: %v", servicesToAdd)
-	for ta := range servicesToAdd {
-		// Add the service
-		if err := createService(s.clientset, ns, s.labelgenkey, s.serviceBuilder, ta); err != nil {
+	for _, c := range p.Services {
+		if c.Action == ActionDelete {
+			continue
+		}
+		if c.Action == ActionUpdate {
+			if err := updateService(s.clientset, ns, c.service, c.Name); err != nil {
+				handle(err)
+			}
+			s.log.Debugf("updated service for %v", c.tr)
+			continue
+		}
+		if _, err := s.clientset.CoreV1().Services(ns).Create(c.service); err != nil {
 			handle(err)
 		}
-		s.log.Debugf("created service for %v", ta)
+		s.log.Debugf("created service for %v", c.tr)
+	}
+}
+
+func createDeployment(cs kubernetes.Interface, ns string, d *appsv1.Deployment, dname string) error {
+	_, err := cs.AppsV1().Deployments(ns).Create(d)
+
+	if err != nil && err.Error() == fmt.Sprintf("deployments.apps: \"%v\" already exists", dname) {
+		err = nil
 	}
+	return err
 }
 
-func createDeployment(cs kubernetes.Interface, ns string, lblkey string, db DeploymentBuilder, dn DeploymentNamer, ta repos.TrackedRepository) error {
-	d, err := db(ta)
+// updateDeployment replaces the live Deployment dname with d.
+func updateDeployment(cs kubernetes.Interface, ns string, d *appsv1.Deployment, dname string) error {
+	live, err := cs.AppsV1().Deployments(ns).Get(dname, metav1.GetOptions{})
 	if err != nil {
 		return err
 	}
-	dname, err := dn(ta)
+	d = d.DeepCopy()
+	d.Name = dname
+	d.ResourceVersion = live.ResourceVersion
+	_, err = cs.AppsV1().Deployments(ns).Update(d)
+	return err
+}
+
+// updateService replaces the live Service sname with svc. The cluster IP
+// the live Service was given is kept, as it can't be changed.
+func updateService(cs kubernetes.Interface, ns string, svc *apiv1.Service, sname string) error {
+	live, err := cs.CoreV1().Services(ns).Get(sname, metav1.GetOptions{})
 	if err != nil {
 		return err
 	}
+	svc = svc.DeepCopy()
+	svc.Name = sname
+	svc.ResourceVersion = live.ResourceVersion
+	if svc.Spec.ClusterIP == "" {
+		svc.Spec.ClusterIP = live.Spec.ClusterIP
+	}
+	_, err = cs.CoreV1().Services(ns).Update(svc)
+	return err
+}
 
+// labelDeployment gives d the labels the supervisor finds its Deployments
+// and their repositories by.
+func labelDeployment(d *appsv1.Deployment, lblkey string, ta repos.TrackedRepository) {
 	// Give the deployment our uinque label
 	if d.ObjectMeta.Labels == nil {
 		d.ObjectMeta.Labels = make(map[string]string, 0)
@@ -356,11 +723,7 @@ func createDeployment(cs kubernetes.Interface, ns string, lblkey string, db Depl
 	d.ObjectMeta.Labels["repository"] = ta.Name
 
 	// Add the branch to the deployment labels
-	defaultBranch := "master"
-	if ta.DefaultBranch != "" {
-		defaultBranch = ta.DefaultBranch
-	}
-	d.ObjectMeta.Labels["branch"] = defaultBranch
+	d.ObjectMeta.Labels["branch"] = trackedBranch(ta)
 
 	// Give the pods our uinque label
 	if d.Spec.Template.ObjectMeta.Labels == nil {
@@ -371,21 +734,19 @@ func createDeployment(cs kubernetes.Interface, ns string, lblkey string, db Depl
 	// Add Owner and repository labels to the pods
 	d.Spec.Template.ObjectMeta.Labels["owner"] = ta.Owner
 	d.Spec.Template.ObjectMeta.Labels["repository"] = ta.Name
-
-	_, err = cs.AppsV1().Deployments(ns).Create(d)
-
-	if err != nil && err.Error() == fmt.Sprintf("deployments.apps: \"%v\" already exists", dname) {
-		err = nil
-	}
-	return err
 }
 
-func createService(cs kubernetes.Interface, ns string, labelgenkey string, sb ServiceBuilder, ta repos.TrackedRepository) error {
-	svc, err := sb(ta)
-	if err != nil {
-		return err
+// trackedBranch returns the branch a Deployment tracks for ta.
+func trackedBranch(ta repos.TrackedRepository) string {
+	if ta.DefaultBranch != "" {
+		return ta.DefaultBranch
 	}
+	return "master"
+}
 
+// labelService gives svc the labels the supervisor finds its Services and
+// their repositories by.
+func labelService(svc *apiv1.Service, labelgenkey string, ta repos.TrackedRepository) {
 	if svc.ObjectMeta.Labels == nil {
 		svc.ObjectMeta.Labels = make(map[string]string, 0)
 	}
@@ -394,9 +755,6 @@ func createService(cs kubernetes.Interface, ns string, labelgenkey string, sb Se
 
 	svc.ObjectMeta.Labels["owner"] = ta.Owner
 	svc.ObjectMeta.Labels["repository"] = ta.Name
-
-	_, err = cs.CoreV1().Services(ns).Create(svc)
-	return err
 }
 
 // Returns the difference between this set
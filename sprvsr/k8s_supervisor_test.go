@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/GoogleCloudPlatform/devrel-services/repos"
 
@@ -531,23 +532,29 @@ func TestUpdatedImagesAreReplaced(t *testing.T) {
 	spr.updateCorpusRepoList(ctx, func(error) {})
 
 	ncreate := 0
+	nupdate := 0
 	ndelete := 0
 	for _, a := range clientSet.Actions() {
 		t.Logf("Got verb %v", a.GetVerb())
 
 		if a.GetVerb() == "create" {
 			ncreate++
+		} else if a.GetVerb() == "update" {
+			nupdate++
 		} else if a.GetVerb() == "delete" {
 			ndelete++
 		}
 	}
-	// Want 2 as we are creating one service and one deployment
-	if ncreate != 2 {
-		t.Errorf("Wanted %v Created. Got %v", 2, ncreate)
+	// Want 1 as we are creating one service
+	if ncreate != 1 {
+		t.Errorf("Wanted %v Created. Got %v", 1, ncreate)
 	}
-	// We should delete the existing deployment
-	if ndelete != 1 {
-		t.Errorf("Wanted %v Deleted. Got %v", 1, ndelete)
+	// We should update the existing deployment in place
+	if nupdate != 1 {
+		t.Errorf("Wanted %v Updated. Got %v", 1, nupdate)
+	}
+	if ndelete != 0 {
+		t.Errorf("Wanted %v Deleted. Got %v", 0, ndelete)
 	}
 }
 
@@ -648,22 +655,558 @@ func TestChangedBranchesAreReplaced(t *testing.T) {
 	spr.updateCorpusRepoList(ctx, func(error) {})
 
 	ncreate := 0
+	nupdate := 0
 	ndelete := 0
 	for _, a := range clientSet.Actions() {
 		t.Logf("Got verb %v", a.GetVerb())
 
 		if a.GetVerb() == "create" {
 			ncreate++
+		} else if a.GetVerb() == "update" {
+			nupdate++
 		} else if a.GetVerb() == "delete" {
 			ndelete++
 		}
 	}
-	// Want 2 as we are creating one service and one deployment
-	if ncreate != 2 {
-		t.Errorf("Wanted %v Created. Got %v", 2, ncreate)
+	// Want 1 as we are creating one service
+	if ncreate != 1 {
+		t.Errorf("Wanted %v Created. Got %v", 1, ncreate)
+	}
+	// We should update the existing deployment in place
+	if nupdate != 1 {
+		t.Errorf("Wanted %v Updated. Got %v", 1, nupdate)
+	}
+	if ndelete != 0 {
+		t.Errorf("Wanted %v Deleted. Got %v", 0, ndelete)
+	}
+}
+
+func TestPlanDoesNotChangeTheCluster(t *testing.T) {
+	log := logrus.New()
+
+	appid := "testapp"
+
+	// This deployment tracks another branch, so the plan updates it.
+	existingDeployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "d-foo-bar",
+			Labels: map[string]string{
+				"owner":                  "foo",
+				"repository":             "bar",
+				"testapp-sprvsr-autogen": "true",
+				"branch":                 "branch0",
+			},
+			Namespace: apiv1.NamespaceDefault,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: apiv1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"owner":                  "foo",
+						"repository":             "bar",
+						"testapp-sprvsr-autogen": "true",
+					},
+				},
+			},
+		},
+	}
+	// This deployment's repository is no longer tracked.
+	goneDeployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "d-baz-biz",
+			Labels: map[string]string{
+				"owner":                  "baz",
+				"repository":             "biz",
+				"testapp-sprvsr-autogen": "true",
+			},
+			Namespace: apiv1.NamespaceDefault,
+		},
+	}
+
+	clientSet := fake.NewSimpleClientset(existingDeployment, goneDeployment)
+	config := K8sConfiguration{
+		ServiceNamer: func(a repos.TrackedRepository) (string, error) {
+			return fmt.Sprintf("s-%v-%v", a.Owner, a.Name), nil
+		},
+		DeploymentNamer: func(a repos.TrackedRepository) (string, error) {
+			return fmt.Sprintf("d-%v-%v", a.Owner, a.Name), nil
+		},
+		ServiceBuilder: func(a repos.TrackedRepository) (*apiv1.Service, error) {
+			return &apiv1.Service{ObjectMeta: metav1.ObjectMeta{
+				Name: fmt.Sprintf("s-%v-%v", a.Owner, a.Name),
+			}}, nil
+		},
+		DeploymentBuilder: func(a repos.TrackedRepository) (*appsv1.Deployment, error) {
+			return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+				Name: fmt.Sprintf("d-%v-%v", a.Owner, a.Name),
+			}}, nil
+		},
+		PreDeploy:    func(repos.TrackedRepository) error { return nil },
+		ShouldDeploy: func(tr repos.TrackedRepository) bool { return tr.IsTrackingSamples },
+	}
+	repoList := &fakeRepoList{
+		idx: -1,
+		RepoStack: [][]repos.TrackedRepository{
+			{
+				repos.TrackedRepository{
+					Owner:             "foo",
+					Name:              "bar",
+					IsTrackingSamples: true,
+					DefaultBranch:     "branch1",
+				},
+			},
+		},
 	}
-	// We should delete the existing deployment
+
+	spr, err := newK8sSupervisor(log, clientSet, config, repoList, appid)
+	if err != nil {
+		t.Errorf("Got an error making a new supervisor: %v", err)
+	}
+
+	ctx := context.Background()
+	p, err := spr.planRepoList(ctx)
+	if err != nil {
+		t.Fatalf("Got an error planning: %v", err)
+	}
+
+	for _, a := range clientSet.Actions() {
+		if a.GetVerb() != "list" {
+			t.Errorf("Did not expect planning to %v a resource: %v", a.GetVerb(), a)
+		}
+	}
+
+	gotDeployments := make(map[string]string)
+	for _, c := range p.Deployments {
+		gotDeployments[c.Name] = c.Action
+	}
+	wantDeployments := map[string]string{
+		"d-foo-bar": ActionUpdate,
+		"d-baz-biz": ActionDelete,
+	}
+	if diff := cmp.Diff(wantDeployments, gotDeployments); diff != "" {
+		t.Errorf("Planned deployments differ (-want +got)\n%s", diff)
+	}
+	if len(p.Services) != 1 || p.Services[0].Name != "s-foo-bar" || p.Services[0].Action != ActionCreate {
+		t.Errorf("Wanted to create service s-foo-bar. Got %v", p.Services)
+	}
+
+	for _, c := range p.Deployments {
+		if c.Action != ActionUpdate {
+			continue
+		}
+		want := []*FieldDiff{{Path: "metadata.labels.branch", Old: "branch0", New: "branch1"}}
+		if diff := cmp.Diff(want, c.Diff); diff != "" {
+			t.Errorf("Update diff differs (-want +got)\n%s", diff)
+		}
+	}
+
+	// The plan consumed the change to the repo list, but the update still
+	// applies it.
+	spr.updateCorpusRepoList(ctx, func(err error) { t.Errorf("Got an error updating: %v", err) })
+
+	ncreate, nupdate, ndelete := countVerbs(clientSet)
+	// Want 1 as we are creating one service
+	if ncreate != 1 {
+		t.Errorf("Wanted %v Created. Got %v", 1, ncreate)
+	}
+	// Want 1 as we are updating one deployment in place
+	if nupdate != 1 {
+		t.Errorf("Wanted %v Updated. Got %v", 1, nupdate)
+	}
+	// Want 1 as we are deleting the other deployment
 	if ndelete != 1 {
 		t.Errorf("Wanted %v Deleted. Got %v", 1, ndelete)
 	}
 }
+
+func TestDryRunDoesNotChangeTheCluster(t *testing.T) {
+	log := logrus.New()
+	clientSet := fake.NewSimpleClientset()
+	config := K8sConfiguration{
+		ServiceNamer:    func(repos.TrackedRepository) (string, error) { return "foo", nil },
+		DeploymentNamer: func(repos.TrackedRepository) (string, error) { return "foo", nil },
+		ServiceBuilder: func(repos.TrackedRepository) (*apiv1.Service, error) {
+			return &apiv1.Service{}, nil
+		},
+		DeploymentBuilder: func(repos.TrackedRepository) (*appsv1.Deployment, error) {
+			return &appsv1.Deployment{}, nil
+		},
+		PreDeploy:    func(repos.TrackedRepository) error { return nil },
+		ShouldDeploy: func(tr repos.TrackedRepository) bool { return tr.IsTrackingSamples },
+		DryRun:       true,
+	}
+	repoList := &fakeRepoList{
+		idx: -1,
+		RepoStack: [][]repos.TrackedRepository{
+			{
+				repos.TrackedRepository{
+					Owner:             "foo",
+					Name:              "bar",
+					IsTrackingSamples: true,
+				},
+			},
+		},
+	}
+
+	spr, err := newK8sSupervisor(log, clientSet, config, repoList, "testapp")
+	if err != nil {
+		t.Errorf("Got an error making a new supervisor: %v", err)
+	}
+
+	spr.updateCorpusRepoList(context.Background(), func(error) {})
+
+	for _, a := range clientSet.Actions() {
+		if a.GetVerb() != "list" {
+			t.Errorf("Did not expect a dry run to %v a resource: %v", a.GetVerb(), a)
+		}
+	}
+}
+
+func TestDiffFields(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Existing *appsv1.Deployment
+		Desired  *appsv1.Deployment
+		Want     []*FieldDiff
+	}{
+		{
+			Name:     "New object yields every field",
+			Existing: nil,
+			Desired: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo"},
+			},
+			Want: []*FieldDiff{{Path: "metadata.name", New: "foo"}},
+		},
+		{
+			Name: "Equal objects yield nothing",
+			Existing: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo"},
+			},
+			Desired: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo"},
+			},
+			Want: []*FieldDiff{},
+		},
+		{
+			Name: "Fields set by the cluster are ignored",
+			Existing: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo", ResourceVersion: "3"},
+			},
+			Desired: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo"},
+			},
+			Want: []*FieldDiff{},
+		},
+		{
+			Name: "Changed container image",
+			Existing: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{Template: apiv1.PodTemplateSpec{Spec: apiv1.PodSpec{
+					Containers: []apiv1.Container{{Name: "c", Image: "foo:1"}},
+				}}},
+			},
+			Desired: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{Template: apiv1.PodTemplateSpec{Spec: apiv1.PodSpec{
+					Containers: []apiv1.Container{{Name: "c", Image: "foo:2"}},
+				}}},
+			},
+			Want: []*FieldDiff{{Path: "spec.template.spec.containers[0].image", Old: "foo:1", New: "foo:2"}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			got, err := diffFields(c.Existing, c.Desired)
+			if err != nil {
+				t.Fatalf("Test: %v got an error: %v", c.Name, err)
+			}
+			if diff := cmp.Diff(c.Want, got); diff != "" {
+				t.Errorf("Test: %v Diffs differ (-want +got)\n%s", c.Name, diff)
+			}
+		})
+	}
+}
+
+func reconcileConfig() K8sConfiguration {
+	return K8sConfiguration{
+		ServiceNamer: func(a repos.TrackedRepository) (string, error) {
+			return fmt.Sprintf("s-%v-%v", a.Owner, a.Name), nil
+		},
+		DeploymentNamer: func(a repos.TrackedRepository) (string, error) {
+			return fmt.Sprintf("d-%v-%v", a.Owner, a.Name), nil
+		},
+		ServiceBuilder: func(a repos.TrackedRepository) (*apiv1.Service, error) {
+			return &apiv1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: fmt.Sprintf("s-%v-%v", a.Owner, a.Name),
+				},
+				Spec: apiv1.ServiceSpec{
+					Ports: []apiv1.ServicePort{{Name: "http", Port: 80}},
+				},
+			}, nil
+		},
+		DeploymentBuilder: func(a repos.TrackedRepository) (*appsv1.Deployment, error) {
+			return &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: fmt.Sprintf("d-%v-%v", a.Owner, a.Name),
+				},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: int32Ptr(1),
+					Template: apiv1.PodTemplateSpec{
+						Spec: apiv1.PodSpec{
+							Containers: []apiv1.Container{
+								apiv1.Container{
+									Image: "foo-bar:biz",
+								},
+							},
+						},
+					},
+				},
+			}, nil
+		},
+		PreDeploy:    func(repos.TrackedRepository) error { return nil },
+		ShouldDeploy: func(tr repos.TrackedRepository) bool { return tr.IsTrackingSamples },
+	}
+}
+
+func int32Ptr(i int32) *int32 { return &i }
+
+func countVerbs(clientSet *fake.Clientset) (ncreate, nupdate, ndelete int) {
+	for _, a := range clientSet.Actions() {
+		switch a.GetVerb() {
+		case "create":
+			ncreate++
+		case "update":
+			nupdate++
+		case "delete":
+			ndelete++
+		}
+	}
+	return ncreate, nupdate, ndelete
+}
+
+func TestReconcileRepairsDrift(t *testing.T) {
+	log := logrus.New()
+	clientSet := fake.NewSimpleClientset()
+	repoList := &fakeRepoList{
+		idx: -1,
+		RepoStack: [][]repos.TrackedRepository{
+			{
+				repos.TrackedRepository{Owner: "foo", Name: "bar", IsTrackingSamples: true, DefaultBranch: "main"},
+				repos.TrackedRepository{Owner: "baz", Name: "biz", IsTrackingSamples: true, DefaultBranch: "main"},
+			},
+		},
+	}
+
+	spr, err := newK8sSupervisor(log, clientSet, reconcileConfig(), repoList, "testapp")
+	if err != nil {
+		t.Errorf("Got an error making a new supervisor: %v", err)
+	}
+
+	ctx := context.Background()
+	failOnError := func(err error) { t.Errorf("Got an error reconciling: %v", err) }
+	spr.updateCorpusRepoList(ctx, failOnError)
+
+	// Nothing drifted yet
+	clientSet.ClearActions()
+	spr.reconcile(ctx, failOnError)
+	if ncreate, nupdate, ndelete := countVerbs(clientSet); ncreate != 0 || nupdate != 0 || ndelete != 0 {
+		t.Errorf("Did not expect to change the cluster. Got %v creates, %v updates and %v deletes", ncreate, nupdate, ndelete)
+	}
+
+	// A deployment is deleted, a deployment scaled down and a service
+	// edited by hand
+	if err := clientSet.AppsV1().Deployments(ns).Delete("d-foo-bar", &metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	d, err := clientSet.AppsV1().Deployments(ns).Get("d-baz-biz", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Spec.Replicas = int32Ptr(0)
+	if _, err := clientSet.AppsV1().Deployments(ns).Update(d); err != nil {
+		t.Fatal(err)
+	}
+	svc, err := clientSet.CoreV1().Services(ns).Get("s-foo-bar", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc.Spec.Ports[0].Port = 8080
+	if _, err := clientSet.CoreV1().Services(ns).Update(svc); err != nil {
+		t.Fatal(err)
+	}
+
+	clientSet.ClearActions()
+	spr.reconcile(ctx, failOnError)
+
+	ncreate, nupdate, ndelete := countVerbs(clientSet)
+	// Want 1 as we are recreating the deleted deployment
+	if ncreate != 1 {
+		t.Errorf("Wanted %v Created. Got %v", 1, ncreate)
+	}
+	// Want 2 as we are updating 1 deployment and 1 service in place
+	if nupdate != 2 {
+		t.Errorf("Wanted %v Updated. Got %v", 2, nupdate)
+	}
+	if ndelete != 0 {
+		t.Errorf("Wanted %v Deleted. Got %v", 0, ndelete)
+	}
+
+	if _, err := clientSet.AppsV1().Deployments(ns).Get("d-foo-bar", metav1.GetOptions{}); err != nil {
+		t.Errorf("Wanted the deleted deployment to be recreated. Got %v", err)
+	}
+	d, err = clientSet.AppsV1().Deployments(ns).Get("d-baz-biz", metav1.GetOptions{})
+	if err != nil || *d.Spec.Replicas != 1 {
+		t.Errorf("Wanted the scaled deployment to have 1 replica. Got %v, %v", d, err)
+	}
+	svc, err = clientSet.CoreV1().Services(ns).Get("s-foo-bar", metav1.GetOptions{})
+	if err != nil || svc.Spec.Ports[0].Port != 80 {
+		t.Errorf("Wanted the edited service to listen on port 80. Got %v, %v", svc, err)
+	}
+
+	// Repaired
+	clientSet.ClearActions()
+	spr.reconcile(ctx, failOnError)
+	if ncreate, nupdate, ndelete := countVerbs(clientSet); ncreate != 0 || nupdate != 0 || ndelete != 0 {
+		t.Errorf("Did not expect to change the cluster. Got %v creates, %v updates and %v deletes", ncreate, nupdate, ndelete)
+	}
+}
+
+func TestReconcileLoopRepairsDeletions(t *testing.T) {
+	log := logrus.New()
+	clientSet := fake.NewSimpleClientset()
+	repoList := &fakeRepoList{
+		idx: -1,
+		RepoStack: [][]repos.TrackedRepository{
+			{
+				repos.TrackedRepository{Owner: "foo", Name: "bar", IsTrackingSamples: true, DefaultBranch: "main"},
+			},
+		},
+	}
+	config := reconcileConfig()
+	config.ReconcileInterval = time.Hour
+
+	spr, err := newK8sSupervisor(log, clientSet, config, repoList, "testapp")
+	if err != nil {
+		t.Errorf("Got an error making a new supervisor: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	spr.updateCorpusRepoList(ctx, func(err error) { t.Errorf("Got an error updating: %v", err) })
+	go spr.reconcileLoop(ctx, func(error) {})
+
+	// Wait for the watches to start
+	for i := 0; i < 100 && countWatches(clientSet) < 2; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := clientSet.AppsV1().Deployments(ns).Delete("d-foo-bar", &metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := clientSet.AppsV1().Deployments(ns).Get("d-foo-bar", metav1.GetOptions{}); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Wanted the deleted deployment to be recreated")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func countWatches(clientSet *fake.Clientset) int {
+	n := 0
+	for _, a := range clientSet.Actions() {
+		if a.GetVerb() == "watch" {
+			n++
+		}
+	}
+	return n
+}
+
+func TestStatusListsWorkloads(t *testing.T) {
+	log := logrus.New()
+	clientSet := fake.NewSimpleClientset()
+	repoList := &fakeRepoList{
+		idx: -1,
+		RepoStack: [][]repos.TrackedRepository{
+			{
+				repos.TrackedRepository{Owner: "foo", Name: "bar", IsTrackingSamples: true, DefaultBranch: "main"},
+				repos.TrackedRepository{Owner: "baz", Name: "biz", IsTrackingSamples: false, DefaultBranch: "main"},
+			},
+		},
+	}
+
+	spr, err := newK8sSupervisor(log, clientSet, reconcileConfig(), repoList, "testapp")
+	if err != nil {
+		t.Errorf("Got an error making a new supervisor: %v", err)
+	}
+	spr.updateCorpusRepoList(context.Background(), func(err error) { t.Errorf("Got an error updating: %v", err) })
+
+	d, err := clientSet.AppsV1().Deployments(ns).Get("d-foo-bar", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, restarts := range []int32{2, 1} {
+		pod := &apiv1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   fmt.Sprintf("d-foo-bar-%v", i),
+				Labels: d.Spec.Template.Labels,
+			},
+			Status: apiv1.PodStatus{
+				ContainerStatuses: []apiv1.ContainerStatus{{RestartCount: restarts}},
+			},
+		}
+		if _, err := clientSet.CoreV1().Pods(ns).Create(pod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d.Status.Replicas = 2
+	d.Status.ReadyReplicas = 1
+	if _, err := clientSet.AppsV1().Deployments(ns).Update(d); err != nil {
+		t.Fatal(err)
+	}
+
+	// A deployment left over from a repository no longer tracked
+	orphan := d.DeepCopy()
+	orphan.ObjectMeta = metav1.ObjectMeta{Name: "d-beep-boop", Labels: map[string]string{}}
+	for k, v := range d.Labels {
+		orphan.Labels[k] = v
+	}
+	orphan.Labels["owner"] = "beep"
+	orphan.Labels["repository"] = "boop"
+	orphan.Status = appsv1.DeploymentStatus{}
+	if _, err := clientSet.AppsV1().Deployments(ns).Create(orphan); err != nil {
+		t.Fatal(err)
+	}
+
+	st, err := spr.currentStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.LastResult != "ok" || st.LastUpdate.IsZero() {
+		t.Errorf("Wanted an ok last update. Got %v at %v", st.LastResult, st.LastUpdate)
+	}
+	want := []*Workload{
+		{
+			Repository: "beep/boop",
+			Deployment: "d-beep-boop",
+			Images:     []string{"foo-bar:biz"},
+		},
+		{
+			Repository:    "foo/bar",
+			Tracked:       true,
+			Deployment:    "d-foo-bar",
+			Service:       "s-foo-bar",
+			Images:        []string{"foo-bar:biz"},
+			Replicas:      2,
+			ReadyReplicas: 1,
+			Restarts:      3,
+		},
+	}
+	if diff := cmp.Diff(want, st.Workloads); diff != "" {
+		t.Errorf("Workloads differ (-want +got)\n%s", diff)
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprvsr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// The defaults of a LeaderElection, those of the Kubernetes core clients.
+const (
+	defaultLeaseDuration = 15 * time.Second
+	defaultRenewDeadline = 10 * time.Second
+	defaultRetryPeriod   = 2 * time.Second
+)
+
+// proxiedHeader marks an /update a follower proxied to the leader, with the
+// identity of the follower, so it is never proxied twice.
+const proxiedHeader = "X-Sprvsr-Proxied-By"
+
+// LeaderElection lets several replicas of a supervisor run at once. They
+// compete for a Kubernetes Lease, and only the replica holding it updates
+// the cluster. The others proxy /update to it.
+type LeaderElection struct {
+	// LeaseName is the name of the Lease, in the default namespace.
+	LeaseName string
+	// Identity names this replica in the Lease. It must be the host:port
+	// the other replicas reach its HTTP server at, e.g. its Pod's IP and
+	// port, as they proxy /update to it while it leads.
+	Identity string
+	// LeaseDuration is how long followers wait after the leader last
+	// renewed the Lease before taking it over. Defaults to 15 seconds.
+	LeaseDuration time.Duration
+	// RenewDeadline is how long the leader tries to renew the Lease before
+	// it stops leading. Defaults to 10 seconds.
+	RenewDeadline time.Duration
+	// RetryPeriod is how often the replicas try to take or renew the
+	// Lease. Defaults to 2 seconds.
+	RetryPeriod time.Duration
+}
+
+// PodIdentity returns the Identity of a replica listening on listen, from
+// the POD_IP environment variable, which the Deployment of the supervisor
+// sets with the downward API.
+func PodIdentity(listen string) (string, error) {
+	ip := os.Getenv("POD_IP")
+	if ip == "" {
+		return "", fmt.Errorf("POD_IP is not set")
+	}
+	_, port, err := net.SplitHostPort(listen)
+	if err != nil {
+		return "", err
+	}
+	return net.JoinHostPort(ip, port), nil
+}
+
+// Leadership is the leader of a supervisor's replicas, as served on /leader.
+type Leadership struct {
+	// Leader is the identity of the leader, empty if none is known yet.
+	Leader string `json:"leader"`
+	// Identity is the identity of the replica answering.
+	Identity string `json:"identity"`
+	Leading  bool   `json:"leading"`
+}
+
+// newElector returns the LeaderElector of s, which reconciles the cluster
+// as soon as s starts leading and on every reconcile interval until it
+// stops.
+func (s *k8supervisor) newElector(handle func(error)) (*leaderelection.LeaderElector, error) {
+	e := s.election
+	if e.LeaseName == "" || e.Identity == "" {
+		return nil, fmt.Errorf("leader election needs a LeaseName and an Identity")
+	}
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Name:      e.LeaseName,
+			Namespace: apiv1.NamespaceDefault,
+		},
+		Client:     s.clientset.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{Identity: e.Identity},
+	}
+	orDefault := func(d, def time.Duration) time.Duration {
+		if d <= 0 {
+			return def
+		}
+		return d
+	}
+	return leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:          lock,
+		LeaseDuration: orDefault(e.LeaseDuration, defaultLeaseDuration),
+		RenewDeadline: orDefault(e.RenewDeadline, defaultRenewDeadline),
+		RetryPeriod:   orDefault(e.RetryPeriod, defaultRetryPeriod),
+		Name:          e.LeaseName,
+		// Let a follower take over right away on shutdown
+		ReleaseOnCancel: true,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				s.log.Infof("leading as %v", e.Identity)
+				s.setLeading(true)
+				// The cluster may have changed under another leader
+				s.reconcile(ctx, handle)
+				if s.reconcileInterval > 0 {
+					s.reconcileLoop(ctx, handle)
+				}
+			},
+			OnStoppedLeading: func() {
+				s.log.Infof("not leading")
+				s.setLeading(false)
+			},
+			OnNewLeader: func(identity string) {
+				s.log.Infof("new leader: %v", identity)
+				s.setLeader(identity)
+			},
+		},
+	})
+}
+
+// campaign stands for leader until ctx is done, standing again each time
+// s stops leading.
+func (s *k8supervisor) campaign(ctx context.Context, le *leaderelection.LeaderElector) {
+	for ctx.Err() == nil {
+		le.Run(ctx)
+	}
+}
+
+func (s *k8supervisor) setLeader(identity string) {
+	s.leaderMu.Lock()
+	defer s.leaderMu.Unlock()
+	s.leader = identity
+}
+
+func (s *k8supervisor) setLeading(leading bool) {
+	s.leaderMu.Lock()
+	defer s.leaderMu.Unlock()
+	s.leading = leading
+}
+
+func (s *k8supervisor) leadership() Leadership {
+	s.leaderMu.RLock()
+	defer s.leaderMu.RUnlock()
+	l := Leadership{Leader: s.leader, Leading: s.leading}
+	if s.election != nil {
+		l.Identity = s.election.Identity
+	}
+	return l
+}
+
+// follow proxies r to the leader if s takes part in an election and does
+// not lead it, and reports whether it did. Without a leader to proxy to, it
+// answers with the Leadership s knows of.
+func (s *k8supervisor) follow(w http.ResponseWriter, r *http.Request) bool {
+	if s.election == nil {
+		return false
+	}
+	l := s.leadership()
+	if l.Leading {
+		return false
+	}
+	if l.Leader == "" || l.Leader == l.Identity || r.Header.Get(proxiedHeader) != "" {
+		serveLeadership(w, http.StatusServiceUnavailable, l)
+		return true
+	}
+
+	s.log.Debugf("proxying %v to the leader %v", r.URL.Path, l.Leader)
+	p := httputil.NewSingleHostReverseProxy(&url.URL{Scheme: "http", Host: l.Leader})
+	p.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		s.log.Errorf("could not proxy %v to the leader %v: %v", r.URL.Path, l.Leader, err)
+		serveLeadership(w, http.StatusBadGateway, l)
+	}
+	r.Header.Set(proxiedHeader, l.Identity)
+	p.ServeHTTP(w, r)
+	return true
+}
+
+func serveLeadership(w http.ResponseWriter, code int, l Leadership) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(l)
+}
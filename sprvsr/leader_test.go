@@ -0,0 +1,229 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprvsr
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/devrel-services/repos"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type replica struct {
+	spr    *k8supervisor
+	srv    *httptest.Server
+	cancel context.CancelFunc
+}
+
+func startReplica(t *testing.T, clientSet *fake.Clientset) *replica {
+	srv := httptest.NewUnstartedServer(nil)
+	config := reconcileConfig()
+	config.LeaderElection = &LeaderElection{
+		LeaseName:     "testapp-sprvsr",
+		Identity:      srv.Listener.Addr().String(),
+		LeaseDuration: time.Second,
+		RenewDeadline: 500 * time.Millisecond,
+		RetryPeriod:   100 * time.Millisecond,
+	}
+	repoList := &fakeRepoList{
+		idx: -1,
+		RepoStack: [][]repos.TrackedRepository{
+			{
+				repos.TrackedRepository{Owner: "foo", Name: "bar", IsTrackingSamples: true, DefaultBranch: "main"},
+			},
+		},
+	}
+	spr, err := newK8sSupervisor(logrus.New(), clientSet, config, repoList, "testapp")
+	if err != nil {
+		t.Fatalf("Got an error making a new supervisor: %v", err)
+	}
+	handle := func(err error) { t.Errorf("Got an error updating: %v", err) }
+	le, err := spr.newElector(handle)
+	if err != nil {
+		t.Fatalf("Got an error making an elector: %v", err)
+	}
+
+	srv.Config.Handler = spr.router(handle)
+	srv.Start()
+	ctx, cancel := context.WithCancel(context.Background())
+	go spr.campaign(ctx, le)
+	return &replica{spr: spr, srv: srv, cancel: cancel}
+}
+
+func (r *replica) stop() {
+	r.cancel()
+	r.srv.Close()
+}
+
+func (r *replica) lastUpdate() time.Time {
+	r.spr.statusMu.Lock()
+	defer r.spr.statusMu.Unlock()
+	return r.spr.status.LastUpdate
+}
+
+func (r *replica) leader(t *testing.T) Leadership {
+	resp, err := http.Get(r.srv.URL + "/leader")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var l Leadership
+	if err := json.NewDecoder(resp.Body).Decode(&l); err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	deadline := time.Now().Add(10 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %v", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestLeaderElectionFailover(t *testing.T) {
+	clientSet := fake.NewSimpleClientset()
+
+	first := startReplica(t, clientSet)
+	defer first.stop()
+	waitFor(t, "the first replica to lead", func() bool {
+		return !first.lastUpdate().IsZero()
+	})
+
+	second := startReplica(t, clientSet)
+	defer second.stop()
+	waitFor(t, "the second replica to see the leader", func() bool {
+		return second.spr.leadership().Leader == first.srv.Listener.Addr().String()
+	})
+	want := Leadership{Leader: first.srv.Listener.Addr().String(), Identity: second.srv.Listener.Addr().String()}
+	if got := second.leader(t); got != want {
+		t.Errorf("Wanted %v Got %v", want, got)
+	}
+	if got := second.lastUpdate(); !got.IsZero() {
+		t.Errorf("Wanted the follower not to update. Got an update at %v", got)
+	}
+
+	// The follower proxies /update to the leader
+	before := first.lastUpdate()
+	resp, err := http.Get(second.srv.URL + "/update")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Wanted status %v Got %v", http.StatusOK, resp.StatusCode)
+	}
+	if got := first.lastUpdate(); !got.After(before) {
+		t.Errorf("Wanted the leader to update after %v. Got %v", before, got)
+	}
+	if got := second.lastUpdate(); !got.IsZero() {
+		t.Errorf("Wanted the follower not to update. Got an update at %v", got)
+	}
+
+	// The leader goes away and the follower takes over
+	first.stop()
+	waitFor(t, "the second replica to lead", func() bool {
+		return second.spr.leadership().Leading && !second.lastUpdate().IsZero()
+	})
+	want = Leadership{Leader: second.srv.Listener.Addr().String(), Identity: second.srv.Listener.Addr().String(), Leading: true}
+	if got := second.leader(t); got != want {
+		t.Errorf("Wanted %v Got %v", want, got)
+	}
+}
+
+func TestFollowerWithoutLeader(t *testing.T) {
+	config := reconcileConfig()
+	config.LeaderElection = &LeaderElection{LeaseName: "testapp-sprvsr", Identity: "10.0.0.1:8080"}
+	spr, err := newK8sSupervisor(logrus.New(), fake.NewSimpleClientset(), config, &fakeRepoList{idx: -1}, "testapp")
+	if err != nil {
+		t.Fatalf("Got an error making a new supervisor: %v", err)
+	}
+	srv := httptest.NewServer(spr.router(func(err error) { t.Errorf("Got an error updating: %v", err) }))
+	defer srv.Close()
+
+	cases := []struct {
+		Name   string
+		Leader string
+		Header string
+		Want   int
+	}{
+		{Name: "No leader yet", Want: http.StatusServiceUnavailable},
+		{Name: "Already proxied", Leader: "10.0.0.2:8080", Header: "10.0.0.3:8080", Want: http.StatusServiceUnavailable},
+		// Stale leadership of this replica
+		{Name: "Self", Leader: "10.0.0.1:8080", Want: http.StatusServiceUnavailable},
+		// Nothing listens on port 1
+		{Name: "Unreachable leader", Leader: "127.0.0.1:1", Want: http.StatusBadGateway},
+	}
+	for _, c := range cases {
+		spr.setLeader(c.Leader)
+		req, err := http.NewRequest("GET", srv.URL+"/update", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Header != "" {
+			req.Header.Set(proxiedHeader, c.Header)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var l Leadership
+		err = json.NewDecoder(resp.Body).Decode(&l)
+		resp.Body.Close()
+		if resp.StatusCode != c.Want {
+			t.Errorf("Test: %v Wanted status %v Got %v", c.Name, c.Want, resp.StatusCode)
+		}
+		if err != nil || l.Leader != c.Leader {
+			t.Errorf("Test: %v Wanted leader %v Got %v, %v", c.Name, c.Leader, l.Leader, err)
+		}
+	}
+}
+
+func TestPodIdentity(t *testing.T) {
+	cases := []struct {
+		Name    string
+		PodIP   string
+		Listen  string
+		Want    string
+		WantErr bool
+	}{
+		{Name: "Any host", PodIP: "10.0.0.1", Listen: ":6343", Want: "10.0.0.1:6343"},
+		{Name: "IPv6", PodIP: "fd00::1", Listen: "0.0.0.0:6343", Want: "[fd00::1]:6343"},
+		{Name: "No POD_IP", Listen: ":6343", WantErr: true},
+		{Name: "No port", PodIP: "10.0.0.1", Listen: "localhost", WantErr: true},
+	}
+	for _, c := range cases {
+		os.Setenv("POD_IP", c.PodIP)
+		got, err := PodIdentity(c.Listen)
+		if (err != nil) != c.WantErr {
+			t.Errorf("Test: %v Wanted error %v Got %v", c.Name, c.WantErr, err)
+		}
+		if got != c.Want {
+			t.Errorf("Test: %v Wanted %v Got %v", c.Name, c.Want, got)
+		}
+	}
+	os.Unsetenv("POD_IP")
+}
@@ -0,0 +1,545 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprvsr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/GoogleCloudPlatform/devrel-services/repos"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/negroni"
+)
+
+// Ensure localSupervisor is Supervisor
+var _ Supervisor = &localSupervisor{}
+
+const (
+	// How long a process has to exit after being interrupted before it
+	// is killed.
+	defaultStopTimeout = 10 * time.Second
+	// Bounds of the delay before a process that exited is restarted. The
+	// delay doubles every time the process exits without running for
+	// maxRestartDelay.
+	minRestartDelay = time.Second
+	maxRestartDelay = time.Minute
+)
+
+type localSupervisor struct {
+	mu  sync.Mutex
+	log *logrus.Logger
+
+	processNamer   ProcessNamer
+	processBuilder ProcessBuilder
+	processPrep    DeploymentPrep
+	processCheck   DeploymentCheck
+	portNames      []string
+	addressFile    string
+	stopTimeout    time.Duration
+
+	// The list of repositories to track
+	repoList repos.RepoList
+
+	// The running processes, by name
+	procs map[string]*process
+
+	// The status served on /status. Guarded by statusMu so it can be read
+	// during an update.
+	statusMu sync.Mutex
+	status   Status
+}
+
+// ProcessNamer is called to determine what to name a process Given a
+// TrackedRepository. Names must be unique per repository.
+type ProcessNamer func(repos.TrackedRepository) (string, error)
+
+// ProcessBuilder builds the command to run for the given TrackedRepository.
+// ports maps the names in LocalConfiguration.Ports to the ports allocated
+// for it to listen on. It is called again each time the process is
+// restarted, with the same ports.
+type ProcessBuilder func(tr repos.TrackedRepository, ports map[string]int) (*exec.Cmd, error)
+
+// LocalConfiguration is a struct to describe the set of operations
+// a local supervisor needs to manage processes
+type LocalConfiguration struct {
+	ProcessNamer   ProcessNamer
+	ProcessBuilder ProcessBuilder
+	PreDeploy      DeploymentPrep
+	ShouldDeploy   DeploymentCheck
+	// Ports names the ports each process listens on, e.g. "http" and
+	// "internal". A free port is allocated for each.
+	Ports []string
+	// AddressFile, if set, is kept up to date with the address of each
+	// port of each process, keyed by repository then port name. It can be
+	// given to the routers' static resolver.
+	AddressFile string
+	// StopTimeout is how long a process has to exit after being
+	// interrupted before it is killed. Defaults to 10 seconds.
+	StopTimeout time.Duration
+}
+
+// process is a running child process and how to run it again.
+type process struct {
+	tr    repos.TrackedRepository
+	ports map[string]int
+	// spec is the command line of the process. A process whose
+	// TrackedRepository now builds another one is replaced.
+	spec string
+
+	mu       sync.Mutex
+	cmd      *exec.Cmd
+	stopping bool
+	running  bool
+	// restarts is the number of times the process was restarted.
+	restarts int32
+	// done is closed when the process exits for good.
+	done chan struct{}
+}
+
+// NewLocalSupervisor creates a new supervisor that runs a child process on
+// the local machine for each repository
+func NewLocalSupervisor(log *logrus.Logger, lconfig LocalConfiguration, rl repos.RepoList) (Supervisor, error) {
+	return newLocalSupervisor(log, lconfig, rl)
+}
+
+func newLocalSupervisor(log *logrus.Logger, lconfig LocalConfiguration, rl repos.RepoList) (*localSupervisor, error) {
+	if lconfig.ProcessNamer == nil || lconfig.ProcessBuilder == nil {
+		return nil, fmt.Errorf("a local supervisor needs a ProcessNamer and a ProcessBuilder")
+	}
+	prep := lconfig.PreDeploy
+	if prep == nil {
+		prep = func(repos.TrackedRepository) error { return nil }
+	}
+	check := lconfig.ShouldDeploy
+	if check == nil {
+		check = func(repos.TrackedRepository) bool { return true }
+	}
+	timeout := lconfig.StopTimeout
+	if timeout <= 0 {
+		timeout = defaultStopTimeout
+	}
+	return &localSupervisor{
+		log:            log,
+		processNamer:   lconfig.ProcessNamer,
+		processBuilder: lconfig.ProcessBuilder,
+		processPrep:    prep,
+		processCheck:   check,
+		portNames:      lconfig.Ports,
+		addressFile:    lconfig.AddressFile,
+		stopTimeout:    timeout,
+		repoList:       rl,
+		procs:          make(map[string]*process),
+	}, nil
+}
+
+// Supervise registers an http server on the given address
+// and error handler. Processes are started and stopped to match
+// the tracked repositories with the /update route. Every process
+// is stopped when the supervisor is interrupted.
+func (s *localSupervisor) Supervise(address string, handle func(error)) error {
+	go s.updateProcesses(context.Background(), handle)
+
+	s.log.Debugf("creating router")
+	// Send everything through Mux
+	r := mux.NewRouter()
+
+	s.log.Debugf("handling update")
+	r.HandleFunc("/update", func(w http.ResponseWriter, r *http.Request) {
+		s.updateProcesses(r.Context(), handle)
+	}).Methods("GET", "POST")
+
+	s.log.Debugf("handling status")
+	r.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		serveStatus(w, s.currentStatus())
+	}).Methods("GET")
+
+	s.log.Debugf("handling healthz")
+	r.HandleFunc("/_healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		w.Write([]byte("ok"))
+	})
+
+	// Add middleware support
+	n := negroni.New()
+	l := negroni.NewLogger()
+	n.Use(l)
+	n.Use(negroni.NewRecovery())
+	n.UseHandler(r)
+
+	srv := &http.Server{Addr: address, Handler: n}
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+	go func() {
+		sig := <-sigs
+		s.log.Infof("received %v. stopping processes", sig)
+		srv.Shutdown(context.Background())
+	}()
+
+	err := srv.ListenAndServe()
+	s.stopAll()
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
+
+func (s *localSupervisor) updateProcesses(ctx context.Context, handle func(error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var errs []string
+	report := handle
+	handle = func(err error) {
+		errs = append(errs, err.Error())
+		report(err)
+	}
+	defer func() {
+		s.statusMu.Lock()
+		s.status.LastUpdate = now
+		s.status.LastResult = result(errs)
+		s.statusMu.Unlock()
+	}()
+
+	changed, err := s.repoList.UpdateTrackedRepos(ctx)
+	if err != nil {
+		handle(err)
+		return
+	}
+	if !changed {
+		s.log.Debug("skipping updating processes. unchanged")
+		return
+	}
+
+	// The processes the tracked repositories should have, keyed by name
+	// and command line like the running ones.
+	trSet := make(map[string]repos.TrackedRepository)
+	names := make(map[repos.TrackedRepository]string)
+	for _, tr := range s.repoList.GetTrackedRepos() {
+		if !s.processCheck(tr) {
+			continue
+		}
+		name, err := s.processNamer(tr)
+		if err != nil {
+			handle(err)
+			return
+		}
+		spec := ""
+		if p, ok := s.procs[name]; ok {
+			// Build with the ports it has, to compare command lines.
+			cmd, err := s.processBuilder(tr, p.ports)
+			if err != nil {
+				handle(err)
+				return
+			}
+			spec = cmdSpec(cmd)
+		}
+		trSet[name+"\x00"+spec] = tr
+		names[tr] = name
+	}
+
+	procSet := make(map[string]repos.TrackedRepository)
+	for name, p := range s.procs {
+		procSet[name+"\x00"+p.spec] = p.tr
+	}
+
+	s.log.Debugf("have processes: %v. want: %v", len(procSet), len(trSet))
+
+	// A process whose repository is new has no spec yet: it is neither
+	// stopped nor started twice.
+	toStop := setDifference(procSet, trSet)
+	s.log.Debugf("have processes to stop: %v", toStop)
+	for td := range toStop {
+		for name, p := range s.procs {
+			if p.tr == td {
+				s.stop(p)
+				delete(s.procs, name)
+			}
+		}
+	}
+
+	toStart := setDifference(trSet, procSet)
+	s.log.Debugf("have processes to start: %v", toStart)
+	for ta := range toStart {
+		name := names[ta]
+		if _, ok := s.procs[name]; ok {
+			continue
+		}
+		if err := s.processPrep(ta); err != nil {
+			handle(err)
+			continue
+		}
+		p, err := s.start(ta, name, handle)
+		if err != nil {
+			handle(err)
+			continue
+		}
+		s.procs[name] = p
+	}
+
+	if err := s.writeAddresses(); err != nil {
+		handle(err)
+	}
+}
+
+// start allocates the ports of a process for ta and starts it.
+func (s *localSupervisor) start(ta repos.TrackedRepository, name string, handle func(error)) (*process, error) {
+	ports, err := freePorts(len(s.portNames))
+	if err != nil {
+		return nil, err
+	}
+	p := &process{
+		tr:    ta,
+		ports: make(map[string]int, len(s.portNames)),
+		done:  make(chan struct{}),
+	}
+	for i, pn := range s.portNames {
+		p.ports[pn] = ports[i]
+	}
+	cmd, err := s.processBuilder(ta, p.ports)
+	if err != nil {
+		return nil, err
+	}
+	p.spec = cmdSpec(cmd)
+	if err := startCmd(cmd); err != nil {
+		return nil, err
+	}
+	p.cmd = cmd
+	p.running = true
+	s.log.Infof("started process %v for %v. pid: %v ports: %v", name, ta, cmd.Process.Pid, p.ports)
+
+	go s.watch(p, name, handle)
+	return p, nil
+}
+
+// watch restarts p each time it exits, until it is stopped.
+func (s *localSupervisor) watch(p *process, name string, handle func(error)) {
+	defer close(p.done)
+	delay := minRestartDelay
+	for {
+		p.mu.Lock()
+		cmd := p.cmd
+		p.mu.Unlock()
+
+		started := time.Now()
+		err := cmd.Wait()
+
+		p.mu.Lock()
+		p.running = false
+		if p.stopping {
+			p.mu.Unlock()
+			return
+		}
+		p.mu.Unlock()
+
+		handle(fmt.Errorf("process %v for %v exited: %v", name, p.tr, err))
+		if time.Since(started) > maxRestartDelay {
+			delay = minRestartDelay
+		}
+
+		for {
+			time.Sleep(delay)
+			if delay *= 2; delay > maxRestartDelay {
+				delay = maxRestartDelay
+			}
+
+			p.mu.Lock()
+			if p.stopping {
+				p.mu.Unlock()
+				return
+			}
+			cmd, err = s.processBuilder(p.tr, p.ports)
+			if err == nil {
+				err = startCmd(cmd)
+			}
+			if err == nil {
+				p.cmd = cmd
+				p.running = true
+				p.restarts++
+			}
+			p.mu.Unlock()
+
+			if err == nil {
+				s.log.Infof("restarted process %v for %v. pid: %v", name, p.tr, cmd.Process.Pid)
+				break
+			}
+			handle(fmt.Errorf("restarting process %v for %v: %v", name, p.tr, err))
+		}
+	}
+}
+
+// stop interrupts p and kills it if it has not exited after the stop
+// timeout. It returns once p has exited.
+func (s *localSupervisor) stop(p *process) {
+	p.mu.Lock()
+	p.stopping = true
+	if p.cmd.ProcessState == nil {
+		p.cmd.Process.Signal(os.Interrupt)
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-p.done:
+	case <-time.After(s.stopTimeout):
+		p.mu.Lock()
+		p.cmd.Process.Kill()
+		p.mu.Unlock()
+		<-p.done
+	}
+	s.log.Infof("stopped process for %v", p.tr)
+}
+
+// currentStatus returns the status of the last update and of the running
+// processes.
+func (s *localSupervisor) currentStatus() *Status {
+	s.statusMu.Lock()
+	st := s.status
+	s.statusMu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st.PendingDeletions = make([]*PendingDeletion, 0)
+	st.Workloads = make([]*Workload, 0, len(s.procs))
+	for name, p := range s.procs {
+		w := &Workload{
+			Repository: p.tr.String(),
+			Tracked:    true,
+			Process:    name,
+			Images:     make([]string, 0),
+			Replicas:   1,
+		}
+		p.mu.Lock()
+		if p.running {
+			w.ReadyReplicas = 1
+		}
+		w.Restarts = p.restarts
+		p.mu.Unlock()
+		st.Workloads = append(st.Workloads, w)
+	}
+	sortWorkloads(st.Workloads)
+	return &st
+}
+
+func (s *localSupervisor) stopAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for name, p := range s.procs {
+		wg.Add(1)
+		go func(p *process) {
+			defer wg.Done()
+			s.stop(p)
+		}(p)
+		delete(s.procs, name)
+	}
+	wg.Wait()
+	if err := s.writeAddresses(); err != nil {
+		s.log.Error(err)
+	}
+}
+
+// writeAddresses writes the address of each port of each process to the
+// address file. The file is JSON, which the YAML static resolver also
+// reads.
+func (s *localSupervisor) writeAddresses() error {
+	if s.addressFile == "" {
+		return nil
+	}
+	addrs := make(map[string]map[string]string, len(s.procs))
+	for _, p := range s.procs {
+		ports := make(map[string]string, len(p.ports))
+		for pn, port := range p.ports {
+			ports[pn] = net.JoinHostPort("localhost", fmt.Sprint(port))
+		}
+		addrs[p.tr.String()] = ports
+	}
+	b, err := json.MarshalIndent(addrs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.addressFile, b)
+}
+
+// writeFileAtomic writes b to a temporary file then renames it to path, so
+// readers never see half a file.
+func writeFileAtomic(path string, b []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// startCmd starts cmd, sending its output to ours unless it goes elsewhere.
+func startCmd(cmd *exec.Cmd) error {
+	if cmd.Stdout == nil {
+		cmd.Stdout = os.Stdout
+	}
+	if cmd.Stderr == nil {
+		cmd.Stderr = os.Stderr
+	}
+	return cmd.Start()
+}
+
+// cmdSpec returns the command line of cmd.
+func cmdSpec(cmd *exec.Cmd) string {
+	return strings.Join(append([]string{cmd.Path}, cmd.Args...), " ")
+}
+
+// freePorts returns n distinct ports that are free on the local machine.
+func freePorts(n int) ([]int, error) {
+	ports := make([]int, 0, n)
+	lns := make([]net.Listener, 0, n)
+	defer func() {
+		for _, ln := range lns {
+			ln.Close()
+		}
+	}()
+	// Hold each port open until all are allocated, so none repeats.
+	for i := 0; i < n; i++ {
+		ln, err := net.Listen("tcp", "localhost:0")
+		if err != nil {
+			return nil, err
+		}
+		lns = append(lns, ln)
+		ports = append(ports, ln.Addr().(*net.TCPAddr).Port)
+	}
+	return ports, nil
+}
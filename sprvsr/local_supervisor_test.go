@@ -0,0 +1,184 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprvsr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/devrel-services/repos"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TestHelperProcess is the process the local supervisor runs in tests. It
+// waits to be interrupted.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("SPRVSR_HELPER_PROCESS") != "1" {
+		return
+	}
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt)
+	<-sigs
+	os.Exit(0)
+}
+
+func helperBuilder(tr repos.TrackedRepository, ports map[string]int) (*exec.Cmd, error) {
+	cmd := exec.Command(os.Args[0], "-test.run=TestHelperProcess", "--",
+		fmt.Sprintf("--owner=%v", tr.Owner),
+		fmt.Sprintf("--repo=%v", tr.Name),
+		fmt.Sprintf("--branch=%v", tr.DefaultBranch),
+		fmt.Sprintf("--listen=:%v", ports["grpc"]),
+	)
+	cmd.Env = append(os.Environ(), "SPRVSR_HELPER_PROCESS=1")
+	cmd.Stdout = ioutil.Discard
+	cmd.Stderr = ioutil.Discard
+	return cmd, nil
+}
+
+func TestLocalUpdateStartsAndStopsProcesses(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sprvsr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	addrFile := filepath.Join(dir, "addresses.yaml")
+
+	config := LocalConfiguration{
+		ProcessNamer: func(a repos.TrackedRepository) (string, error) {
+			return fmt.Sprintf("p-%v", a.RepoSha()), nil
+		},
+		ProcessBuilder: helperBuilder,
+		ShouldDeploy:   func(tr repos.TrackedRepository) bool { return tr.IsTrackingSamples },
+		Ports:          []string{"grpc", "internal"},
+		AddressFile:    addrFile,
+		StopTimeout:    5 * time.Second,
+	}
+	foo := repos.TrackedRepository{Owner: "foo", Name: "bar", IsTrackingSamples: true, DefaultBranch: "main"}
+	beep := repos.TrackedRepository{Owner: "beep", Name: "boop", IsTrackingSamples: true, DefaultBranch: "main"}
+	skipped := repos.TrackedRepository{Owner: "beep", Name: "blarp", IsTrackingSamples: false}
+	fooMaster := foo
+	fooMaster.DefaultBranch = "master"
+
+	repoList := &fakeRepoList{
+		idx: -1,
+		RepoStack: [][]repos.TrackedRepository{
+			{foo, beep, skipped},
+			{foo, beep, skipped},
+			// beep is removed and foo's command line changes
+			{fooMaster, skipped},
+		},
+	}
+
+	spr, err := newLocalSupervisor(logrus.New(), config, repoList)
+	if err != nil {
+		t.Fatalf("Got an error making a new supervisor: %v", err)
+	}
+	defer spr.stopAll()
+
+	ctx := context.Background()
+	handle := func(err error) { t.Errorf("Got an error updating processes: %v", err) }
+
+	spr.updateProcesses(ctx, handle)
+	if len(spr.procs) != 2 {
+		t.Fatalf("Wanted %v processes. Got %v", 2, len(spr.procs))
+	}
+	pids := make(map[string]int)
+	for name, p := range spr.procs {
+		pids[name] = p.cmd.Process.Pid
+		if len(p.ports) != 2 || p.ports["grpc"] == p.ports["internal"] {
+			t.Errorf("Wanted 2 distinct ports. Got %v", p.ports)
+		}
+	}
+
+	addrs := readAddresses(t, addrFile)
+	if len(addrs) != 2 || addrs["foo/bar"]["grpc"] == "" || addrs["beep/boop"]["internal"] == "" {
+		t.Errorf("Wanted the addresses of foo/bar and beep/boop. Got %v", addrs)
+	}
+
+	// Unchanged: the processes keep running.
+	spr.updateProcesses(ctx, handle)
+	for name, p := range spr.procs {
+		if p.cmd.Process.Pid != pids[name] {
+			t.Errorf("Did not expect process %v to be restarted", name)
+		}
+	}
+
+	spr.updateProcesses(ctx, handle)
+	if len(spr.procs) != 1 {
+		t.Fatalf("Wanted %v processes. Got %v", 1, len(spr.procs))
+	}
+	for name, p := range spr.procs {
+		if p.tr != fooMaster {
+			t.Errorf("Wanted a process for %v. Got %v", fooMaster, p.tr)
+		}
+		if p.cmd.Process.Pid == pids[name] {
+			t.Errorf("Wanted process %v to be restarted with its new command line", name)
+		}
+	}
+
+	st := spr.currentStatus()
+	if st.LastResult != "ok" || len(st.Workloads) != 1 {
+		t.Fatalf("Wanted an ok status with 1 workload. Got %v %v", st.LastResult, st.Workloads)
+	}
+	if w := st.Workloads[0]; w.Repository != "foo/bar" || !w.Tracked || w.Process == "" || w.ReadyReplicas != 1 {
+		t.Errorf("Wanted a running process for foo/bar. Got %+v", w)
+	}
+
+	addrs = readAddresses(t, addrFile)
+	if _, ok := addrs["beep/boop"]; ok || len(addrs) != 1 {
+		t.Errorf("Wanted only the addresses of foo/bar. Got %v", addrs)
+	}
+
+	spr.stopAll()
+	if addrs := readAddresses(t, addrFile); len(addrs) != 0 {
+		t.Errorf("Wanted no addresses after stopping. Got %v", addrs)
+	}
+}
+
+func readAddresses(t *testing.T, path string) map[string]map[string]string {
+	t.Helper()
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Got an error reading the address file: %v", err)
+	}
+	addrs := make(map[string]map[string]string)
+	if err := json.Unmarshal(b, &addrs); err != nil {
+		t.Fatalf("Got an error parsing the address file: %v", err)
+	}
+	return addrs
+}
+
+func TestFreePortsAreDistinct(t *testing.T) {
+	ports, err := freePorts(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[int]bool)
+	for _, p := range ports {
+		if p == 0 || seen[p] {
+			t.Errorf("Wanted 5 distinct ports. Got %v", ports)
+		}
+		seen[p] = true
+	}
+}
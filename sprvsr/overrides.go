@@ -0,0 +1,78 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprvsr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/devrel-services/repos"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// ImageWithTag returns image with its tag or digest replaced by tag. It
+// returns image unchanged if tag is empty.
+func ImageWithTag(image, tag string) string {
+	if tag == "" {
+		return image
+	}
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	// A colon before the last slash separates a registry's port
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image + ":" + tag
+}
+
+// WithResources returns defaults with the resources set in r overriding
+// them. It returns an error if a quantity of r does not parse.
+func WithResources(defaults apiv1.ResourceRequirements, r repos.Resources) (apiv1.ResourceRequirements, error) {
+	ret := apiv1.ResourceRequirements{
+		Requests: apiv1.ResourceList{},
+		Limits:   apiv1.ResourceList{},
+	}
+	for k, v := range defaults.Requests {
+		ret.Requests[k] = v
+	}
+	for k, v := range defaults.Limits {
+		ret.Limits[k] = v
+	}
+
+	overrides := []struct {
+		list  apiv1.ResourceList
+		name  apiv1.ResourceName
+		value string
+	}{
+		{ret.Requests, apiv1.ResourceCPU, r.CPURequest},
+		{ret.Requests, apiv1.ResourceMemory, r.MemoryRequest},
+		{ret.Limits, apiv1.ResourceCPU, r.CPULimit},
+		{ret.Limits, apiv1.ResourceMemory, r.MemoryLimit},
+	}
+	for _, o := range overrides {
+		if o.value == "" {
+			continue
+		}
+		q, err := resource.ParseQuantity(o.value)
+		if err != nil {
+			return ret, fmt.Errorf("parsing %v %q: %v", o.name, o.value, err)
+		}
+		o.list[o.name] = q
+	}
+	return ret, nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprvsr
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/devrel-services/repos"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestImageWithTag(t *testing.T) {
+	cases := []struct {
+		Name  string
+		Image string
+		Tag   string
+		Want  string
+	}{
+		{
+			Name:  "No tag keeps the image",
+			Image: "gcr.io/foo/maintnerd:latest",
+			Want:  "gcr.io/foo/maintnerd:latest",
+		},
+		{
+			Name:  "Replaces the tag",
+			Image: "gcr.io/foo/maintnerd:latest",
+			Tag:   "v1.2",
+			Want:  "gcr.io/foo/maintnerd:v1.2",
+		},
+		{
+			Name:  "Adds a tag",
+			Image: "gcr.io/foo/maintnerd",
+			Tag:   "v1.2",
+			Want:  "gcr.io/foo/maintnerd:v1.2",
+		},
+		{
+			Name:  "Keeps the registry port",
+			Image: "localhost:5000/maintnerd",
+			Tag:   "v1.2",
+			Want:  "localhost:5000/maintnerd:v1.2",
+		},
+		{
+			Name:  "Replaces a digest",
+			Image: "gcr.io/foo/maintnerd@sha256:abcd",
+			Tag:   "v1.2",
+			Want:  "gcr.io/foo/maintnerd:v1.2",
+		},
+	}
+	for _, c := range cases {
+		if got := ImageWithTag(c.Image, c.Tag); got != c.Want {
+			t.Errorf("Test: %v Wanted %v Got %v", c.Name, c.Want, got)
+		}
+	}
+}
+
+func TestWithResources(t *testing.T) {
+	defaults := apiv1.ResourceRequirements{
+		Requests: apiv1.ResourceList{
+			apiv1.ResourceCPU:    resource.MustParse("50m"),
+			apiv1.ResourceMemory: resource.MustParse("160M"),
+		},
+		Limits: apiv1.ResourceList{
+			apiv1.ResourceMemory: resource.MustParse("2G"),
+		},
+	}
+
+	got, err := WithResources(defaults, repos.Resources{MemoryRequest: "1G", MemoryLimit: "8G"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"requests.cpu":    "50m",
+		"requests.memory": "1G",
+		"limits.memory":   "8G",
+	}
+	have := map[string]string{}
+	for k, v := range got.Requests {
+		have["requests."+string(k)] = v.String()
+	}
+	for k, v := range got.Limits {
+		have["limits."+string(k)] = v.String()
+	}
+	if len(have) != len(want) {
+		t.Errorf("Wanted %v Got %v", want, have)
+	}
+	for k, v := range want {
+		if have[k] != v {
+			t.Errorf("Wanted %v to be %v Got %v", k, v, have[k])
+		}
+	}
+	if q := defaults.Requests[apiv1.ResourceMemory]; q.String() != "160M" {
+		t.Errorf("Did not expect the defaults to change. Got %v", q.String())
+	}
+
+	if _, err := WithResources(defaults, repos.Resources{CPULimit: "lots"}); err == nil {
+		t.Errorf("Wanted an error for a bad quantity")
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprvsr
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/GoogleCloudPlatform/devrel-services/repos"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+// The actions of a Change.
+const (
+	ActionCreate = "create"
+	// ActionUpdate changes an existing object in place.
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// Plan is the set of changes an update would make to the cluster.
+type Plan struct {
+	// Deployments are the Deployments to create, update or delete,
+	// ordered by name.
+	Deployments []*Change `json:"deployments"`
+	// Services are the Services to create, update or delete, ordered by
+	// name.
+	Services []*Change `json:"services"`
+
+	// The number of Deployments the supervisor manages
+	managed int
+}
+
+// Change is a Deployment or Service to create, update or delete.
+type Change struct {
+	Action     string `json:"action"`
+	Name       string `json:"name"`
+	Repository string `json:"repository"`
+	// Diff is the fields the change sets, for creates and updates.
+	Diff []*FieldDiff `json:"diff,omitempty"`
+
+	tr         repos.TrackedRepository
+	deployment *appsv1.Deployment
+	service    *apiv1.Service
+}
+
+// FieldDiff is a field of an object and its values before and after a
+// Change. Old is nil for fields the object does not have yet.
+type FieldDiff struct {
+	Path string      `json:"path"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new"`
+}
+
+// Empty reports whether p changes nothing.
+func (p *Plan) Empty() bool {
+	return len(p.Deployments) == 0 && len(p.Services) == 0
+}
+
+// String summarizes p.
+func (p *Plan) String() string {
+	count := func(cs []*Change) map[string]int {
+		n := make(map[string]int)
+		for _, c := range cs {
+			n[c.Action]++
+		}
+		return n
+	}
+	d, s := count(p.Deployments), count(p.Services)
+	return fmt.Sprintf("deployments: %v to create, %v to update, %v to delete. services: %v to create, %v to update, %v to delete",
+		d[ActionCreate], d[ActionUpdate], d[ActionDelete], s[ActionCreate], s[ActionUpdate], s[ActionDelete])
+}
+
+func sortChanges(cs []*Change) {
+	sort.Slice(cs, func(i, j int) bool {
+		if cs[i].Name != cs[j].Name {
+			return cs[i].Name < cs[j].Name
+		}
+		return cs[i].Repository < cs[j].Repository
+	})
+}
+
+// diffFields returns the fields set in desired whose value in existing,
+// which may be nil, differs. Fields desired leaves unset are not compared,
+// so those the cluster fills in are not reported.
+func diffFields(existing, desired interface{}) ([]*FieldDiff, error) {
+	have, err := toGeneric(existing)
+	if err != nil {
+		return nil, err
+	}
+	want, err := toGeneric(desired)
+	if err != nil {
+		return nil, err
+	}
+	diffs := make([]*FieldDiff, 0)
+	walkDiff("", have, want, &diffs)
+	return diffs, nil
+}
+
+// toGeneric returns v as the maps, slices and values it encodes to in JSON.
+func toGeneric(v interface{}) (interface{}, error) {
+	if v == nil || reflect.ValueOf(v).IsNil() {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var g interface{}
+	if err := json.Unmarshal(b, &g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func walkDiff(path string, have, want interface{}, diffs *[]*FieldDiff) {
+	switch n := want.(type) {
+	case nil:
+		return
+	case map[string]interface{}:
+		o, _ := have.(map[string]interface{})
+		keys := make([]string, 0, len(n))
+		for k := range n {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			walkDiff(p, o[k], n[k], diffs)
+		}
+	case []interface{}:
+		o, _ := have.([]interface{})
+		for i := range n {
+			var oi interface{}
+			if i < len(o) {
+				oi = o[i]
+			}
+			walkDiff(fmt.Sprintf("%v[%v]", path, i), oi, n[i], diffs)
+		}
+	default:
+		if !reflect.DeepEqual(have, want) {
+			*diffs = append(*diffs, &FieldDiff{Path: path, Old: have, New: want})
+		}
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprvsr
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// reconcileLoop reconciles the cluster every s.reconcileInterval, and as
+// soon as a Deployment or Service of the supervisor is deleted, until ctx is
+// done.
+func (s *k8supervisor) reconcileLoop(ctx context.Context, handle func(error)) {
+	deleted := make(chan struct{}, 1)
+	if err := s.watchDeletions(ctx, deleted); err != nil {
+		handle(err)
+	}
+
+	t := time.NewTicker(s.reconcileInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			s.log.Debug("reconciling on schedule")
+		case <-deleted:
+			s.log.Info("reconciling after a deletion")
+		}
+		s.reconcile(ctx, handle)
+	}
+}
+
+// watchDeletions sends on deleted, without blocking, each time a Deployment
+// or Service with the label of the supervisor is deleted, until ctx is done.
+func (s *k8supervisor) watchDeletions(ctx context.Context, deleted chan<- struct{}) error {
+	ns := apiv1.NamespaceDefault
+	opts := metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%v=%v", s.labelgenkey, labelgenvalue),
+	}
+	watches := []func() (watch.Interface, error){
+		func() (watch.Interface, error) { return s.clientset.AppsV1().Deployments(ns).Watch(opts) },
+		func() (watch.Interface, error) { return s.clientset.CoreV1().Services(ns).Watch(opts) },
+	}
+	for _, start := range watches {
+		w, err := start()
+		if err != nil {
+			return err
+		}
+		go s.forwardDeletions(ctx, w, start, deleted)
+	}
+	return nil
+}
+
+// forwardDeletions sends on deleted for each deletion w reports, starting
+// the watch again when the API server ends it.
+func (s *k8supervisor) forwardDeletions(ctx context.Context, w watch.Interface, start func() (watch.Interface, error), deleted chan<- struct{}) {
+	for {
+		select {
+		case <-ctx.Done():
+			w.Stop()
+			return
+		case ev, ok := <-w.ResultChan():
+			if ok {
+				if ev.Type == watch.Deleted {
+					select {
+					case deleted <- struct{}{}:
+					default:
+					}
+				}
+				continue
+			}
+		}
+
+		// The watch ended. Start it again, after a while if it fails
+		for {
+			var err error
+			if w, err = start(); err == nil {
+				break
+			}
+			s.log.Errorf("could not watch for deletions: %v", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(s.reconcileInterval):
+			}
+		}
+	}
+}
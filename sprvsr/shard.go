@@ -0,0 +1,336 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprvsr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/GoogleCloudPlatform/devrel-services/repos"
+
+	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ShardMapKey is the key of the ShardMap in the ConfigMap of a ConfigMap
+// ShardStore.
+const ShardMapKey = "shards.json"
+
+// defaultShardSlack is how much heavier than the average shard, as a
+// fraction, a shard may grow before repositories are moved off it.
+const defaultShardSlack = 0.2
+
+// Weigher returns how much of a shard a repository takes, e.g. its number
+// of issues or the size of its snapshot.
+type Weigher func(repos.TrackedRepository) (int64, error)
+
+// ShardStore keeps the ShardMap where the routers read it.
+type ShardStore interface {
+	// Load returns the ShardMap saved last, or nil if there is none.
+	Load() (*repos.ShardMap, error)
+	Save(*repos.ShardMap) error
+}
+
+// ShardConfiguration describes how a ShardedRepoList packs repositories
+// into shards.
+type ShardConfiguration struct {
+	// Shards is the number of shards to pack the repositories into.
+	Shards int
+	// Weigher weighs each repository. Defaults to weighing each 1, which
+	// packs the shards by count.
+	Weigher Weigher
+	// ShouldShard reports whether a repository is served by a shard.
+	// Defaults to every repository.
+	ShouldShard DeploymentCheck
+	// Slack is how much heavier than the average shard, as a fraction, a
+	// shard may grow before repositories are moved off it. Defaults to
+	// 0.2. More slack moves fewer repositories as weights change.
+	Slack float64
+	// Store, if set, is where the ShardMap is saved for the routers, and
+	// loaded from on start so a restart does not reshuffle the shards.
+	Store ShardStore
+}
+
+// ShardedRepoList is a RepoList of shards. It packs the repositories of
+// another RepoList into a fixed number of shards, each standing in for its
+// repositories as a TrackedRepository with the Owner repos.ShardOwner, so a
+// supervisor runs one Deployment or process per shard. Repositories keep
+// their shard while it is not too heavy, so adding or removing one moves
+// few others.
+type ShardedRepoList struct {
+	rl      repos.RepoList
+	n       int
+	weigher Weigher
+	check   DeploymentCheck
+	slack   float64
+	store   ShardStore
+
+	mu      sync.RWMutex
+	m       *repos.ShardMap
+	shards  []repos.TrackedRepository
+	members map[repos.TrackedRepository][]repos.TrackedRepository
+	// Whether the ShardMap is yet to be saved to the store
+	unsaved bool
+}
+
+// Ensure ShardedRepoList is a RepoList
+var _ repos.RepoList = &ShardedRepoList{}
+
+// NewShardedRepoList returns a ShardedRepoList packing the repositories of
+// rl.
+func NewShardedRepoList(rl repos.RepoList, scfg ShardConfiguration) (*ShardedRepoList, error) {
+	if scfg.Shards < 1 {
+		return nil, fmt.Errorf("sharding needs at least 1 shard. got %v", scfg.Shards)
+	}
+	weigher := scfg.Weigher
+	if weigher == nil {
+		weigher = func(repos.TrackedRepository) (int64, error) { return 1, nil }
+	}
+	check := scfg.ShouldShard
+	if check == nil {
+		check = func(repos.TrackedRepository) bool { return true }
+	}
+	slack := scfg.Slack
+	if slack <= 0 {
+		slack = defaultShardSlack
+	}
+	return &ShardedRepoList{
+		rl:      rl,
+		n:       scfg.Shards,
+		weigher: weigher,
+		check:   check,
+		slack:   slack,
+		store:   scfg.Store,
+		shards:  make([]repos.TrackedRepository, 0),
+		members: make(map[repos.TrackedRepository][]repos.TrackedRepository),
+	}, nil
+}
+
+// UpdateTrackedRepos updates the underlying RepoList and packs its
+// repositories again. It returns true if the repositories of a shard
+// changed. The ShardMap is saved before the shards are redeployed, so a
+// repository moved to another shard may be briefly unavailable.
+func (s *ShardedRepoList) UpdateTrackedRepos(ctx context.Context) (bool, error) {
+	changed, err := s.rl.UpdateTrackedRepos(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !changed && s.m != nil && !s.unsaved {
+		return false, nil
+	}
+
+	prev := s.m
+	if prev == nil && s.store != nil {
+		if prev, err = s.store.Load(); err != nil {
+			return false, err
+		}
+	}
+
+	trs := make([]repos.TrackedRepository, 0)
+	weights := make(map[string]int64)
+	for _, tr := range s.rl.GetTrackedRepos() {
+		if !s.check(tr) {
+			continue
+		}
+		w, err := s.weigher(tr)
+		if err != nil {
+			return false, fmt.Errorf("weighing %v: %v", tr, err)
+		}
+		trs = append(trs, tr)
+		weights[tr.String()] = w
+	}
+
+	m := assignShards(trs, weights, s.n, prev, s.slack)
+	members := make(map[repos.TrackedRepository][]repos.TrackedRepository)
+	for _, tr := range trs {
+		shard := repos.ShardRepository(m.Assignments[tr.String()])
+		members[shard] = append(members[shard], tr)
+	}
+	shards := make([]repos.TrackedRepository, 0, len(members))
+	for i := 0; i < s.n; i++ {
+		shard := repos.ShardRepository(i)
+		if trs, ok := members[shard]; ok {
+			sort.Slice(trs, func(i, j int) bool { return trs[i].String() < trs[j].String() })
+			shards = append(shards, shard)
+		}
+	}
+
+	changed = s.m == nil || !reflect.DeepEqual(members, s.members)
+	s.m, s.shards, s.members = m, shards, members
+	if s.store != nil && (changed || s.unsaved) {
+		if err := s.store.Save(m); err != nil {
+			s.unsaved = true
+			return false, fmt.Errorf("saving the shard map: %v", err)
+		}
+		s.unsaved = false
+	}
+	return changed, nil
+}
+
+// GetTrackedRepos returns the TrackedRepository of each shard serving at
+// least one repository.
+func (s *ShardedRepoList) GetTrackedRepos() []repos.TrackedRepository {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.shards
+}
+
+// Members returns the repositories of the shard standing as shard, and
+// false if it is not a shard.
+func (s *ShardedRepoList) Members(shard repos.TrackedRepository) ([]repos.TrackedRepository, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	trs, ok := s.members[shard]
+	return trs, ok
+}
+
+// assignShards packs trs into n shards. Repositories keep their shard in
+// prev, heaviest first, while it weighs no more than slack over the
+// average. The others go to the lightest shard, heaviest first.
+func assignShards(trs []repos.TrackedRepository, weights map[string]int64, n int, prev *repos.ShardMap, slack float64) *repos.ShardMap {
+	names := make([]string, 0, len(trs))
+	total := int64(0)
+	for _, tr := range trs {
+		names = append(names, tr.String())
+		total += weights[tr.String()]
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if weights[names[i]] != weights[names[j]] {
+			return weights[names[i]] > weights[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	capacity := float64(total) / float64(n) * (1 + slack)
+
+	m := &repos.ShardMap{Shards: n, Assignments: make(map[string]int, len(names))}
+	load := make([]int64, n)
+	unplaced := make([]string, 0)
+	for _, name := range names {
+		w := weights[name]
+		if prev != nil && prev.Shards == n {
+			// A repository heavier than the capacity keeps an empty shard
+			if i, ok := prev.Assignments[name]; ok && i >= 0 && i < n && (load[i] == 0 || float64(load[i]+w) <= capacity) {
+				m.Assignments[name] = i
+				load[i] += w
+				continue
+			}
+		}
+		unplaced = append(unplaced, name)
+	}
+	for _, name := range unplaced {
+		lightest := 0
+		for i := range load {
+			if load[i] < load[lightest] {
+				lightest = i
+			}
+		}
+		m.Assignments[name] = lightest
+		load[lightest] += weights[name]
+	}
+	return m
+}
+
+// NewFileShardStore returns a ShardStore keeping the ShardMap in the JSON
+// file at path, for routers on the same machine.
+func NewFileShardStore(path string) ShardStore {
+	return &fileShardStore{path: path}
+}
+
+type fileShardStore struct {
+	path string
+}
+
+func (f *fileShardStore) Load() (*repos.ShardMap, error) {
+	m, err := repos.LoadShardMap(f.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return m, err
+}
+
+func (f *fileShardStore) Save(m *repos.ShardMap) error {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(f.path, b)
+}
+
+// NewConfigMapShardStore returns a ShardStore keeping the ShardMap under
+// ShardMapKey in the ConfigMap name in the namespace ns, creating it if
+// needed. Routers read it by mounting the ConfigMap.
+func NewConfigMapShardStore(cs kubernetes.Interface, ns, name string) ShardStore {
+	return &configMapShardStore{cs: cs, ns: ns, name: name}
+}
+
+type configMapShardStore struct {
+	cs   kubernetes.Interface
+	ns   string
+	name string
+}
+
+func (c *configMapShardStore) Load() (*repos.ShardMap, error) {
+	cm, err := c.cs.CoreV1().ConfigMaps(c.ns).Get(c.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, ok := cm.Data[ShardMapKey]
+	if !ok {
+		return nil, nil
+	}
+	m := &repos.ShardMap{}
+	if err := json.Unmarshal([]byte(data), m); err != nil {
+		return nil, fmt.Errorf("parsing ConfigMap %v: %v", c.name, err)
+	}
+	return m, nil
+}
+
+func (c *configMapShardStore) Save(m *repos.ShardMap) error {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	cms := c.cs.CoreV1().ConfigMaps(c.ns)
+	cm, err := cms.Get(c.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = cms.Create(&apiv1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: c.name},
+			Data:       map[string]string{ShardMapKey: string(b)},
+		})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if cm.Data == nil {
+		cm.Data = make(map[string]string)
+	}
+	cm.Data[ShardMapKey] = string(b)
+	_, err = cms.Update(cm)
+	return err
+}
@@ -0,0 +1,186 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprvsr
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/devrel-services/repos"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func shardTestRepos(n int) ([]repos.TrackedRepository, map[string]int64) {
+	trs := make([]repos.TrackedRepository, 0, n)
+	weights := make(map[string]int64)
+	for i := 0; i < n; i++ {
+		tr := repos.TrackedRepository{Owner: "foo", Name: fmt.Sprintf("repo-%v", i)}
+		trs = append(trs, tr)
+		// A few heavy repositories and many light ones
+		weights[tr.String()] = int64(1 + (i%5)*(i%5)*10)
+	}
+	return trs, weights
+}
+
+func shardLoads(m *repos.ShardMap, weights map[string]int64) []int64 {
+	load := make([]int64, m.Shards)
+	for name, i := range m.Assignments {
+		load[i] += weights[name]
+	}
+	return load
+}
+
+func TestAssignShardsBalances(t *testing.T) {
+	trs, weights := shardTestRepos(100)
+	m := assignShards(trs, weights, 4, nil, defaultShardSlack)
+
+	if len(m.Assignments) != len(trs) {
+		t.Errorf("Wanted %v repositories assigned. Got %v", len(trs), len(m.Assignments))
+	}
+	total := int64(0)
+	for _, w := range weights {
+		total += w
+	}
+	for i, l := range shardLoads(m, weights) {
+		if float64(l) > float64(total)/4*(1+defaultShardSlack) {
+			t.Errorf("Wanted shard %v to weigh about %v. Got %v", i, total/4, l)
+		}
+	}
+}
+
+func TestAssignShardsIsStable(t *testing.T) {
+	trs, weights := shardTestRepos(100)
+	m := assignShards(trs, weights, 4, nil, defaultShardSlack)
+
+	cases := []struct {
+		Name  string
+		Repos []repos.TrackedRepository
+	}{
+		{
+			Name:  "Unchanged",
+			Repos: trs,
+		},
+		{
+			Name:  "Repository added",
+			Repos: append(append([]repos.TrackedRepository{}, trs...), repos.TrackedRepository{Owner: "foo", Name: "new"}),
+		},
+		{
+			Name:  "Repository removed",
+			Repos: trs[1:],
+		},
+	}
+	for _, c := range cases {
+		got := assignShards(c.Repos, weights, 4, m, defaultShardSlack)
+		moved := 0
+		for name, i := range got.Assignments {
+			if j, ok := m.Assignments[name]; ok && i != j {
+				moved++
+			}
+		}
+		if moved != 0 {
+			t.Errorf("Test: %v Wanted no repositories moved. Got %v", c.Name, moved)
+		}
+	}
+
+	// Changing the number of shards packs them again
+	got := assignShards(trs, weights, 5, m, defaultShardSlack)
+	if got.Shards != 5 {
+		t.Errorf("Wanted %v shards. Got %v", 5, got.Shards)
+	}
+	for _, l := range shardLoads(got, weights) {
+		if l == 0 {
+			t.Errorf("Wanted every shard to be used. Got %v", shardLoads(got, weights))
+		}
+	}
+}
+
+func TestShardedRepoList(t *testing.T) {
+	foo := repos.TrackedRepository{Owner: "foo", Name: "bar", IsTrackingSamples: true}
+	baz := repos.TrackedRepository{Owner: "baz", Name: "biz", IsTrackingSamples: true}
+	beep := repos.TrackedRepository{Owner: "beep", Name: "boop", IsTrackingSamples: true}
+	skipped := repos.TrackedRepository{Owner: "beep", Name: "blarp", IsTrackingSamples: false}
+	repoList := &fakeRepoList{
+		idx: -1,
+		RepoStack: [][]repos.TrackedRepository{
+			{foo, baz, skipped},
+			{foo, baz, skipped},
+			{foo, baz, beep, skipped},
+		},
+	}
+	clientSet := fake.NewSimpleClientset()
+	store := NewConfigMapShardStore(clientSet, apiv1.NamespaceDefault, "shards")
+
+	srl, err := NewShardedRepoList(repoList, ShardConfiguration{
+		Shards:      2,
+		ShouldShard: func(tr repos.TrackedRepository) bool { return tr.IsTrackingSamples },
+		Store:       store,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if changed, err := srl.UpdateTrackedRepos(ctx); err != nil || !changed {
+		t.Fatalf("Wanted the first update to change the shards. Got %v, %v", changed, err)
+	}
+	shards := srl.GetTrackedRepos()
+	if len(shards) != 2 {
+		t.Fatalf("Wanted %v shards. Got %v", 2, shards)
+	}
+	nmembers := 0
+	for _, shard := range shards {
+		if shard.Owner != repos.ShardOwner {
+			t.Errorf("Wanted a shard. Got %v", shard)
+		}
+		members, ok := srl.Members(shard)
+		if !ok {
+			t.Errorf("Wanted the members of %v", shard)
+		}
+		nmembers += len(members)
+	}
+	if nmembers != 2 {
+		t.Errorf("Wanted %v repositories in the shards. Got %v", 2, nmembers)
+	}
+
+	saved, err := store.Load()
+	if err != nil || saved == nil {
+		t.Fatalf("Wanted the shard map to be saved. Got %v, %v", saved, err)
+	}
+	if _, ok := saved.Assignments["beep/blarp"]; ok || len(saved.Assignments) != 2 {
+		t.Errorf("Wanted foo/bar and baz/biz to be assigned. Got %v", saved.Assignments)
+	}
+	fooShard, ok := saved.Shard("FOO", "bar")
+	if !ok {
+		t.Errorf("Wanted foo/bar to be served by a shard")
+	}
+
+	if changed, err := srl.UpdateTrackedRepos(ctx); err != nil || changed {
+		t.Errorf("Wanted the shards to be unchanged. Got %v, %v", changed, err)
+	}
+
+	if changed, err := srl.UpdateTrackedRepos(ctx); err != nil || !changed {
+		t.Errorf("Wanted a new repository to change the shards. Got %v, %v", changed, err)
+	}
+	saved, _ = store.Load()
+	if got, _ := saved.Shard("foo", "bar"); got != fooShard {
+		t.Errorf("Wanted foo/bar to stay on %v. Got %v", fooShard, got)
+	}
+	if _, ok := saved.Shard("beep", "boop"); !ok {
+		t.Errorf("Wanted beep/boop to be served by a shard")
+	}
+}
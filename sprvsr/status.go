@@ -0,0 +1,181 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprvsr
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Status is the state of a supervisor and of the workloads it manages. It
+// is served as JSON on /status.
+type Status struct {
+	// LastUpdate is when the cluster was last reconciled.
+	LastUpdate time.Time `json:"last_update"`
+	// LastResult is "ok", or the errors of the last reconcile.
+	LastResult string `json:"last_result"`
+	// Blocked is why the deletions of the last update were blocked, if
+	// they were.
+	Blocked string `json:"blocked,omitempty"`
+	// PendingDeletions are the deletions waiting for the grace period.
+	PendingDeletions []*PendingDeletion `json:"pending_deletions"`
+	// Workloads are the workloads of each repository, ordered by
+	// repository.
+	Workloads []*Workload `json:"workloads"`
+}
+
+// PendingDeletion is a Deployment or Service that will be deleted once its
+// grace period is over, unless its repository is tracked again.
+type PendingDeletion struct {
+	Kind       string    `json:"kind"`
+	Name       string    `json:"name"`
+	Repository string    `json:"repository"`
+	Due        time.Time `json:"due"`
+}
+
+// Workload is what a supervisor runs for a repository.
+type Workload struct {
+	Repository string `json:"repository"`
+	// Tracked is false for the workloads of repositories no longer
+	// tracked.
+	Tracked bool `json:"tracked"`
+	// Deployment and Service are the names of the objects of the
+	// repository, empty if it has none.
+	Deployment string `json:"deployment,omitempty"`
+	Service    string `json:"service,omitempty"`
+	// Process is the name of the process of the repository, for
+	// supervisors running them locally.
+	Process       string   `json:"process,omitempty"`
+	Images        []string `json:"images"`
+	Replicas      int32    `json:"replicas"`
+	ReadyReplicas int32    `json:"ready_replicas"`
+	// Restarts is the number of times the containers or process of the
+	// repository restarted.
+	Restarts int32 `json:"restarts"`
+}
+
+// result returns the LastResult of a reconcile with errs.
+func result(errs []string) string {
+	if len(errs) == 0 {
+		return "ok"
+	}
+	return strings.Join(errs, "; ")
+}
+
+func sortWorkloads(ws []*Workload) {
+	sort.Slice(ws, func(i, j int) bool { return ws[i].Repository < ws[j].Repository })
+}
+
+// serveStatus writes st as JSON.
+func serveStatus(w http.ResponseWriter, st *Status) {
+	b, err := json.Marshal(st)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(b)
+}
+
+// currentStatus returns the status of the last reconcile and of the
+// Deployments, Services and Pods with the label of the supervisor now.
+func (s *k8supervisor) currentStatus() (*Status, error) {
+	s.statusMu.Lock()
+	st := s.status
+	tracked := s.tracked
+	s.statusMu.Unlock()
+
+	ns := apiv1.NamespaceDefault
+	opts := metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%v=%v", s.labelgenkey, labelgenvalue),
+	}
+
+	workloads := make(map[string]*Workload)
+	workload := func(labels map[string]string) *Workload {
+		owner, ok := labels["owner"]
+		if !ok {
+			return nil
+		}
+		repo, ok := labels["repository"]
+		if !ok {
+			return nil
+		}
+		name := fmt.Sprintf("%v/%v", owner, repo)
+		w, ok := workloads[name]
+		if !ok {
+			w = &Workload{Repository: name, Tracked: tracked[name], Images: make([]string, 0)}
+			workloads[name] = w
+		}
+		return w
+	}
+	for name := range tracked {
+		workloads[name] = &Workload{Repository: name, Tracked: true, Images: make([]string, 0)}
+	}
+
+	deployments, err := s.clientset.AppsV1().Deployments(ns).List(opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range deployments.Items {
+		w := workload(d.Labels)
+		if w == nil {
+			continue
+		}
+		w.Deployment = d.Name
+		for _, c := range d.Spec.Template.Spec.Containers {
+			w.Images = append(w.Images, c.Image)
+		}
+		w.Replicas = d.Status.Replicas
+		w.ReadyReplicas = d.Status.ReadyReplicas
+	}
+
+	services, err := s.clientset.CoreV1().Services(ns).List(opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, svc := range services.Items {
+		if w := workload(svc.Labels); w != nil {
+			w.Service = svc.Name
+		}
+	}
+
+	pods, err := s.clientset.CoreV1().Pods(ns).List(opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range pods.Items {
+		w := workload(pod.Labels)
+		if w == nil {
+			continue
+		}
+		for _, cs := range pod.Status.ContainerStatuses {
+			w.Restarts += cs.RestartCount
+		}
+	}
+
+	st.Workloads = make([]*Workload, 0, len(workloads))
+	for _, w := range workloads {
+		st.Workloads = append(st.Workloads, w)
+	}
+	sortWorkloads(st.Workloads)
+	return &st, nil
+}
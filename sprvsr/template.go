@@ -0,0 +1,193 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprvsr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/GoogleCloudPlatform/devrel-services/repos"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// TemplateConfiguration configures the builders NewTemplateBuilders returns.
+type TemplateConfiguration struct {
+	// Deployment and Service are the text/template sources of the YAML of
+	// the Deployment and Service of a repository, rendered with a
+	// TemplateData
+	Deployment string
+	Service    string
+
+	DeploymentNamer DeploymentNamer
+	ServiceNamer    ServiceNamer
+	// Values returns what the supervisor knows of a repository that the
+	// templates may use as .Values, e.g. its image or command line. May be
+	// nil
+	Values func(repos.TrackedRepository) (map[string]interface{}, error)
+}
+
+// TemplateData is what the templates of a repository are rendered with.
+type TemplateData struct {
+	Repository repos.TrackedRepository
+	// The names of the Deployment and Service of Repository
+	Deployment string
+	Service    string
+	Values     map[string]interface{}
+}
+
+// templateFuncs are the functions the templates may call besides the
+// text/template builtins.
+var templateFuncs = template.FuncMap{
+	// json renders a value as JSON, which is also YAML, e.g. a command
+	// line or a quoted string
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"lower":        strings.ToLower,
+	"imageWithTag": ImageWithTag,
+}
+
+// NewTemplateBuilders returns the DeploymentBuilder and ServiceBuilder
+// rendering the templates of cfg, so what the supervisor deploys can change
+// without rebuilding it. A rendered object must not have fields its type
+// does not know, must be named by the namers, and is checked for the
+// fields the supervisor relies on.
+func NewTemplateBuilders(cfg TemplateConfiguration) (DeploymentBuilder, ServiceBuilder, error) {
+	if cfg.DeploymentNamer == nil || cfg.ServiceNamer == nil {
+		return nil, nil, fmt.Errorf("templates need a DeploymentNamer and a ServiceNamer")
+	}
+	dtmpl, err := template.New("deployment").Funcs(templateFuncs).Option("missingkey=error").Parse(cfg.Deployment)
+	if err != nil {
+		return nil, nil, err
+	}
+	stmpl, err := template.New("service").Funcs(templateFuncs).Option("missingkey=error").Parse(cfg.Service)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data := func(tr repos.TrackedRepository) (TemplateData, error) {
+		d := TemplateData{Repository: tr, Values: map[string]interface{}{}}
+		var err error
+		if d.Deployment, err = cfg.DeploymentNamer(tr); err != nil {
+			return d, err
+		}
+		if d.Service, err = cfg.ServiceNamer(tr); err != nil {
+			return d, err
+		}
+		if cfg.Values != nil {
+			if d.Values, err = cfg.Values(tr); err != nil {
+				return d, err
+			}
+		}
+		return d, nil
+	}
+
+	bd := func(tr repos.TrackedRepository) (*appsv1.Deployment, error) {
+		d, err := data(tr)
+		if err != nil {
+			return nil, err
+		}
+		dep := &appsv1.Deployment{}
+		if err := render(dtmpl, d, dep); err != nil {
+			return nil, fmt.Errorf("deployment of %v: %v", tr, err)
+		}
+		if err := validateDeployment(dep, d.Deployment); err != nil {
+			return nil, fmt.Errorf("deployment of %v: %v", tr, err)
+		}
+		return dep, nil
+	}
+	bs := func(tr repos.TrackedRepository) (*apiv1.Service, error) {
+		d, err := data(tr)
+		if err != nil {
+			return nil, err
+		}
+		svc := &apiv1.Service{}
+		if err := render(stmpl, d, svc); err != nil {
+			return nil, fmt.Errorf("service of %v: %v", tr, err)
+		}
+		if err := validateService(svc, d.Service); err != nil {
+			return nil, fmt.Errorf("service of %v: %v", tr, err)
+		}
+		return svc, nil
+	}
+	return bd, bs, nil
+}
+
+// render executes t with d and decodes the YAML it renders into o.
+func render(t *template.Template, d TemplateData, o interface{}) error {
+	var b bytes.Buffer
+	if err := t.Execute(&b, d); err != nil {
+		return err
+	}
+	return yaml.UnmarshalStrict(b.Bytes(), o)
+}
+
+func validateDeployment(d *appsv1.Deployment, name string) error {
+	if err := validateMeta(&d.TypeMeta, &d.ObjectMeta, "apps/v1", "Deployment", name); err != nil {
+		return err
+	}
+	if d.Spec.Selector == nil || len(d.Spec.Selector.MatchLabels) == 0 {
+		return fmt.Errorf("spec.selector.matchLabels is empty")
+	}
+	for k, v := range d.Spec.Selector.MatchLabels {
+		if d.Spec.Template.Labels[k] != v {
+			return fmt.Errorf("spec.template.metadata.labels do not match the selector %v=%v", k, v)
+		}
+	}
+	if len(d.Spec.Template.Spec.Containers) == 0 {
+		return fmt.Errorf("spec.template.spec.containers is empty")
+	}
+	for i, c := range d.Spec.Template.Spec.Containers {
+		if c.Name == "" || c.Image == "" {
+			return fmt.Errorf("container %v needs a name and an image", i)
+		}
+	}
+	return nil
+}
+
+func validateService(s *apiv1.Service, name string) error {
+	if err := validateMeta(&s.TypeMeta, &s.ObjectMeta, "v1", "Service", name); err != nil {
+		return err
+	}
+	if len(s.Spec.Ports) == 0 {
+		return fmt.Errorf("spec.ports is empty")
+	}
+	return nil
+}
+
+// validateMeta checks the kind and name of a rendered object. The kind is
+// then cleared, as the builders in Go leave it unset and the objects read
+// back from the cluster do not have it, so it would be seen as drift.
+func validateMeta(t *metav1.TypeMeta, m *metav1.ObjectMeta, apiVersion, kind, name string) error {
+	if t.APIVersion != "" && t.APIVersion != apiVersion {
+		return fmt.Errorf("apiVersion is %q, want %q", t.APIVersion, apiVersion)
+	}
+	if t.Kind != "" && t.Kind != kind {
+		return fmt.Errorf("kind is %q, want %q", t.Kind, kind)
+	}
+	if m.Name != name {
+		return fmt.Errorf("named %q, want %q", m.Name, name)
+	}
+	*t = metav1.TypeMeta{}
+	return nil
+}
@@ -0,0 +1,238 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprvsr
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/devrel-services/repos"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const testDeploymentTemplate = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{.Deployment}}
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: {{.Deployment}}
+  template:
+    metadata:
+      labels:
+        app: {{.Deployment}}
+    spec:
+      containers:
+      - name: worker
+        image: {{imageWithTag "gcr.io/foo/worker:latest" .Repository.ImageTag}}
+        command: {{json .Values.Command}}
+        env:
+        - name: OWNER
+          value: {{json .Repository.Owner}}
+        resources:
+          limits:
+            memory: {{or .Repository.Resources.MemoryLimit "1G"}}
+`
+
+const testServiceTemplate = `apiVersion: v1
+kind: Service
+metadata:
+  name: {{.Service}}
+spec:
+  selector:
+    app: {{.Deployment}}
+  ports:
+  - name: http
+    port: 80
+    targetPort: 80
+`
+
+func templateConfig() TemplateConfiguration {
+	return TemplateConfiguration{
+		Deployment: testDeploymentTemplate,
+		Service:    testServiceTemplate,
+		DeploymentNamer: func(a repos.TrackedRepository) (string, error) {
+			return fmt.Sprintf("d-%v-%v", a.Owner, a.Name), nil
+		},
+		ServiceNamer: func(a repos.TrackedRepository) (string, error) {
+			return fmt.Sprintf("s-%v-%v", a.Owner, a.Name), nil
+		},
+		Values: func(a repos.TrackedRepository) (map[string]interface{}, error) {
+			return map[string]interface{}{
+				"Command": append([]string{"/worker", "--repo=" + a.Name}, a.Flags()...),
+			}, nil
+		},
+	}
+}
+
+func wantDeployment(name, image, memory string, command ...string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: int32Ptr(1),
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": name},
+			},
+			Template: apiv1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"app": name},
+				},
+				Spec: apiv1.PodSpec{
+					Containers: []apiv1.Container{
+						{
+							Name:    "worker",
+							Image:   image,
+							Command: command,
+							Env:     []apiv1.EnvVar{{Name: "OWNER", Value: "foo"}},
+							Resources: apiv1.ResourceRequirements{
+								Limits: apiv1.ResourceList{
+									apiv1.ResourceMemory: resource.MustParse(memory),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestTemplateBuilders(t *testing.T) {
+	bd, bs, err := NewTemplateBuilders(templateConfig())
+	if err != nil {
+		t.Fatalf("Got an error making the builders: %v", err)
+	}
+
+	withFlags := func(tr repos.TrackedRepository, flags ...string) repos.TrackedRepository {
+		tr.SetFlags(flags)
+		return tr
+	}
+
+	cases := []struct {
+		Name string
+		Repo repos.TrackedRepository
+		Want *appsv1.Deployment
+	}{
+		{
+			Name: "Defaults",
+			Repo: repos.TrackedRepository{Owner: "foo", Name: "bar"},
+			Want: wantDeployment("d-foo-bar", "gcr.io/foo/worker:latest", "1G", "/worker", "--repo=bar"),
+		},
+		{
+			Name: "Overrides",
+			Repo: withFlags(repos.TrackedRepository{
+				Owner:     "foo",
+				Name:      "baz",
+				ImageTag:  "v2",
+				Resources: repos.Resources{MemoryLimit: "6G"},
+			}, "--verbose", "--label=needs triage"),
+			Want: wantDeployment("d-foo-baz", "gcr.io/foo/worker:v2", "6G", "/worker", "--repo=baz", "--verbose", "--label=needs triage"),
+		},
+	}
+	for _, c := range cases {
+		got, err := bd(c.Repo)
+		if err != nil {
+			t.Errorf("Test: %v Got an error building the deployment: %v", c.Name, err)
+			continue
+		}
+		if diff := cmp.Diff(c.Want, got); diff != "" {
+			t.Errorf("Test: %v Deployments differ (-want +got)\n%s", c.Name, diff)
+		}
+	}
+
+	want := &apiv1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "s-foo-bar"},
+		Spec: apiv1.ServiceSpec{
+			Selector: map[string]string{"app": "d-foo-bar"},
+			Ports: []apiv1.ServicePort{
+				{Name: "http", Port: 80, TargetPort: intstr.FromInt(80)},
+			},
+		},
+	}
+	got, err := bs(repos.TrackedRepository{Owner: "foo", Name: "bar"})
+	if err != nil {
+		t.Fatalf("Got an error building the service: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Services differ (-want +got)\n%s", diff)
+	}
+}
+
+func TestTemplateBuildersRejectInvalidTemplates(t *testing.T) {
+	const service = "metadata: {name: {{.Service}}}\nspec: {ports: [{port: 80}]}\n"
+	const deployment = `metadata: {name: {{.Deployment}}}
+spec:
+  selector: {matchLabels: {app: a}}
+  template:
+    metadata: {labels: {app: a}}
+    spec: {containers: [{name: c, image: i}]}
+`
+	cases := []struct {
+		Name       string
+		Deployment string
+		Service    string
+		WantErr    bool
+	}{
+		{Name: "Valid", Deployment: deployment, Service: service},
+		{Name: "Bad template", Deployment: "{{.Deployment", Service: service, WantErr: true},
+		{Name: "Bad YAML", Deployment: "metadata: [", Service: service, WantErr: true},
+		{Name: "Unknown field", Deployment: deployment + "replicaz: 2\n", Service: service, WantErr: true},
+		{Name: "Missing value", Deployment: "metadata: {name: {{.Values.Image}}}", Service: service, WantErr: true},
+		{Name: "Wrong kind", Deployment: "kind: Service\n" + deployment, Service: service, WantErr: true},
+		{Name: "Wrong name", Deployment: "metadata: {name: foo}", Service: service, WantErr: true},
+		{
+			Name:       "Selector does not match",
+			Deployment: "metadata: {name: {{.Deployment}}}\nspec: {selector: {matchLabels: {app: a}}, template: {spec: {containers: [{name: c, image: i}]}}}",
+			Service:    service,
+			WantErr:    true,
+		},
+		{
+			Name:       "No containers",
+			Deployment: "metadata: {name: {{.Deployment}}}\nspec: {selector: {matchLabels: {app: a}}, template: {metadata: {labels: {app: a}}}}",
+			Service:    service,
+			WantErr:    true,
+		},
+		{Name: "No ports", Deployment: deployment, Service: "metadata: {name: {{.Service}}}", WantErr: true},
+	}
+	for _, c := range cases {
+		cfg := templateConfig()
+		cfg.Deployment = c.Deployment
+		cfg.Service = c.Service
+		cfg.Values = nil
+		err := func() error {
+			bd, bs, err := NewTemplateBuilders(cfg)
+			if err != nil {
+				return err
+			}
+			tr := repos.TrackedRepository{Owner: "foo", Name: "bar"}
+			if _, err := bd(tr); err != nil {
+				return err
+			}
+			_, err = bs(tr)
+			return err
+		}()
+		if (err != nil) != c.WantErr {
+			t.Errorf("Test: %v Wanted error %v Got %v", c.Name, c.WantErr, err)
+		}
+	}
+}